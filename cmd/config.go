@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/Bermos/Platform/internal/config"
+	"github.com/danielgtaylor/huma/v2/humacli"
+	"github.com/spf13/cobra"
+)
+
+// loadConfig layers the flags that were set explicitly on root over the
+// configuration file and environment, and validates the result.
+func loadConfig(opts *Options, root *cobra.Command) (*config.Config, error) {
+	cfg, err := config.Load(opts.Config, os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := root.PersistentFlags()
+	if flags.Changed("debug") && opts.Debug {
+		cfg.Logging.Level = "debug"
+	}
+	if flags.Changed("host") {
+		cfg.Server.Host = opts.Host
+	}
+	if flags.Changed("port") {
+		cfg.Server.Port = opts.Port
+	}
+	if flags.Changed("database") {
+		cfg.Storage.Driver = config.StorageSQLite
+		cfg.Storage.Path = opts.Database
+		if opts.Database == "" {
+			cfg.Storage.Driver = config.StorageMemory
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// newLogger creates the default logger described by cfg.
+func newLogger(cfg config.LoggingConfig) *slog.Logger {
+	var level slog.Level
+	// Validate has already restricted the level to known names.
+	_ = level.UnmarshalText([]byte(cfg.Level))
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	if cfg.Format == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, handlerOpts))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, handlerOpts))
+}
+
+// newConfigCommand returns the `config` command for inspecting settings.
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	}
+
+	var effective bool
+	printCmd := &cobra.Command{
+		Use:   "print",
+		Short: "Print the default or effective configuration as YAML, with secrets redacted",
+		Args:  cobra.NoArgs,
+		Run: humacli.WithOptions(func(cmd *cobra.Command, args []string, opts *Options) {
+			cfg := config.Default()
+			if effective {
+				var err error
+				if cfg, err = loadConfig(opts, cmd.Root()); err != nil {
+					fmt.Fprintln(cmd.ErrOrStderr(), err)
+					os.Exit(1)
+				}
+			}
+
+			b, err := cfg.YAML()
+			if err != nil {
+				fmt.Fprintln(cmd.ErrOrStderr(), "Error:", err)
+				os.Exit(1)
+			}
+			cmd.OutOrStdout().Write(b)
+		}),
+	}
+	printCmd.Flags().BoolVar(&effective, "effective", false, "Print the merged configuration from file, environment and flags instead of the defaults")
+	cmd.AddCommand(printCmd)
+
+	return cmd
+}
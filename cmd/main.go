@@ -4,67 +4,277 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/Bermos/Platform/internal"
 	v1 "github.com/Bermos/Platform/internal/api/v1"
 	"github.com/Bermos/Platform/internal/app"
+	"github.com/Bermos/Platform/internal/config"
+	"github.com/Bermos/Platform/internal/database/memory"
+	"github.com/Bermos/Platform/internal/database/sqlite"
+	"github.com/Bermos/Platform/internal/jobs"
+	"github.com/Bermos/Platform/internal/prometheus"
+	"github.com/Bermos/Platform/internal/resource"
+	_ "github.com/Bermos/Platform/internal/resource/all"
+	tfmodule "github.com/Bermos/Platform/internal/resource/terraform-module"
+	"github.com/Bermos/Platform/internal/terraform"
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humago"
 	"github.com/danielgtaylor/huma/v2/humacli"
 	"github.com/spf13/cobra"
 	"log/slog"
+	"net"
 	"net/http"
-	"time"
+	"os"
+	"slices"
+	"sync"
 )
 
+// Options are the command-line flags. They override the configuration file
+// and environment, see internal/config.
 type Options struct {
-	Debug bool   `doc:"Enable debug logging"`
-	Host  string `doc:"Hostname to listen on."`
-	Port  int    `doc:"Port to listen on." short:"p" default:"8080"`
+	Config   string `doc:"Path to a YAML configuration file." short:"c"`
+	Debug    bool   `doc:"Enable debug logging"`
+	Host     string `doc:"Hostname to listen on."`
+	Port     int    `doc:"Port to listen on." short:"p"`
+	Database string `doc:"Path to a SQLite database file. State is kept in memory when empty."`
 }
 
-func main() {
-	mux := http.NewServeMux()
+// newAPI registers all routes for a on mux, behind the admin token when
+// auth is enabled.
+func newAPI(mux *http.ServeMux, a *app.App, auth config.AuthConfig) huma.API {
 	api := humago.New(mux, huma.DefaultConfig("Platform", "1.0.0"))
-
-	a := app.NewApp()
+	if auth.Enabled {
+		v1.RequireAdminToken(api, auth.AdminToken)
+	}
 	v1.Register(api, a)
+	return api
+}
+
+// newCatalog registers the configured Terraform modules and offers the
+// configured resource types from the registry.
+func newCatalog(cfg *config.Config) (*resource.Catalog, error) {
+	for _, m := range cfg.Integrations.Terraform.Modules {
+		if err := tfmodule.Register(resource.DefaultRegistry, m.Type, m.Source); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make([]resource.CatalogEntry, len(cfg.Resources))
+	for i, res := range cfg.Resources {
+		entries[i] = resource.CatalogEntry{Type: res.Type, Version: res.Version, Settings: res.Settings}
+	}
+	return resource.DefaultRegistry.Catalog(entries)
+}
+
+// isModule reports whether res is provisioned with Terraform.
+func isModule(res resource.Resource) bool {
+	_, ok := res.(*tfmodule.Resource)
+	return ok
+}
 
-	// Then, create the CLI.
-	cli := humacli.New(func(hooks humacli.Hooks, opts *Options) {
-		fmt.Printf("I was run with debug:%v host:%v port%v\n",
-			opts.Debug, opts.Host, opts.Port)
+// terraformOptions set up Terraform for the catalog's module resources.
+// The workspaces share downloaded providers and keep their state in the
+// API's state backend. Drift is only detected for module resources, as
+// no provider is configured for the others.
+func terraformOptions(cfg *config.Config) ([]app.Option, error) {
+	tf := cfg.Integrations.Terraform
+	pluginCache, err := terraform.NewWorkspaces(tf.WorkDir).PluginCache()
+	if err != nil {
+		return nil, err
+	}
+	cli := terraform.NewCLI(tf.Binary, terraform.CLIOptions{Env: []string{"TF_PLUGIN_CACHE_DIR=" + pluginCache}})
+	return []app.Option{
+		app.WithTerraform(cli, tf.WorkDir),
+		app.WithStateBackend(cfg.Server.LocalURL()),
+		app.WithWorkspaceSweep(tf.SweepInterval),
+		app.WithDriftDetection(cfg.Drift.Interval),
+	}, nil
+}
+
+// newJobQueue creates the background job queue on repo.
+func newJobQueue(cfg config.JobsConfig, repo jobs.Repository) *jobs.Queue {
+	return jobs.NewQueue(repo, jobs.Options{
+		Workers:     cfg.Workers,
+		Timeout:     cfg.Timeout,
+		MaxAttempts: cfg.MaxAttempts,
+		Backoff:     cfg.Backoff,
+		MaxBackoff:  cfg.MaxBackoff,
+	})
+}
+
+// newApp creates the application with the configured resource catalog and
+// job queue, backed by SQLite when configured. It fails when that
+// database's schema does not match this binary. The returned function
+// releases the storage.
+func newApp(ctx context.Context, cfg *config.Config) (*app.App, func() error, error) {
+	catalog, err := newCatalog(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	instance := &internal.Instance{
+		Name:      "Platform",
+		Catalog:   catalog,
+		Namespace: cfg.Integrations.Kubernetes.Namespace,
+	}
+	appOpts := []app.Option{app.WithInstance(instance)}
+	if slices.ContainsFunc(catalog.Resources(), isModule) {
+		tfOpts, err := terraformOptions(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		appOpts = append(appOpts, tfOpts...)
+	}
+	if prom := cfg.Integrations.Prometheus; prom.URL != "" {
+		client, err := prometheus.New(prom.URL, prometheus.Options{BearerToken: prom.BearerToken, Timeout: prom.Timeout})
+		if err != nil {
+			return nil, nil, err
+		}
+		appOpts = append(appOpts, app.WithPrometheus(client))
+	}
+	var jobRepo jobs.Repository = memory.NewJobRepository()
+
+	closeStorage := func() error { return nil }
+	if cfg.Storage.Driver == config.StorageSQLite {
+		db, err := sqlite.Open(ctx, cfg.Storage.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := checkSchema(ctx, db); err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+		appOpts = append(appOpts,
+			app.WithProjectRepository(sqlite.NewProjectRepository(db)),
+			app.WithServiceRepository(sqlite.NewServiceRepository(db)),
+			app.WithOperationRepository(sqlite.NewOperationRepository(db)),
+			app.WithStateRepository(sqlite.NewStateRepository(db)),
+		)
+		jobRepo = sqlite.NewJobRepository(db)
+		closeStorage = db.Close
+	}
+
+	appOpts = append(appOpts, app.WithJobQueue(newJobQueue(cfg.Jobs, jobRepo)))
+	return app.NewApp(appOpts...), closeStorage, nil
+}
+
+func main() {
+	var cli humacli.CLI
+	cli = humacli.New(func(hooks humacli.Hooks, opts *Options) {
+		cfg, err := loadConfig(opts, cli.Root())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		slog.SetDefault(newLogger(cfg.Logging))
 
 		// Create the HTTP server.
 		server := http.Server{
-			Addr:    fmt.Sprintf(":%d", opts.Port),
-			Handler: mux,
+			Addr: cfg.Server.Addr(),
 		}
 
+		// started is set once the application runs; OnStop drains it and
+		// then releases its storage with closeStorage.
+		var (
+			mu           sync.Mutex
+			started      *app.App
+			closeStorage func() error
+		)
+
+		// stopApp lets running jobs finish and then closes the storage they
+		// record their outcome in. Jobs still running at the deadline are
+		// cancelled and queued again for the next start.
+		stopApp := func() {
+			mu.Lock()
+			a, closeDB := started, closeStorage
+			started, closeStorage = nil, nil
+			mu.Unlock()
+			if a == nil {
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.Jobs.DrainTimeout)
+			defer cancel()
+			if err := a.Stop(ctx); err != nil {
+				slog.Warn("Job queue did not drain in time", "error", err)
+			}
+			if err := closeDB(); err != nil {
+				slog.Error("Failed to close the database", "error", err)
+			}
+		}
+
+		// Failing to start exits non-zero, as humacli exits cleanly once
+		// OnStart returns.
 		hooks.OnStart(func() {
-			// Start your server here
-			err := server.ListenAndServe()
+			a, closeDB, err := newApp(context.Background(), cfg)
+			if err != nil {
+				slog.Error("Failed to set up the application", "error", err)
+				os.Exit(1)
+			}
+			mux := http.NewServeMux()
+			newAPI(mux, a, cfg.Auth)
+			server.Handler = mux
+
+			// Listen before starting the jobs, as Terraform reaches the
+			// state backend through the server.
+			ln, err := net.Listen("tcp", server.Addr)
+			if err != nil {
+				closeDB()
+				slog.Error("Failed to start server", "error", err)
+				os.Exit(1)
+			}
+			if err := a.Start(context.Background()); err != nil {
+				ln.Close()
+				closeDB()
+				slog.Error("Failed to start the job queue", "error", err)
+				os.Exit(1)
+			}
+			mu.Lock()
+			started, closeStorage = a, closeDB
+			mu.Unlock()
+
+			slog.Info("Listening", "addr", server.Addr)
+			err = server.Serve(ln)
 			if err != nil && !errors.Is(err, http.ErrServerClosed) {
 				slog.Error("Failed to start server", "error", err)
+				stopApp()
+				os.Exit(1)
 			}
 		})
 
 		hooks.OnStop(func() {
-			// Gracefully shutdown your server here
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			// Stop accepting requests first.
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 			defer cancel()
 			server.Shutdown(ctx)
+			stopApp()
 		})
 	})
 
+	cli.Root().AddCommand(newMigrateCommand())
+	cli.Root().AddCommand(newConfigCommand())
+
 	cli.Root().AddCommand(&cobra.Command{
 		Use:   "openapi",
 		Short: "Print the OpenAPI spec",
-		Run: func(cmd *cobra.Command, args []string) {
+		Run: humacli.WithOptions(func(cmd *cobra.Command, args []string, opts *Options) {
+			cfg, err := loadConfig(opts, cmd.Root())
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			// The spec includes the configuration schemas of the catalog.
+			catalog, err := newCatalog(cfg)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+
+			a := app.NewApp(app.WithInstance(&internal.Instance{Name: "Platform", Catalog: catalog}))
+			api := newAPI(http.NewServeMux(), a, cfg.Auth)
 			b, err := api.OpenAPI().YAML()
 			if err != nil {
 				panic(err)
 			}
 			fmt.Println(string(b))
-		},
+		}),
 	})
 
 	// Run the CLI. When passed no commands, it starts the server.
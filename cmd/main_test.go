@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runMainEnv makes the test binary run main with the arguments in
+// mainArgsEnv instead of the tests, so that its exit status can be checked.
+const (
+	runMainEnv  = "PLATFORM_TEST_RUN_MAIN"
+	mainArgsEnv = "PLATFORM_TEST_MAIN_ARGS"
+)
+
+func TestMain_RefusesOutdatedSchema(t *testing.T) {
+	if os.Getenv(runMainEnv) == "1" {
+		os.Args = append([]string{"platform"}, strings.Fields(os.Getenv(mainArgsEnv))...)
+		main()
+		return
+	}
+
+	dir := t.TempDir()
+	// A new database has none of the migrations applied.
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMain_RefusesOutdatedSchema$")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), runMainEnv+"=1", mainArgsEnv+"=--database "+filepath.Join(dir, "platform.db"))
+	out, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Fatalf("starting with an outdated schema: err = %v, want exit status 1; output:\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "migrate up") {
+		t.Errorf("output = %q, want it to say how to migrate", out)
+	}
+}
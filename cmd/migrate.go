@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Bermos/Platform/internal/config"
+	"github.com/Bermos/Platform/internal/database/migrations"
+	"github.com/Bermos/Platform/internal/database/sqlite"
+	"github.com/danielgtaylor/huma/v2/humacli"
+	"github.com/spf13/cobra"
+)
+
+// newMigrateCommand returns the `migrate` command with its up, down and
+// status subcommands. They operate on the configured SQLite database.
+func newMigrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage the database schema",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending migrations",
+		Args:  cobra.NoArgs,
+		Run: withMigrator(func(ctx context.Context, cmd *cobra.Command, args []string, m *migrations.Migrator) error {
+			applied, err := m.Up(ctx)
+			for _, mig := range applied {
+				fmt.Fprintf(cmd.OutOrStdout(), "applied %04d_%s\n", mig.Version, mig.Name)
+			}
+			if err != nil {
+				return err
+			}
+			if len(applied) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "schema is up to date")
+			}
+			return nil
+		}),
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "down N",
+		Short: "Roll back the N most recent migrations",
+		Args:  cobra.ExactArgs(1),
+		Run: withMigrator(func(ctx context.Context, cmd *cobra.Command, args []string, m *migrations.Migrator) error {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("N must be a positive number, got %q", args[0])
+			}
+			rolledBack, err := m.Down(ctx, n)
+			for _, mig := range rolledBack {
+				fmt.Fprintf(cmd.OutOrStdout(), "rolled back %04d_%s\n", mig.Version, mig.Name)
+			}
+			return err
+		}),
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "Show applied and pending migrations",
+		Args:  cobra.NoArgs,
+		Run: withMigrator(func(ctx context.Context, cmd *cobra.Command, args []string, m *migrations.Migrator) error {
+			statuses, err := m.Status(ctx)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+			for _, s := range statuses {
+				state, appliedAt := "pending", ""
+				if s.Applied {
+					state, appliedAt = "applied", s.AppliedAt.Local().Format(time.RFC3339)
+				}
+				switch {
+				case s.Unknown:
+					state = "unknown"
+				case s.Modified:
+					state = "modified"
+				}
+				fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+			}
+			return w.Flush()
+		}),
+	})
+
+	return cmd
+}
+
+// withMigrator opens the configured database for the duration of fn. The
+// process exits non-zero when fn fails, since humacli ignores command errors.
+func withMigrator(fn func(ctx context.Context, cmd *cobra.Command, args []string, m *migrations.Migrator) error) func(*cobra.Command, []string) {
+	return humacli.WithOptions(func(cmd *cobra.Command, args []string, opts *Options) {
+		if err := runMigrator(cmd, args, opts, fn); err != nil {
+			fmt.Fprintln(cmd.ErrOrStderr(), "Error:", err)
+			os.Exit(1)
+		}
+	})
+}
+
+func runMigrator(cmd *cobra.Command, args []string, opts *Options, fn func(ctx context.Context, cmd *cobra.Command, args []string, m *migrations.Migrator) error) error {
+	cfg, err := loadConfig(opts, cmd.Root())
+	if err != nil {
+		return err
+	}
+	if cfg.Storage.Driver != config.StorageSQLite {
+		return errors.New("migrations need a database, set --database or configure the sqlite storage driver")
+	}
+	db, err := sqlite.Open(cmd.Context(), cfg.Storage.Path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return fn(cmd.Context(), cmd, args, migrations.New(db, migrations.Embedded()))
+}
+
+// checkSchema refuses to run against a database whose schema does not match
+// the migrations compiled into this binary.
+func checkSchema(ctx context.Context, db *sql.DB) error {
+	err := migrations.New(db, migrations.Embedded()).Check(ctx)
+	if errors.Is(err, migrations.ErrSchemaBehind) {
+		return fmt.Errorf("%w; run `migrate up` first", err)
+	}
+	return err
+}
@@ -0,0 +1,19 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/Bermos/Platform/internal/app"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func registerAdmin(api huma.API, app *app.App) {
+	huma.Register(api, huma.Operation{
+		OperationID: "ListServiceWorkspaces",
+		Description: "List the services' Terraform working directories with their disk usage. " +
+			"Orphaned ones, whose service no longer exists, are removed by the periodic sweep.",
+		Method: http.MethodGet,
+		Path:   "/api/v1/admin/workspaces",
+		Tags:   []string{"admin"},
+	}, app.ListWorkspaces)
+}
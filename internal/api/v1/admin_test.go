@@ -0,0 +1,20 @@
+package v1
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/app"
+)
+
+func TestAdmin_ListWorkspaces(t *testing.T) {
+	server := newTestServer(t, app.NewApp())
+
+	var list []app.WorkspaceBody
+	if code := doJSON(t, http.MethodGet, server.URL+"/api/v1/admin/workspaces", "", &list); code != http.StatusOK {
+		t.Fatalf("GET /admin/workspaces = %d, want %d", code, http.StatusOK)
+	}
+	if list == nil || len(list) != 0 {
+		t.Errorf("GET /admin/workspaces = %v, want an empty list", list)
+	}
+}
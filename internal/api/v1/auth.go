@@ -0,0 +1,62 @@
+package v1
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Security schemes of the API.
+const (
+	// AdminScheme is the bearer admin token required by RequireAdminToken.
+	AdminScheme = "admin"
+	// WorkspaceScheme is HTTP basic authentication with the name and
+	// password of a Terraform state workspace.
+	WorkspaceScheme = "workspace"
+)
+
+// workspaceSecurity marks the operations that authenticate with a state
+// workspace's credentials instead of the admin token, so that Terraform
+// can use them.
+var workspaceSecurity = []map[string][]string{{WorkspaceScheme: {}}}
+
+// addSecurityScheme documents scheme in the OpenAPI components.
+func addSecurityScheme(api huma.API, name string, scheme *huma.SecurityScheme) {
+	components := api.OpenAPI().Components
+	if components.SecuritySchemes == nil {
+		components.SecuritySchemes = map[string]*huma.SecurityScheme{}
+	}
+	components.SecuritySchemes[name] = scheme
+}
+
+// RequireAdminToken makes every operation registered on api afterwards
+// require token as a bearer token, except those authenticating with a
+// workspace's credentials. Call it before Register.
+func RequireAdminToken(api huma.API, token string) {
+	addSecurityScheme(api, AdminScheme, &huma.SecurityScheme{
+		Type:        "http",
+		Scheme:      "bearer",
+		Description: "The admin token configured as auth.admin_token",
+	})
+	api.OpenAPI().Security = []map[string][]string{{AdminScheme: {}}}
+
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		if op := ctx.Operation(); op != nil && slices.ContainsFunc(op.Security, func(s map[string][]string) bool {
+			_, ok := s[WorkspaceScheme]
+			return ok
+		}) {
+			next(ctx)
+			return
+		}
+		given, ok := strings.CutPrefix(ctx.Header("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			ctx.SetHeader("WWW-Authenticate", "Bearer")
+			huma.WriteErr(api, ctx, http.StatusUnauthorized, "a valid admin token is required")
+			return
+		}
+		next(ctx)
+	})
+}
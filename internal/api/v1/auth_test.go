@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/app"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+)
+
+const testAdminToken = "0123456789abcdef"
+
+func TestRequireAdminToken(t *testing.T) {
+	router := chi.NewRouter()
+	api := humachi.New(router, huma.DefaultConfig("Test API", "1.0.0"))
+	RequireAdminToken(api, testAdminToken)
+	application := app.NewApp()
+	Register(api, application)
+	server := httptest.NewServer(router)
+	t.Cleanup(func() {
+		server.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		application.Stop(ctx)
+	})
+
+	do := func(method, path, authorization, body string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to build request: %v", err)
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{"no_token", "", http.StatusUnauthorized},
+		{"wrong_token", "Bearer fedcba9876543210", http.StatusUnauthorized},
+		{"wrong_scheme", "Basic " + testAdminToken, http.StatusUnauthorized},
+		{"admin_token", "Bearer " + testAdminToken, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := do(http.MethodGet, "/api/v1/projects", tt.authorization, "")
+			if resp.StatusCode != tt.want {
+				t.Fatalf("GET projects = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("WWW-Authenticate = %q, want Bearer", resp.Header.Get("WWW-Authenticate"))
+			}
+		})
+	}
+
+	// Creating a state workspace takes the admin token; Terraform then
+	// uses the workspace's credentials alone.
+	if resp := do(http.MethodPost, "/api/v1/terraform/workspaces", "", `{"name":"shop-web"}`); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("POST workspace without token = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+	resp := do(http.MethodPost, "/api/v1/terraform/workspaces", "Bearer "+testAdminToken, `{"name":"shop-web"}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("POST workspace = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	var ws app.CreatedStateWorkspaceBody
+	if err := json.NewDecoder(resp.Body).Decode(&ws); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	state := &stateClient{t: t, url: server.URL + ws.Address, user: ws.Username, password: ws.Password}
+	if code, _, _ := state.do(http.MethodGet, "", "", nil); code != http.StatusNoContent {
+		t.Errorf("GET state with workspace credentials = %d, want %d", code, http.StatusNoContent)
+	}
+	workspace := &stateClient{t: t, url: server.URL + "/api/v1/terraform/workspaces/shop-web", user: ws.Username, password: ws.Password}
+	if code := workspace.doJSON(http.MethodGet, "/versions", nil); code != http.StatusOK {
+		t.Errorf("GET versions with workspace credentials = %d, want %d", code, http.StatusOK)
+	}
+
+	spec := api.OpenAPI()
+	if spec.Components.SecuritySchemes[AdminScheme] == nil || spec.Components.SecuritySchemes[WorkspaceScheme] == nil {
+		t.Errorf("security schemes = %v, want %s and %s", spec.Components.SecuritySchemes, AdminScheme, WorkspaceScheme)
+	}
+	if len(spec.Security) != 1 || spec.Security[0][AdminScheme] == nil {
+		t.Errorf("security = %v, want the admin token", spec.Security)
+	}
+}
@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/Bermos/Platform/internal/app"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func registerOperations(api huma.API, app *app.App) {
+	huma.Register(api, huma.Operation{
+		OperationID: "GetOperation",
+		Description: "Get the progress, steps, logs and outcome of an operation",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/operations/{id}",
+		Tags:        []string{"operations"},
+		Errors:      []int{http.StatusNotFound, http.StatusUnprocessableEntity},
+	}, app.GetOperation)
+
+	// Custom methods follow the {id}:method convention. They share one
+	// route because the colon is not a path segment boundary.
+	huma.Register(api, huma.Operation{
+		OperationID: "OperationMethod",
+		Summary:     "Cancel, wait for or approve an operation",
+		Description: "POST /api/v1/operations/{id}:cancel cancels an unfinished operation. " +
+			"POST /api/v1/operations/{id}:wait blocks until the operation is done or the timeout elapses and returns it either way; check done. " +
+			"POST /api/v1/operations/{id}:approve applies the plan of an operation awaiting approval, which it does when its plan deletes or replaces resources.",
+		Method: http.MethodPost,
+		Path:   "/api/v1/operations/{id}",
+		Tags:   []string{"operations"},
+		Errors: []int{http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity},
+	}, app.OperationMethod)
+}
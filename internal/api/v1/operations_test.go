@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Bermos/Platform/internal"
+	"github.com/Bermos/Platform/internal/app"
+	"github.com/Bermos/Platform/internal/project"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+// waitOperation blocks until the operation is done and returns it.
+func waitOperation(t *testing.T, serverURL string, id uuid.UUID) app.OperationBody {
+	t.Helper()
+
+	var op app.OperationBody
+	if code := doJSON(t, http.MethodPost, serverURL+"/api/v1/operations/"+id.String()+":wait?timeout=5s", "", &op); code != http.StatusOK {
+		t.Fatalf("POST operation :wait = %d, want %d", code, http.StatusOK)
+	}
+	if !op.Done {
+		t.Fatalf("operation %s is still %s after 5s", id, op.State)
+	}
+	return op
+}
+
+func TestOperations(t *testing.T) {
+	instance := &internal.Instance{
+		Name:    "Test Instance",
+		Catalog: testutil.NewTestCatalog(testutil.NewMockResource().WithKey("small-vm")),
+	}
+	server := newTestServer(t, app.NewApp(app.WithInstance(instance)))
+
+	var proj project.Project
+	if code := doJSON(t, http.MethodPost, server.URL+"/api/v1/projects", `{"name":"shop"}`, &proj); code != http.StatusCreated {
+		t.Fatalf("POST /projects = %d, want %d", code, http.StatusCreated)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/api/v1/projects/"+proj.ID.String()+"/services", strings.NewReader(`{"name":"api","resource":"small-vm"}`))
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	var accepted app.OperationBody
+	if err := json.NewDecoder(resp.Body).Decode(&accepted); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST service = %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+	location := resp.Header.Get("Location")
+	if location != "/api/v1/operations/"+accepted.ID.String() {
+		t.Errorf("Location = %q, want the operation", location)
+	}
+
+	done := waitOperation(t, server.URL, accepted.ID)
+	if done.State != "succeeded" || done.Progress != 100 || len(done.Steps) != 3 {
+		t.Errorf("finished operation = %+v, want it succeeded with 3 steps", done)
+	}
+
+	var got app.OperationBody
+	if code := doJSON(t, http.MethodGet, server.URL+location, "", &got); code != http.StatusOK {
+		t.Fatalf("GET operation = %d, want %d", code, http.StatusOK)
+	}
+	if got.ID != accepted.ID || !got.Done {
+		t.Errorf("GET operation = %+v, want the finished operation", got)
+	}
+
+	if code := doJSON(t, http.MethodPost, server.URL+location+":cancel", "", nil); code != http.StatusConflict {
+		t.Errorf("POST finished operation :cancel = %d, want %d", code, http.StatusConflict)
+	}
+	if code := doJSON(t, http.MethodPost, server.URL+location+":explode", "", nil); code != http.StatusUnprocessableEntity {
+		t.Errorf("POST operation :explode = %d, want %d", code, http.StatusUnprocessableEntity)
+	}
+	if code := doJSON(t, http.MethodPost, server.URL+location+":wait?timeout=forever", "", nil); code != http.StatusUnprocessableEntity {
+		t.Errorf("POST operation :wait with invalid timeout = %d, want %d", code, http.StatusUnprocessableEntity)
+	}
+	unknown := server.URL + "/api/v1/operations/00000000-0000-0000-0000-000000000001"
+	if code := doJSON(t, http.MethodGet, unknown, "", nil); code != http.StatusNotFound {
+		t.Errorf("GET unknown operation = %d, want %d", code, http.StatusNotFound)
+	}
+	if code := doJSON(t, http.MethodPost, unknown+":cancel", "", nil); code != http.StatusNotFound {
+		t.Errorf("POST unknown operation :cancel = %d, want %d", code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/Bermos/Platform/internal/app"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func registerProjects(api huma.API, app *app.App) {
+	huma.Register(api, huma.Operation{
+		OperationID: "ListProjects",
+		Description: "List all projects",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/projects",
+		Tags:        []string{"projects"},
+	}, app.ListProjects)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "CreateProject",
+		Description:   "Create a project",
+		Method:        http.MethodPost,
+		Path:          "/api/v1/projects",
+		Tags:          []string{"projects"},
+		DefaultStatus: http.StatusCreated,
+		Errors:        []int{http.StatusConflict, http.StatusUnprocessableEntity},
+	}, app.CreateProject)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "GetProject",
+		Description: "Get a project",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/projects/{id}",
+		Tags:        []string{"projects"},
+		Errors:      []int{http.StatusNotFound, http.StatusUnprocessableEntity},
+	}, app.GetProject)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "UpdateProject",
+		Description: "Replace a project's name and description",
+		Method:      http.MethodPut,
+		Path:        "/api/v1/projects/{id}",
+		Tags:        []string{"projects"},
+		Errors:      []int{http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity},
+	}, app.UpdateProject)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "DeleteProject",
+		Description:   "Report what deleting a project would remove and, unless dryRun is set, delete it. A project without services is deleted at once (204). With cascade or force, its services are deleted first and the project once they are gone (202).",
+		Method:        http.MethodDelete,
+		Path:          "/api/v1/projects/{id}",
+		Tags:          []string{"projects"},
+		DefaultStatus: http.StatusAccepted,
+		Errors:        []int{http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity},
+	}, app.DeleteProject)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "GetProjectGraph",
+		Description: "Get the dependency graph of a project's services and the order they are provisioned in",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/projects/{id}/graph",
+		Tags:        []string{"projects"},
+		Errors:      []int{http.StatusNotFound, http.StatusUnprocessableEntity},
+	}, app.GetProjectGraph)
+}
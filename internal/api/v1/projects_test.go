@@ -0,0 +1,183 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/app"
+	"github.com/Bermos/Platform/internal/project"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+)
+
+// newTestServer registers all routes for application on a fresh router.
+func newTestServer(t *testing.T, application *app.App) *httptest.Server {
+	t.Helper()
+
+	router := chi.NewRouter()
+	humaAPI := humachi.New(router, huma.DefaultConfig("Test API", "1.0.0"))
+	Register(humaAPI, application)
+
+	if err := application.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start app: %v", err)
+	}
+	server := httptest.NewServer(router)
+	t.Cleanup(func() {
+		server.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		application.Stop(ctx)
+	})
+	return server
+}
+
+// doJSON sends a request with an optional JSON body and decodes the JSON
+// response into out when out is non-nil. Error responses are only decoded
+// into a *huma.ErrorModel.
+func doJSON(t *testing.T, method, url, body string, out any) int {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	_, wantProblem := out.(*huma.ErrorModel)
+	if out != nil && (resp.StatusCode < 300 || wantProblem) {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestProjects_CRUD(t *testing.T) {
+	server := newTestServer(t, app.NewApp())
+	base := server.URL + "/api/v1/projects"
+
+	var created project.Project
+	if code := doJSON(t, http.MethodPost, base, `{"name":"shop","description":"Online shop"}`, &created); code != http.StatusCreated {
+		t.Fatalf("POST /projects = %d, want %d", code, http.StatusCreated)
+	}
+	if created.Name != "shop" {
+		t.Errorf("created name = %q, want %q", created.Name, "shop")
+	}
+
+	var got project.Project
+	if code := doJSON(t, http.MethodGet, base+"/"+created.ID.String(), "", &got); code != http.StatusOK {
+		t.Fatalf("GET /projects/{id} = %d, want %d", code, http.StatusOK)
+	}
+	if got.ID != created.ID {
+		t.Errorf("GET returned ID %s, want %s", got.ID, created.ID)
+	}
+
+	var updated project.Project
+	if code := doJSON(t, http.MethodPut, base+"/"+created.ID.String(), `{"name":"storefront"}`, &updated); code != http.StatusOK {
+		t.Fatalf("PUT /projects/{id} = %d, want %d", code, http.StatusOK)
+	}
+	if updated.Name != "storefront" {
+		t.Errorf("updated name = %q, want %q", updated.Name, "storefront")
+	}
+
+	var list []project.Project
+	if code := doJSON(t, http.MethodGet, base, "", &list); code != http.StatusOK {
+		t.Fatalf("GET /projects = %d, want %d", code, http.StatusOK)
+	}
+	if len(list) != 1 {
+		t.Errorf("GET /projects returned %d projects, want 1", len(list))
+	}
+
+	if code := doJSON(t, http.MethodDelete, base+"/"+created.ID.String(), "", nil); code != http.StatusNoContent {
+		t.Fatalf("DELETE /projects/{id} = %d, want %d", code, http.StatusNoContent)
+	}
+	if code := doJSON(t, http.MethodGet, base+"/"+created.ID.String(), "", nil); code != http.StatusNotFound {
+		t.Errorf("GET deleted project = %d, want %d", code, http.StatusNotFound)
+	}
+}
+
+func TestProjects_ProblemResponses(t *testing.T) {
+	server := newTestServer(t, app.NewApp())
+	base := server.URL + "/api/v1/projects"
+
+	if code := doJSON(t, http.MethodPost, base, `{"name":"shop"}`, nil); code != http.StatusCreated {
+		t.Fatalf("seeding project = %d, want %d", code, http.StatusCreated)
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "duplicate_name_is_conflict",
+			method:   http.MethodPost,
+			path:     "",
+			body:     `{"name":"shop"}`,
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "invalid_name_is_unprocessable",
+			method:   http.MethodPost,
+			path:     "",
+			body:     `{"name":"Bad Name"}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "missing_name_is_unprocessable",
+			method:   http.MethodPost,
+			path:     "",
+			body:     `{}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "malformed_id_is_unprocessable",
+			method:   http.MethodGet,
+			path:     "/not-a-uuid",
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "unknown_id_is_not_found",
+			method:   http.MethodGet,
+			path:     "/00000000-0000-0000-0000-000000000001",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "update_unknown_id_is_not_found",
+			method:   http.MethodPut,
+			path:     "/00000000-0000-0000-0000-000000000001",
+			body:     `{"name":"other"}`,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "delete_unknown_id_is_not_found",
+			method:   http.MethodDelete,
+			path:     "/00000000-0000-0000-0000-000000000001",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := doJSON(t, tt.method, base+tt.path, tt.body, nil); code != tt.wantCode {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Bermos/Platform/internal/app"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func registerResources(api huma.API, app *app.App) {
+	huma.Register(api, huma.Operation{
+		OperationID: "ListResources",
+		Description: "List the resources services can run on",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/resources",
+		Tags:        []string{"resources"},
+	}, app.ListResources)
+
+	// Publish every configuration schema so clients can generate forms from
+	// the OpenAPI document alone.
+	schemas := api.OpenAPI().Components.Schemas.Map()
+	for _, res := range app.Catalog().Resources() {
+		if schema := res.ConfigSchema(); schema != nil {
+			schemas[configSchemaName(res.Key())] = schema
+		}
+	}
+}
+
+// configSchemaName returns the OpenAPI component name of a resource's
+// configuration schema, e.g. K8sPodConfig for k8s-pod.
+func configSchemaName(key string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(key, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	b.WriteString("Config")
+	return b.String()
+}
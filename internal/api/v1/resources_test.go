@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Bermos/Platform/internal"
+	"github.com/Bermos/Platform/internal/app"
+	"github.com/Bermos/Platform/internal/project"
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func TestResources_List(t *testing.T) {
+	instance := &internal.Instance{
+		Name:    "Test Instance",
+		Catalog: testutil.NewTestCatalog(testutil.NewMockResource().WithKey("small-vm").WithPrice(0.5)),
+	}
+	server := newTestServer(t, app.NewApp(app.WithInstance(instance)))
+
+	var list []app.ResourceBody
+	if code := doJSON(t, http.MethodGet, server.URL+"/api/v1/resources", "", &list); code != http.StatusOK {
+		t.Fatalf("GET /resources = %d, want %d", code, http.StatusOK)
+	}
+	if len(list) != 1 {
+		t.Fatalf("GET /resources returned %d resources, want 1", len(list))
+	}
+	if list[0].Key != "small-vm" || list[0].PricePerHour != 0.5 {
+		t.Errorf("GET /resources = %+v, want small-vm at 0.5 per hour", list[0])
+	}
+}
+
+func TestResources_ConfigSchema(t *testing.T) {
+	type vmConfig struct {
+		Cores int `json:"cores" minimum:"1" maximum:"8"`
+	}
+	instance := &internal.Instance{
+		Name:    "Test Instance",
+		Catalog: testutil.NewTestCatalog(testutil.NewMockResource().WithKey("small-vm").WithConfigSchema(resource.SchemaFor[vmConfig]())),
+	}
+	a := app.NewApp(app.WithInstance(instance))
+	server := newTestServer(t, a)
+
+	var spec struct {
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if code := doJSON(t, http.MethodGet, server.URL+"/openapi.json", "", &spec); code != http.StatusOK {
+		t.Fatalf("GET /openapi.json = %d, want %d", code, http.StatusOK)
+	}
+	if _, ok := spec.Components.Schemas["SmallVmConfig"]; !ok {
+		t.Error("OpenAPI components should include SmallVmConfig")
+	}
+
+	var proj project.Project
+	if code := doJSON(t, http.MethodPost, server.URL+"/api/v1/projects", `{"name":"shop"}`, &proj); code != http.StatusCreated {
+		t.Fatalf("POST /projects = %d, want %d", code, http.StatusCreated)
+	}
+	servicesURL := server.URL + "/api/v1/projects/" + proj.ID.String() + "/services"
+
+	var problem huma.ErrorModel
+	if code := doJSON(t, http.MethodPost, servicesURL, `{"name":"api","resource":"small-vm","config":{"cores":64}}`, &problem); code != http.StatusUnprocessableEntity {
+		t.Fatalf("POST service with invalid config = %d, want %d", code, http.StatusUnprocessableEntity)
+	}
+	if len(problem.Errors) != 1 || problem.Errors[0].Location != "body.config.cores" {
+		t.Errorf("POST service with invalid config errors = %+v, want one at body.config.cores", problem.Errors)
+	}
+
+	var accepted app.OperationBody
+	if code := doJSON(t, http.MethodPost, servicesURL, `{"name":"api","resource":"small-vm","config":{"cores":2}}`, &accepted); code != http.StatusAccepted {
+		t.Fatalf("POST service = %d, want %d", code, http.StatusAccepted)
+	}
+	var created app.ServiceBody
+	if code := doJSON(t, http.MethodGet, server.URL+"/api/v1/services/"+accepted.ServiceID.String(), "", &created); code != http.StatusOK {
+		t.Fatalf("GET service = %d, want %d", code, http.StatusOK)
+	}
+	if created.Config["cores"] != float64(2) {
+		t.Errorf("created config = %v, want cores 2", created.Config)
+	}
+}
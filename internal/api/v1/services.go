@@ -0,0 +1,113 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/Bermos/Platform/internal/app"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func registerServices(api huma.API, app *app.App) {
+	huma.Register(api, huma.Operation{
+		OperationID:   "CreateService",
+		Description:   "Create a service in a project, bound to one of the instance's available resources, and start provisioning it. Returns the operation; the service ID is its serviceId.",
+		Method:        http.MethodPost,
+		Path:          "/api/v1/projects/{projectID}/services",
+		Tags:          []string{"services"},
+		DefaultStatus: http.StatusAccepted,
+		Errors:        []int{http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity},
+	}, app.CreateService)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "ListServices",
+		Description: "List the services of a project",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/projects/{projectID}/services",
+		Tags:        []string{"services"},
+		Errors:      []int{http.StatusNotFound, http.StatusUnprocessableEntity},
+	}, app.ListServices)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "GetService",
+		Description: "Get a service",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/services/{id}",
+		Tags:        []string{"services"},
+		Errors:      []int{http.StatusNotFound, http.StatusUnprocessableEntity},
+	}, app.GetService)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "UpdateService",
+		Description:   "Replace a service's name, description, configuration and dependencies, and start applying the change. The resource cannot be changed. Returns the operation.",
+		Method:        http.MethodPut,
+		Path:          "/api/v1/services/{id}",
+		Tags:          []string{"services"},
+		DefaultStatus: http.StatusAccepted,
+		Errors:        []int{http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity},
+	}, app.UpdateService)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "ReconcileService",
+		Description:   "Start re-applying a service's configuration unchanged, undoing drift. Returns the operation.",
+		Method:        http.MethodPost,
+		Path:          "/api/v1/services/{id}/reconcile",
+		Tags:          []string{"services"},
+		DefaultStatus: http.StatusAccepted,
+		Errors:        []int{http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity},
+	}, app.ReconcileService)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "DeleteService",
+		Description:   "Report what deleting a service would break and, unless dryRun is set, start destroying it. Services depending on it block the deletion unless force or cascade is set. Returns the report and the operations; the service is gone once its operation succeeds.",
+		Method:        http.MethodDelete,
+		Path:          "/api/v1/services/{id}",
+		Tags:          []string{"services"},
+		DefaultStatus: http.StatusAccepted,
+		Errors:        []int{http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity},
+	}, app.DeleteService)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "GetServiceManifests",
+		Description: "Render the Kubernetes objects Mahler applies for a service, labelled with its project and service IDs",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/services/{id}/manifests",
+		Tags:        []string{"services"},
+		Errors:      []int{http.StatusNotFound, http.StatusUnprocessableEntity},
+	}, app.GetServiceManifests)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "GetServiceStatus",
+		Description: "Get a service's lifecycle status and when, by whom and why it last changed",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/services/{id}/status",
+		Tags:        []string{"services"},
+		Errors:      []int{http.StatusNotFound, http.StatusUnprocessableEntity},
+	}, app.GetServiceStatus)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "ListServiceHistory",
+		Description: "List a service's status transitions, oldest first",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/services/{id}/history",
+		Tags:        []string{"services"},
+		Errors:      []int{http.StatusNotFound, http.StatusUnprocessableEntity},
+	}, app.ListServiceHistory)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "GetServiceOutputs",
+		Description: "List the values a service exposed when it was last provisioned, such as its URL. Sensitive values are left out unless showSensitive is set",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/services/{id}/outputs",
+		Tags:        []string{"services"},
+		Errors:      []int{http.StatusNotFound, http.StatusUnprocessableEntity},
+	}, app.GetServiceOutputs)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "GetServiceMetrics",
+		Description: "Get time series of a service's CPU or memory usage over the given range, ending now, from Prometheus",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/services/{id}/metrics",
+		Tags:        []string{"services"},
+		Errors:      []int{http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusNotImplemented, http.StatusBadGateway},
+	}, app.GetServiceMetrics)
+}
@@ -0,0 +1,265 @@
+package v1
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal"
+	"github.com/Bermos/Platform/internal/app"
+	"github.com/Bermos/Platform/internal/project"
+	"github.com/Bermos/Platform/internal/prometheus"
+	"github.com/Bermos/Platform/internal/prometheus/fake"
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+func TestServices_CRUD(t *testing.T) {
+	instance := &internal.Instance{
+		Name:    "Test Instance",
+		Catalog: testutil.NewTestCatalog(testutil.NewMockResource().WithKey("small-vm")),
+	}
+	server := newTestServer(t, app.NewApp(app.WithInstance(instance)))
+
+	var proj project.Project
+	if code := doJSON(t, http.MethodPost, server.URL+"/api/v1/projects", `{"name":"shop"}`, &proj); code != http.StatusCreated {
+		t.Fatalf("POST /projects = %d, want %d", code, http.StatusCreated)
+	}
+	servicesURL := server.URL + "/api/v1/projects/" + proj.ID.String() + "/services"
+
+	if code := doJSON(t, http.MethodPost, servicesURL, `{"name":"api","resource":"mainframe"}`, nil); code != http.StatusUnprocessableEntity {
+		t.Errorf("POST service with unknown resource = %d, want %d", code, http.StatusUnprocessableEntity)
+	}
+
+	var accepted app.OperationBody
+	if code := doJSON(t, http.MethodPost, servicesURL, `{"name":"api","resource":"small-vm"}`, &accepted); code != http.StatusAccepted {
+		t.Fatalf("POST service = %d, want %d", code, http.StatusAccepted)
+	}
+	waitOperation(t, server.URL, accepted.ID)
+	serviceURL := server.URL + "/api/v1/services/" + accepted.ServiceID.String()
+	var created app.ServiceBody
+	if code := doJSON(t, http.MethodGet, serviceURL, "", &created); code != http.StatusOK {
+		t.Fatalf("GET service = %d, want %d", code, http.StatusOK)
+	}
+	if created.Resource != "small-vm" {
+		t.Errorf("created resource = %q, want %q", created.Resource, "small-vm")
+	}
+	var outputs []app.OutputBody
+	if code := doJSON(t, http.MethodGet, serviceURL+"/outputs?showSensitive=true", "", &outputs); code != http.StatusOK {
+		t.Fatalf("GET outputs = %d, want %d", code, http.StatusOK)
+	}
+	if len(outputs) != 0 {
+		t.Errorf("outputs of a service Mahler does not provision = %v, want none", outputs)
+	}
+
+	var list []app.ServiceBody
+	if code := doJSON(t, http.MethodGet, servicesURL, "", &list); code != http.StatusOK {
+		t.Fatalf("GET services = %d, want %d", code, http.StatusOK)
+	}
+	if len(list) != 1 {
+		t.Errorf("GET services returned %d services, want 1", len(list))
+	}
+
+	if code := doJSON(t, http.MethodPut, serviceURL, `{"name":"gateway","resource":"small-vm"}`, &accepted); code != http.StatusAccepted {
+		t.Fatalf("PUT service = %d, want %d", code, http.StatusAccepted)
+	}
+	waitOperation(t, server.URL, accepted.ID)
+	var updated app.ServiceBody
+	if code := doJSON(t, http.MethodGet, serviceURL, "", &updated); code != http.StatusOK {
+		t.Fatalf("GET service = %d, want %d", code, http.StatusOK)
+	}
+	if updated.Name != "gateway" {
+		t.Errorf("updated name = %q, want %q", updated.Name, "gateway")
+	}
+
+	if code := doJSON(t, http.MethodPost, serviceURL+"/reconcile", "", &accepted); code != http.StatusAccepted {
+		t.Fatalf("POST reconcile = %d, want %d", code, http.StatusAccepted)
+	}
+	if accepted.Kind != "service.reconcile" {
+		t.Errorf("reconcile kind = %q, want %q", accepted.Kind, "service.reconcile")
+	}
+	waitOperation(t, server.URL, accepted.ID)
+
+	if code := doJSON(t, http.MethodDelete, server.URL+"/api/v1/projects/"+proj.ID.String(), "", nil); code != http.StatusConflict {
+		t.Errorf("DELETE project with services = %d, want %d", code, http.StatusConflict)
+	}
+	var deletion app.DeletionBody
+	if code := doJSON(t, http.MethodDelete, serviceURL, "", &deletion); code != http.StatusAccepted {
+		t.Fatalf("DELETE service = %d, want %d", code, http.StatusAccepted)
+	}
+	if len(deletion.Operations) != 1 {
+		t.Fatalf("DELETE service started %d operations, want 1", len(deletion.Operations))
+	}
+	waitOperation(t, server.URL, deletion.Operations[0].ID)
+	if code := doJSON(t, http.MethodGet, serviceURL, "", nil); code != http.StatusNotFound {
+		t.Errorf("GET deleted service = %d, want %d", code, http.StatusNotFound)
+	}
+}
+
+func TestServices_Dependencies(t *testing.T) {
+	instance := &internal.Instance{
+		Name:    "Test Instance",
+		Catalog: testutil.NewTestCatalog(testutil.NewMockResource().WithKey("small-vm")),
+	}
+	server := newTestServer(t, app.NewApp(app.WithInstance(instance)))
+
+	var proj project.Project
+	if code := doJSON(t, http.MethodPost, server.URL+"/api/v1/projects", `{"name":"shop"}`, &proj); code != http.StatusCreated {
+		t.Fatalf("POST /projects = %d, want %d", code, http.StatusCreated)
+	}
+	servicesURL := server.URL + "/api/v1/projects/" + proj.ID.String() + "/services"
+
+	var db, api app.OperationBody
+	if code := doJSON(t, http.MethodPost, servicesURL, `{"name":"db","resource":"small-vm"}`, &db); code != http.StatusAccepted {
+		t.Fatalf("POST service = %d, want %d", code, http.StatusAccepted)
+	}
+	body := `{"name":"api","resource":"small-vm","dependsOn":["` + db.ServiceID.String() + `"]}`
+	if code := doJSON(t, http.MethodPost, servicesURL, body, &api); code != http.StatusAccepted {
+		t.Fatalf("POST dependent service = %d, want %d", code, http.StatusAccepted)
+	}
+	waitOperation(t, server.URL, api.ID)
+
+	var graph app.ProjectGraphBody
+	if code := doJSON(t, http.MethodGet, server.URL+"/api/v1/projects/"+proj.ID.String()+"/graph", "", &graph); code != http.StatusOK {
+		t.Fatalf("GET graph = %d, want %d", code, http.StatusOK)
+	}
+	if len(graph.Levels) != 2 || graph.Levels[0][0] != db.ServiceID || graph.Levels[1][0] != api.ServiceID {
+		t.Errorf("graph levels = %v, want db before api", graph.Levels)
+	}
+	if len(graph.Edges) != 1 || graph.Edges[0] != (app.GraphEdge{From: api.ServiceID, To: db.ServiceID}) {
+		t.Errorf("graph edges = %v, want api -> db", graph.Edges)
+	}
+
+	dbURL := server.URL + "/api/v1/services/" + db.ServiceID.String()
+	cycle := `{"name":"db","resource":"small-vm","dependsOn":["` + api.ServiceID.String() + `"]}`
+	if code := doJSON(t, http.MethodPut, dbURL, cycle, nil); code != http.StatusUnprocessableEntity {
+		t.Errorf("PUT service closing a cycle = %d, want %d", code, http.StatusUnprocessableEntity)
+	}
+	if code := doJSON(t, http.MethodDelete, dbURL, "", nil); code != http.StatusConflict {
+		t.Errorf("DELETE service with dependents = %d, want %d", code, http.StatusConflict)
+	}
+
+	var report app.DeletionBody
+	if code := doJSON(t, http.MethodDelete, dbURL+"?dryRun=true", "", &report); code != http.StatusOK {
+		t.Fatalf("DELETE service with dryRun = %d, want %d", code, http.StatusOK)
+	}
+	if !report.DryRun || len(report.Impact.Dependents) != 1 || report.Impact.Dependents[0].ID != api.ServiceID {
+		t.Errorf("dry run report = %+v, want api as dependent", report.Impact)
+	}
+
+	var deletion app.DeletionBody
+	if code := doJSON(t, http.MethodDelete, dbURL+"?cascade=true", "", &deletion); code != http.StatusAccepted {
+		t.Fatalf("DELETE service with cascade = %d, want %d", code, http.StatusAccepted)
+	}
+	if len(deletion.Operations) != 2 || deletion.Operations[0].ServiceID != api.ServiceID {
+		t.Fatalf("cascading delete operations = %+v, want the dependent's first", deletion.Operations)
+	}
+	for _, op := range deletion.Operations {
+		waitOperation(t, server.URL, op.ID)
+	}
+	if code := doJSON(t, http.MethodDelete, server.URL+"/api/v1/projects/"+proj.ID.String(), "", nil); code != http.StatusNoContent {
+		t.Errorf("DELETE empty project = %d, want %d", code, http.StatusNoContent)
+	}
+}
+
+func TestServices_UnknownProject(t *testing.T) {
+	server := newTestServer(t, app.NewApp())
+	url := server.URL + "/api/v1/projects/00000000-0000-0000-0000-000000000001/services"
+
+	if code := doJSON(t, http.MethodGet, url, "", nil); code != http.StatusNotFound {
+		t.Errorf("GET services of unknown project = %d, want %d", code, http.StatusNotFound)
+	}
+}
+
+func TestServices_StatusAndHistory(t *testing.T) {
+	instance := &internal.Instance{
+		Name:    "Test Instance",
+		Catalog: testutil.NewTestCatalog(testutil.NewMockResource().WithKey("small-vm")),
+	}
+	server := newTestServer(t, app.NewApp(app.WithInstance(instance)))
+
+	var proj project.Project
+	if code := doJSON(t, http.MethodPost, server.URL+"/api/v1/projects", `{"name":"shop"}`, &proj); code != http.StatusCreated {
+		t.Fatalf("POST /projects = %d, want %d", code, http.StatusCreated)
+	}
+	var accepted app.OperationBody
+	if code := doJSON(t, http.MethodPost, server.URL+"/api/v1/projects/"+proj.ID.String()+"/services", `{"name":"api","resource":"small-vm"}`, &accepted); code != http.StatusAccepted {
+		t.Fatalf("POST service = %d, want %d", code, http.StatusAccepted)
+	}
+	waitOperation(t, server.URL, accepted.ID)
+	serviceURL := server.URL + "/api/v1/services/" + accepted.ServiceID.String()
+
+	var status app.ServiceStatusBody
+	if code := doJSON(t, http.MethodGet, serviceURL+"/status", "", &status); code != http.StatusOK {
+		t.Fatalf("GET status = %d, want %d", code, http.StatusOK)
+	}
+	if status.Status != "ready" || status.ServiceID != accepted.ServiceID {
+		t.Errorf("GET status = %+v, want a ready status for %s", status, accepted.ServiceID)
+	}
+
+	var history []app.TransitionBody
+	if code := doJSON(t, http.MethodGet, serviceURL+"/history", "", &history); code != http.StatusOK {
+		t.Fatalf("GET history = %d, want %d", code, http.StatusOK)
+	}
+	if len(history) != 2 || history[0].To != "provisioning" || history[1].To != "ready" {
+		t.Errorf("GET history of a new service = %v, want provisioning then ready", history)
+	}
+
+	unknown := server.URL + "/api/v1/services/00000000-0000-0000-0000-000000000001"
+	if code := doJSON(t, http.MethodGet, unknown+"/status", "", nil); code != http.StatusNotFound {
+		t.Errorf("GET status of unknown service = %d, want %d", code, http.StatusNotFound)
+	}
+	if code := doJSON(t, http.MethodGet, unknown+"/history", "", nil); code != http.StatusNotFound {
+		t.Errorf("GET history of unknown service = %d, want %d", code, http.StatusNotFound)
+	}
+}
+
+func TestServices_Metrics(t *testing.T) {
+	prom := fake.NewServer()
+	defer prom.Close()
+	prom.SetSeries(prometheus.Series{
+		Labels: map[string]string{},
+		Points: []prometheus.Point{{Time: time.Now().Add(-time.Minute), Value: 0.5}},
+	})
+	client, err := prometheus.New(prom.URL, prometheus.Options{})
+	if err != nil {
+		t.Fatalf("prometheus.New() error = %v", err)
+	}
+	res := testutil.NewMockResource().WithKey("small-vm")
+	res.CPUMetrics, res.MemoryMetrics = `vm_cpu{service="{{.ServiceID}}"}`, ""
+	instance := &internal.Instance{Name: "Test Instance", Catalog: testutil.NewTestCatalog(res)}
+	server := newTestServer(t, app.NewApp(app.WithInstance(instance), app.WithPrometheus(client)))
+
+	var proj project.Project
+	if code := doJSON(t, http.MethodPost, server.URL+"/api/v1/projects", `{"name":"shop"}`, &proj); code != http.StatusCreated {
+		t.Fatalf("POST /projects = %d, want %d", code, http.StatusCreated)
+	}
+	var accepted app.OperationBody
+	if code := doJSON(t, http.MethodPost, server.URL+"/api/v1/projects/"+proj.ID.String()+"/services", `{"name":"api","resource":"small-vm"}`, &accepted); code != http.StatusAccepted {
+		t.Fatalf("POST service = %d, want %d", code, http.StatusAccepted)
+	}
+	metricsURL := server.URL + "/api/v1/services/" + accepted.ServiceID.String() + "/metrics"
+
+	var metrics app.MetricsBody
+	if code := doJSON(t, http.MethodGet, metricsURL+"?metric=cpu&range=2h&step=1m", "", &metrics); code != http.StatusOK {
+		t.Fatalf("GET metrics = %d, want %d", code, http.StatusOK)
+	}
+	if metrics.Query != `vm_cpu{service="`+accepted.ServiceID.String()+`"}` {
+		t.Errorf("GET metrics query = %q, want it scoped to the service", metrics.Query)
+	}
+	if metrics.Step != "1m0s" || metrics.End.Sub(metrics.Start) != 2*time.Hour {
+		t.Errorf("GET metrics range = %s to %s every %s, want 2h every 1m", metrics.Start, metrics.End, metrics.Step)
+	}
+	if len(metrics.Series) != 1 || len(metrics.Series[0].Points) != 1 || metrics.Series[0].Points[0].Value != 0.5 {
+		t.Errorf("GET metrics series = %+v, want the series Prometheus returned", metrics.Series)
+	}
+
+	if code := doJSON(t, http.MethodGet, metricsURL, "", nil); code != http.StatusUnprocessableEntity {
+		t.Errorf("GET metrics without metric = %d, want %d", code, http.StatusUnprocessableEntity)
+	}
+	if code := doJSON(t, http.MethodGet, metricsURL+"?metric=disk", "", nil); code != http.StatusUnprocessableEntity {
+		t.Errorf("GET metrics of unknown metric = %d, want %d", code, http.StatusUnprocessableEntity)
+	}
+	if code := doJSON(t, http.MethodGet, metricsURL+"?metric=memory", "", nil); code != http.StatusNotFound {
+		t.Errorf("GET metrics the resource has none of = %d, want %d", code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/Bermos/Platform/internal/app"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// maxStateBytes bounds the size of a state Terraform can write.
+const maxStateBytes = 64 << 20
+
+// Methods of Terraform's http backend protocol for locking. Routers that
+// only know the standard methods, such as chi, need them registered.
+const (
+	MethodLock   = "LOCK"
+	MethodUnlock = "UNLOCK"
+)
+
+func registerTerraform(api huma.API, app *app.App) {
+	addSecurityScheme(api, WorkspaceScheme, &huma.SecurityScheme{
+		Type:        "http",
+		Scheme:      "basic",
+		Description: "The name and password of a state workspace, as returned when creating it",
+	})
+	stateErrors := []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusLocked}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "GetTerraformState",
+		Description: "Get a workspace's current state; part of Terraform's http backend protocol",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/terraform/state/{workspace}",
+		Security:    workspaceSecurity,
+		Tags:        []string{"terraform"},
+		Errors:      []int{http.StatusUnauthorized},
+	}, app.GetState)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "PutTerraformState",
+		Description:   "Write a workspace's state as a new version; part of Terraform's http backend protocol",
+		Method:        http.MethodPost,
+		Path:          "/api/v1/terraform/state/{workspace}",
+		Security:      workspaceSecurity,
+		Tags:          []string{"terraform"},
+		DefaultStatus: http.StatusOK,
+		MaxBodyBytes:  maxStateBytes,
+		Errors:        append(stateErrors, http.StatusConflict),
+	}, app.PutState)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "DeleteTerraformState",
+		Description:   "Delete a workspace's state; part of Terraform's http backend protocol",
+		Method:        http.MethodDelete,
+		Path:          "/api/v1/terraform/state/{workspace}",
+		Security:      workspaceSecurity,
+		Tags:          []string{"terraform"},
+		DefaultStatus: http.StatusOK,
+		Errors:        stateErrors,
+	}, app.DeleteState)
+
+	// OpenAPI has no room for custom methods, so locking is undocumented
+	// there.
+	huma.Register(api, huma.Operation{
+		OperationID: "LockTerraformState",
+		Method:      MethodLock,
+		Path:        "/api/v1/terraform/state/{workspace}",
+		Security:    workspaceSecurity,
+		Hidden:      true,
+	}, app.LockState)
+
+	unlockBody := &huma.RequestBody{}
+	huma.Register(api, huma.Operation{
+		OperationID:   "UnlockTerraformState",
+		Method:        MethodUnlock,
+		Path:          "/api/v1/terraform/state/{workspace}",
+		Security:      workspaceSecurity,
+		DefaultStatus: http.StatusOK,
+		RequestBody:   unlockBody,
+		Hidden:        true,
+	}, app.UnlockState)
+	// Huma requires raw bodies, but terraform force-unlock sends none.
+	unlockBody.Required = false
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "CreateTerraformWorkspace",
+		Description:   "Create a state workspace. The response holds its http backend password, which is not shown again.",
+		Method:        http.MethodPost,
+		Path:          "/api/v1/terraform/workspaces",
+		Tags:          []string{"terraform"},
+		DefaultStatus: http.StatusCreated,
+		Errors:        []int{http.StatusConflict, http.StatusUnprocessableEntity},
+	}, app.CreateStateWorkspace)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "GetTerraformWorkspace",
+		Description: "Get a state workspace with its current version and lock, authenticated with the workspace's credentials",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/terraform/workspaces/{workspace}",
+		Security:    workspaceSecurity,
+		Tags:        []string{"terraform"},
+		Errors:      []int{http.StatusUnauthorized},
+	}, app.GetStateWorkspace)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "DeleteTerraformWorkspace",
+		Description:   "Delete an unlocked state workspace with all of its state, authenticated with the workspace's credentials",
+		Method:        http.MethodDelete,
+		Path:          "/api/v1/terraform/workspaces/{workspace}",
+		Security:      workspaceSecurity,
+		Tags:          []string{"terraform"},
+		DefaultStatus: http.StatusNoContent,
+		Errors:        []int{http.StatusUnauthorized, http.StatusLocked},
+	}, app.DeleteStateWorkspace)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "ListTerraformStateVersions",
+		Description: "List a workspace's state versions, oldest first, authenticated with the workspace's credentials",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/terraform/workspaces/{workspace}/versions",
+		Security:    workspaceSecurity,
+		Tags:        []string{"terraform"},
+		Errors:      []int{http.StatusUnauthorized},
+	}, app.ListStateVersions)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "GetTerraformStateVersion",
+		Description: "Get the state of a version, authenticated with the workspace's credentials",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/terraform/workspaces/{workspace}/versions/{version}",
+		Security:    workspaceSecurity,
+		Tags:        []string{"terraform"},
+		Errors:      []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusUnprocessableEntity},
+	}, app.GetStateVersion)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "RestoreTerraformStateVersion",
+		Description:   "Make a version's state current again by writing it as a new version, authenticated with the workspace's credentials",
+		Method:        http.MethodPost,
+		Path:          "/api/v1/terraform/workspaces/{workspace}/versions/{version}/restore",
+		Security:      workspaceSecurity,
+		Tags:          []string{"terraform"},
+		DefaultStatus: http.StatusCreated,
+		Errors:        []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusLocked, http.StatusUnprocessableEntity},
+	}, app.RestoreStateVersion)
+}
@@ -0,0 +1,196 @@
+package v1
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/app"
+	"github.com/Bermos/Platform/internal/tfstate"
+	"github.com/go-chi/chi/v5"
+)
+
+func init() {
+	chi.RegisterMethod(MethodLock)
+	chi.RegisterMethod(MethodUnlock)
+}
+
+// stateClient speaks Terraform's http backend protocol.
+type stateClient struct {
+	t        *testing.T
+	url      string
+	user     string
+	password string
+}
+
+func (c *stateClient) do(method, query, body string, header http.Header) (int, string, http.Header) {
+	c.t.Helper()
+
+	req, err := http.NewRequest(method, c.url+query, strings.NewReader(body))
+	if err != nil {
+		c.t.Fatalf("Failed to build request: %v", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.SetBasicAuth(c.user, c.password)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatalf("Failed to read response: %v", err)
+	}
+	return resp.StatusCode, string(b), resp.Header
+}
+
+// doJSON does a request without body and decodes a successful response
+// into out when it is not nil.
+func (c *stateClient) doJSON(method, query string, out any) int {
+	c.t.Helper()
+
+	code, body, _ := c.do(method, query, "", nil)
+	if out != nil && code < 300 {
+		if err := json.Unmarshal([]byte(body), out); err != nil {
+			c.t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return code
+}
+
+func TestTerraform_StateBackend(t *testing.T) {
+	server := newTestServer(t, app.NewApp())
+
+	var ws app.CreatedStateWorkspaceBody
+	if code := doJSON(t, http.MethodPost, server.URL+"/api/v1/terraform/workspaces", `{"name":"shop-web"}`, &ws); code != http.StatusCreated {
+		t.Fatalf("POST workspace = %d, want %d", code, http.StatusCreated)
+	}
+	if code := doJSON(t, http.MethodPost, server.URL+"/api/v1/terraform/workspaces", `{"name":"shop-web"}`, nil); code != http.StatusConflict {
+		t.Errorf("POST duplicate workspace = %d, want %d", code, http.StatusConflict)
+	}
+	state := &stateClient{t: t, url: server.URL + ws.Address, user: ws.Username, password: ws.Password}
+
+	t.Run("authentication", func(t *testing.T) {
+		for _, c := range []*stateClient{
+			{t: t, url: state.url, user: ws.Username, password: "wrong"},
+			{t: t, url: state.url, user: "shop-db", password: ws.Password},
+			{t: t, url: server.URL + "/api/v1/terraform/state/unknown", user: "unknown", password: ws.Password},
+		} {
+			if code, _, _ := c.do(http.MethodGet, "", "", nil); code != http.StatusUnauthorized {
+				t.Errorf("GET state as %s = %d, want %d", c.user, code, http.StatusUnauthorized)
+			}
+		}
+	})
+
+	if code, body, _ := state.do(http.MethodGet, "", "", nil); code != http.StatusNoContent || body != "" {
+		t.Errorf("GET state of new workspace = %d %q, want %d without body", code, body, http.StatusNoContent)
+	}
+
+	lockInfo := `{"ID":"0b6e9a7c","Operation":"OperationTypeApply","Who":"ci@runner","Version":"1.9.0"}`
+	if code, _, _ := state.do(MethodLock, "", lockInfo, nil); code != http.StatusOK {
+		t.Fatalf("LOCK = %d, want %d", code, http.StatusOK)
+	}
+	if code, body, _ := state.do(MethodLock, "", `{"ID":"77aa","Who":"laptop"}`, nil); code != http.StatusLocked || body != lockInfo {
+		t.Errorf("LOCK of locked state = %d %s, want %d with the holder's information", code, body, http.StatusLocked)
+	}
+
+	v1 := `{"version":4,"serial":1,"lineage":"3f0c","resources":[]}`
+	if code, _, _ := state.do(http.MethodPost, "", v1, nil); code != http.StatusLocked {
+		t.Errorf("POST state without the lock = %d, want %d", code, http.StatusLocked)
+	}
+	if code, _, _ := state.do(http.MethodPost, "?ID=0b6e9a7c", v1, http.Header{"Content-Md5": {"AAAAAAAAAAAAAAAAAAAAAA=="}}); code != http.StatusBadRequest {
+		t.Errorf("POST state with wrong Content-MD5 = %d, want %d", code, http.StatusBadRequest)
+	}
+	if code, _, _ := state.do(http.MethodPost, "?ID=0b6e9a7c", v1, http.Header{"Content-Md5": {tfstate.Checksum([]byte(v1))}}); code != http.StatusOK {
+		t.Fatalf("POST state = %d, want %d", code, http.StatusOK)
+	}
+	if code, _, _ := state.do(MethodUnlock, "", lockInfo, nil); code != http.StatusOK {
+		t.Fatalf("UNLOCK = %d, want %d", code, http.StatusOK)
+	}
+
+	v2 := `{"version":4,"serial":2,"lineage":"3f0c","resources":[{"type":"null_resource"}]}`
+	if code, _, _ := state.do(http.MethodPost, "", v2, nil); code != http.StatusOK {
+		t.Fatalf("POST unlocked state = %d, want %d", code, http.StatusOK)
+	}
+	if code, _, _ := state.do(http.MethodPost, "?ID=0b6e9a7c", v2, nil); code != http.StatusConflict {
+		t.Errorf("POST state with a released lock = %d, want %d", code, http.StatusConflict)
+	}
+	code, body, header := state.do(http.MethodGet, "", "", nil)
+	if code != http.StatusOK || body != v2 {
+		t.Errorf("GET state = %d %s, want %d with the latest state", code, body, http.StatusOK)
+	}
+	if header.Get("Content-MD5") != tfstate.Checksum([]byte(v2)) {
+		t.Errorf("GET state Content-MD5 = %q, want the state's checksum", header.Get("Content-MD5"))
+	}
+
+	// History and rollback.
+	workspace := &stateClient{t: t, url: server.URL + "/api/v1/terraform/workspaces/shop-web", user: ws.Username, password: ws.Password}
+	anonymous := &stateClient{t: t, url: workspace.url}
+	for _, req := range []struct{ method, query string }{
+		{http.MethodGet, ""},
+		{http.MethodGet, "/versions"},
+		{http.MethodGet, "/versions/1"},
+		{http.MethodPost, "/versions/1/restore"},
+		{http.MethodDelete, ""},
+	} {
+		if code := anonymous.doJSON(req.method, req.query, nil); code != http.StatusUnauthorized {
+			t.Errorf("%s workspace%s without credentials = %d, want %d", req.method, req.query, code, http.StatusUnauthorized)
+		}
+	}
+	var versions []app.StateVersionBody
+	if code := workspace.doJSON(http.MethodGet, "/versions", &versions); code != http.StatusOK {
+		t.Fatalf("GET versions = %d, want %d", code, http.StatusOK)
+	}
+	if len(versions) != 2 || versions[0].Serial != 1 || versions[1].Version != 2 {
+		t.Errorf("GET versions = %+v, want both versions oldest first", versions)
+	}
+	var restored app.StateVersionBody
+	if code := workspace.doJSON(http.MethodPost, "/versions/1/restore", &restored); code != http.StatusCreated {
+		t.Fatalf("POST restore = %d, want %d", code, http.StatusCreated)
+	}
+	if restored.Version != 3 || restored.Serial != 3 || restored.Lineage != "3f0c" {
+		t.Errorf("restored version = %+v, want version 3 with serial 3", restored)
+	}
+	if _, body, _ := state.do(http.MethodGet, "", "", nil); strings.Contains(body, "null_resource") {
+		t.Errorf("GET state after restore = %s, want the first state", body)
+	}
+	if code := workspace.doJSON(http.MethodGet, "/versions/9", nil); code != http.StatusNotFound {
+		t.Errorf("GET unknown version = %d, want %d", code, http.StatusNotFound)
+	}
+
+	// A crashed run leaves its lock behind until it is forced open.
+	state.do(MethodLock, "", lockInfo, nil)
+	var got app.StateWorkspaceBody
+	if code := workspace.doJSON(http.MethodGet, "", &got); code != http.StatusOK {
+		t.Fatalf("GET workspace = %d, want %d", code, http.StatusOK)
+	}
+	if got.LatestVersion != 3 || got.Lock == nil || got.Lock.Info["Who"] != "ci@runner" {
+		t.Errorf("GET workspace = %+v, want version 3 locked by ci@runner", got)
+	}
+	if code := workspace.doJSON(http.MethodDelete, "", nil); code != http.StatusLocked {
+		t.Errorf("DELETE locked workspace = %d, want %d", code, http.StatusLocked)
+	}
+	if code, _, _ := state.do(MethodUnlock, "", "", nil); code != http.StatusOK {
+		t.Fatalf("UNLOCK without lock information = %d, want %d", code, http.StatusOK)
+	}
+
+	if code, _, _ := state.do(http.MethodDelete, "", "", nil); code != http.StatusOK {
+		t.Fatalf("DELETE state = %d, want %d", code, http.StatusOK)
+	}
+	if code, _, _ := state.do(http.MethodGet, "", "", nil); code != http.StatusNoContent {
+		t.Errorf("GET deleted state = %d, want %d", code, http.StatusNoContent)
+	}
+	if code := workspace.doJSON(http.MethodDelete, "", nil); code != http.StatusNoContent {
+		t.Errorf("DELETE workspace = %d, want %d", code, http.StatusNoContent)
+	}
+	if code, _, _ := state.do(http.MethodGet, "", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("GET state of deleted workspace = %d, want %d", code, http.StatusUnauthorized)
+	}
+}
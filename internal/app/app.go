@@ -2,15 +2,257 @@ package app
 
 import (
 	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Bermos/Platform/internal"
+	"github.com/Bermos/Platform/internal/database/memory"
+	"github.com/Bermos/Platform/internal/jobs"
+	"github.com/Bermos/Platform/internal/operation"
+	"github.com/Bermos/Platform/internal/project"
+	"github.com/Bermos/Platform/internal/prometheus"
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/Bermos/Platform/internal/terraform"
+	"github.com/Bermos/Platform/internal/tfstate"
+	"github.com/google/uuid"
 )
 
-func NewApp() *App {
-	return &App{}
+// Option configures an App.
+type Option func(*App)
+
+// WithProjectRepository sets the repository used to store projects.
+func WithProjectRepository(r project.Repository) Option {
+	return func(a *App) {
+		a.projects = r
+	}
 }
 
-type App struct {
+// WithServiceRepository sets the repository used to store services.
+func WithServiceRepository(r service.Repository) Option {
+	return func(a *App) {
+		a.services = r
+	}
+}
+
+// WithOperationRepository sets the repository used to store operations.
+func WithOperationRepository(r operation.Repository) Option {
+	return func(a *App) {
+		a.operations = r
+	}
+}
+
+// WithStateRepository sets the repository used to store Terraform state.
+func WithStateRepository(r tfstate.Repository) Option {
+	return func(a *App) {
+		a.states = r
+	}
+}
+
+// WithJobQueue sets the queue operations run on. The app registers its
+// handlers on it; Start and Stop start and stop it.
+func WithJobQueue(q *jobs.Queue) Option {
+	return func(a *App) {
+		a.jobs = q
+	}
+}
+
+// WithProvider sets the system services' objects are provisioned into.
+// Without one, operations only record the services' status.
+func WithProvider(p resource.Provider) Option {
+	return func(a *App) {
+		a.provider = p
+	}
+}
+
+// WithTerraform sets the executor provisioning services of Terraform module
+// resources, in per-service workspaces below workDir. Without it, those
+// operations only record the services' status.
+func WithTerraform(exec terraform.Executor, workDir string) Option {
+	return func(a *App) {
+		a.terraform = exec
+		a.workspaces = terraform.NewWorkspaces(workDir)
+	}
+}
+
+// WithStateBackend keeps the state of the services' Terraform workspaces in
+// the app's own state backend, which Terraform reaches at baseURL, e.g.
+// http://127.0.0.1:8080. Without it, state is kept in the workspaces.
+func WithStateBackend(baseURL string) Option {
+	return func(a *App) {
+		a.stateURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithWorkspaceSweep removes the workspaces of services that no longer
+// exist every interval while the app runs. Deleting a service removes its
+// workspace; the sweep catches those left behind, e.g. by a crash.
+func WithWorkspaceSweep(interval time.Duration) Option {
+	return func(a *App) {
+		a.sweepInterval = interval
+	}
+}
+
+// WithDriftDetection checks the ready services for changes made outside of
+// Mahler every interval while the app runs, see detectDrift.
+func WithDriftDetection(interval time.Duration) Option {
+	return func(a *App) {
+		a.driftInterval = interval
+	}
+}
+
+// WithPrometheus sets the Prometheus server services' metrics are queried
+// from. Without it, metrics are unavailable.
+func WithPrometheus(c *prometheus.Client) Option {
+	return func(a *App) {
+		a.prometheus = c
+	}
 }
 
-func (a App) ListProjects(ctx context.Context, i *struct{}) (*struct{}, error) {
-	return nil, nil
+// WithInstance sets the platform instance whose available resources services
+// can be bound to.
+func WithInstance(i *internal.Instance) Option {
+	return func(a *App) {
+		a.instance = i
+	}
+}
+
+// NewApp creates an App. Without options all state is kept in memory.
+// Operations only run once the app is started.
+func NewApp(opts ...Option) *App {
+	a := &App{
+		projects:          memory.NewProjectRepository(),
+		services:          memory.NewServiceRepository(),
+		operations:        memory.NewOperationRepository(),
+		states:            memory.NewStateRepository(),
+		instance:          &internal.Instance{},
+		pollInterval:      defaultPollInterval,
+		dependencyTimeout: defaultDependencyTimeout,
+	}
+	a.tasksCtx, a.stopTasks = context.WithCancel(context.Background())
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.jobs == nil {
+		a.jobs = jobs.NewQueue(memory.NewJobRepository(), jobs.Options{})
+	}
+	a.jobs.Handle(operationJobKind, a.runOperation)
+	a.jobs.Handle(projectDeletionJobKind, a.runProjectDeletion)
+	return a
+}
+
+// Start starts running operations, including those a previous process left
+// unfinished, and the periodic tasks.
+func (a *App) Start(ctx context.Context) error {
+	if err := a.jobs.Start(ctx); err != nil {
+		return err
+	}
+	if a.workspaces != nil && a.sweepInterval > 0 {
+		a.every(a.sweepInterval, func(ctx context.Context) {
+			if _, err := a.sweepWorkspaces(ctx); err != nil {
+				slog.Error("Failed to sweep workspaces", "error", err)
+			}
+		})
+	}
+	if a.driftInterval > 0 {
+		a.every(a.driftInterval, a.detectDrift)
+	}
+	return nil
+}
+
+// Stop stops the periodic tasks and waits for running operations until ctx
+// is done, see jobs.Queue.Stop.
+func (a *App) Stop(ctx context.Context) error {
+	a.stopTasks()
+	a.tasks.Wait()
+	return a.jobs.Stop(ctx)
+}
+
+// every runs fn every interval until the app stops. Runs do not overlap.
+func (a *App) every(interval time.Duration, fn func(ctx context.Context)) {
+	a.tasks.Add(1)
+	go func() {
+		defer a.tasks.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-a.tasksCtx.Done():
+				return
+			case <-ticker.C:
+				fn(a.tasksCtx)
+			}
+		}
+	}()
+}
+
+// serviceLocks holds a mutex per service.
+type serviceLocks struct {
+	mu    sync.Mutex
+	locks map[uuid.UUID]*sync.Mutex
+}
+
+func (l *serviceLocks) get(id uuid.UUID) *sync.Mutex {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.locks == nil {
+		l.locks = map[uuid.UUID]*sync.Mutex{}
+	}
+	m, ok := l.locks[id]
+	if !ok {
+		m = &sync.Mutex{}
+		l.locks[id] = m
+	}
+	return m
+}
+
+// Lock waits until service id is free and returns the function freeing it.
+func (l *serviceLocks) Lock(id uuid.UUID) func() {
+	m := l.get(id)
+	m.Lock()
+	return m.Unlock
+}
+
+// TryLock is like Lock but returns false instead of waiting.
+func (l *serviceLocks) TryLock(id uuid.UUID) (func(), bool) {
+	m := l.get(id)
+	if !m.TryLock() {
+		return nil, false
+	}
+	return m.Unlock, true
+}
+
+type App struct {
+	projects   project.Repository
+	services   service.Repository
+	operations operation.Repository
+	states     tfstate.Repository
+	jobs       *jobs.Queue
+	provider   resource.Provider
+	instance   *internal.Instance
+	// terraform runs Terraform in the services' workspaces.
+	terraform     terraform.Executor
+	workspaces    *terraform.Workspaces
+	sweepInterval time.Duration
+	// stateURL is the base URL Terraform reaches the state backend at.
+	stateURL string
+	// prometheus is queried for the services' metrics.
+	prometheus *prometheus.Client
+	// pollInterval is how often operations check whether objects became
+	// ready, and how often waiting clients check on operations.
+	pollInterval time.Duration
+	// dependencyTimeout is how long an operation waits for other services.
+	dependencyTimeout time.Duration
+	// driftInterval is how often services are checked for drift.
+	driftInterval time.Duration
+	// busy serializes the operations and drift checks of each service, so
+	// that they do not change its infrastructure or workspace at once.
+	busy serviceLocks
+
+	// tasks are the periodic tasks, which stop when tasksCtx is done.
+	tasks     sync.WaitGroup
+	tasksCtx  context.Context
+	stopTasks context.CancelFunc
 }
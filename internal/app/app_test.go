@@ -10,18 +10,22 @@ import (
 func TestNewApp(t *testing.T) {
 	tests := []struct {
 		name string
-		want *App
+		opts []Option
 	}{
 		{
 			name: "creates_new_app_instance",
-			want: &App{},
+		},
+		{
+			name: "creates_app_with_project_repository",
+			opts: []Option{WithProjectRepository(testutil.NewMockProjectRepository())},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewApp()
+			got := NewApp(tt.opts...)
 			testutil.AssertNotNil(t, got, "NewApp should return non-nil App")
+			testutil.AssertNotNil(t, got.projects, "NewApp should set a project repository")
 
 			// Verify the returned app is of the correct type by checking it's not nil
 			// and is a valid *App pointer
@@ -51,43 +55,49 @@ func TestNewApp_Multiple(t *testing.T) {
 func TestApp_ListProjects(t *testing.T) {
 	tests := []struct {
 		name        string
-		app         *App
+		seed        []string
 		input       *struct{}
-		wantResult  *struct{}
-		wantErr     bool
+		wantNames   []string
 		description string
 	}{
 		{
-			name:        "returns_nil_result_and_nil_error",
-			app:         &App{},
+			name:        "returns_empty_list_without_projects",
 			input:       &struct{}{},
-			wantResult:  nil,
-			wantErr:     false,
-			description: "ListProjects should return nil result and nil error",
+			wantNames:   []string{},
+			description: "ListProjects should return an empty list when nothing was created",
 		},
 		{
 			name:        "handles_nil_input",
-			app:         &App{},
 			input:       nil,
-			wantResult:  nil,
-			wantErr:     false,
+			wantNames:   []string{},
 			description: "ListProjects should handle nil input gracefully",
 		},
+		{
+			name:        "returns_projects_ordered_by_name",
+			seed:        []string{"web", "api"},
+			input:       &struct{}{},
+			wantNames:   []string{"api", "web"},
+			description: "ListProjects should return all projects ordered by name",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := testutil.NewTestContext(t)
+			app := NewApp()
+			for _, name := range tt.seed {
+				_, err := app.CreateProject(ctx, &CreateProjectInput{Body: ProjectInputBody{Name: name}})
+				testutil.AssertNoError(t, err, "seeding project should succeed")
+			}
 
-			gotResult, gotErr := tt.app.ListProjects(ctx, tt.input)
+			gotResult, gotErr := app.ListProjects(ctx, tt.input)
 
-			if tt.wantErr {
-				testutil.AssertError(t, gotErr, "expected error")
-			} else {
-				testutil.AssertNoError(t, gotErr, "should not return error")
+			testutil.AssertNoError(t, gotErr, tt.description)
+			testutil.AssertNotNil(t, gotResult, "result should not be nil")
+			testutil.AssertEqual(t, len(gotResult.Body), len(tt.wantNames), "project count should match")
+			for idx, want := range tt.wantNames {
+				testutil.AssertEqual(t, gotResult.Body[idx].Name, want, "project order should match")
 			}
-
-			testutil.AssertEqual(t, gotResult, tt.wantResult, "result should match expected")
 		})
 	}
 }
@@ -131,7 +141,7 @@ func TestApp_ListProjects_Context(t *testing.T) {
 				return ctx
 			},
 			expectNoError: true,
-			description:   "currently doesn't check context cancellation",
+			description:   "the in-memory repository doesn't check context cancellation",
 		},
 	}
 
@@ -0,0 +1,297 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Bermos/Platform/internal/jobs"
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+// GraphEdge is the dependency of one service on another, drawn as a
+// connection on the architecture canvas.
+type GraphEdge struct {
+	From uuid.UUID `json:"from" doc:"ID of the dependent service"`
+	To   uuid.UUID `json:"to" doc:"ID of the service it depends on"`
+}
+
+// ProjectGraphBody is the dependency graph of a project's services.
+type ProjectGraphBody struct {
+	Levels [][]uuid.UUID `json:"levels" doc:"Service IDs in provisioning order. The services of a level are provisioned in parallel once those of the levels before are ready, and destroyed in parallel once those of the levels after are gone"`
+	Edges  []GraphEdge   `json:"edges" doc:"Dependencies, ordered as the services' dependsOn lists"`
+}
+
+type GetProjectGraphInput struct {
+	ID uuid.UUID `path:"id" doc:"Project ID"`
+}
+
+type ProjectGraphOutput struct {
+	Body *ProjectGraphBody
+}
+
+// GetProjectGraph returns the dependency graph of a project's services and
+// the order they are provisioned in.
+func (a *App) GetProjectGraph(ctx context.Context, i *GetProjectGraphInput) (*ProjectGraphOutput, error) {
+	if _, err := a.projects.Get(ctx, i.ID); err != nil {
+		return nil, projectError(err)
+	}
+	services, err := a.services.ListByProject(ctx, i.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	levels, err := service.Levels(services)
+	if err != nil {
+		// Cycles are rejected when dependencies are declared.
+		return nil, huma.Error500InternalServerError("the project's dependency graph is invalid", err)
+	}
+
+	body := &ProjectGraphBody{Levels: make([][]uuid.UUID, len(levels)), Edges: []GraphEdge{}}
+	for i, level := range levels {
+		for _, svc := range level {
+			body.Levels[i] = append(body.Levels[i], svc.ID)
+		}
+	}
+	for _, svc := range services {
+		for _, dep := range svc.DependsOn {
+			body.Edges = append(body.Edges, GraphEdge{From: svc.ID, To: dep})
+		}
+	}
+	return &ProjectGraphOutput{Body: body}, nil
+}
+
+// checkDependencies validates the dependencies and references of body, for
+// the service with ID self among the services of its project, and returns
+// the dependencies by name.
+func (a *App) checkDependencies(body ServiceInputBody, self uuid.UUID, services []*service.Service) (map[string]*service.Service, []error) {
+	byID := make(map[uuid.UUID]*service.Service, len(services))
+	for _, svc := range services {
+		byID[svc.ID] = svc
+	}
+
+	var details []error
+	deps := make(map[string]*service.Service, len(body.DependsOn))
+	for i, id := range body.DependsOn {
+		location := fmt.Sprintf("body.dependsOn[%d]", i)
+		dep := byID[id]
+		var msg string
+		switch {
+		case id == self:
+			msg = "a service cannot depend on itself"
+		case dep == nil:
+			msg = "no service with this ID exists in the project"
+		case deps[dep.Name] != nil:
+			msg = "dependency is listed twice"
+		case dep.Status == service.StatusDeleting:
+			msg = fmt.Sprintf("service %s is being deleted", dep.Name)
+		default:
+			deps[dep.Name] = dep
+			continue
+		}
+		details = append(details, &huma.ErrorDetail{Location: location, Message: msg, Value: id})
+	}
+
+	graph := []*service.Service{{ID: self, Name: body.Name, DependsOn: body.DependsOn}}
+	for _, svc := range services {
+		if svc.ID != self {
+			graph = append(graph, svc)
+		}
+	}
+	if _, err := service.Levels(graph); errors.Is(err, service.ErrDependencyCycle) {
+		details = append(details, &huma.ErrorDetail{
+			Location: "body.dependsOn",
+			Message:  err.Error(),
+			Value:    body.DependsOn,
+		})
+	}
+
+	for _, ref := range service.References(body.Config) {
+		dep := deps[ref.Service]
+		var msg string
+		switch {
+		case dep == nil:
+			msg = fmt.Sprintf("%s refers to %s, which is not a dependency of the service", ref, ref.Service)
+		case !a.hasOutput(dep, ref.Output):
+			msg = fmt.Sprintf("%s refers to an output service %s does not have", ref, ref.Service)
+		default:
+			continue
+		}
+		details = append(details, &huma.ErrorDetail{Location: "body.config", Message: msg, Value: ref.String()})
+	}
+
+	// Dependents refer to the service by name, so it keeps its name while
+	// they do.
+	if old := byID[self]; old != nil && old.Name != body.Name {
+		for _, dependent := range dependents(services, self) {
+			for _, ref := range service.References(dependent.Config) {
+				if ref.Service == old.Name {
+					details = append(details, &huma.ErrorDetail{
+						Location: "body.name",
+						Message:  fmt.Sprintf("service %s refers to this service as %s", dependent.Name, ref),
+						Value:    body.Name,
+					})
+				}
+			}
+		}
+	}
+	return deps, details
+}
+
+// hasOutput reports whether dep has the named output, or will once
+// provisioned according to its resource's capabilities.
+func (a *App) hasOutput(dep *service.Service, name string) bool {
+	if _, ok := dep.Outputs[name]; ok {
+		return true
+	}
+	res := a.instance.Catalog.Get(dep.ResourceKey)
+	if res == nil {
+		return false
+	}
+	for _, c := range res.Provides() {
+		if slices.ContainsFunc(c.Outputs, func(o resource.Output) bool { return o.Name == name }) {
+			return true
+		}
+	}
+	return false
+}
+
+// previewConfig resolves the references of config with the outputs deps
+// have recorded so far, for validation. Sensitive outputs are left
+// unresolved, so that validation errors cannot reveal them. It reports
+// whether any reference remained.
+func previewConfig(config map[string]any, deps map[string]*service.Service) (map[string]any, bool) {
+	resolved, unresolved := service.Resolve(config, func(ref service.Reference) (any, bool) {
+		o, ok := dependencyOutput(deps, ref)
+		return o.Value, ok && !o.Sensitive
+	})
+	return resolved, len(unresolved) > 0
+}
+
+// dependencyOutput looks up the output ref refers to among deps.
+func dependencyOutput(deps map[string]*service.Service, ref service.Reference) (service.Output, bool) {
+	dep := deps[ref.Service]
+	if dep == nil {
+		return service.Output{}, false
+	}
+	o, ok := dep.Outputs[ref.Output]
+	return o, ok
+}
+
+// isUnresolvedReference reports whether a validation error is about a
+// value that is still a reference. Such values are checked once the
+// dependency's outputs are known.
+func isUnresolvedReference(err error) bool {
+	var detail *huma.ErrorDetail
+	if !errors.As(err, &detail) {
+		return false
+	}
+	s, ok := detail.Value.(string)
+	return ok && service.HasReference(s)
+}
+
+// dependents returns the services depending on the service with ID id.
+func dependents(services []*service.Service, id uuid.UUID) []*service.Service {
+	var found []*service.Service
+	for _, svc := range services {
+		if slices.Contains(svc.DependsOn, id) {
+			found = append(found, svc)
+		}
+	}
+	return found
+}
+
+// dependencyConfig returns the configuration of svc with the references to
+// its dependencies' outputs replaced by their values, validated against its
+// resource's schema. While a dependency is still being provisioned or
+// updated, it returns that dependency instead; a dependency that failed or
+// is going away fails it, as do references left without a dependency.
+// $${ escapes are replaced as well, so every configuration applied goes
+// through here.
+func (a *App) dependencyConfig(ctx context.Context, svc *service.Service) (map[string]any, *service.Service, error) {
+	deps := make(map[string]*service.Service, len(svc.DependsOn))
+	for _, id := range svc.DependsOn {
+		dep, err := a.services.Get(ctx, id)
+		if err != nil {
+			return nil, nil, fmt.Errorf("dependency %s: %w", id, err)
+		}
+		switch dep.Status {
+		case service.StatusPending, service.StatusProvisioning, service.StatusUpdating:
+			return nil, dep, nil
+		case service.StatusFailed, service.StatusDeleting, service.StatusDeleted:
+			return nil, nil, fmt.Errorf("dependency %s is %s", dep.Name, dep.Status)
+		}
+		deps[dep.Name] = dep
+	}
+
+	config, unresolved := service.Resolve(svc.Config, func(ref service.Reference) (any, bool) {
+		o, ok := dependencyOutput(deps, ref)
+		return o.Value, ok
+	})
+	if len(unresolved) > 0 {
+		refs := make([]string, len(unresolved))
+		for i, ref := range unresolved {
+			refs[i] = ref.String()
+		}
+		return nil, nil, fmt.Errorf("unresolved references: %s", strings.Join(refs, ", "))
+	}
+	if res := a.instance.Catalog.Get(svc.ResourceKey); res != nil {
+		if errs := resource.ValidateConfig(res.ConfigSchema(), config, "config"); len(errs) > 0 {
+			return nil, nil, fmt.Errorf("configuration with dependency outputs is invalid: %w", errors.Join(errs...))
+		}
+	}
+	return config, nil, nil
+}
+
+// resolveConfig replaces the configuration of svc, a copy only this run
+// uses, with the one referring to its dependencies' current outputs. The
+// operation is snoozed while a dependency is not ready yet.
+func (r *operationRun) resolveConfig(ctx context.Context, svc *service.Service) error {
+	config, waiting, err := r.app.dependencyConfig(ctx, svc)
+	if err != nil {
+		return err
+	}
+	if waiting != nil {
+		return r.wait(fmt.Sprintf("Waiting for dependency %s, which is %s", waiting.Name, waiting.Status))
+	}
+	svc.Config = config
+	return nil
+}
+
+// awaitDependents snoozes the deletion of svc until the services depending
+// on it are gone. Dependents that are not being deleted fail it.
+func (r *operationRun) awaitDependents(ctx context.Context, svc *service.Service) error {
+	services, err := r.app.services.ListByProject(ctx, svc.ProjectID)
+	if err != nil {
+		return err
+	}
+	found := dependents(services, svc.ID)
+	for _, dependent := range found {
+		if dependent.Status != service.StatusDeleting {
+			return fmt.Errorf("service %s depends on it", dependent.Name)
+		}
+	}
+	if len(found) > 0 {
+		return r.wait(fmt.Sprintf("Waiting for dependent service %s to be deleted", found[0].Name))
+	}
+	return nil
+}
+
+// wait logs msg, unless it is the last message already, and snoozes the
+// operation's job. Once the job has been queued for longer than the
+// dependency timeout, it fails the operation instead, as the service it
+// waits for may never settle, e.g. when its plan is never approved.
+func (r *operationRun) wait(msg string) error {
+	if waited := r.now().Sub(r.queuedAt); waited > r.app.dependencyTimeout {
+		return fmt.Errorf("gave up after %s: %s", waited.Round(time.Second), msg)
+	}
+	if n := len(r.op.Logs); n == 0 || r.op.Logs[n-1].Message != msg {
+		r.op.Logf(r.now(), "%s", msg)
+	}
+	return jobs.Snooze(r.app.pollInterval)
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal"
+	"github.com/Bermos/Platform/internal/operation"
+	"github.com/Bermos/Platform/internal/provider/fake"
+	"github.com/Bermos/Platform/internal/resource"
+	k8s_pod "github.com/Bermos/Platform/internal/resource/k8s-pod"
+	k8s_service "github.com/Bermos/Platform/internal/resource/k8s-service"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+// newDependencyTestApp creates an App offering Kubernetes pods and
+// services provisioned into provider, together with one project called
+// "shop".
+func newDependencyTestApp(t *testing.T, provider resource.Provider) (*App, uuid.UUID) {
+	t.Helper()
+	pod, err := k8s_pod.New(resource.Settings{})
+	testutil.AssertNoError(t, err, "creating the pod resource should succeed")
+	svcResource, err := k8s_service.New(resource.Settings{})
+	testutil.AssertNoError(t, err, "creating the service resource should succeed")
+	instance := &internal.Instance{Name: "Test Instance", Catalog: testutil.NewTestCatalog(pod, svcResource), Namespace: "shop"}
+	app := NewApp(WithInstance(instance), WithProvider(provider))
+	proj, err := app.CreateProject(context.Background(), &CreateProjectInput{Body: ProjectInputBody{Name: "shop"}})
+	testutil.AssertNoError(t, err, "create project should succeed")
+	return app, proj.Body.ID
+}
+
+// dbServiceBody is a Kubernetes Service in front of the Pod called db.
+func dbServiceBody(db uuid.UUID) ServiceInputBody {
+	return ServiceInputBody{
+		Name:      "db-svc",
+		Resource:  k8s_service.Type,
+		Config:    map[string]any{"target": "${db.name}", "ports": []any{map[string]any{"port": 5432}}},
+		DependsOn: []uuid.UUID{db},
+	}
+}
+
+func TestApp_ServiceDependencies(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	provider := fake.NewProvider()
+	provider.SetInitialStatus(resource.Status{Phase: resource.PhaseProgressing, Message: "pulling image"})
+	app, projectID := newDependencyTestApp(t, provider)
+	startApp(t, app)
+
+	db, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: ServiceInputBody{Name: "db", Resource: k8s_pod.Type, Config: map[string]any{"image": "postgres:17"}}})
+	testutil.AssertNoError(t, err, "create should succeed")
+	dbSvc, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: dbServiceBody(db.Body.ServiceID)})
+	testutil.AssertNoError(t, err, "a reference to an output the dependency will have should be accepted")
+	web, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: ServiceInputBody{
+		Name:      "web",
+		Resource:  k8s_pod.Type,
+		Config:    map[string]any{"image": "nginx:1.27", "env": []any{map[string]any{"name": "DATABASE_URL", "value": "${db-svc.url}/shop"}}},
+		DependsOn: []uuid.UUID{dbSvc.Body.ServiceID},
+	}})
+	testutil.AssertNoError(t, err, "create should succeed")
+
+	waited, err := app.OperationMethod(ctx, &OperationMethodInput{ID: dbSvc.Body.ID.String() + ":wait", Timeout: "50ms"})
+	testutil.AssertNoError(t, err, "wait should succeed")
+	testutil.AssertFalse(t, waited.Body.Done, "the dependent should wait while its dependency is provisioning")
+	testutil.AssertEqual(t, waited.Body.Logs[len(waited.Body.Logs)-1].Message, "Waiting for dependency db, which is provisioning", "the wait should be logged once")
+	testutil.AssertEqual(t, provider.Len(), 1, "only the dependency should be applied yet")
+
+	provider.SetInitialStatus(resource.Status{Phase: resource.PhaseReady})
+	objects, err := provider.List(ctx, db.Body.ServiceID)
+	testutil.AssertNoError(t, err, "list should succeed")
+	testutil.AssertNoError(t, provider.SetStatus(objects[0].Key, resource.Status{Phase: resource.PhaseReady}), "setting the status should succeed")
+	for _, accepted := range []*AcceptedOutput{db, dbSvc, web} {
+		op := waitDone(t, app, accepted)
+		testutil.AssertEqual(t, op.State, operation.StateSucceeded, "provisioning should succeed in dependency order")
+	}
+	objects, err = provider.List(ctx, web.Body.ServiceID)
+	testutil.AssertNoError(t, err, "list should succeed")
+	testutil.AssertTrue(t, strings.Contains(string(objects[0].Spec), "http://db-svc.shop.svc.cluster.local:5432/shop"), "the dependency's url should be wired into the Pod")
+	got, err := app.GetService(ctx, &GetServiceInput{ID: web.Body.ServiceID})
+	testutil.AssertNoError(t, err, "get should succeed")
+	testutil.AssertEqual(t, got.Body.Config["env"].([]any)[0].(map[string]any)["value"], any("${db-svc.url}/shop"), "the stored config should keep the reference")
+	testutil.AssertEqual(t, got.Body.DependsOn[0], dbSvc.Body.ServiceID, "the dependency should be listed")
+
+	graph, err := app.GetProjectGraph(ctx, &GetProjectGraphInput{ID: projectID})
+	testutil.AssertNoError(t, err, "get graph should succeed")
+	testutil.AssertEqual(t, len(graph.Body.Levels), 3, "every service should be on its own level")
+	testutil.AssertEqual(t, graph.Body.Levels[0][0], db.Body.ServiceID, "the dependency should come first")
+	testutil.AssertEqual(t, graph.Body.Levels[2][0], web.Body.ServiceID, "the dependent should come last")
+	testutil.AssertEqual(t, len(graph.Body.Edges), 2, "every dependency should be an edge")
+
+	_, err = app.DeleteService(ctx, &DeleteServiceInput{ID: db.Body.ServiceID})
+	assertStatus(t, err, http.StatusConflict, "a service with dependents should not be deleted")
+	var deletes []*DeletionOutput
+	for _, id := range []uuid.UUID{web.Body.ServiceID, dbSvc.Body.ServiceID, db.Body.ServiceID} {
+		deleted, err := app.DeleteService(ctx, &DeleteServiceInput{ID: id})
+		testutil.AssertNoError(t, err, "delete should succeed once the dependents are being deleted")
+		deletes = append(deletes, deleted)
+	}
+	for _, deleted := range deletes {
+		op := waitDeleted(t, app, deleted)
+		testutil.AssertEqual(t, op.State, operation.StateSucceeded, "deleting should succeed in reverse dependency order")
+	}
+	testutil.AssertEqual(t, provider.Len(), 0, "every object should be destroyed")
+}
+
+func TestApp_ServiceDependencyTimeout(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	provider := fake.NewProvider()
+	provider.SetInitialStatus(resource.Status{Phase: resource.PhaseProgressing, Message: "pulling image"})
+	app, projectID := newDependencyTestApp(t, provider)
+	app.dependencyTimeout = 50 * time.Millisecond
+	startApp(t, app)
+
+	db, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: ServiceInputBody{Name: "db", Resource: k8s_pod.Type, Config: map[string]any{"image": "postgres:17"}}})
+	testutil.AssertNoError(t, err, "create should succeed")
+	dbSvc, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: dbServiceBody(db.Body.ServiceID)})
+	testutil.AssertNoError(t, err, "create should succeed")
+
+	op := waitDone(t, app, dbSvc)
+	testutil.AssertEqual(t, op.State, operation.StateFailed, "the dependent should stop waiting for a dependency that does not settle")
+	testutil.AssertTrue(t, strings.HasPrefix(op.Error, "gave up after"), "the error should say the wait timed out")
+	testutil.AssertTrue(t, strings.Contains(op.Error, "Waiting for dependency db, which is provisioning"), "the error should name the dependency")
+	got, err := app.GetService(ctx, &GetServiceInput{ID: dbSvc.Body.ServiceID})
+	testutil.AssertNoError(t, err, "get should succeed")
+	testutil.AssertEqual(t, got.Body.Status, service.StatusFailed, "the dependent should fail")
+
+	_, err = app.OperationMethod(ctx, &OperationMethodInput{ID: db.Body.ID.String() + ":cancel"})
+	testutil.AssertNoError(t, err, "cancelling the dependency's operation should succeed")
+}
+
+func TestApp_ServiceDependencyValidation(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	app, projectID := newDependencyTestApp(t, fake.NewProvider())
+	db, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: ServiceInputBody{Name: "db", Resource: k8s_pod.Type, Config: map[string]any{"image": "postgres:17"}}})
+	testutil.AssertNoError(t, err, "create should succeed")
+	dbID := db.Body.ServiceID
+
+	env := func(value string) map[string]any {
+		return map[string]any{"image": "nginx:1.27", "env": []any{map[string]any{"name": "DB", "value": value}}}
+	}
+	tests := []struct {
+		name string
+		body ServiceInputBody
+	}{
+		{"unknown_dependency", ServiceInputBody{Name: "api", Resource: k8s_pod.Type, Config: env("x"), DependsOn: []uuid.UUID{uuid.New()}}},
+		{"duplicate_dependency", ServiceInputBody{Name: "api", Resource: k8s_pod.Type, Config: env("x"), DependsOn: []uuid.UUID{dbID, dbID}}},
+		{"reference_to_non_dependency", ServiceInputBody{Name: "api", Resource: k8s_pod.Type, Config: env("${db.name}")}},
+		{"reference_to_unknown_output", ServiceInputBody{Name: "api", Resource: k8s_pod.Type, Config: env("${db.password}"), DependsOn: []uuid.UUID{dbID}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: tt.body})
+			assertStatus(t, err, http.StatusUnprocessableEntity, "the dependencies should be rejected")
+		})
+	}
+
+	api, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: ServiceInputBody{Name: "api", Resource: k8s_pod.Type, Config: env("${db.name}"), DependsOn: []uuid.UUID{dbID}}})
+	testutil.AssertNoError(t, err, "create should succeed")
+	_, err = app.UpdateService(ctx, &UpdateServiceInput{ID: dbID, Body: ServiceInputBody{Name: "db", Resource: k8s_pod.Type, Config: map[string]any{"image": "postgres:17"}, DependsOn: []uuid.UUID{api.Body.ServiceID}}})
+	assertStatus(t, err, http.StatusUnprocessableEntity, "a cycle should be rejected")
+	_, err = app.UpdateService(ctx, &UpdateServiceInput{ID: dbID, Body: ServiceInputBody{Name: "database", Resource: k8s_pod.Type, Config: map[string]any{"image": "postgres:17"}}})
+	assertStatus(t, err, http.StatusUnprocessableEntity, "a service referred to by name should keep it")
+}
@@ -0,0 +1,187 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Bermos/Platform/internal/resource"
+	tfmodule "github.com/Bermos/Platform/internal/resource/terraform-module"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/Bermos/Platform/internal/terraform"
+	"github.com/google/uuid"
+)
+
+// driftPlanFile is the saved refresh-only plan of drift detection, kept
+// apart from the plan operations apply.
+const driftPlanFile = "drift.tfplan"
+
+// driftActions translates what applying would do to an object into what
+// happened to it outside of Mahler.
+var driftActions = map[resource.Action]terraform.DiffAction{
+	resource.ActionCreate: terraform.DiffDelete,
+	resource.ActionUpdate: terraform.DiffUpdate,
+	resource.ActionDelete: terraform.DiffCreate,
+}
+
+// detectDrift checks every ready or drifted service for drift, one after
+// the other. Failed checks are logged and tried again on the next run.
+func (a *App) detectDrift(ctx context.Context) {
+	projects, err := a.projects.List(ctx)
+	if err != nil {
+		slog.Error("Failed to list projects for drift detection", "error", err)
+		return
+	}
+	for _, proj := range projects {
+		services, err := a.services.ListByProject(ctx, proj.ID)
+		if err != nil {
+			slog.Error("Failed to list services for drift detection", "project", proj.ID, "error", err)
+			continue
+		}
+		for _, svc := range services {
+			if ctx.Err() != nil {
+				return
+			}
+			if svc.Status != service.StatusReady && svc.Status != service.StatusDrifted {
+				continue
+			}
+			if err := a.checkDrift(ctx, svc); err != nil {
+				slog.Warn("Drift detection failed", "service", svc.ID, "error", err)
+			}
+		}
+	}
+}
+
+// checkDrift compares the infrastructure of svc with its configuration and
+// records the outcome. Services Mahler does not provision are skipped, as
+// are those an operation is working on; they are checked on the next run.
+func (a *App) checkDrift(ctx context.Context, svc *service.Service) error {
+	unlock, ok := a.busy.TryLock(svc.ID)
+	if !ok {
+		return nil
+	}
+	defer unlock()
+	// An operation may have been requested since the service was listed;
+	// once its job runs, it waits for this check to finish.
+	svc, err := a.services.Get(ctx, svc.ID)
+	if err != nil || (svc.Status != service.StatusReady && svc.Status != service.StatusDrifted) {
+		return err
+	}
+
+	config, waiting, err := a.dependencyConfig(ctx, svc)
+	if err != nil || waiting != nil {
+		// Checked again once its dependencies are settled.
+		return err
+	}
+	svc.Config = config
+
+	var changes *terraform.Diff
+	res := a.instance.Catalog.Get(svc.ResourceKey)
+	mod, isModule := res.(*tfmodule.Resource)
+	lc, isLifecycle := res.(resource.Lifecycle)
+	switch {
+	case isModule && a.terraform != nil:
+		changes, err = a.moduleDrift(ctx, svc, mod)
+	case isLifecycle && a.provider != nil:
+		changes, err = a.lifecycleDrift(ctx, svc, lc)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return a.recordDrift(ctx, svc.ID, changes)
+}
+
+// moduleDrift runs a refresh-only plan, which compares the state with the
+// real infrastructure without planning any change.
+func (a *App) moduleDrift(ctx context.Context, svc *service.Service, mod *tfmodule.Resource) (*terraform.Diff, error) {
+	ctx, err := a.stateCredentials(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := a.writeWorkspace(svc, mod)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.terraform.Init(ctx, dir, terraform.InitOptions{MigrateState: true}); err != nil {
+		return nil, err
+	}
+	summary, err := a.terraform.Plan(ctx, dir, terraform.PlanOptions{RefreshOnly: true, Out: driftPlanFile})
+	if err != nil {
+		return nil, err
+	}
+	if !summary.HasChanges {
+		return &terraform.Diff{Resources: []terraform.ResourceDiff{}}, nil
+	}
+	plan, err := a.terraform.Show(ctx, dir, driftPlanFile)
+	if err != nil {
+		return nil, err
+	}
+	return terraform.NewDiff(plan.ResourceDrift)
+}
+
+// lifecycleDrift plans the service's configuration; every change the plan
+// would make undoes one made outside of Mahler.
+func (a *App) lifecycleDrift(ctx context.Context, svc *service.Service, lc resource.Lifecycle) (*terraform.Diff, error) {
+	plan, err := lc.Plan(ctx, a.provider, resource.Request{Service: a.serviceRef(svc), Config: svc.Config})
+	if err != nil {
+		return nil, err
+	}
+	changes := &terraform.Diff{Resources: make([]terraform.ResourceDiff, len(plan.Changes))}
+	for i, c := range plan.Changes {
+		changes.Resources[i] = terraform.ResourceDiff{
+			Address:    c.Object.String(),
+			Type:       c.Object.Kind,
+			Action:     driftActions[c.Action],
+			Attributes: []terraform.AttributeChange{},
+		}
+	}
+	return changes, nil
+}
+
+// recordDrift stores the drift found on service id. A ready service with
+// drift becomes drifted, which is recorded in its history with the changed
+// objects and logged; a drifted one without becomes ready again. Only the
+// drift is written, so a configuration stored meanwhile is kept; services
+// an operation has taken over since the check began are left alone.
+func (a *App) recordDrift(ctx context.Context, id uuid.UUID, changes *terraform.Diff) error {
+	svc, err := a.services.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if svc.Status != service.StatusReady && svc.Status != service.StatusDrifted {
+		return nil
+	}
+
+	if changes.Empty() {
+		if svc.Status != service.StatusDrifted {
+			return nil
+		}
+		if err := a.services.UpdateDrift(ctx, svc.ID, nil); err != nil {
+			return err
+		}
+		slog.Info("Drift resolved", "project", svc.ProjectID, "service", svc.ID, "name", svc.Name)
+		return a.transition(ctx, svc, service.StatusReady, service.ActorSystem, "drift no longer detected")
+	}
+
+	drift := &service.Drift{DetectedAt: time.Now().UTC(), Changes: changes}
+	if svc.Status == service.StatusDrifted && svc.Drift != nil {
+		// The service has been drifted since the first check found it.
+		drift.DetectedAt = svc.Drift.DetectedAt
+	}
+	if err := a.services.UpdateDrift(ctx, svc.ID, drift); err != nil {
+		return err
+	}
+	if svc.Status == service.StatusDrifted {
+		return nil
+	}
+	addresses := make([]string, len(changes.Resources))
+	for i, r := range changes.Resources {
+		addresses[i] = fmt.Sprintf("%s %s", r.Address, r.Action)
+	}
+	slog.Warn("Drift detected", "project", svc.ProjectID, "service", svc.ID, "name", svc.Name, "changes", addresses)
+	return a.transition(ctx, svc, service.StatusDrifted, service.ActorSystem, "drift detected: "+strings.Join(addresses, ", "))
+}
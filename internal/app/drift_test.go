@@ -0,0 +1,131 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/operation"
+	"github.com/Bermos/Platform/internal/provider/fake"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/Bermos/Platform/internal/terraform"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+func TestApp_DriftDetection(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	provider := fake.NewProvider()
+	app, projectID := newPodTestApp(t, provider)
+	startApp(t, app)
+
+	created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: podBody})
+	testutil.AssertNoError(t, err, "create should succeed")
+	waitDone(t, app, created)
+	id := created.Body.ServiceID
+
+	app.detectDrift(ctx)
+	svc, _ := app.GetService(ctx, &GetServiceInput{ID: id})
+	testutil.AssertEqual(t, svc.Body.Status, service.StatusReady, "a service as configured should stay ready")
+	testutil.AssertTrue(t, svc.Body.Drift == nil, "no drift should be recorded")
+
+	objects, err := provider.List(ctx, id)
+	testutil.AssertNoError(t, err, "list should succeed")
+	pod := objects[0]
+	testutil.AssertNoError(t, provider.Delete(ctx, pod.Key), "deleting the Pod behind Mahler's back should succeed")
+
+	app.detectDrift(ctx)
+	svc, _ = app.GetService(ctx, &GetServiceInput{ID: id})
+	testutil.AssertEqual(t, svc.Body.Status, service.StatusDrifted, "the service should be drifted")
+	testutil.AssertTrue(t, svc.Body.Drift != nil, "the drift should be recorded")
+	testutil.AssertEqual(t, len(svc.Body.Drift.Changes.Resources), 1, "the drift should list the Pod")
+	testutil.AssertEqual(t, svc.Body.Drift.Changes.Resources[0].Action, terraform.DiffDelete, "the Pod should be reported deleted")
+	testutil.AssertEqual(t, svc.Body.Drift.Changes.Resources[0].Address, pod.Key.String(), "the drift should name the Pod")
+	history, err := app.ListServiceHistory(ctx, &ListServiceHistoryInput{ID: id})
+	testutil.AssertNoError(t, err, "history should succeed")
+	last := history.Body[len(history.Body)-1]
+	testutil.AssertEqual(t, last.Actor, service.ActorSystem, "drift should be detected by the system")
+	testutil.AssertTrue(t, strings.HasPrefix(last.Reason, "drift detected: "), "the reason should describe the drift, got "+last.Reason)
+
+	detectedAt, transitions := svc.Body.Drift.DetectedAt, len(history.Body)
+	app.detectDrift(ctx)
+	svc, _ = app.GetService(ctx, &GetServiceInput{ID: id})
+	testutil.AssertEqual(t, svc.Body.Drift.DetectedAt, detectedAt, "checking a drifted service again should keep when the drift was detected")
+	history, err = app.ListServiceHistory(ctx, &ListServiceHistoryInput{ID: id})
+	testutil.AssertNoError(t, err, "history should succeed")
+	testutil.AssertEqual(t, len(history.Body), transitions, "drift found again should not be recorded again")
+
+	// A drifted service is checked again, and becomes ready once the
+	// change is undone outside of Mahler.
+	testutil.AssertNoError(t, provider.Put(ctx, pod), "restoring the Pod should succeed")
+	app.detectDrift(ctx)
+	svc, _ = app.GetService(ctx, &GetServiceInput{ID: id})
+	testutil.AssertEqual(t, svc.Body.Status, service.StatusReady, "the service should be ready again")
+	testutil.AssertTrue(t, svc.Body.Drift == nil, "the drift should be cleared")
+	history, err = app.ListServiceHistory(ctx, &ListServiceHistoryInput{ID: id})
+	testutil.AssertNoError(t, err, "history should succeed")
+	last = history.Body[len(history.Body)-1]
+	testutil.AssertEqual(t, last.To, service.StatusReady, "the resolution should be recorded")
+	testutil.AssertEqual(t, last.Actor, service.ActorSystem, "drift should be resolved by the system")
+	testutil.AssertEqual(t, last.Reason, "drift no longer detected", "the reason should say why")
+}
+
+func TestApp_ReconcileService(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	provider := fake.NewProvider()
+	app, projectID := newPodTestApp(t, provider)
+
+	created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: podBody})
+	testutil.AssertNoError(t, err, "create should succeed")
+	_, err = app.ReconcileService(ctx, &ReconcileServiceInput{ID: created.Body.ServiceID})
+	assertStatus(t, err, http.StatusConflict, "a service with a running operation should not be reconciled")
+
+	startApp(t, app)
+	waitDone(t, app, created)
+	objects, _ := provider.List(ctx, created.Body.ServiceID)
+	testutil.AssertNoError(t, provider.Delete(ctx, objects[0].Key), "deleting the Pod should succeed")
+	app.detectDrift(ctx)
+
+	reconciled, err := app.ReconcileService(ctx, &ReconcileServiceInput{ID: created.Body.ServiceID})
+	testutil.AssertNoError(t, err, "reconcile should succeed")
+	testutil.AssertEqual(t, reconciled.Body.Kind, operation.KindReconcileService, "a reconcile operation should be started")
+	op := waitDone(t, app, reconciled)
+	testutil.AssertEqual(t, op.State, operation.StateSucceeded, "reconciling should succeed")
+	testutil.AssertEqual(t, provider.Len(), 1, "the Pod should be applied again")
+	svc, _ := app.GetService(ctx, &GetServiceInput{ID: created.Body.ServiceID})
+	testutil.AssertEqual(t, svc.Body.Status, service.StatusReady, "the service should be ready")
+	testutil.AssertTrue(t, svc.Body.Drift == nil, "the drift should be cleared")
+
+	_, err = app.ReconcileService(ctx, &ReconcileServiceInput{ID: uuid.New()})
+	assertStatus(t, err, http.StatusNotFound, "a missing service should not be reconciled")
+}
+
+func TestApp_ModuleDrift(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	exec := &fakeTerraform{}
+	app, projectID, _ := newModuleTestApp(t, exec)
+	exec.planChanges(resourceChange("create"))
+	created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: ServiceInputBody{Name: "cache", Resource: "cache"}})
+	testutil.AssertNoError(t, err, "create should succeed")
+	waitDone(t, app, created)
+
+	exec.driftChanges(resourceChange("update"))
+	applied := len(exec.Commands())
+	app.detectDrift(ctx)
+	testutil.AssertEqual(t, strings.Join(exec.Commands()[applied:], ", "), "init, plan -refresh-only, show drift.tfplan", "drift should be found by a refresh-only plan")
+	svc, _ := app.GetService(ctx, &GetServiceInput{ID: created.Body.ServiceID})
+	testutil.AssertEqual(t, svc.Body.Status, service.StatusDrifted, "the service should be drifted")
+	testutil.AssertEqual(t, svc.Body.Drift.Changes.Count(terraform.DiffUpdate), 1, "the changed resource should be reported")
+
+	// Services an operation is working on are left for the next run.
+	unlock := app.busy.Lock(created.Body.ServiceID)
+	exec.driftChanges()
+	checked := len(exec.Commands())
+	app.detectDrift(ctx)
+	unlock()
+	testutil.AssertEqual(t, len(exec.Commands()), checked, "the workspace should not be touched while an operation uses it")
+
+	app.detectDrift(ctx)
+	svc, _ = app.GetService(ctx, &GetServiceInput{ID: created.Body.ServiceID})
+	testutil.AssertEqual(t, svc.Body.Status, service.StatusReady, "the service should be ready once the drift is gone")
+}
@@ -0,0 +1,239 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Bermos/Platform/internal/operation"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+// ImpactService is a service a deletion affects.
+type ImpactService struct {
+	ID     uuid.UUID      `json:"id"`
+	Name   string         `json:"name"`
+	Status service.Status `json:"status"`
+}
+
+// OutputReference is a reference in a service's configuration to an output
+// of a service being deleted.
+type OutputReference struct {
+	ServiceID   uuid.UUID `json:"serviceId" doc:"ID of the service whose configuration refers to the output"`
+	ServiceName string    `json:"serviceName"`
+	Reference   string    `json:"reference" doc:"The reference, e.g. ${db.host}"`
+}
+
+// ImpactBody describes what deleting services would break.
+type ImpactBody struct {
+	Services          []*ImpactService   `json:"services" doc:"Services the deletion removes, each before the services it depends on"`
+	Dependents        []*ImpactService   `json:"dependents" doc:"Services left in place that depend on a removed service, directly or through others, ordered by name"`
+	ReferencedOutputs []*OutputReference `json:"referencedOutputs" doc:"References of the dependents to outputs of removed services"`
+	BoundSecrets      []*OutputReference `json:"boundSecrets" doc:"References of the dependents to sensitive outputs of removed services, such as passwords"`
+}
+
+// breaks returns the names of the dependents the deletion would break.
+// Dependents being deleted themselves do not count.
+func (b *ImpactBody) breaks() []string {
+	var names []string
+	for _, dep := range b.Dependents {
+		if dep.Status != service.StatusDeleting {
+			names = append(names, dep.Name)
+		}
+	}
+	return names
+}
+
+// DeletionBody reports what a deletion affects and, unless it is a dry
+// run, the operations carrying it out.
+type DeletionBody struct {
+	DryRun     bool             `json:"dryRun"`
+	Impact     *ImpactBody      `json:"impact"`
+	Operations []*OperationBody `json:"operations" doc:"Operations deleting the services, dependents first; empty for dry runs"`
+}
+
+// DeletionOutput is the response to deleting a service or project: 200
+// with the report of a dry run, 202 once deleting has started, or 204 for
+// a project without services, which is deleted at once.
+type DeletionOutput struct {
+	Status   int
+	Location string `header:"Location" doc:"URL of the operation deleting the requested service"`
+	Body     *DeletionBody
+}
+
+// deletion is a planned deletion of services of one project.
+type deletion struct {
+	impact *ImpactBody
+	// removed are the services to delete, dependents first.
+	removed []*service.Service
+	// detach are the services left in place that depend on removed ones
+	// directly.
+	detach []*service.Service
+}
+
+// planDeletion works out the impact of deleting the services with the given
+// IDs among the services of their project. With cascade, the services
+// depending on them are deleted too.
+func (a *App) planDeletion(services []*service.Service, ids []uuid.UUID, cascade bool) (*deletion, error) {
+	removed := make(map[uuid.UUID]bool, len(services))
+	for _, id := range ids {
+		removed[id] = true
+	}
+	// Follow the dependencies backwards to every service that relies on a
+	// removed one, directly or through others.
+	affected := make(map[uuid.UUID]bool, len(services))
+	for changed := true; changed; {
+		changed = false
+		for _, svc := range services {
+			if removed[svc.ID] || affected[svc.ID] {
+				continue
+			}
+			if slices.ContainsFunc(svc.DependsOn, func(id uuid.UUID) bool { return removed[id] || affected[id] }) {
+				affected[svc.ID], changed = true, true
+			}
+		}
+	}
+	if cascade {
+		for id := range affected {
+			removed[id] = true
+		}
+	}
+
+	d := &deletion{impact: &ImpactBody{
+		Services:          []*ImpactService{},
+		Dependents:        []*ImpactService{},
+		ReferencedOutputs: []*OutputReference{},
+		BoundSecrets:      []*OutputReference{},
+	}}
+	byID := make(map[uuid.UUID]*service.Service, len(services))
+	var doomed []*service.Service
+	for _, svc := range services {
+		byID[svc.ID] = svc
+		switch {
+		case removed[svc.ID]:
+			doomed = append(doomed, svc)
+		case affected[svc.ID]:
+			d.impact.Dependents = append(d.impact.Dependents, newImpactService(svc))
+		}
+	}
+	slices.SortFunc(d.impact.Dependents, func(a, b *ImpactService) int { return strings.Compare(a.Name, b.Name) })
+
+	order, err := service.Order(doomed)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(order)
+	d.removed = order
+	for _, svc := range order {
+		d.impact.Services = append(d.impact.Services, newImpactService(svc))
+	}
+
+	for _, svc := range services {
+		if removed[svc.ID] {
+			continue
+		}
+		deps := map[string]*service.Service{}
+		for _, id := range svc.DependsOn {
+			if removed[id] && byID[id] != nil {
+				deps[byID[id].Name] = byID[id]
+			}
+		}
+		if len(deps) == 0 {
+			continue
+		}
+		d.detach = append(d.detach, svc)
+		for _, ref := range service.References(svc.Config) {
+			dep := deps[ref.Service]
+			if dep == nil {
+				continue
+			}
+			r := &OutputReference{ServiceID: svc.ID, ServiceName: svc.Name, Reference: ref.String()}
+			if a.isSensitiveOutput(dep, ref.Output) {
+				d.impact.BoundSecrets = append(d.impact.BoundSecrets, r)
+			} else {
+				d.impact.ReferencedOutputs = append(d.impact.ReferencedOutputs, r)
+			}
+		}
+	}
+	return d, nil
+}
+
+// isSensitiveOutput reports whether the named output of svc is a secret,
+// going by its recorded outputs or else its resource's capabilities.
+func (a *App) isSensitiveOutput(svc *service.Service, name string) bool {
+	if o, ok := svc.Outputs[name]; ok {
+		return o.Sensitive
+	}
+	res := a.instance.Catalog.Get(svc.ResourceKey)
+	if res == nil {
+		return false
+	}
+	for _, c := range res.Provides() {
+		for _, o := range c.Outputs {
+			if o.Name == name && o.Sensitive {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// start starts deleting the planned services. Services being deleted
+// already are left to their operations. Dependents left in place lose
+// their dependencies on removed services, unless they are being deleted
+// too; references to the outputs fail their next operation.
+func (d *deletion) start(ctx context.Context, a *App) ([]*AcceptedOutput, error) {
+	var idle []*service.Service
+	for _, svc := range d.removed {
+		if svc.Status == service.StatusDeleting {
+			continue
+		}
+		if checkIdle(svc, service.StatusDeleting) != nil {
+			return nil, huma.Error409Conflict(fmt.Sprintf("service %s is %s; wait for its current operation to finish", svc.Name, svc.Status))
+		}
+		idle = append(idle, svc)
+	}
+
+	removed := make(map[uuid.UUID]bool, len(d.removed))
+	for _, svc := range d.removed {
+		removed[svc.ID] = true
+	}
+	for _, svc := range d.detach {
+		if svc.Status == service.StatusDeleting {
+			// Removed services are only destroyed after it.
+			continue
+		}
+		svc.DependsOn = slices.DeleteFunc(slices.Clone(svc.DependsOn), func(id uuid.UUID) bool { return removed[id] })
+		svc.UpdatedAt = time.Now().UTC()
+		if err := a.services.Update(ctx, svc); err != nil {
+			return nil, serviceError(err)
+		}
+	}
+
+	accepted := make([]*AcceptedOutput, 0, len(idle))
+	for _, svc := range idle {
+		out, err := a.startOperation(ctx, operation.KindDeleteService, svc, service.StatusDeleting)
+		if err != nil {
+			return nil, err
+		}
+		accepted = append(accepted, out)
+	}
+	return accepted, nil
+}
+
+func newImpactService(svc *service.Service) *ImpactService {
+	return &ImpactService{ID: svc.ID, Name: svc.Name, Status: svc.Status}
+}
+
+// deletionBody lists the operations of a deletion that has started.
+func deletionBody(impact *ImpactBody, accepted []*AcceptedOutput) *DeletionBody {
+	body := &DeletionBody{Impact: impact, Operations: make([]*OperationBody, len(accepted))}
+	for i, out := range accepted {
+		body.Operations[i] = out.Body
+	}
+	return body
+}
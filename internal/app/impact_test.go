@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal"
+	"github.com/Bermos/Platform/internal/operation"
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+func TestApp_DeletionImpact(t *testing.T) {
+	type appConfig struct {
+		DatabaseHost     string `json:"databaseHost,omitempty"`
+		DatabasePassword string `json:"databasePassword,omitempty"`
+	}
+	ctx := testutil.NewTestContext(t)
+	instance := &internal.Instance{
+		Name: "Test Instance",
+		Catalog: testutil.NewTestCatalog(
+			testutil.NewMockResource().WithKey("postgres").WithProvides(resource.Provide(resource.PostgresConnection)),
+			testutil.NewMockResource().WithKey("app").WithConfigSchema(resource.SchemaFor[appConfig]()),
+		),
+	}
+	app := NewApp(WithInstance(instance))
+	startApp(t, app)
+	proj, err := app.CreateProject(ctx, &CreateProjectInput{Body: ProjectInputBody{Name: "shop"}})
+	testutil.AssertNoError(t, err, "create project should succeed")
+	projectID := proj.Body.ID
+
+	create := func(body ServiceInputBody) uuid.UUID {
+		t.Helper()
+		created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: body})
+		testutil.AssertNoError(t, err, "create should succeed")
+		waitDone(t, app, created)
+		return created.Body.ServiceID
+	}
+	db := create(ServiceInputBody{Name: "db", Resource: "postgres"})
+	api := create(ServiceInputBody{
+		Name:      "api",
+		Resource:  "app",
+		Config:    map[string]any{"databaseHost": "${db.host}", "databasePassword": "${db.password}"},
+		DependsOn: []uuid.UUID{db},
+	})
+	create(ServiceInputBody{Name: "worker", Resource: "app", DependsOn: []uuid.UUID{api}})
+
+	report, err := app.DeleteService(ctx, &DeleteServiceInput{ID: db, DryRun: true})
+	testutil.AssertNoError(t, err, "a dry run should succeed")
+	testutil.AssertEqual(t, report.Status, http.StatusOK, "a dry run should report")
+	impact := report.Body.Impact
+	testutil.AssertEqual(t, len(impact.Services), 1, "only the service itself should be removed")
+	testutil.AssertEqual(t, len(impact.Dependents), 2, "direct and indirect dependents should be reported")
+	testutil.AssertEqual(t, impact.Dependents[0].Name, "api", "dependents should be ordered by name")
+	testutil.AssertEqual(t, len(impact.ReferencedOutputs), 1, "the referenced host should be reported")
+	testutil.AssertEqual(t, impact.ReferencedOutputs[0].Reference, "${db.host}", "the reference should be named")
+	testutil.AssertEqual(t, len(impact.BoundSecrets), 1, "the bound password should be reported")
+	testutil.AssertEqual(t, impact.BoundSecrets[0].ServiceName, "api", "the service holding the secret should be named")
+	testutil.AssertEqual(t, len(report.Body.Operations), 0, "a dry run should start nothing")
+
+	_, err = app.DeleteService(ctx, &DeleteServiceInput{ID: db})
+	assertStatus(t, err, http.StatusConflict, "deleting a service with dependents should need force or cascade")
+	_, err = app.DeleteService(ctx, &DeleteServiceInput{ID: db, Force: true, Cascade: true})
+	assertStatus(t, err, http.StatusUnprocessableEntity, "force and cascade should exclude each other")
+
+	report, err = app.DeleteService(ctx, &DeleteServiceInput{ID: api, Cascade: true, DryRun: true})
+	testutil.AssertNoError(t, err, "a dry run should succeed")
+	testutil.AssertEqual(t, len(report.Body.Impact.Services), 2, "cascading should remove the dependents")
+	testutil.AssertEqual(t, report.Body.Impact.Services[0].Name, "worker", "dependents should be removed first")
+	testutil.AssertEqual(t, len(report.Body.Impact.Dependents), 0, "nothing should be left broken")
+
+	forced, err := app.DeleteService(ctx, &DeleteServiceInput{ID: db, Force: true})
+	testutil.AssertNoError(t, err, "a forced delete should succeed")
+	testutil.AssertEqual(t, forced.Status, http.StatusAccepted, "deleting should start")
+	testutil.AssertEqual(t, waitDeleted(t, app, forced).State, operation.StateSucceeded, "deleting should succeed")
+	got, err := app.GetService(ctx, &GetServiceInput{ID: api})
+	testutil.AssertNoError(t, err, "the dependent should be kept")
+	testutil.AssertEqual(t, len(got.Body.DependsOn), 0, "the dependent should lose the dependency")
+	reconciled, err := app.ReconcileService(ctx, &ReconcileServiceInput{ID: api})
+	testutil.AssertNoError(t, err, "reconcile should start")
+	op := waitDone(t, app, reconciled)
+	testutil.AssertEqual(t, op.State, operation.StateFailed, "the dependent's references should be broken")
+
+	report, err = app.DeleteProject(ctx, &DeleteProjectInput{ID: projectID, DryRun: true})
+	testutil.AssertNoError(t, err, "a dry run should succeed")
+	testutil.AssertEqual(t, len(report.Body.Impact.Services), 2, "every service should be removed")
+	_, err = app.DeleteProject(ctx, &DeleteProjectInput{ID: projectID})
+	assertStatus(t, err, http.StatusConflict, "deleting a project with services should need cascade")
+
+	deleted, err := app.DeleteProject(ctx, &DeleteProjectInput{ID: projectID, Cascade: true})
+	testutil.AssertNoError(t, err, "a cascading delete should succeed")
+	testutil.AssertEqual(t, len(deleted.Body.Operations), 2, "every service should be deleted")
+	testutil.AssertEqual(t, waitDeleted(t, app, deleted).State, operation.StateSucceeded, "deleting should succeed")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err = app.GetProject(context.Background(), &GetProjectInput{ID: projectID})
+		if err != nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assertStatus(t, err, http.StatusNotFound, "the project should be deleted once its services are gone")
+}
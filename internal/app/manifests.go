@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+// ManifestsBody holds the Kubernetes objects rendered for a service.
+type ManifestsBody struct {
+	Objects []map[string]any `json:"objects" doc:"Rendered objects in the order they are applied"`
+	YAML    string           `json:"yaml" doc:"The objects as a multi-document YAML stream, ready for kubectl apply -f"`
+}
+
+type GetServiceManifestsInput struct {
+	ID uuid.UUID `path:"id" doc:"Service ID"`
+}
+
+type ManifestsOutput struct {
+	Body *ManifestsBody
+}
+
+func (a *App) GetServiceManifests(ctx context.Context, i *GetServiceManifestsInput) (*ManifestsOutput, error) {
+	svc, err := a.services.Get(ctx, i.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	objects, err := a.render(svc)
+	if err != nil {
+		return nil, err
+	}
+
+	yamlOut, err := k8s.YAML(objects...)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("rendering manifests failed", err)
+	}
+	body := &ManifestsBody{Objects: make([]map[string]any, 0, len(objects)), YAML: string(yamlOut)}
+	for _, obj := range objects {
+		b, err := json.Marshal(obj)
+		if err != nil {
+			return nil, huma.Error500InternalServerError("rendering manifests failed", err)
+		}
+		var m map[string]any
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, huma.Error500InternalServerError("rendering manifests failed", err)
+		}
+		body.Objects = append(body.Objects, m)
+	}
+	return &ManifestsOutput{Body: body}, nil
+}
+
+// render returns the Kubernetes objects of svc. It fails with a 404 when
+// the service's resource is not deployed as Kubernetes objects.
+func (a *App) render(svc *service.Service) ([]k8s.Object, error) {
+	renderer, ok := a.instance.Catalog.Get(svc.ResourceKey).(k8s.Renderer)
+	if !ok {
+		return nil, huma.Error404NotFound(fmt.Sprintf("resource %q has no Kubernetes manifests", svc.ResourceKey))
+	}
+	objects, err := renderer.Render(k8s.OwnerOf(a.serviceRef(svc)), svc.Config)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("rendering manifests failed", err)
+	}
+	return objects, nil
+}
+
+// serviceRef identifies svc to the resource provisioning it.
+func (a *App) serviceRef(svc *service.Service) resource.ServiceRef {
+	return resource.ServiceRef{
+		ProjectID: svc.ProjectID,
+		ServiceID: svc.ID,
+		Name:      svc.Name,
+		Namespace: a.instance.Namespace,
+	}
+}
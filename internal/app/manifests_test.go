@@ -0,0 +1,103 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Bermos/Platform/internal"
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/resource"
+	k8s_deployment "github.com/Bermos/Platform/internal/resource/k8s-deployment"
+	k8s_ingress "github.com/Bermos/Platform/internal/resource/k8s-ingress"
+	k8s_pod "github.com/Bermos/Platform/internal/resource/k8s-pod"
+	k8s_service "github.com/Bermos/Platform/internal/resource/k8s-service"
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+func TestApp_GetServiceManifests(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	pod, err := k8s_pod.New(resource.Settings{})
+	testutil.AssertNoError(t, err, "creating the pod resource should succeed")
+	instance := &internal.Instance{
+		Name:      "Test Instance",
+		Catalog:   testutil.NewTestCatalog(pod, testutil.NewMockResource().WithKey("static")),
+		Namespace: "shop-ns",
+	}
+	app := NewApp(WithInstance(instance))
+	proj, err := app.CreateProject(ctx, &CreateProjectInput{Body: ProjectInputBody{Name: "shop"}})
+	testutil.AssertNoError(t, err, "create project should succeed")
+
+	web, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: proj.Body.ID, Body: ServiceInputBody{
+		Name:     "web",
+		Resource: k8s_pod.Type,
+		Config:   map[string]any{"image": "nginx:1.27", "labels": map[string]any{"team": "web"}},
+	}})
+	testutil.AssertNoError(t, err, "create pod service should succeed")
+
+	got, err := app.GetServiceManifests(ctx, &GetServiceManifestsInput{ID: web.Body.ServiceID})
+	testutil.AssertNoError(t, err, "rendering should succeed")
+	testutil.AssertEqual(t, len(got.Body.Objects), 1, "a pod service renders one object")
+	testutil.AssertEqual(t, got.Body.Objects[0]["kind"], any("Pod"), "object should be a Pod")
+	meta := got.Body.Objects[0]["metadata"].(map[string]any)
+	testutil.AssertEqual(t, meta["namespace"], any("shop-ns"), "object should be in the instance namespace")
+	labels := meta["labels"].(map[string]any)
+	testutil.AssertEqual(t, labels[k8s.LabelServiceID], any(web.Body.ServiceID.String()), "object should carry the service ID")
+	testutil.AssertEqual(t, labels[k8s.LabelProjectID], any(proj.Body.ID.String()), "object should carry the project ID")
+	testutil.AssertTrue(t, strings.Contains(got.Body.YAML, "kind: Pod"), "YAML should contain the Pod")
+
+	static, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: proj.Body.ID, Body: ServiceInputBody{Name: "static", Resource: "static"}})
+	testutil.AssertNoError(t, err, "create static service should succeed")
+	_, err = app.GetServiceManifests(ctx, &GetServiceManifestsInput{ID: static.Body.ServiceID})
+	assertStatus(t, err, http.StatusNotFound, "resources without manifests should be not found")
+
+	_, err = app.CreateService(ctx, &CreateServiceInput{ProjectID: proj.Body.ID, Body: ServiceInputBody{
+		Name:     "bad",
+		Resource: k8s_pod.Type,
+		Config:   map[string]any{"image": "nginx", "labels": map[string]any{k8s.LabelServiceID: "x"}},
+	}})
+	assertStatus(t, err, http.StatusUnprocessableEntity, "reserved labels should be rejected on create")
+}
+
+func TestApp_KubernetesStack(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	var resources []resource.Resource
+	for _, typ := range []string{k8s_deployment.Type, k8s_service.Type, k8s_ingress.Type} {
+		res, err := resource.DefaultRegistry.New(typ, 1, nil)
+		testutil.AssertNoError(t, err, "creating "+typ+" should succeed")
+		resources = append(resources, res)
+	}
+	app := NewApp(WithInstance(&internal.Instance{Name: "Test Instance", Catalog: testutil.NewTestCatalog(resources...)}))
+	proj, err := app.CreateProject(ctx, &CreateProjectInput{Body: ProjectInputBody{Name: "shop"}})
+	testutil.AssertNoError(t, err, "create project should succeed")
+
+	create := func(name, typ string, config map[string]any) (*AcceptedOutput, error) {
+		return app.CreateService(ctx, &CreateServiceInput{ProjectID: proj.Body.ID, Body: ServiceInputBody{Name: name, Resource: typ, Config: config}})
+	}
+	svcConfig := map[string]any{"target": "api", "ports": []any{map[string]any{"port": 80, "targetPort": 8080}}}
+
+	_, err = create("api-svc", k8s_service.Type, svcConfig)
+	assertStatus(t, err, http.StatusUnprocessableEntity, "a Service needs a workload to route to")
+
+	api, err := create("api", k8s_deployment.Type, map[string]any{"image": "example/api:1.0", "replicas": 3})
+	testutil.AssertNoError(t, err, "create deployment should succeed")
+	apiSvc, err := create("api-svc", k8s_service.Type, svcConfig)
+	testutil.AssertNoError(t, err, "create Service should succeed once a workload exists")
+	_, err = create("public", k8s_ingress.Type, map[string]any{"rules": []any{
+		map[string]any{"host": "shop.example.com", "paths": []any{map[string]any{"path": "/", "service": "api-svc", "port": 80}}},
+	}})
+	testutil.AssertNoError(t, err, "create Ingress should succeed once an endpoint exists")
+
+	deployment, err := app.GetServiceManifests(ctx, &GetServiceManifestsInput{ID: api.Body.ServiceID})
+	testutil.AssertNoError(t, err, "rendering the deployment should succeed")
+	spec := deployment.Body.Objects[0]["spec"].(map[string]any)
+	testutil.AssertEqual(t, spec["replicas"], any(float64(3)), "replicas should be rendered")
+	podLabels := spec["template"].(map[string]any)["metadata"].(map[string]any)["labels"].(map[string]any)
+
+	service, err := app.GetServiceManifests(ctx, &GetServiceManifestsInput{ID: apiSvc.Body.ServiceID})
+	testutil.AssertNoError(t, err, "rendering the Service should succeed")
+	selector := service.Body.Objects[0]["spec"].(map[string]any)["selector"].(map[string]any)
+	for key, value := range selector {
+		testutil.AssertEqual(t, podLabels[key], value, "the Service should select the deployment's Pods by "+key)
+	}
+}
@@ -0,0 +1,157 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Bermos/Platform/internal/prometheus"
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxMetricsRange caps how far back metrics are queried.
+	maxMetricsRange = 31 * 24 * time.Hour
+	// maxMetricsPoints caps the points of a series, as Prometheus does.
+	maxMetricsPoints = 11000
+	// defaultMetricsPoints is the number of points of a series when no step
+	// is given.
+	defaultMetricsPoints = 120
+)
+
+// MetricsBody holds time series of a service's resource usage.
+type MetricsBody struct {
+	Metric string          `json:"metric" enum:"cpu,memory"`
+	Unit   string          `json:"unit" enum:"cores,bytes"`
+	Query  string          `json:"query" doc:"The PromQL query the series are the result of"`
+	Start  time.Time       `json:"start"`
+	End    time.Time       `json:"end"`
+	Step   string          `json:"step" doc:"Time between points, e.g. 30s"`
+	Series []*MetricSeries `json:"series" doc:"The series the query returned; empty when there is no data, e.g. before the service runs"`
+}
+
+// MetricSeries is one time series of a metric.
+type MetricSeries struct {
+	Labels map[string]string `json:"labels"`
+	Points []MetricPoint     `json:"points" doc:"Points in time order; times without a value are left out"`
+}
+
+// MetricPoint is the value of a metric at one time.
+type MetricPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+type GetServiceMetricsInput struct {
+	ID     uuid.UUID `path:"id" doc:"Service ID"`
+	Metric string    `query:"metric" required:"true" enum:"cpu,memory" doc:"cpu is the usage in cores, memory the working set in bytes"`
+	Range  string    `query:"range" default:"1h" doc:"How far back the series go, e.g. 15m or 24h, up to 744h"`
+	Step   string    `query:"step" doc:"Time between points, e.g. 30s; a 120th of range when empty"`
+}
+
+type MetricsOutput struct {
+	Body *MetricsBody
+}
+
+// GetServiceMetrics queries Prometheus for the CPU or memory usage of a
+// service over the requested range, ending now.
+func (a *App) GetServiceMetrics(ctx context.Context, i *GetServiceMetricsInput) (*MetricsOutput, error) {
+	svc, err := a.services.Get(ctx, i.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	window, step, err := metricsRange(i.Range, i.Step)
+	if err != nil {
+		return nil, err
+	}
+
+	var tmpl, unit string
+	res := a.instance.Catalog.Get(svc.ResourceKey)
+	switch i.Metric {
+	case "cpu":
+		unit = "cores"
+		if res != nil {
+			tmpl = res.MetricsCPU()
+		}
+	case "memory":
+		unit = "bytes"
+		if res != nil {
+			tmpl = res.MetricsMemory()
+		}
+	default:
+		return nil, huma.Error422UnprocessableEntity("validation failed", &huma.ErrorDetail{
+			Location: "query.metric",
+			Message:  "must be cpu or memory",
+			Value:    i.Metric,
+		})
+	}
+	if tmpl == "" {
+		return nil, huma.Error404NotFound(fmt.Sprintf("resource %q has no %s metric", svc.ResourceKey, i.Metric))
+	}
+	if a.prometheus == nil {
+		return nil, huma.Error501NotImplemented("metrics are unavailable as no Prometheus server is configured")
+	}
+	query, err := resource.MetricsQuery(tmpl, a.serviceRef(svc))
+	if err != nil {
+		return nil, huma.Error500InternalServerError("rendering the metrics query failed", err)
+	}
+
+	// Aligning the range to the step keeps the points of repeated queries
+	// in place.
+	end := time.Now().UTC().Truncate(step)
+	start := end.Add(-window)
+	series, err := a.prometheus.QueryRange(ctx, query, prometheus.Range{Start: start, End: end, Step: step})
+	if err != nil {
+		return nil, huma.Error502BadGateway("querying Prometheus failed", err)
+	}
+
+	body := &MetricsBody{
+		Metric: i.Metric,
+		Unit:   unit,
+		Query:  query,
+		Start:  start,
+		End:    end,
+		Step:   step.String(),
+		Series: make([]*MetricSeries, len(series)),
+	}
+	for n, s := range series {
+		out := &MetricSeries{Labels: s.Labels, Points: make([]MetricPoint, 0, len(s.Points))}
+		for _, p := range s.Points {
+			// JSON has no NaN or infinity.
+			if math.IsNaN(p.Value) || math.IsInf(p.Value, 0) {
+				continue
+			}
+			out.Points = append(out.Points, MetricPoint{Time: p.Time, Value: p.Value})
+		}
+		body.Series[n] = out
+	}
+	return &MetricsOutput{Body: body}, nil
+}
+
+// metricsRange parses the range and step of a metrics query. The step
+// defaults to a defaultMetricsPoints-th of the range, at least a second.
+func metricsRange(rawRange, rawStep string) (time.Duration, time.Duration, error) {
+	window, err := time.ParseDuration(rawRange)
+	if err != nil || window < time.Minute || window > maxMetricsRange {
+		return 0, 0, huma.Error422UnprocessableEntity("validation failed", &huma.ErrorDetail{
+			Location: "query.range",
+			Message:  fmt.Sprintf("must be a duration between 1m and %s", maxMetricsRange),
+			Value:    rawRange,
+		})
+	}
+	if rawStep == "" {
+		return window, max((window / defaultMetricsPoints).Truncate(time.Second), time.Second), nil
+	}
+	step, err := time.ParseDuration(rawStep)
+	if err != nil || step < time.Second || window/step > maxMetricsPoints {
+		return 0, 0, huma.Error422UnprocessableEntity("validation failed", &huma.ErrorDetail{
+			Location: "query.step",
+			Message:  fmt.Sprintf("must be a duration of at least 1s giving at most %d points over the range", maxMetricsPoints),
+			Value:    rawStep,
+		})
+	}
+	return window, step, nil
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal"
+	"github.com/Bermos/Platform/internal/prometheus"
+	"github.com/Bermos/Platform/internal/prometheus/fake"
+	"github.com/Bermos/Platform/internal/resource"
+	k8s_pod "github.com/Bermos/Platform/internal/resource/k8s-pod"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+// newMetricsTestApp creates an App querying server, offering Kubernetes
+// Pods and a mock resource without metrics, and creates a Pod called web
+// and a mock service called db.
+func newMetricsTestApp(t *testing.T, opts ...Option) (*App, uuid.UUID, uuid.UUID) {
+	t.Helper()
+	pod, err := k8s_pod.New(resource.Settings{})
+	testutil.AssertNoError(t, err, "creating the pod resource should succeed")
+	mock := testutil.NewMockResource().WithKey("mock")
+	mock.CPUMetrics, mock.MemoryMetrics = "", ""
+	instance := &internal.Instance{Name: "Test Instance", Catalog: testutil.NewTestCatalog(pod, mock), Namespace: "shop"}
+	app := NewApp(append([]Option{WithInstance(instance)}, opts...)...)
+
+	ctx := context.Background()
+	proj, err := app.CreateProject(ctx, &CreateProjectInput{Body: ProjectInputBody{Name: "shop"}})
+	testutil.AssertNoError(t, err, "create project should succeed")
+	web, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: proj.Body.ID, Body: ServiceInputBody{Name: "web", Resource: k8s_pod.Type, Config: map[string]any{"image": "nginx:1.27"}}})
+	testutil.AssertNoError(t, err, "create should succeed")
+	db, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: proj.Body.ID, Body: ServiceInputBody{Name: "db", Resource: "mock"}})
+	testutil.AssertNoError(t, err, "create should succeed")
+	return app, web.Body.ServiceID, db.Body.ServiceID
+}
+
+func TestApp_GetServiceMetrics(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	server := fake.NewServer()
+	defer server.Close()
+	client, err := prometheus.New(server.URL, prometheus.Options{})
+	testutil.AssertNoError(t, err, "creating the client should succeed")
+	app, web, db := newMetricsTestApp(t, WithPrometheus(client))
+
+	now := time.Now().UTC().Truncate(time.Minute)
+	server.SetSeries(prometheus.Series{
+		Labels: map[string]string{},
+		Points: []prometheus.Point{{Time: now.Add(-time.Minute), Value: 0.25}, {Time: now, Value: math.NaN()}},
+	})
+
+	got, err := app.GetServiceMetrics(ctx, &GetServiceMetricsInput{ID: web, Metric: "cpu", Range: "1h"})
+	testutil.AssertNoError(t, err, "querying metrics should succeed")
+	testutil.AssertEqual(t, got.Body.Unit, "cores", "CPU should be in cores")
+	testutil.AssertEqual(t, got.Body.Step, "30s", "the step should default to a 120th of the range")
+	testutil.AssertEqual(t, got.Body.End.Sub(got.Body.Start), time.Hour, "the series should cover the range")
+	testutil.AssertEqual(t, len(got.Body.Series), 1, "the series should be returned")
+	testutil.AssertEqual(t, len(got.Body.Series[0].Points), 1, "points without a value should be left out")
+	testutil.AssertEqual(t, got.Body.Series[0].Points[0].Value, 0.25, "values should be returned")
+
+	queries := server.Queries()
+	testutil.AssertEqual(t, len(queries), 1, "Prometheus should be queried")
+	query := queries[0].Query
+	testutil.AssertEqual(t, query, got.Body.Query, "the query should be reported")
+	testutil.AssertTrue(t, strings.Contains(query, "container_cpu_usage_seconds_total"), "the resource's CPU query should be used")
+	testutil.AssertTrue(t, strings.Contains(query, `label_mahler_io_service_id="`+web.String()+`"`), "the query should be scoped to the service")
+	testutil.AssertTrue(t, strings.Contains(query, `namespace="shop"`), "the query should be scoped to the namespace")
+	testutil.AssertEqual(t, queries[0].Step, 30*time.Second, "the step should be sent")
+	testutil.AssertTrue(t, queries[0].End.Equal(got.Body.End), "the range should be sent")
+
+	got, err = app.GetServiceMetrics(ctx, &GetServiceMetricsInput{ID: web, Metric: "memory", Range: "24h", Step: "5m"})
+	testutil.AssertNoError(t, err, "querying metrics should succeed")
+	testutil.AssertEqual(t, got.Body.Unit, "bytes", "memory should be in bytes")
+	testutil.AssertEqual(t, server.Queries()[1].Step, 5*time.Minute, "the step should be sent")
+	testutil.AssertTrue(t, strings.Contains(server.Queries()[1].Query, "container_memory_working_set_bytes"), "the resource's memory query should be used")
+
+	tests := []struct {
+		name  string
+		input GetServiceMetricsInput
+		want  int
+	}{
+		{"unknown_service", GetServiceMetricsInput{ID: uuid.New(), Metric: "cpu", Range: "1h"}, http.StatusNotFound},
+		{"unknown_metric", GetServiceMetricsInput{ID: web, Metric: "disk", Range: "1h"}, http.StatusUnprocessableEntity},
+		{"invalid_range", GetServiceMetricsInput{ID: web, Metric: "cpu", Range: "1d"}, http.StatusUnprocessableEntity},
+		{"range_too_long", GetServiceMetricsInput{ID: web, Metric: "cpu", Range: "745h"}, http.StatusUnprocessableEntity},
+		{"step_too_short", GetServiceMetricsInput{ID: web, Metric: "cpu", Range: "1h", Step: "100ms"}, http.StatusUnprocessableEntity},
+		{"too_many_points", GetServiceMetricsInput{ID: web, Metric: "cpu", Range: "744h", Step: "1m"}, http.StatusUnprocessableEntity},
+		{"no_metric", GetServiceMetricsInput{ID: db, Metric: "cpu", Range: "1h"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := app.GetServiceMetrics(ctx, &tt.input)
+			assertStatus(t, err, tt.want, "the query should be rejected")
+		})
+	}
+	testutil.AssertEqual(t, len(server.Queries()), 2, "rejected queries should not reach Prometheus")
+
+	server.FailWith(&prometheus.Error{StatusCode: http.StatusBadRequest, Type: "bad_data", Message: "parse error"})
+	_, err = app.GetServiceMetrics(ctx, &GetServiceMetricsInput{ID: web, Metric: "cpu", Range: "1h"})
+	assertStatus(t, err, http.StatusBadGateway, "a failing query should be reported")
+}
+
+func TestApp_GetServiceMetrics_NoPrometheus(t *testing.T) {
+	app, web, _ := newMetricsTestApp(t)
+	_, err := app.GetServiceMetrics(testutil.NewTestContext(t), &GetServiceMetricsInput{ID: web, Metric: "cpu", Range: "1h"})
+	assertStatus(t, err, http.StatusNotImplemented, "metrics should be unavailable without Prometheus")
+}
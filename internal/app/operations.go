@@ -0,0 +1,300 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Bermos/Platform/internal/jobs"
+	"github.com/Bermos/Platform/internal/operation"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/Bermos/Platform/internal/terraform"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPollInterval = time.Second
+	// defaultDependencyTimeout is how long an operation waits for the
+	// services it depends on, or that depend on it, before it fails.
+	defaultDependencyTimeout = time.Hour
+	// maxWaitTimeout caps how long a :wait call blocks.
+	maxWaitTimeout = 10 * time.Minute
+)
+
+// OperationBody is the API representation of an operation.
+type OperationBody struct {
+	ID         uuid.UUID            `json:"id"`
+	Kind       operation.Kind       `json:"kind" enum:"service.create,service.update,service.delete,service.reconcile"`
+	ProjectID  uuid.UUID            `json:"projectId"`
+	ServiceID  uuid.UUID            `json:"serviceId" doc:"Service the operation changes"`
+	State      operation.State      `json:"state" enum:"pending,running,awaiting_approval,succeeded,failed,cancelled"`
+	Done       bool                 `json:"done" doc:"Whether the operation finished; result or error is set once it has"`
+	Progress   int                  `json:"progress" minimum:"0" maximum:"100" doc:"Percentage of steps completed"`
+	Steps      []operation.Step     `json:"steps"`
+	Logs       []operation.LogEntry `json:"logs"`
+	Plan       *terraform.Diff      `json:"plan,omitempty" doc:"What the Terraform plan of the operation changes; set once planned"`
+	ApprovedAt *time.Time           `json:"approvedAt,omitempty" doc:"When the plan was approved"`
+	Result     map[string]any       `json:"result,omitempty" doc:"Outcome of a succeeded operation"`
+	Error      string               `json:"error,omitempty" doc:"Why the operation failed or was cancelled"`
+	CreatedAt  time.Time            `json:"createdAt"`
+	UpdatedAt  time.Time            `json:"updatedAt"`
+	FinishedAt *time.Time           `json:"finishedAt,omitempty"`
+}
+
+// AcceptedOutput is returned by calls that start an operation.
+type AcceptedOutput struct {
+	Location string `header:"Location" doc:"URL of the operation"`
+	Body     *OperationBody
+}
+
+type OperationOutput struct {
+	Body *OperationBody
+}
+
+type GetOperationInput struct {
+	ID uuid.UUID `path:"id" doc:"Operation ID"`
+}
+
+type OperationMethodInput struct {
+	ID      string `path:"id" pattern:"^[0-9a-fA-F-]{36}:(cancel|wait|approve)$" patternDescription:"operation ID followed by :cancel, :wait or :approve" doc:"Operation ID and method, e.g. 3f0c…:wait"`
+	Timeout string `query:"timeout" default:"30s" doc:"How long :wait blocks at most, e.g. 30s or 5m, up to 10m"`
+}
+
+func (a *App) GetOperation(ctx context.Context, i *GetOperationInput) (*OperationOutput, error) {
+	op, err := a.operations.Get(ctx, i.ID)
+	if err != nil {
+		return nil, operationError(err)
+	}
+	return &OperationOutput{Body: newOperationBody(op)}, nil
+}
+
+// OperationMethod runs a custom method on an operation: {id}:cancel cancels
+// it, {id}:wait blocks until it is done or the timeout elapses and
+// {id}:approve lets an operation awaiting approval apply its plan.
+func (a *App) OperationMethod(ctx context.Context, i *OperationMethodInput) (*OperationOutput, error) {
+	raw, method, _ := strings.Cut(i.ID, ":")
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, huma.Error422UnprocessableEntity("validation failed", &huma.ErrorDetail{
+			Location: "path.id",
+			Message:  "invalid operation ID",
+			Value:    raw,
+		})
+	}
+
+	var op *operation.Operation
+	switch method {
+	case "cancel":
+		op, err = a.cancelOperation(ctx, id)
+	case "approve":
+		op, err = a.approveOperation(ctx, id)
+	default:
+		timeout, perr := time.ParseDuration(i.Timeout)
+		if perr != nil || timeout < 0 || timeout > maxWaitTimeout {
+			return nil, huma.Error422UnprocessableEntity("validation failed", &huma.ErrorDetail{
+				Location: "query.timeout",
+				Message:  fmt.Sprintf("must be a duration between 0s and %s", maxWaitTimeout),
+				Value:    i.Timeout,
+			})
+		}
+		op, err = a.waitOperation(ctx, id, timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &OperationOutput{Body: newOperationBody(op)}, nil
+}
+
+// cancelOperation cancels an unfinished operation. One that has not started
+// yet or awaits approval is cancelled at once; a running one stops at its
+// next opportunity. Either way the service ends up failed.
+func (a *App) cancelOperation(ctx context.Context, id uuid.UUID) (*operation.Operation, error) {
+	op, err := a.operations.Get(ctx, id)
+	if err != nil {
+		return nil, operationError(err)
+	}
+	if op.State.Done() {
+		return nil, huma.Error409Conflict(fmt.Sprintf("operation is already %s", op.State))
+	}
+
+	reason := "Cancelled while awaiting approval"
+	if op.State != operation.StateAwaitingApproval {
+		// The job of an operation awaiting approval has finished.
+		reason = "Cancelled before it started"
+		if op.State == operation.StateRunning {
+			// The job is queued again, e.g. waiting for dependencies.
+			reason = "Cancelled while waiting to run again"
+		}
+		job, err := a.jobs.Cancel(ctx, op.JobID)
+		switch {
+		case errors.Is(err, jobs.ErrFinished):
+			// The operation finished while we looked.
+			return a.getOperation(ctx, id)
+		case err != nil && !errors.Is(err, jobs.ErrNotFound):
+			return nil, huma.Error500InternalServerError("cancelling the operation failed", err)
+		case err == nil && job.State == jobs.StateRunning:
+			// The running operation records its cancellation itself.
+			return op, nil
+		}
+	}
+
+	now := time.Now().UTC()
+	from := op.State
+	op.Logf(now, "%s", reason)
+	op.Finish(operation.StateCancelled, nil, errCancelled, now)
+	// An approval may have queued the operation meanwhile.
+	if err := a.operations.UpdateFrom(ctx, op, from); err != nil {
+		return nil, operationError(err)
+	}
+	a.failService(ctx, op, errCancelled.Error())
+	return op, nil
+}
+
+// approveOperation approves the plan of an operation awaiting approval and
+// queues a job applying it.
+func (a *App) approveOperation(ctx context.Context, id uuid.UUID) (*operation.Operation, error) {
+	op, err := a.operations.Get(ctx, id)
+	if err != nil {
+		return nil, operationError(err)
+	}
+	now := time.Now().UTC()
+	if err := op.Approve(now); err != nil {
+		return nil, huma.Error409Conflict(fmt.Sprintf("operation is %s, not awaiting approval", op.State))
+	}
+	op.Logf(now, "Plan approved")
+	// Only one of concurrent approvals and cancellations wins.
+	if err := a.operations.UpdateFrom(ctx, op, operation.StateAwaitingApproval); err != nil {
+		return nil, operationError(err)
+	}
+	if _, err := a.jobs.Enqueue(ctx, operationJobKind, operationPayload{OperationID: op.ID}, jobs.WithID(op.JobID)); err != nil {
+		return nil, huma.Error500InternalServerError("queueing the operation failed", err)
+	}
+	return op, nil
+}
+
+// waitOperation polls an operation until it is done, timeout elapses or the
+// client goes away, and returns its latest state.
+func (a *App) waitOperation(ctx context.Context, id uuid.UUID, timeout time.Duration) (*operation.Operation, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(a.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		op, err := a.getOperation(ctx, id)
+		if err != nil || op.State.Done() {
+			return op, err
+		}
+		select {
+		case <-ctx.Done():
+			return op, nil
+		case <-deadline.C:
+			return op, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *App) getOperation(ctx context.Context, id uuid.UUID) (*operation.Operation, error) {
+	op, err := a.operations.Get(ctx, id)
+	if err != nil {
+		return nil, operationError(err)
+	}
+	return op, nil
+}
+
+// startOperation moves svc to status to and queues an operation of kind
+// that takes it from there.
+func (a *App) startOperation(ctx context.Context, kind operation.Kind, svc *service.Service, to service.Status) (*AcceptedOutput, error) {
+	op, err := a.claimService(ctx, kind, svc, to)
+	if err != nil {
+		return nil, err
+	}
+	return a.queueOperation(ctx, op)
+}
+
+// claimService moves svc to status to for a new operation of kind and
+// returns the operation, which is yet to be queued. The transition claims
+// the service against concurrent requests, so only the request that wins
+// it may change the service.
+func (a *App) claimService(ctx context.Context, kind operation.Kind, svc *service.Service, to service.Status) (*operation.Operation, error) {
+	now := time.Now().UTC()
+	op := operation.New(kind, svc.ProjectID, svc.ID, now)
+	op.Logf(now, "Requested %s of service %s", kind, svc.Name)
+
+	if err := a.transition(ctx, svc, to, service.ActorAPI, fmt.Sprintf("%s operation %s", kind, op.ID)); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// queueOperation records and queues op on the service claimed for it.
+// Should the operation not get queued after all, the service fails rather
+// than staying in a status no operation will ever leave.
+func (a *App) queueOperation(ctx context.Context, op *operation.Operation) (*AcceptedOutput, error) {
+	// The request may be gone by the time the service has to be failed.
+	cleanup := context.WithoutCancel(ctx)
+	if err := a.operations.Create(ctx, op); err != nil {
+		a.failService(cleanup, op, "recording the operation failed")
+		return nil, operationError(err)
+	}
+	if _, err := a.jobs.Enqueue(ctx, operationJobKind, operationPayload{OperationID: op.ID}, jobs.WithID(op.JobID)); err != nil {
+		now := time.Now().UTC()
+		op.Logf(now, "Queueing failed: %v", err)
+		op.Finish(operation.StateFailed, nil, err, now)
+		a.operations.Update(cleanup, op)
+		a.failService(cleanup, op, "queueing the operation failed")
+		return nil, huma.Error500InternalServerError("queueing the operation failed", err)
+	}
+	return &AcceptedOutput{Location: "/api/v1/operations/" + op.ID.String(), Body: newOperationBody(op)}, nil
+}
+
+// checkIdle fails with a 409 when svc cannot start an operation that
+// moves it to status to, typically because another one is running.
+func checkIdle(svc *service.Service, to service.Status) error {
+	switch svc.Status {
+	case service.StatusProvisioning, service.StatusUpdating, service.StatusDeleting:
+		// An operation holds the service until it settles.
+	default:
+		if service.CanTransition(svc.Status, to) {
+			return nil
+		}
+	}
+	return huma.Error409Conflict(fmt.Sprintf("service is %s; wait for its current operation to finish", svc.Status))
+}
+
+func newOperationBody(op *operation.Operation) *OperationBody {
+	return &OperationBody{
+		ID:         op.ID,
+		Kind:       op.Kind,
+		ProjectID:  op.ProjectID,
+		ServiceID:  op.ServiceID,
+		State:      op.State,
+		Done:       op.State.Done(),
+		Progress:   op.Progress(),
+		Steps:      op.Steps,
+		Logs:       op.Logs,
+		Plan:       op.Plan,
+		ApprovedAt: op.ApprovedAt,
+		Result:     op.Result,
+		Error:      op.Error,
+		CreatedAt:  op.CreatedAt,
+		UpdatedAt:  op.UpdatedAt,
+		FinishedAt: op.FinishedAt,
+	}
+}
+
+// operationError maps repository errors to problem responses.
+func operationError(err error) error {
+	switch {
+	case errors.Is(err, operation.ErrNotFound):
+		return huma.Error404NotFound("operation not found")
+	case errors.Is(err, operation.ErrStateConflict):
+		return huma.Error409Conflict("operation changed concurrently; try again")
+	default:
+		return huma.Error500InternalServerError("operation storage failed", err)
+	}
+}
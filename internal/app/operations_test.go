@@ -0,0 +1,278 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal"
+	"github.com/Bermos/Platform/internal/database/memory"
+	"github.com/Bermos/Platform/internal/jobs"
+	"github.com/Bermos/Platform/internal/operation"
+	"github.com/Bermos/Platform/internal/provider/fake"
+	"github.com/Bermos/Platform/internal/resource"
+	k8s_pod "github.com/Bermos/Platform/internal/resource/k8s-pod"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+// startApp runs app's operations until the end of the test, polling
+// quickly.
+func startApp(t *testing.T, a *App) {
+	t.Helper()
+	a.pollInterval = time.Millisecond
+	testutil.AssertNoError(t, a.Start(context.Background()), "start should succeed")
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		a.Stop(ctx)
+	})
+}
+
+// waitDone waits for the operation started by an accepted call and returns
+// its final state.
+func waitDone(t *testing.T, a *App, accepted *AcceptedOutput) *OperationBody {
+	t.Helper()
+	got, err := a.OperationMethod(context.Background(), &OperationMethodInput{ID: accepted.Body.ID.String() + ":wait", Timeout: "5s"})
+	testutil.AssertNoError(t, err, "wait should succeed")
+	if !got.Body.Done {
+		t.Fatalf("operation %s is still %s after 5s", got.Body.ID, got.Body.State)
+	}
+	return got.Body
+}
+
+// waitDeleted waits for the operations started by a deletion and returns
+// the final state of the last, which deletes the requested service.
+func waitDeleted(t *testing.T, a *App, deleted *DeletionOutput) *OperationBody {
+	t.Helper()
+	var last *OperationBody
+	for _, op := range deleted.Body.Operations {
+		last = waitDone(t, a, &AcceptedOutput{Body: op})
+	}
+	return last
+}
+
+// newPodTestApp creates an App offering Kubernetes pods provisioned into
+// provider, together with one project called "shop".
+func newPodTestApp(t *testing.T, provider resource.Provider, opts ...Option) (*App, uuid.UUID) {
+	t.Helper()
+	pod, err := k8s_pod.New(resource.Settings{})
+	testutil.AssertNoError(t, err, "creating the pod resource should succeed")
+	instance := &internal.Instance{
+		Name:      "Test Instance",
+		Catalog:   testutil.NewTestCatalog(pod),
+		Namespace: "shop",
+	}
+	app := NewApp(append([]Option{WithInstance(instance), WithProvider(provider)}, opts...)...)
+	proj, err := app.CreateProject(context.Background(), &CreateProjectInput{Body: ProjectInputBody{Name: "shop"}})
+	testutil.AssertNoError(t, err, "create project should succeed")
+	return app, proj.Body.ID
+}
+
+var podBody = ServiceInputBody{Name: "web", Resource: k8s_pod.Type, Config: map[string]any{"image": "nginx:1.27"}}
+
+func TestApp_OperationProvisionsService(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	provider := fake.NewProvider()
+	app, projectID := newPodTestApp(t, provider)
+	startApp(t, app)
+
+	created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: podBody})
+	testutil.AssertNoError(t, err, "create should succeed")
+	testutil.AssertEqual(t, created.Location, "/api/v1/operations/"+created.Body.ID.String(), "Location should point at the operation")
+
+	op := waitDone(t, app, created)
+	testutil.AssertEqual(t, op.State, operation.StateSucceeded, "provisioning should succeed")
+	testutil.AssertEqual(t, op.Progress, 100, "a succeeded operation should be complete")
+	for _, step := range op.Steps {
+		testutil.AssertEqual(t, step.State, operation.StepSucceeded, step.Name+" should succeed")
+	}
+	testutil.AssertEqual(t, len(op.Result["changes"].([]any)), 1, "the result should list the created Pod")
+	testutil.AssertTrue(t, len(op.Logs) > 1, "the operation should log its progress")
+	testutil.AssertEqual(t, provider.Len(), 1, "the Pod should be applied")
+
+	svc, err := app.GetService(ctx, &GetServiceInput{ID: created.Body.ServiceID})
+	testutil.AssertNoError(t, err, "get should succeed")
+	testutil.AssertEqual(t, svc.Body.Status, service.StatusReady, "the service should be ready")
+
+	unchanged, err := app.UpdateService(ctx, &UpdateServiceInput{ID: svc.Body.ID, Body: podBody})
+	testutil.AssertNoError(t, err, "update should succeed")
+	op = waitDone(t, app, unchanged)
+	testutil.AssertEqual(t, op.Steps[1].State, operation.StepSkipped, "an unchanged service should skip apply")
+
+	deleted, err := app.DeleteService(ctx, &DeleteServiceInput{ID: svc.Body.ID})
+	testutil.AssertNoError(t, err, "delete should succeed")
+	op = waitDeleted(t, app, deleted)
+	testutil.AssertEqual(t, op.State, operation.StateSucceeded, "deleting should succeed")
+	testutil.AssertEqual(t, provider.Len(), 0, "the Pod should be destroyed")
+	_, err = app.GetService(ctx, &GetServiceInput{ID: svc.Body.ID})
+	assertStatus(t, err, http.StatusNotFound, "the service should be gone")
+
+	got, err := app.GetOperation(ctx, &GetOperationInput{ID: created.Body.ID})
+	testutil.AssertNoError(t, err, "operations should outlive their service")
+	testutil.AssertEqual(t, got.Body.ServiceID, svc.Body.ID, "the operation should still name the service")
+}
+
+func TestApp_OperationFailure(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	provider := fake.NewProvider()
+	provider.FailOn(fake.OpPut, errors.New("cluster unreachable"))
+	app, projectID := newPodTestApp(t, provider)
+	startApp(t, app)
+
+	created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: podBody})
+	testutil.AssertNoError(t, err, "create should succeed")
+	op := waitDone(t, app, created)
+	testutil.AssertEqual(t, op.State, operation.StateFailed, "provisioning should fail")
+	testutil.AssertTrue(t, op.Error != "", "the error should be reported")
+	testutil.AssertEqual(t, op.Steps[1].State, operation.StepFailed, "the apply step should fail")
+	testutil.AssertEqual(t, op.Steps[2].State, operation.StepPending, "later steps should not run")
+
+	svc, err := app.GetService(ctx, &GetServiceInput{ID: created.Body.ServiceID})
+	testutil.AssertNoError(t, err, "get should succeed")
+	testutil.AssertEqual(t, svc.Body.Status, service.StatusFailed, "the service should fail with its operation")
+
+	provider.FailOn(fake.OpPut, nil)
+	retried, err := app.UpdateService(ctx, &UpdateServiceInput{ID: svc.Body.ID, Body: podBody})
+	testutil.AssertNoError(t, err, "failed services should accept updates")
+	testutil.AssertEqual(t, waitDone(t, app, retried).State, operation.StateSucceeded, "the update should provision the service")
+}
+
+func TestApp_OperationWithoutLifecycle(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	app, projectID := newServiceTestApp(t)
+
+	created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: ServiceInputBody{Name: "api", Resource: "small-vm"}})
+	testutil.AssertNoError(t, err, "create should succeed")
+	op := waitDone(t, app, created)
+	testutil.AssertEqual(t, op.State, operation.StateSucceeded, "there should be nothing to fail")
+	for _, step := range op.Steps {
+		testutil.AssertEqual(t, step.State, operation.StepSkipped, step.Name+" should be skipped")
+	}
+}
+
+func TestApp_CancelOperation(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+
+	t.Run("before_it_starts", func(t *testing.T) {
+		app, projectID := newPodTestApp(t, fake.NewProvider())
+		created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: podBody})
+		testutil.AssertNoError(t, err, "create should succeed")
+
+		_, err = app.UpdateService(ctx, &UpdateServiceInput{ID: created.Body.ServiceID, Body: podBody})
+		assertStatus(t, err, http.StatusConflict, "a service with a running operation should not be updated")
+		_, err = app.DeleteService(ctx, &DeleteServiceInput{ID: created.Body.ServiceID})
+		assertStatus(t, err, http.StatusConflict, "a service with a running operation should not be deleted")
+
+		got, err := app.OperationMethod(ctx, &OperationMethodInput{ID: created.Body.ID.String() + ":cancel"})
+		testutil.AssertNoError(t, err, "cancel should succeed")
+		testutil.AssertEqual(t, got.Body.State, operation.StateCancelled, "a pending operation should be cancelled at once")
+		svc, _ := app.GetService(ctx, &GetServiceInput{ID: created.Body.ServiceID})
+		testutil.AssertEqual(t, svc.Body.Status, service.StatusFailed, "the service should fail")
+
+		_, err = app.OperationMethod(ctx, &OperationMethodInput{ID: created.Body.ID.String() + ":cancel"})
+		assertStatus(t, err, http.StatusConflict, "finished operations cannot be cancelled")
+	})
+
+	t.Run("while_running", func(t *testing.T) {
+		provider := fake.NewProvider()
+		provider.SetInitialStatus(resource.Status{Phase: resource.PhaseProgressing, Message: "pulling image"})
+		app, projectID := newPodTestApp(t, provider)
+		startApp(t, app)
+		created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: podBody})
+		testutil.AssertNoError(t, err, "create should succeed")
+
+		waited, err := app.OperationMethod(ctx, &OperationMethodInput{ID: created.Body.ID.String() + ":wait", Timeout: "50ms"})
+		testutil.AssertNoError(t, err, "wait should succeed")
+		testutil.AssertFalse(t, waited.Body.Done, "wait should return unfinished operations after the timeout")
+		testutil.AssertEqual(t, waited.Body.State, operation.StateRunning, "the operation should wait for the Pod")
+
+		_, err = app.OperationMethod(ctx, &OperationMethodInput{ID: created.Body.ID.String() + ":cancel"})
+		testutil.AssertNoError(t, err, "cancel should succeed")
+		op := waitDone(t, app, created)
+		testutil.AssertEqual(t, op.State, operation.StateCancelled, "the running operation should be cancelled")
+		testutil.AssertEqual(t, op.Steps[2].State, operation.StepFailed, "the interrupted step should fail")
+		svc, _ := app.GetService(ctx, &GetServiceInput{ID: created.Body.ServiceID})
+		testutil.AssertEqual(t, svc.Body.Status, service.StatusFailed, "the service should fail")
+	})
+}
+
+func TestApp_OperationMethodErrors(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	app, _ := newServiceTestApp(t)
+	unknown := uuid.New().String()
+
+	_, err := app.GetOperation(ctx, &GetOperationInput{ID: uuid.New()})
+	assertStatus(t, err, http.StatusNotFound, "unknown operations should be not found")
+	_, err = app.OperationMethod(ctx, &OperationMethodInput{ID: unknown + ":cancel"})
+	assertStatus(t, err, http.StatusNotFound, "cancelling unknown operations should be not found")
+	_, err = app.OperationMethod(ctx, &OperationMethodInput{ID: unknown + ":wait", Timeout: "1s"})
+	assertStatus(t, err, http.StatusNotFound, "waiting for unknown operations should be not found")
+	_, err = app.OperationMethod(ctx, &OperationMethodInput{ID: unknown + ":wait", Timeout: "1h"})
+	assertStatus(t, err, http.StatusUnprocessableEntity, "overlong timeouts should be rejected")
+	_, err = app.OperationMethod(ctx, &OperationMethodInput{ID: "not-a-uuid-not-a-uuid-not-a-uuid-xx:wait", Timeout: "1s"})
+	assertStatus(t, err, http.StatusUnprocessableEntity, "malformed IDs should be rejected")
+}
+
+func TestApp_OperationsSurviveRestart(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	provider := fake.NewProvider()
+	jobRepo := memory.NewJobRepository()
+	storage := []Option{
+		WithServiceRepository(memory.NewServiceRepository()),
+		WithOperationRepository(memory.NewOperationRepository()),
+	}
+
+	// The first process accepts the request but stops before running it.
+	before, projectID := newPodTestApp(t, provider, append(storage, WithJobQueue(jobs.NewQueue(jobRepo, jobs.Options{})))...)
+	created, err := before.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: podBody})
+	testutil.AssertNoError(t, err, "create should succeed")
+
+	after, _ := newPodTestApp(t, provider, append(storage, WithJobQueue(jobs.NewQueue(jobRepo, jobs.Options{})))...)
+	startApp(t, after)
+	op := waitDone(t, after, created)
+	testutil.AssertEqual(t, op.State, operation.StateSucceeded, "the next process should run the operation")
+	testutil.AssertEqual(t, provider.Len(), 1, "the Pod should be applied")
+}
+
+// failingOperations is an operation repository that cannot record new
+// operations.
+type failingOperations struct {
+	operation.Repository
+}
+
+func (failingOperations) Create(context.Context, *operation.Operation) error {
+	return errors.New("disk full")
+}
+
+// failingJobs is a job repository that cannot record new jobs.
+type failingJobs struct {
+	jobs.Repository
+}
+
+func (failingJobs) Create(context.Context, *jobs.Job) error {
+	return errors.New("disk full")
+}
+
+func TestApp_OperationNotQueued(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+
+	for name, opt := range map[string]Option{
+		"operation_not_recorded": WithOperationRepository(failingOperations{memory.NewOperationRepository()}),
+		"job_not_queued":         WithJobQueue(jobs.NewQueue(failingJobs{memory.NewJobRepository()}, jobs.Options{})),
+	} {
+		t.Run(name, func(t *testing.T) {
+			app, projectID := newPodTestApp(t, fake.NewProvider(), opt)
+			_, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: podBody})
+			assertStatus(t, err, http.StatusInternalServerError, "create should fail")
+
+			services, err := app.ListServices(ctx, &ListServicesInput{ProjectID: projectID})
+			testutil.AssertNoError(t, err, "list should succeed")
+			testutil.AssertEqual(t, len(services.Body), 1, "the service should be recorded")
+			testutil.AssertEqual(t, services.Body[0].Status, service.StatusFailed, "the service should not stay provisioning")
+		})
+	}
+}
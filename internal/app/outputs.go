@@ -0,0 +1,109 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/Bermos/Platform/internal/terraform"
+	"github.com/google/uuid"
+)
+
+// OutputBody is one value a provisioned service exposes.
+type OutputBody struct {
+	Name      string `json:"name"`
+	Value     any    `json:"value,omitempty" doc:"The output's value; left out for sensitive outputs unless showSensitive is set"`
+	Sensitive bool   `json:"sensitive" doc:"Whether the value is a secret, such as a password"`
+}
+
+type GetServiceOutputsInput struct {
+	ID            uuid.UUID `path:"id" doc:"Service ID"`
+	ShowSensitive bool      `query:"showSensitive" doc:"Include the values of sensitive outputs"`
+}
+
+type ServiceOutputsOutput struct {
+	Body []*OutputBody
+}
+
+// GetServiceOutputs lists the outputs recorded when the service was last
+// provisioned, ordered by name. The values of sensitive outputs are only
+// included on request.
+func (a *App) GetServiceOutputs(ctx context.Context, i *GetServiceOutputsInput) (*ServiceOutputsOutput, error) {
+	svc, err := a.services.Get(ctx, i.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	bodies := make([]*OutputBody, 0, len(svc.Outputs))
+	for _, name := range slices.Sorted(maps.Keys(svc.Outputs)) {
+		o := svc.Outputs[name]
+		body := &OutputBody{Name: name, Sensitive: o.Sensitive}
+		if !o.Sensitive || i.ShowSensitive {
+			body.Value = o.Value
+		}
+		bodies = append(bodies, body)
+	}
+	return &ServiceOutputsOutput{Body: bodies}, nil
+}
+
+// serviceURL returns the service's url output, unless it is missing or
+// sensitive.
+func serviceURL(svc *service.Service) string {
+	o, ok := svc.Outputs["url"]
+	if !ok || o.Sensitive {
+		return ""
+	}
+	url, _ := o.Value.(string)
+	return url
+}
+
+// recordOutputs replaces the outputs of service id.
+func (r *operationRun) recordOutputs(ctx context.Context, id uuid.UUID, outputs map[string]service.Output) error {
+	svc, err := r.app.services.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	svc.Outputs = outputs
+	if err := r.app.services.Update(ctx, svc); err != nil {
+		return err
+	}
+	r.op.Logf(r.now(), "Recorded %d outputs", len(outputs))
+	return r.save()
+}
+
+// lifecycleOutputs returns the outputs a resource's plan reports. Outputs
+// the resource's capabilities declare sensitive are marked so.
+func lifecycleOutputs(res resource.Resource, values map[string]any) (map[string]service.Output, error) {
+	sensitive := map[string]bool{}
+	for _, c := range res.Provides() {
+		for _, o := range c.Outputs {
+			sensitive[o.Name] = sensitive[o.Name] || o.Sensitive
+		}
+	}
+	// Values are stored as decoded JSON, as they read back from storage.
+	plain, err := toResult(values)
+	if err != nil {
+		return nil, err
+	}
+	outputs := make(map[string]service.Output, len(plain))
+	for name, v := range plain {
+		outputs[name] = service.Output{Value: v, Sensitive: sensitive[name]}
+	}
+	return outputs, nil
+}
+
+// moduleOutputs converts the root module outputs of a service's workspace.
+func moduleOutputs(values map[string]terraform.OutputValue) (map[string]service.Output, error) {
+	outputs := make(map[string]service.Output, len(values))
+	for name, v := range values {
+		var value any
+		if err := json.Unmarshal(v.Value, &value); err != nil {
+			return nil, fmt.Errorf("decode output %s: %w", name, err)
+		}
+		outputs[name] = service.Output{Value: value, Sensitive: v.Sensitive}
+	}
+	return outputs, nil
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Bermos/Platform/internal"
+	"github.com/Bermos/Platform/internal/provider/fake"
+	"github.com/Bermos/Platform/internal/resource"
+	k8s_pod "github.com/Bermos/Platform/internal/resource/k8s-pod"
+	k8s_service "github.com/Bermos/Platform/internal/resource/k8s-service"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+func TestApp_ModuleOutputs(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	exec := &fakeTerraform{}
+	app, projectID, _ := newModuleTestApp(t, exec)
+	exec.planChanges(resourceChange("create"))
+	created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: ServiceInputBody{Name: "cache", Resource: "cache"}})
+	testutil.AssertNoError(t, err, "create should succeed")
+	waitDone(t, app, created)
+
+	got, err := app.GetServiceOutputs(ctx, &GetServiceOutputsInput{ID: created.Body.ServiceID})
+	testutil.AssertNoError(t, err, "get outputs should succeed")
+	testutil.AssertEqual(t, len(got.Body), 2, "every output should be listed")
+	host, password := got.Body[0], got.Body[1]
+	testutil.AssertEqual(t, host.Name, "host", "outputs should be ordered by name")
+	testutil.AssertEqual(t, host.Value, any("cache.internal"), "the value should be shown")
+	testutil.AssertFalse(t, host.Sensitive, "the host should not be sensitive")
+	testutil.AssertTrue(t, password.Sensitive, "the password should be sensitive")
+	testutil.AssertTrue(t, password.Value == nil, "sensitive values should be hidden")
+
+	got, err = app.GetServiceOutputs(ctx, &GetServiceOutputsInput{ID: created.Body.ServiceID, ShowSensitive: true})
+	testutil.AssertNoError(t, err, "get outputs should succeed")
+	testutil.AssertEqual(t, got.Body[1].Value, any("hunter2"), "sensitive values should be shown on request")
+
+	_, err = app.GetServiceOutputs(ctx, &GetServiceOutputsInput{ID: uuid.New()})
+	assertStatus(t, err, http.StatusNotFound, "a missing service has no outputs")
+}
+
+func TestApp_LifecycleOutputs(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	pod, err := k8s_pod.New(resource.Settings{})
+	testutil.AssertNoError(t, err, "creating the pod resource should succeed")
+	svcResource, err := k8s_service.New(resource.Settings{})
+	testutil.AssertNoError(t, err, "creating the service resource should succeed")
+	instance := &internal.Instance{Name: "Test Instance", Catalog: testutil.NewTestCatalog(pod, svcResource), Namespace: "shop"}
+	app := NewApp(WithInstance(instance), WithProvider(fake.NewProvider()))
+	startApp(t, app)
+	proj, err := app.CreateProject(context.Background(), &CreateProjectInput{Body: ProjectInputBody{Name: "shop"}})
+	testutil.AssertNoError(t, err, "create project should succeed")
+
+	created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: proj.Body.ID, Body: podBody})
+	testutil.AssertNoError(t, err, "create should succeed")
+	waitDone(t, app, created)
+	outputs, err := app.GetServiceOutputs(ctx, &GetServiceOutputsInput{ID: created.Body.ServiceID})
+	testutil.AssertNoError(t, err, "get outputs should succeed")
+	testutil.AssertEqual(t, len(outputs.Body), 2, "a Pod should expose its name and namespace")
+	testutil.AssertEqual(t, outputs.Body[0].Value, any("web"), "the name should be the service's")
+
+	created, err = app.CreateService(ctx, &CreateServiceInput{ProjectID: proj.Body.ID, Body: ServiceInputBody{
+		Name:     "web-svc",
+		Resource: k8s_service.Type,
+		Config:   map[string]any{"target": "web", "ports": []any{map[string]any{"port": 8080}}},
+	}})
+	testutil.AssertNoError(t, err, "create should succeed")
+	waitDone(t, app, created)
+	svc, err := app.GetService(ctx, &GetServiceInput{ID: created.Body.ServiceID})
+	testutil.AssertNoError(t, err, "get should succeed")
+	testutil.AssertEqual(t, svc.Body.URL, "http://web-svc.shop.svc.cluster.local:8080", "the service should carry its URL")
+	outputs, err = app.GetServiceOutputs(ctx, &GetServiceOutputsInput{ID: created.Body.ServiceID})
+	testutil.AssertNoError(t, err, "get outputs should succeed")
+	testutil.AssertEqual(t, outputs.Body[1].Name, "port", "the port should be listed")
+	testutil.AssertEqual(t, outputs.Body[1].Value, any(float64(8080)), "values should be plain JSON values")
+}
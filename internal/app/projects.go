@@ -0,0 +1,204 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Bermos/Platform/internal/jobs"
+	"github.com/Bermos/Platform/internal/project"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+// ProjectInputBody is the writable part of a project.
+type ProjectInputBody struct {
+	Name        string `json:"name" minLength:"1" maxLength:"63" pattern:"^[a-z]([-a-z0-9]*[a-z0-9])?$" patternDescription:"lowercase letters, digits and hyphens" doc:"Unique project name"`
+	Description string `json:"description,omitempty" maxLength:"1024" doc:"Free-form description"`
+}
+
+type ProjectOutput struct {
+	Body *project.Project
+}
+
+type ListProjectsOutput struct {
+	Body []*project.Project
+}
+
+type CreateProjectInput struct {
+	Body ProjectInputBody
+}
+
+type GetProjectInput struct {
+	ID uuid.UUID `path:"id" doc:"Project ID"`
+}
+
+type UpdateProjectInput struct {
+	ID   uuid.UUID `path:"id" doc:"Project ID"`
+	Body ProjectInputBody
+}
+
+type DeleteProjectInput struct {
+	ID      uuid.UUID `path:"id" doc:"Project ID"`
+	DryRun  bool      `query:"dryRun" doc:"Only report what deleting the project would remove"`
+	Force   bool      `query:"force" doc:"Delete the project's services as well; the same as cascade"`
+	Cascade bool      `query:"cascade" doc:"Delete the project's services as well, each after the services depending on it"`
+}
+
+func (a *App) ListProjects(ctx context.Context, i *struct{}) (*ListProjectsOutput, error) {
+	projects, err := a.projects.List(ctx)
+	if err != nil {
+		return nil, projectError(err)
+	}
+	return &ListProjectsOutput{Body: projects}, nil
+}
+
+func (a *App) CreateProject(ctx context.Context, i *CreateProjectInput) (*ProjectOutput, error) {
+	if err := validateProjectBody(i.Body); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	proj := &project.Project{
+		ID:          uuid.New(),
+		Name:        i.Body.Name,
+		Description: i.Body.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := a.projects.Create(ctx, proj); err != nil {
+		return nil, projectError(err)
+	}
+	return &ProjectOutput{Body: proj}, nil
+}
+
+func (a *App) GetProject(ctx context.Context, i *GetProjectInput) (*ProjectOutput, error) {
+	proj, err := a.projects.Get(ctx, i.ID)
+	if err != nil {
+		return nil, projectError(err)
+	}
+	return &ProjectOutput{Body: proj}, nil
+}
+
+func (a *App) UpdateProject(ctx context.Context, i *UpdateProjectInput) (*ProjectOutput, error) {
+	if err := validateProjectBody(i.Body); err != nil {
+		return nil, err
+	}
+
+	proj, err := a.projects.Get(ctx, i.ID)
+	if err != nil {
+		return nil, projectError(err)
+	}
+	proj.Name = i.Body.Name
+	proj.Description = i.Body.Description
+	proj.UpdatedAt = time.Now().UTC()
+
+	if err := a.projects.Update(ctx, proj); err != nil {
+		return nil, projectError(err)
+	}
+	return &ProjectOutput{Body: proj}, nil
+}
+
+// DeleteProject reports what deleting a project would remove and, unless
+// it is a dry run, deletes it. A project with services is only deleted
+// with force or cascade: its services are deleted first, and the project
+// once they are all gone.
+func (a *App) DeleteProject(ctx context.Context, i *DeleteProjectInput) (*DeletionOutput, error) {
+	if _, err := a.projects.Get(ctx, i.ID); err != nil {
+		return nil, projectError(err)
+	}
+	services, err := a.services.ListByProject(ctx, i.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	ids := make([]uuid.UUID, len(services))
+	for n, svc := range services {
+		ids[n] = svc.ID
+	}
+	d, err := a.planDeletion(services, ids, true)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("the project's dependency graph is invalid", err)
+	}
+	if i.DryRun {
+		return &DeletionOutput{Status: http.StatusOK, Body: &DeletionBody{DryRun: true, Impact: d.impact, Operations: []*OperationBody{}}}, nil
+	}
+
+	if len(services) == 0 {
+		if err := a.projects.Delete(ctx, i.ID); err != nil {
+			return nil, projectError(err)
+		}
+		return &DeletionOutput{Status: http.StatusNoContent}, nil
+	}
+	if !i.Force && !i.Cascade {
+		return nil, huma.Error409Conflict(fmt.Sprintf("project still has %d services. Pass cascade=true to delete them too; dryRun=true lists them", len(services)))
+	}
+	accepted, err := d.start(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := a.jobs.Enqueue(ctx, projectDeletionJobKind, projectDeletionPayload{ProjectID: i.ID}); err != nil {
+		return nil, huma.Error500InternalServerError("queueing the project deletion failed", err)
+	}
+	return &DeletionOutput{Status: http.StatusAccepted, Body: deletionBody(d.impact, accepted)}, nil
+}
+
+// projectDeletionJobKind is the kind of the jobs deleting projects.
+const projectDeletionJobKind = "project.delete"
+
+// projectDeletionPayload is the payload of jobs deleting projects.
+type projectDeletionPayload struct {
+	ProjectID uuid.UUID `json:"projectId"`
+}
+
+// runProjectDeletion is the job handler deleting a project once the
+// operations deleting its services have removed them. It gives up when
+// one of them failed, leaving the project in place.
+func (a *App) runProjectDeletion(ctx context.Context, job *jobs.Job) error {
+	var payload projectDeletionPayload
+	if err := job.Decode(&payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("decode project deletion job: %w", err))
+	}
+	services, err := a.services.ListByProject(ctx, payload.ProjectID)
+	if err != nil {
+		return err
+	}
+	for _, svc := range services {
+		if svc.Status != service.StatusDeleting && svc.Status != service.StatusDeleted {
+			return jobs.Permanent(fmt.Errorf("service %s is %s, so project %s is kept", svc.Name, svc.Status, payload.ProjectID))
+		}
+	}
+	if len(services) > 0 {
+		return jobs.Snooze(a.pollInterval)
+	}
+	err = a.projects.Delete(ctx, payload.ProjectID)
+	if errors.Is(err, project.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func validateProjectBody(body ProjectInputBody) error {
+	if err := project.ValidateName(body.Name); err != nil {
+		return huma.Error422UnprocessableEntity("validation failed", &huma.ErrorDetail{
+			Location: "body.name",
+			Message:  err.Error(),
+			Value:    body.Name,
+		})
+	}
+	return nil
+}
+
+// projectError maps repository errors to problem responses.
+func projectError(err error) error {
+	switch {
+	case errors.Is(err, project.ErrNotFound):
+		return huma.Error404NotFound("project not found")
+	case errors.Is(err, project.ErrAlreadyExists):
+		return huma.Error409Conflict("a project with this name already exists")
+	default:
+		return huma.Error500InternalServerError("project storage failed", err)
+	}
+}
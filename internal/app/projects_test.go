@@ -0,0 +1,174 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+// assertStatus checks that err is a huma.StatusError with the given status.
+func assertStatus(t *testing.T, err error, want int, message string) {
+	t.Helper()
+	var se huma.StatusError
+	if !errors.As(err, &se) {
+		t.Errorf("%s: got %v, want status error %d", message, err, want)
+		return
+	}
+	testutil.AssertEqual(t, se.GetStatus(), want, message)
+}
+
+func TestApp_CreateProject(t *testing.T) {
+	tests := []struct {
+		name       string
+		seed       []string
+		body       ProjectInputBody
+		wantStatus int
+	}{
+		{
+			name: "creates_project",
+			body: ProjectInputBody{Name: "shop", Description: "Online shop"},
+		},
+		{
+			name:       "rejects_invalid_name",
+			body:       ProjectInputBody{Name: "Not Valid"},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "rejects_empty_name",
+			body:       ProjectInputBody{Name: ""},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "rejects_duplicate_name",
+			seed:       []string{"shop"},
+			body:       ProjectInputBody{Name: "shop"},
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testutil.NewTestContext(t)
+			app := NewApp()
+			for _, name := range tt.seed {
+				_, err := app.CreateProject(ctx, &CreateProjectInput{Body: ProjectInputBody{Name: name}})
+				testutil.AssertNoError(t, err, "seeding project should succeed")
+			}
+
+			got, err := app.CreateProject(ctx, &CreateProjectInput{Body: tt.body})
+
+			if tt.wantStatus != 0 {
+				assertStatus(t, err, tt.wantStatus, "status should match")
+				return
+			}
+			testutil.AssertNoError(t, err, "create should succeed")
+			testutil.AssertNotEqual(t, got.Body.ID, uuid.Nil, "ID should be generated")
+			testutil.AssertEqual(t, got.Body.Name, tt.body.Name, "name should match")
+			testutil.AssertEqual(t, got.Body.Description, tt.body.Description, "description should match")
+			testutil.AssertFalse(t, got.Body.CreatedAt.IsZero(), "creation time should be set")
+		})
+	}
+}
+
+func TestApp_CreateProject_RepositoryError(t *testing.T) {
+	repo := testutil.NewMockProjectRepository()
+	repo.CreateError = errors.New("disk full")
+	app := NewApp(WithProjectRepository(repo))
+
+	_, err := app.CreateProject(testutil.NewTestContext(t), &CreateProjectInput{Body: ProjectInputBody{Name: "shop"}})
+	assertStatus(t, err, http.StatusInternalServerError, "unexpected repository errors should be 500")
+}
+
+func TestApp_GetProject(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	app := NewApp()
+	created, err := app.CreateProject(ctx, &CreateProjectInput{Body: ProjectInputBody{Name: "shop"}})
+	testutil.AssertNoError(t, err, "create should succeed")
+
+	t.Run("returns_existing_project", func(t *testing.T) {
+		got, err := app.GetProject(ctx, &GetProjectInput{ID: created.Body.ID})
+		testutil.AssertNoError(t, err, "get should succeed")
+		testutil.AssertEqual(t, got.Body.Name, "shop", "name should match")
+	})
+
+	t.Run("returns_404_for_missing_project", func(t *testing.T) {
+		_, err := app.GetProject(ctx, &GetProjectInput{ID: uuid.New()})
+		assertStatus(t, err, http.StatusNotFound, "missing project should be 404")
+	})
+}
+
+func TestApp_UpdateProject(t *testing.T) {
+	tests := []struct {
+		name       string
+		missing    bool
+		body       ProjectInputBody
+		wantStatus int
+	}{
+		{
+			name: "updates_name_and_description",
+			body: ProjectInputBody{Name: "storefront", Description: "Renamed"},
+		},
+		{
+			name:       "rejects_invalid_name",
+			body:       ProjectInputBody{Name: "-bad"},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "rejects_name_of_other_project",
+			body:       ProjectInputBody{Name: "other"},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "returns_404_for_missing_project",
+			missing:    true,
+			body:       ProjectInputBody{Name: "storefront"},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testutil.NewTestContext(t)
+			app := NewApp()
+			created, err := app.CreateProject(ctx, &CreateProjectInput{Body: ProjectInputBody{Name: "shop"}})
+			testutil.AssertNoError(t, err, "create should succeed")
+			_, err = app.CreateProject(ctx, &CreateProjectInput{Body: ProjectInputBody{Name: "other"}})
+			testutil.AssertNoError(t, err, "create should succeed")
+
+			id := created.Body.ID
+			if tt.missing {
+				id = uuid.New()
+			}
+			got, err := app.UpdateProject(ctx, &UpdateProjectInput{ID: id, Body: tt.body})
+
+			if tt.wantStatus != 0 {
+				assertStatus(t, err, tt.wantStatus, "status should match")
+				return
+			}
+			testutil.AssertNoError(t, err, "update should succeed")
+			testutil.AssertEqual(t, got.Body.Name, tt.body.Name, "name should be updated")
+			testutil.AssertEqual(t, got.Body.Description, tt.body.Description, "description should be updated")
+			testutil.AssertEqual(t, got.Body.CreatedAt, created.Body.CreatedAt, "creation time should be kept")
+		})
+	}
+}
+
+func TestApp_DeleteProject(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	app := NewApp()
+	created, err := app.CreateProject(ctx, &CreateProjectInput{Body: ProjectInputBody{Name: "shop"}})
+	testutil.AssertNoError(t, err, "create should succeed")
+
+	_, err = app.DeleteProject(ctx, &DeleteProjectInput{ID: created.Body.ID})
+	testutil.AssertNoError(t, err, "delete should succeed")
+
+	_, err = app.GetProject(ctx, &GetProjectInput{ID: created.Body.ID})
+	assertStatus(t, err, http.StatusNotFound, "deleted project should be gone")
+
+	_, err = app.DeleteProject(ctx, &DeleteProjectInput{ID: created.Body.ID})
+	assertStatus(t, err, http.StatusNotFound, "deleting twice should be 404")
+}
@@ -0,0 +1,389 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Bermos/Platform/internal/jobs"
+	"github.com/Bermos/Platform/internal/operation"
+	"github.com/Bermos/Platform/internal/resource"
+	tfmodule "github.com/Bermos/Platform/internal/resource/terraform-module"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/google/uuid"
+)
+
+// operationJobKind is the kind of the jobs running operations.
+const operationJobKind = "operation"
+
+var (
+	// errCancelled is the error of cancelled operations.
+	errCancelled = errors.New("operation cancelled")
+	// errAwaitingApproval stops an operation whose plan must be approved.
+	errAwaitingApproval = errors.New("operation awaits approval")
+)
+
+// operationPayload is the payload of operation jobs.
+type operationPayload struct {
+	OperationID uuid.UUID `json:"operationId"`
+}
+
+// runOperation is the job handler running operations. The outcome of an
+// operation, including failure, is recorded on the operation; only errors
+// recording it make the job fail. An operation interrupted by shutdown
+// starts over on the next start.
+func (a *App) runOperation(ctx context.Context, job *jobs.Job) error {
+	var payload operationPayload
+	if err := job.Decode(&payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("decode operation job: %w", err))
+	}
+	op, err := a.operations.Get(ctx, payload.OperationID)
+	if errors.Is(err, operation.ErrNotFound) {
+		return jobs.Permanent(err)
+	}
+	if err != nil {
+		return err
+	}
+	if op.State.Done() {
+		return nil
+	}
+	if job.ID != op.JobID {
+		// The operation has moved on to another job, e.g. when approved.
+		return nil
+	}
+
+	r := &operationRun{app: a, op: op, queuedAt: job.CreatedAt}
+	op.Start(r.now())
+	if job.Attempts > 1 {
+		op.Logf(r.now(), "Starting over, attempt %d", job.Attempts)
+	}
+	if err := r.save(); err != nil {
+		return err
+	}
+
+	result, err := r.execute(ctx)
+	switch {
+	case err == nil:
+		op.Finish(operation.StateSucceeded, result, nil, r.now())
+	case errors.Is(err, errAwaitingApproval):
+		// The operation goes on in a new job once approved.
+	case jobs.IsSnoozed(err):
+		// The operation goes on when its job runs again.
+		if serr := r.save(); serr != nil {
+			return serr
+		}
+		return err
+	case errors.Is(context.Cause(ctx), jobs.ErrCancelled):
+		op.Logf(r.now(), "Cancelled")
+		op.Finish(operation.StateCancelled, nil, errCancelled, r.now())
+		a.failService(context.WithoutCancel(ctx), op, errCancelled.Error())
+	case ctx.Err() != nil && !errors.Is(ctx.Err(), context.DeadlineExceeded):
+		op.Logf(r.now(), "Interrupted by shutdown; the operation starts over on the next start")
+		if serr := r.save(); serr != nil {
+			return serr
+		}
+		return err
+	default:
+		op.Logf(r.now(), "Failed: %v", err)
+		op.Finish(operation.StateFailed, nil, err, r.now())
+		a.failService(context.WithoutCancel(ctx), op, err.Error())
+	}
+	return r.save()
+}
+
+// failService marks the service of a failed or cancelled operation as
+// failed, unless it is already gone.
+func (a *App) failService(ctx context.Context, op *operation.Operation, reason string) {
+	svc, err := a.services.Get(ctx, op.ServiceID)
+	if err != nil || !service.CanTransition(svc.Status, service.StatusFailed) {
+		return
+	}
+	a.transition(ctx, svc, service.StatusFailed, service.ActorSystem, fmt.Sprintf("%s operation %s: %s", op.Kind, op.ID, reason))
+}
+
+// operationRun executes one attempt of an operation and records its
+// progress.
+type operationRun struct {
+	app *App
+	op  *operation.Operation
+	// queuedAt is when the job running the operation was queued. Snoozing
+	// keeps the job, so waiting is bounded from here.
+	queuedAt time.Time
+}
+
+func (r *operationRun) now() time.Time {
+	return time.Now().UTC()
+}
+
+// save persists the operation. It is not bound to the job's context, so
+// that cancellation and failure can still be recorded.
+func (r *operationRun) save() error {
+	return r.app.operations.Update(context.Background(), r.op)
+}
+
+// step runs fn as the named step. fn returns the message to record, and
+// StepSkipped or StepSucceeded.
+func (r *operationRun) step(name string, fn func() (operation.StepState, string, error)) error {
+	if err := r.op.StartStep(name, r.now()); err != nil {
+		return err
+	}
+	if err := r.save(); err != nil {
+		return err
+	}
+	state, msg, err := fn()
+	if err != nil {
+		state, msg = operation.StepFailed, err.Error()
+	}
+	if ferr := r.op.FinishStep(name, state, msg, r.now()); ferr != nil {
+		return ferr
+	}
+	if serr := r.save(); serr != nil && err == nil {
+		return serr
+	}
+	return err
+}
+
+// skipAll skips every step that has not run yet.
+func (r *operationRun) skipAll(reason string) error {
+	for _, s := range r.op.Steps {
+		if s.State != operation.StepPending {
+			continue
+		}
+		if err := r.op.FinishStep(s.Name, operation.StepSkipped, reason, r.now()); err != nil {
+			return err
+		}
+	}
+	return r.save()
+}
+
+func (r *operationRun) execute(ctx context.Context) (map[string]any, error) {
+	a := r.app
+	svc, err := a.services.Get(ctx, r.op.ServiceID)
+	if errors.Is(err, service.ErrNotFound) && r.op.Kind == operation.KindDeleteService {
+		// A previous attempt got as far as removing the service.
+		return nil, r.skipAll("service already removed")
+	}
+	if err != nil {
+		return nil, err
+	}
+	// A drift check that began before the operation finishes first.
+	unlock := a.busy.Lock(svc.ID)
+	defer unlock()
+	// Services are provisioned after their dependencies and destroyed
+	// before them.
+	if r.op.Kind == operation.KindDeleteService {
+		err = r.awaitDependents(ctx, svc)
+	} else {
+		err = r.resolveConfig(ctx, svc)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := a.instance.Catalog.Get(svc.ResourceKey)
+	mod, isModule := res.(*tfmodule.Resource)
+	if isModule && a.terraform != nil {
+		return r.executeModule(ctx, svc, mod)
+	}
+
+	lc, _ := res.(resource.Lifecycle)
+	var skip string
+	switch {
+	case isModule:
+		skip = "Terraform is not configured"
+	case lc == nil:
+		skip = fmt.Sprintf("resource %q is not provisioned by Mahler", svc.ResourceKey)
+	case a.provider == nil:
+		skip = "no provider is configured"
+	}
+
+	switch r.op.Kind {
+	case operation.KindCreateService, operation.KindUpdateService, operation.KindReconcileService:
+		return r.provision(ctx, svc, lc, skip)
+	case operation.KindDeleteService:
+		return nil, r.deprovision(ctx, svc, func() (operation.StepState, string, error) {
+			if skip != "" {
+				return operation.StepSkipped, skip, nil
+			}
+			if err := lc.Destroy(ctx, a.provider, a.serviceRef(svc)); err != nil {
+				return "", "", err
+			}
+			return operation.StepSucceeded, "objects destroyed", nil
+		})
+	}
+	return nil, fmt.Errorf("unknown operation kind %q", r.op.Kind)
+}
+
+// provision plans and applies the service's configuration, waits for its
+// objects to become ready and marks the service ready.
+func (r *operationRun) provision(ctx context.Context, svc *service.Service, lc resource.Lifecycle, skip string) (map[string]any, error) {
+	a := r.app
+	var changes []resource.Change
+	if skip != "" {
+		r.op.Logf(r.now(), "Nothing to provision: %s", skip)
+		if err := r.skipAll(skip); err != nil {
+			return nil, err
+		}
+	} else {
+		req := resource.Request{Service: a.serviceRef(svc), Config: svc.Config}
+		var plan *resource.Plan
+		err := r.step(operation.StepPlan, func() (operation.StepState, string, error) {
+			var err error
+			if plan, err = lc.Plan(ctx, a.provider, req); err != nil {
+				return "", "", err
+			}
+			r.logChanges("Planned", plan.Changes)
+			return operation.StepSucceeded, fmt.Sprintf("%d changes", len(plan.Changes)), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = r.step(operation.StepApply, func() (operation.StepState, string, error) {
+			if plan.Empty() {
+				return operation.StepSkipped, "already up to date", nil
+			}
+			res, err := lc.Apply(ctx, a.provider, req)
+			if err != nil {
+				return "", "", err
+			}
+			changes = res.Changes
+			r.logChanges("Applied", changes)
+			return operation.StepSucceeded, fmt.Sprintf("%d changes applied", len(changes)), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = r.step(operation.StepVerify, func() (operation.StepState, string, error) {
+			st, err := r.waitReady(ctx, lc, req.Service)
+			if err != nil {
+				return "", "", err
+			}
+			return operation.StepSucceeded, st.Message, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		outputs, err := lifecycleOutputs(lc.(resource.Resource), plan.Outputs)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.recordOutputs(ctx, svc.ID, outputs); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.settle(ctx, svc.ID, service.StatusReady); err != nil {
+		return nil, err
+	}
+	return toResult(map[string]any{"status": service.StatusReady, "changes": nonNilChanges(changes)})
+}
+
+// deprovision runs destroy as the destroy step and removes the service.
+func (r *operationRun) deprovision(ctx context.Context, svc *service.Service, destroy func() (operation.StepState, string, error)) error {
+	a := r.app
+	if err := r.step(operation.StepDestroy, destroy); err != nil {
+		return err
+	}
+
+	return r.step(operation.StepRemove, func() (operation.StepState, string, error) {
+		if err := r.settle(ctx, svc.ID, service.StatusDeleted); err != nil {
+			return "", "", err
+		}
+		if err := a.services.Delete(ctx, svc.ID); err != nil {
+			return "", "", err
+		}
+		return operation.StepSucceeded, "service removed", nil
+	})
+}
+
+// settle moves the service to its final status. A ready service has just
+// been given its configuration, so any drift recorded before is gone.
+func (r *operationRun) settle(ctx context.Context, id uuid.UUID, to service.Status) error {
+	svc, err := r.app.services.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if to == service.StatusReady && svc.Drift != nil {
+		svc.Drift = nil
+		if err := r.app.services.Update(ctx, svc); err != nil {
+			return err
+		}
+	}
+	if svc.Status == to {
+		return nil
+	}
+	return r.app.transition(ctx, svc, to, service.ActorSystem, fmt.Sprintf("%s operation %s succeeded", r.op.Kind, r.op.ID))
+}
+
+// waitReady polls the status of the service's objects until they are ready.
+func (r *operationRun) waitReady(ctx context.Context, lc resource.Lifecycle, ref resource.ServiceRef) (*resource.Status, error) {
+	ticker := time.NewTicker(r.app.pollInterval)
+	defer ticker.Stop()
+
+	var last string
+	for {
+		st, err := lc.Status(ctx, r.app.provider, ref)
+		if err != nil {
+			return nil, err
+		}
+		switch st.Phase {
+		case resource.PhaseReady:
+			return st, nil
+		case resource.PhaseFailed:
+			return nil, fmt.Errorf("objects failed: %s", st.Message)
+		case resource.PhaseMissing:
+			return nil, errors.New("objects are missing after apply")
+		}
+		if st.Message != last {
+			r.op.Logf(r.now(), "Waiting for objects: %s", st.Message)
+			last = st.Message
+			if err := r.save(); err != nil {
+				return nil, err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *operationRun) logChanges(verb string, changes []resource.Change) {
+	if len(changes) == 0 {
+		r.op.Logf(r.now(), "%s no changes", verb)
+		return
+	}
+	lines := make([]string, len(changes))
+	for i, c := range changes {
+		lines[i] = fmt.Sprintf("%s %s", c.Action, c.Object)
+	}
+	r.op.Logf(r.now(), "%s %d changes: %s", verb, len(changes), strings.Join(lines, ", "))
+}
+
+func nonNilChanges(changes []resource.Change) []resource.Change {
+	if changes == nil {
+		return []resource.Change{}
+	}
+	return changes
+}
+
+// toResult converts v to plain JSON values, as stored in an operation's
+// result.
+func toResult(v map[string]any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode operation result: %w", err)
+	}
+	var result map[string]any
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("encode operation result: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/Bermos/Platform/internal/resource"
+)
+
+// ResourceBody is the API representation of a catalog entry.
+type ResourceBody struct {
+	Key          string                 `json:"key" doc:"Stable resource type key, used when creating services"`
+	Version      int                    `json:"version" doc:"Resource type version"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	PricePerHour float64                `json:"pricePerHour" doc:"Price of running the resource for one hour"`
+	Capabilities []resource.Capability  `json:"capabilities" doc:"What the resource provides to other services"`
+	Requires     []resource.Requirement `json:"requires" doc:"Capabilities other services in the project must provide"`
+	ConfigSchema any                    `json:"configSchema,omitempty" doc:"JSON Schema of the service configuration, absent if the resource takes none"`
+}
+
+type ListResourcesOutput struct {
+	Body []*ResourceBody
+}
+
+func (a *App) ListResources(ctx context.Context, i *struct{}) (*ListResourcesOutput, error) {
+	catalog := a.instance.Catalog
+	resources := catalog.Resources()
+
+	bodies := make([]*ResourceBody, 0, len(resources))
+	for _, res := range resources {
+		bodies = append(bodies, newResourceBody(res, catalog.Version(res.Key())))
+	}
+	return &ListResourcesOutput{Body: bodies}, nil
+}
+
+// Catalog returns the resources offered by the instance.
+func (a *App) Catalog() *resource.Catalog {
+	return a.instance.Catalog
+}
+
+func newResourceBody(res resource.Resource, version int) *ResourceBody {
+	capabilities := res.Provides()
+	if capabilities == nil {
+		capabilities = []resource.Capability{}
+	}
+	requires := res.Requires()
+	if requires == nil {
+		requires = []resource.Requirement{}
+	}
+	body := &ResourceBody{
+		Key:          res.Key(),
+		Version:      version,
+		Name:         res.Name(),
+		Description:  res.Description(),
+		PricePerHour: res.Price(time.Hour),
+		Capabilities: capabilities,
+		Requires:     requires,
+	}
+	if schema := res.ConfigSchema(); schema != nil {
+		body.ConfigSchema = schema
+	}
+	return body
+}
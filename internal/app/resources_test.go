@@ -0,0 +1,59 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/Bermos/Platform/internal"
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+func TestApp_ListResources(t *testing.T) {
+	tests := []struct {
+		name     string
+		instance *internal.Instance
+		wantKeys []string
+	}{
+		{
+			name:     "empty_catalog",
+			instance: &internal.Instance{},
+			wantKeys: []string{},
+		},
+		{
+			name: "lists_catalog_by_key",
+			instance: &internal.Instance{Catalog: testutil.NewTestCatalog(
+				testutil.NewMockResource().WithKey("small-vm"),
+				testutil.NewMockResource().WithKey("big-vm"),
+			)},
+			wantKeys: []string{"big-vm", "small-vm"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := NewApp(WithInstance(tt.instance))
+
+			got, err := app.ListResources(testutil.NewTestContext(t), nil)
+			testutil.AssertNoError(t, err, "list should succeed")
+			testutil.AssertNotNil(t, got.Body, "body should never be nil")
+			testutil.AssertEqual(t, len(got.Body), len(tt.wantKeys), "resource count should match")
+			for i, key := range tt.wantKeys {
+				testutil.AssertEqual(t, got.Body[i].Key, key, "resources should be ordered by key")
+			}
+		})
+	}
+}
+
+func TestApp_ListResources_Body(t *testing.T) {
+	res := testutil.NewMockResource().WithKey("small-vm").WithName("Small VM").WithPrice(0.25)
+	res.ProvidesValue = nil
+	app := NewApp(WithInstance(&internal.Instance{Catalog: testutil.NewTestCatalog(res)}))
+
+	got, err := app.ListResources(testutil.NewTestContext(t), nil)
+	testutil.AssertNoError(t, err, "list should succeed")
+
+	body := got.Body[0]
+	testutil.AssertEqual(t, body.Name, "Small VM", "name should match")
+	testutil.AssertEqual(t, body.Version, 1, "version should come from the catalog")
+	testutil.AssertEqual(t, body.PricePerHour, 0.25, "price should be per hour")
+	testutil.AssertNotNil(t, body.Capabilities, "capabilities should never be nil")
+}
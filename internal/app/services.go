@@ -0,0 +1,368 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/operation"
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+// ServiceBody is the API representation of a service.
+type ServiceBody struct {
+	ID          uuid.UUID      `json:"id"`
+	ProjectID   uuid.UUID      `json:"projectId"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Resource    string         `json:"resource" doc:"Key of the resource the service runs on"`
+	Config      map[string]any `json:"config" doc:"Resource configuration"`
+	Status      service.Status `json:"status" enum:"pending,provisioning,ready,updating,degraded,drifted,deleting,deleted,failed" doc:"Lifecycle state, see GET /api/v1/services/{id}/status"`
+	Drift       *service.Drift `json:"drift,omitempty" doc:"Changes made outside of Mahler that drift detection found; cleared once the service is reconciled"`
+	URL         string         `json:"url,omitempty" doc:"The service's url output, once provisioned; see GET /api/v1/services/{id}/outputs"`
+	DependsOn   []uuid.UUID    `json:"dependsOn" doc:"IDs of the services this one depends on"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+}
+
+// ServiceInputBody is the writable part of a service.
+type ServiceInputBody struct {
+	Name        string         `json:"name" minLength:"1" maxLength:"63" pattern:"^[a-z]([-a-z0-9]*[a-z0-9])?$" patternDescription:"lowercase letters, digits and hyphens" doc:"Service name, unique within the project"`
+	Description string         `json:"description,omitempty" maxLength:"1024" doc:"Free-form description"`
+	Resource    string         `json:"resource" minLength:"1" doc:"Key of one of the instance's available resources"`
+	Config      map[string]any `json:"config,omitempty" doc:"Resource configuration, valid against the resource's configSchema as listed by GET /api/v1/resources. Strings can refer to outputs of the services it depends on as ${name.output}, e.g. ${db.host}; write $${ for a literal ${"`
+	DependsOn   []uuid.UUID    `json:"dependsOn,omitempty" doc:"IDs of services in the same project that are provisioned before this one and deleted after it"`
+}
+
+type ServiceOutput struct {
+	Body *ServiceBody
+}
+
+type ListServicesOutput struct {
+	Body []*ServiceBody
+}
+
+type CreateServiceInput struct {
+	ProjectID uuid.UUID `path:"projectID" doc:"Project ID"`
+	Body      ServiceInputBody
+}
+
+type ListServicesInput struct {
+	ProjectID uuid.UUID `path:"projectID" doc:"Project ID"`
+}
+
+type GetServiceInput struct {
+	ID uuid.UUID `path:"id" doc:"Service ID"`
+}
+
+type UpdateServiceInput struct {
+	ID   uuid.UUID `path:"id" doc:"Service ID"`
+	Body ServiceInputBody
+}
+
+type DeleteServiceInput struct {
+	ID      uuid.UUID `path:"id" doc:"Service ID"`
+	DryRun  bool      `query:"dryRun" doc:"Only report what deleting the service would break"`
+	Force   bool      `query:"force" doc:"Delete the service even though other services depend on it. They lose the dependency, and their next operation fails while their configuration refers to its outputs"`
+	Cascade bool      `query:"cascade" doc:"Delete the services depending on it as well, directly or through others"`
+}
+
+type ReconcileServiceInput struct {
+	ID uuid.UUID `path:"id" doc:"Service ID"`
+}
+
+// CreateService stores a pending service and starts provisioning it.
+func (a *App) CreateService(ctx context.Context, i *CreateServiceInput) (*AcceptedOutput, error) {
+	if _, err := a.projects.Get(ctx, i.ProjectID); err != nil {
+		return nil, projectError(err)
+	}
+	services, err := a.services.ListByProject(ctx, i.ProjectID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	id := uuid.New()
+	res, err := a.validateServiceBody(i.Body, id, services)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.checkRequirements(ctx, i.ProjectID, uuid.Nil, res); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	svc := &service.Service{
+		ID:          id,
+		ProjectID:   i.ProjectID,
+		Name:        i.Body.Name,
+		Description: i.Body.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		ResourceKey: res.Key(),
+		Resource:    res,
+		Config:      i.Body.Config,
+		DependsOn:   i.Body.DependsOn,
+		Status:      service.StatusPending,
+	}
+	if err := a.services.Create(ctx, svc); err != nil {
+		return nil, serviceError(err)
+	}
+	return a.startOperation(ctx, operation.KindCreateService, svc, service.StatusProvisioning)
+}
+
+func (a *App) ListServices(ctx context.Context, i *ListServicesInput) (*ListServicesOutput, error) {
+	if _, err := a.projects.Get(ctx, i.ProjectID); err != nil {
+		return nil, projectError(err)
+	}
+	services, err := a.services.ListByProject(ctx, i.ProjectID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	bodies := make([]*ServiceBody, 0, len(services))
+	for _, svc := range services {
+		bodies = append(bodies, newServiceBody(svc))
+	}
+	return &ListServicesOutput{Body: bodies}, nil
+}
+
+func (a *App) GetService(ctx context.Context, i *GetServiceInput) (*ServiceOutput, error) {
+	svc, err := a.services.Get(ctx, i.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return &ServiceOutput{Body: newServiceBody(svc)}, nil
+}
+
+// UpdateService stores the new configuration of a service and starts
+// applying it.
+func (a *App) UpdateService(ctx context.Context, i *UpdateServiceInput) (*AcceptedOutput, error) {
+	svc, err := a.services.Get(ctx, i.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	services, err := a.services.ListByProject(ctx, svc.ProjectID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	res, err := a.validateServiceBody(i.Body, svc.ID, services)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIdle(svc, service.StatusUpdating); err != nil {
+		return nil, err
+	}
+	if err := a.checkRequirements(ctx, svc.ProjectID, svc.ID, res); err != nil {
+		return nil, err
+	}
+	// Operations destroy only what the service's current resource
+	// provisions, so the objects of another one would be left behind.
+	if res.Key() != svc.ResourceKey {
+		return nil, huma.Error422UnprocessableEntity("validation failed", &huma.ErrorDetail{
+			Location: "body.resource",
+			Message:  fmt.Sprintf("the resource of a provisioned service cannot be changed from %q; create a new service instead", svc.ResourceKey),
+			Value:    i.Body.Resource,
+		})
+	}
+
+	// Only the request that claims the service stores its configuration.
+	op, err := a.claimService(ctx, operation.KindUpdateService, svc, service.StatusUpdating)
+	if err != nil {
+		return nil, err
+	}
+	svc.Name = i.Body.Name
+	svc.Description = i.Body.Description
+	svc.Config = i.Body.Config
+	svc.DependsOn = i.Body.DependsOn
+	svc.UpdatedAt = time.Now().UTC()
+	if err := a.services.Update(ctx, svc); err != nil {
+		a.failService(context.WithoutCancel(ctx), op, "storing the configuration failed")
+		return nil, serviceError(err)
+	}
+	return a.queueOperation(ctx, op)
+}
+
+// ReconcileService starts re-applying a service's configuration unchanged,
+// which undoes changes made outside of Mahler.
+func (a *App) ReconcileService(ctx context.Context, i *ReconcileServiceInput) (*AcceptedOutput, error) {
+	svc, err := a.services.Get(ctx, i.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	if err := checkIdle(svc, service.StatusUpdating); err != nil {
+		return nil, err
+	}
+	return a.startOperation(ctx, operation.KindReconcileService, svc, service.StatusUpdating)
+}
+
+// DeleteService reports what deleting a service would break and, unless
+// it is a dry run, starts destroying it. The service is removed once the
+// operation succeeds, after the services depending on it. Services that
+// depend on it and are not being deleted block the deletion, unless it is
+// forced or cascades to them.
+func (a *App) DeleteService(ctx context.Context, i *DeleteServiceInput) (*DeletionOutput, error) {
+	if i.Force && i.Cascade {
+		return nil, huma.Error422UnprocessableEntity("force and cascade cannot be combined")
+	}
+	svc, err := a.services.Get(ctx, i.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	services, err := a.services.ListByProject(ctx, svc.ProjectID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	d, err := a.planDeletion(services, []uuid.UUID{svc.ID}, i.Cascade)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("the project's dependency graph is invalid", err)
+	}
+	if i.DryRun {
+		return &DeletionOutput{Status: http.StatusOK, Body: &DeletionBody{DryRun: true, Impact: d.impact, Operations: []*OperationBody{}}}, nil
+	}
+
+	if err := checkIdle(svc, service.StatusDeleting); err != nil {
+		return nil, err
+	}
+	if broken := d.impact.breaks(); len(broken) > 0 && !i.Force {
+		return nil, huma.Error409Conflict(fmt.Sprintf("other services depend on this service: %s. Pass force=true to leave them without it or cascade=true to delete them too; dryRun=true reports what would break", strings.Join(broken, ", ")))
+	}
+	accepted, err := d.start(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	return &DeletionOutput{
+		Status:   http.StatusAccepted,
+		Location: accepted[len(accepted)-1].Location,
+		Body:     deletionBody(d.impact, accepted),
+	}, nil
+}
+
+// validateServiceBody checks the service name and dependencies, resolves
+// the requested resource in the instance's catalog and validates the
+// configuration against the resource's schema. self is the ID of the
+// service and services are the others of its project.
+func (a *App) validateServiceBody(body ServiceInputBody, self uuid.UUID, services []*service.Service) (resource.Resource, error) {
+	var details []error
+	if err := service.ValidateName(body.Name); err != nil {
+		details = append(details, &huma.ErrorDetail{
+			Location: "body.name",
+			Message:  err.Error(),
+			Value:    body.Name,
+		})
+	}
+	deps, depDetails := a.checkDependencies(body, self, services)
+	details = append(details, depDetails...)
+	// References are checked with the outputs the dependencies have so
+	// far; those still unknown are checked when provisioning.
+	config, unresolved := previewConfig(body.Config, deps)
+
+	res := a.instance.Catalog.Get(body.Resource)
+	if res == nil {
+		details = append(details, &huma.ErrorDetail{
+			Location: "body.resource",
+			Message:  "resource is not available on this instance",
+			Value:    body.Resource,
+		})
+	} else if errs := slices.DeleteFunc(resource.ValidateConfig(res.ConfigSchema(), config, "body.config"), isUnresolvedReference); len(errs) > 0 {
+		details = append(details, errs...)
+	} else if renderer, ok := res.(k8s.Renderer); ok && !unresolved {
+		// Some constraints, such as label syntax, are only checked when
+		// rendering, so render once to reject them up front.
+		if _, err := renderer.Render(k8s.Owner{ServiceName: body.Name}, config); err != nil {
+			details = append(details, &huma.ErrorDetail{
+				Location: "body.config",
+				Message:  err.Error(),
+				Value:    body.Config,
+			})
+		}
+	}
+	if len(details) > 0 {
+		return nil, huma.Error422UnprocessableEntity("validation failed", details...)
+	}
+	return res, nil
+}
+
+// checkRequirements verifies that the other services of the project, all but
+// the one with ID self, provide every capability res requires.
+func (a *App) checkRequirements(ctx context.Context, projectID, self uuid.UUID, res resource.Resource) error {
+	requires := res.Requires()
+	if len(requires) == 0 {
+		return nil
+	}
+
+	services, err := a.services.ListByProject(ctx, projectID)
+	if err != nil {
+		return serviceError(err)
+	}
+	var available []resource.Capability
+	for _, svc := range services {
+		if svc.ID == self {
+			continue
+		}
+		if other := a.instance.Catalog.Get(svc.ResourceKey); other != nil {
+			available = append(available, other.Provides()...)
+		}
+	}
+
+	missing := resource.Unsatisfied(requires, available)
+	if len(missing) == 0 {
+		return nil
+	}
+	details := make([]error, len(missing))
+	for i, req := range missing {
+		details[i] = &huma.ErrorDetail{
+			Location: "body.resource",
+			Message:  fmt.Sprintf("requires a %s, which no other service in the project provides", req.Type),
+			Value:    res.Key(),
+		}
+	}
+	return huma.Error422UnprocessableEntity("unsatisfied resource requirements", details...)
+}
+
+func newServiceBody(svc *service.Service) *ServiceBody {
+	config := svc.Config
+	if config == nil {
+		config = map[string]any{}
+	}
+	dependsOn := svc.DependsOn
+	if dependsOn == nil {
+		dependsOn = []uuid.UUID{}
+	}
+	return &ServiceBody{
+		ID:          svc.ID,
+		ProjectID:   svc.ProjectID,
+		Name:        svc.Name,
+		Description: svc.Description,
+		Resource:    svc.ResourceKey,
+		Config:      config,
+		Status:      svc.Status,
+		Drift:       svc.Drift,
+		URL:         serviceURL(svc),
+		DependsOn:   dependsOn,
+		CreatedAt:   svc.CreatedAt,
+		UpdatedAt:   svc.UpdatedAt,
+	}
+}
+
+// serviceError maps repository errors to problem responses.
+func serviceError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return huma.Error404NotFound("service not found")
+	case errors.Is(err, service.ErrAlreadyExists):
+		return huma.Error409Conflict("a service with this name already exists in the project")
+	case errors.Is(err, service.ErrInvalidDependency):
+		return huma.Error422UnprocessableEntity(err.Error())
+	case errors.Is(err, service.ErrInvalidTransition), errors.Is(err, service.ErrStatusConflict), errors.Is(err, service.ErrHasDependents):
+		return huma.Error409Conflict(err.Error())
+	default:
+		return huma.Error500InternalServerError("service storage failed", err)
+	}
+}
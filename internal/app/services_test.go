@@ -0,0 +1,313 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal"
+	"github.com/Bermos/Platform/internal/database/memory"
+	"github.com/Bermos/Platform/internal/operation"
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+// newServiceTestApp creates an App offering a "small-vm" and a "big-vm"
+// resource, together with one project called "shop".
+func newServiceTestApp(t *testing.T) (*App, uuid.UUID) {
+	t.Helper()
+
+	instance := &internal.Instance{
+		Name: "Test Instance",
+		Catalog: testutil.NewTestCatalog(
+			testutil.NewMockResource().WithKey("small-vm"),
+			testutil.NewMockResource().WithKey("big-vm"),
+		),
+	}
+	app := NewApp(WithInstance(instance))
+	startApp(t, app)
+
+	proj, err := app.CreateProject(context.Background(), &CreateProjectInput{Body: ProjectInputBody{Name: "shop"}})
+	testutil.AssertNoError(t, err, "create project should succeed")
+	return app, proj.Body.ID
+}
+
+func TestApp_CreateService(t *testing.T) {
+	tests := []struct {
+		name       string
+		missing    bool
+		seed       []string
+		body       ServiceInputBody
+		wantStatus int
+	}{
+		{
+			name: "creates_service",
+			body: ServiceInputBody{Name: "api", Resource: "small-vm"},
+		},
+		{
+			name:       "rejects_unknown_resource",
+			body:       ServiceInputBody{Name: "api", Resource: "mainframe"},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "rejects_invalid_name",
+			body:       ServiceInputBody{Name: "API", Resource: "small-vm"},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "rejects_duplicate_name",
+			seed:       []string{"api"},
+			body:       ServiceInputBody{Name: "api", Resource: "big-vm"},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "returns_404_for_missing_project",
+			missing:    true,
+			body:       ServiceInputBody{Name: "api", Resource: "small-vm"},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testutil.NewTestContext(t)
+			app, projectID := newServiceTestApp(t)
+			for _, name := range tt.seed {
+				_, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: ServiceInputBody{Name: name, Resource: "small-vm"}})
+				testutil.AssertNoError(t, err, "seeding service should succeed")
+			}
+			if tt.missing {
+				projectID = uuid.New()
+			}
+
+			got, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: tt.body})
+
+			if tt.wantStatus != 0 {
+				assertStatus(t, err, tt.wantStatus, "status should match")
+				return
+			}
+			testutil.AssertNoError(t, err, "create should succeed")
+			testutil.AssertEqual(t, got.Body.Kind, operation.KindCreateService, "create should start a create operation")
+			testutil.AssertEqual(t, got.Body.ProjectID, projectID, "project ID should match")
+			svc, err := app.GetService(ctx, &GetServiceInput{ID: got.Body.ServiceID})
+			testutil.AssertNoError(t, err, "the service should exist while it is provisioned")
+			testutil.AssertEqual(t, svc.Body.Resource, tt.body.Resource, "resource should match")
+		})
+	}
+}
+
+func TestApp_ListServices(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	app, projectID := newServiceTestApp(t)
+	other, err := app.CreateProject(ctx, &CreateProjectInput{Body: ProjectInputBody{Name: "other"}})
+	testutil.AssertNoError(t, err, "create project should succeed")
+
+	for _, name := range []string{"web", "api"} {
+		_, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: ServiceInputBody{Name: name, Resource: "small-vm"}})
+		testutil.AssertNoError(t, err, "create service should succeed")
+	}
+	_, err = app.CreateService(ctx, &CreateServiceInput{ProjectID: other.Body.ID, Body: ServiceInputBody{Name: "db", Resource: "big-vm"}})
+	testutil.AssertNoError(t, err, "create service should succeed")
+
+	t.Run("lists_only_services_of_project", func(t *testing.T) {
+		got, err := app.ListServices(ctx, &ListServicesInput{ProjectID: projectID})
+		testutil.AssertNoError(t, err, "list should succeed")
+		testutil.AssertEqual(t, len(got.Body), 2, "should list two services")
+		testutil.AssertEqual(t, got.Body[0].Name, "api", "services should be ordered by name")
+	})
+
+	t.Run("returns_404_for_missing_project", func(t *testing.T) {
+		_, err := app.ListServices(ctx, &ListServicesInput{ProjectID: uuid.New()})
+		assertStatus(t, err, http.StatusNotFound, "missing project should be 404")
+	})
+}
+
+func TestApp_GetUpdateDeleteService(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	app, projectID := newServiceTestApp(t)
+	created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: ServiceInputBody{Name: "api", Resource: "small-vm"}})
+	testutil.AssertNoError(t, err, "create should succeed")
+	id := created.Body.ServiceID
+	waitDone(t, app, created)
+
+	got, err := app.GetService(ctx, &GetServiceInput{ID: id})
+	testutil.AssertNoError(t, err, "get should succeed")
+	testutil.AssertEqual(t, got.Body.Name, "api", "name should match")
+
+	updated, err := app.UpdateService(ctx, &UpdateServiceInput{ID: id, Body: ServiceInputBody{Name: "gateway", Resource: "small-vm"}})
+	testutil.AssertNoError(t, err, "update should succeed")
+	testutil.AssertEqual(t, updated.Body.Kind, operation.KindUpdateService, "update should start an update operation")
+	waitDone(t, app, updated)
+	got, err = app.GetService(ctx, &GetServiceInput{ID: id})
+	testutil.AssertNoError(t, err, "get should succeed")
+	testutil.AssertEqual(t, got.Body.Name, "gateway", "name should be updated")
+
+	_, err = app.UpdateService(ctx, &UpdateServiceInput{ID: id, Body: ServiceInputBody{Name: "gateway", Resource: "big-vm"}})
+	assertStatus(t, err, http.StatusUnprocessableEntity, "changing the resource should be 422")
+	got, err = app.GetService(ctx, &GetServiceInput{ID: id})
+	testutil.AssertNoError(t, err, "get should succeed")
+	testutil.AssertEqual(t, got.Body.Resource, "small-vm", "the resource should be kept")
+	testutil.AssertEqual(t, got.Body.Status, service.StatusReady, "the service should stay ready")
+
+	_, err = app.UpdateService(ctx, &UpdateServiceInput{ID: id, Body: ServiceInputBody{Name: "gateway", Resource: "mainframe"}})
+	assertStatus(t, err, http.StatusUnprocessableEntity, "unknown resource should be 422")
+
+	_, err = app.UpdateService(ctx, &UpdateServiceInput{ID: uuid.New(), Body: ServiceInputBody{Name: "gateway", Resource: "small-vm"}})
+	assertStatus(t, err, http.StatusNotFound, "missing service should be 404")
+
+	_, err = app.DeleteProject(ctx, &DeleteProjectInput{ID: projectID})
+	assertStatus(t, err, http.StatusConflict, "project with services should not be deletable")
+
+	deleted, err := app.DeleteService(ctx, &DeleteServiceInput{ID: id})
+	testutil.AssertNoError(t, err, "delete should succeed")
+	testutil.AssertEqual(t, waitDeleted(t, app, deleted).State, operation.StateSucceeded, "delete should succeed")
+
+	_, err = app.GetService(ctx, &GetServiceInput{ID: id})
+	assertStatus(t, err, http.StatusNotFound, "deleted service should be 404")
+
+	_, err = app.DeleteService(ctx, &DeleteServiceInput{ID: id})
+	assertStatus(t, err, http.StatusNotFound, "deleting twice should be 404")
+
+	_, err = app.DeleteProject(ctx, &DeleteProjectInput{ID: projectID})
+	testutil.AssertNoError(t, err, "empty project should be deletable")
+}
+
+// slowServices is a service repository that takes a while to update
+// services, so that concurrent requests overlap.
+type slowServices struct {
+	service.Repository
+}
+
+func (r slowServices) Update(ctx context.Context, svc *service.Service) error {
+	time.Sleep(10 * time.Millisecond)
+	return r.Repository.Update(ctx, svc)
+}
+
+func TestApp_ConcurrentServiceUpdates(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	// Without a running queue the winning update stays in progress.
+	app := NewApp(
+		WithInstance(&internal.Instance{Name: "Test Instance", Catalog: testutil.NewTestCatalog(testutil.NewMockResource().WithKey("small-vm"))}),
+		WithServiceRepository(slowServices{memory.NewServiceRepository()}),
+	)
+	proj, err := app.CreateProject(ctx, &CreateProjectInput{Body: ProjectInputBody{Name: "shop"}})
+	testutil.AssertNoError(t, err, "create project should succeed")
+	svc := testutil.NewServiceBuilder().WithProjectID(proj.Body.ID).WithName("api").Build()
+	svc.ResourceKey = "small-vm"
+	svc.Status = service.StatusReady
+	testutil.AssertNoError(t, app.services.Create(ctx, svc), "create should succeed")
+
+	names := make([]string, 8)
+	won := make([]bool, len(names))
+	var wg sync.WaitGroup
+	for i := range names {
+		names[i] = fmt.Sprintf("api-%d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := app.UpdateService(ctx, &UpdateServiceInput{ID: svc.ID, Body: ServiceInputBody{Name: names[i], Resource: "small-vm"}})
+			if err != nil {
+				assertStatus(t, err, http.StatusConflict, "the updates losing the race should conflict")
+			}
+			won[i] = err == nil
+		}()
+	}
+	wg.Wait()
+
+	got, err := app.GetService(ctx, &GetServiceInput{ID: svc.ID})
+	testutil.AssertNoError(t, err, "get should succeed")
+	winners := 0
+	for i, ok := range won {
+		if ok {
+			winners++
+			testutil.AssertEqual(t, got.Body.Name, names[i], "the winning update should be stored")
+		}
+	}
+	testutil.AssertEqual(t, winners, 1, "exactly one update should win")
+}
+
+func TestApp_ServiceRequirements(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	instance := &internal.Instance{
+		Name: "Test Instance",
+		Catalog: testutil.NewTestCatalog(
+			testutil.NewMockResource().WithKey("postgres").WithProvides(resource.Provide(resource.PostgresConnection)),
+			testutil.NewMockResource().WithKey("web-app").WithRequires(resource.Require(resource.PostgresConnection)),
+		),
+	}
+	app := NewApp(WithInstance(instance))
+	startApp(t, app)
+	proj, err := app.CreateProject(ctx, &CreateProjectInput{Body: ProjectInputBody{Name: "shop"}})
+	testutil.AssertNoError(t, err, "create project should succeed")
+	other, err := app.CreateProject(ctx, &CreateProjectInput{Body: ProjectInputBody{Name: "other"}})
+	testutil.AssertNoError(t, err, "create project should succeed")
+
+	_, err = app.CreateService(ctx, &CreateServiceInput{ProjectID: proj.Body.ID, Body: ServiceInputBody{Name: "web", Resource: "web-app"}})
+	assertStatus(t, err, http.StatusUnprocessableEntity, "unsatisfied requirement should be 422")
+
+	_, err = app.CreateService(ctx, &CreateServiceInput{ProjectID: other.Body.ID, Body: ServiceInputBody{Name: "db", Resource: "postgres"}})
+	testutil.AssertNoError(t, err, "create postgres should succeed")
+	_, err = app.CreateService(ctx, &CreateServiceInput{ProjectID: proj.Body.ID, Body: ServiceInputBody{Name: "web", Resource: "web-app"}})
+	assertStatus(t, err, http.StatusUnprocessableEntity, "capabilities of other projects should not count")
+
+	db, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: proj.Body.ID, Body: ServiceInputBody{Name: "db", Resource: "postgres"}})
+	testutil.AssertNoError(t, err, "create postgres should succeed")
+	web, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: proj.Body.ID, Body: ServiceInputBody{Name: "web", Resource: "web-app"}})
+	testutil.AssertNoError(t, err, "satisfied requirement should allow creation")
+	waitDone(t, app, db)
+	waitDone(t, app, web)
+
+	_, err = app.UpdateService(ctx, &UpdateServiceInput{ID: db.Body.ServiceID, Body: ServiceInputBody{Name: "db", Resource: "web-app"}})
+	assertStatus(t, err, http.StatusUnprocessableEntity, "a service should not satisfy its own requirements")
+
+	_, err = app.UpdateService(ctx, &UpdateServiceInput{ID: web.Body.ServiceID, Body: ServiceInputBody{Name: "frontend", Resource: "web-app"}})
+	testutil.AssertNoError(t, err, "update with satisfied requirement should succeed")
+}
+
+func TestApp_ServiceConfig(t *testing.T) {
+	type vmConfig struct {
+		Cores int `json:"cores" minimum:"1" maximum:"8"`
+	}
+	ctx := testutil.NewTestContext(t)
+	instance := &internal.Instance{
+		Name: "Test Instance",
+		Catalog: testutil.NewTestCatalog(
+			testutil.NewMockResource().WithKey("vm").WithConfigSchema(resource.SchemaFor[vmConfig]()),
+			testutil.NewMockResource().WithKey("static"),
+		),
+	}
+	app := NewApp(WithInstance(instance))
+	proj, err := app.CreateProject(ctx, &CreateProjectInput{Body: ProjectInputBody{Name: "shop"}})
+	testutil.AssertNoError(t, err, "create project should succeed")
+
+	tests := []struct {
+		name       string
+		body       ServiceInputBody
+		wantStatus int
+	}{
+		{name: "valid_config", body: ServiceInputBody{Name: "a", Resource: "vm", Config: map[string]any{"cores": 2}}},
+		{name: "no_config_for_resource_without_schema", body: ServiceInputBody{Name: "b", Resource: "static"}},
+		{name: "missing_required", body: ServiceInputBody{Name: "c", Resource: "vm"}, wantStatus: http.StatusUnprocessableEntity},
+		{name: "out_of_range", body: ServiceInputBody{Name: "d", Resource: "vm", Config: map[string]any{"cores": 64}}, wantStatus: http.StatusUnprocessableEntity},
+		{name: "config_for_resource_without_schema", body: ServiceInputBody{Name: "e", Resource: "static", Config: map[string]any{"cores": 2}}, wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: proj.Body.ID, Body: tt.body})
+			if tt.wantStatus != 0 {
+				assertStatus(t, err, tt.wantStatus, "status should match")
+				return
+			}
+			testutil.AssertNoError(t, err, "create should succeed")
+			svc, err := app.GetService(ctx, &GetServiceInput{ID: got.Body.ServiceID})
+			testutil.AssertNoError(t, err, "get should succeed")
+			testutil.AssertEqual(t, len(svc.Body.Config), len(tt.body.Config), "config should be stored")
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/google/uuid"
+)
+
+// ServiceStatusBody is the current lifecycle state of a service.
+type ServiceStatusBody struct {
+	ServiceID uuid.UUID      `json:"serviceId"`
+	Status    service.Status `json:"status" enum:"pending,provisioning,ready,updating,degraded,drifted,deleting,deleted,failed"`
+	Since     time.Time      `json:"since" doc:"When the service entered the status"`
+	Actor     string         `json:"actor,omitempty" doc:"Who caused the last transition; empty for new services"`
+	Reason    string         `json:"reason,omitempty" doc:"Why the last transition happened"`
+}
+
+// TransitionBody is one recorded status change.
+type TransitionBody struct {
+	From   service.Status `json:"from" enum:"pending,provisioning,ready,updating,degraded,drifted,deleting,deleted,failed"`
+	To     service.Status `json:"to" enum:"pending,provisioning,ready,updating,degraded,drifted,deleting,deleted,failed"`
+	Actor  string         `json:"actor"`
+	Reason string         `json:"reason"`
+	At     time.Time      `json:"at"`
+}
+
+type GetServiceStatusInput struct {
+	ID uuid.UUID `path:"id" doc:"Service ID"`
+}
+
+type ServiceStatusOutput struct {
+	Body *ServiceStatusBody
+}
+
+type ListServiceHistoryInput struct {
+	ID uuid.UUID `path:"id" doc:"Service ID"`
+}
+
+type ServiceHistoryOutput struct {
+	Body []*TransitionBody
+}
+
+func (a *App) GetServiceStatus(ctx context.Context, i *GetServiceStatusInput) (*ServiceStatusOutput, error) {
+	svc, err := a.services.Get(ctx, i.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	history, err := a.services.History(ctx, i.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	body := &ServiceStatusBody{ServiceID: svc.ID, Status: svc.Status, Since: svc.CreatedAt}
+	if n := len(history); n > 0 {
+		last := history[n-1]
+		body.Since, body.Actor, body.Reason = last.At, last.Actor, last.Reason
+	}
+	return &ServiceStatusOutput{Body: body}, nil
+}
+
+func (a *App) ListServiceHistory(ctx context.Context, i *ListServiceHistoryInput) (*ServiceHistoryOutput, error) {
+	history, err := a.services.History(ctx, i.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	bodies := make([]*TransitionBody, 0, len(history))
+	for _, t := range history {
+		bodies = append(bodies, &TransitionBody{From: t.From, To: t.To, Actor: t.Actor, Reason: t.Reason, At: t.At})
+	}
+	return &ServiceHistoryOutput{Body: bodies}, nil
+}
+
+// transition moves svc to status to and records who caused it and why. All
+// status changes of the app go through it; svc.Status is updated on
+// success.
+func (a *App) transition(ctx context.Context, svc *service.Service, to service.Status, actor, reason string) error {
+	t, err := service.NewTransition(svc, to, actor, reason, time.Now())
+	if err != nil {
+		return serviceError(err)
+	}
+	if err := a.services.Transition(ctx, t); err != nil {
+		return serviceError(err)
+	}
+	svc.Status = to
+	return nil
+}
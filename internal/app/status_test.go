@@ -0,0 +1,79 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+func TestApp_ServiceStatus(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	app, projectID := newServiceTestApp(t)
+	created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: ServiceInputBody{Name: "api", Resource: "small-vm"}})
+	testutil.AssertNoError(t, err, "create should succeed")
+	id := created.Body.ServiceID
+	waitDone(t, app, created)
+
+	status, err := app.GetServiceStatus(ctx, &GetServiceStatusInput{ID: id})
+	testutil.AssertNoError(t, err, "get status should succeed")
+	testutil.AssertEqual(t, status.Body.Status, service.StatusReady, "a provisioned service should be ready")
+	testutil.AssertEqual(t, status.Body.Actor, service.ActorSystem, "actor should be that of the last transition")
+
+	history, err := app.ListServiceHistory(ctx, &ListServiceHistoryInput{ID: id})
+	testutil.AssertNoError(t, err, "list history should succeed")
+	testutil.AssertEqual(t, len(history.Body), 2, "creation should record two transitions")
+	testutil.AssertEqual(t, history.Body[0].From, service.StatusPending, "history should be oldest first")
+	testutil.AssertEqual(t, history.Body[0].Actor, service.ActorAPI, "the API should start provisioning")
+	testutil.AssertEqual(t, history.Body[1].To, service.StatusReady, "history should end with the current status")
+
+	svc, err := app.services.Get(ctx, id)
+	testutil.AssertNoError(t, err, "get should succeed")
+	err = app.transition(ctx, svc, service.StatusPending, service.ActorAPI, "")
+	assertStatus(t, err, http.StatusConflict, "ready to pending should be rejected")
+
+	stale := *svc
+	stale.Status = service.StatusProvisioning
+	err = app.transition(ctx, &stale, service.StatusFailed, service.ActorSystem, "timeout")
+	assertStatus(t, err, http.StatusConflict, "a transition from a stale status should be rejected")
+
+	got, err := app.GetService(ctx, &GetServiceInput{ID: id})
+	testutil.AssertNoError(t, err, "get should succeed")
+	testutil.AssertEqual(t, got.Body.Status, service.StatusReady, "service body should include the status")
+
+	updated, err := app.UpdateService(ctx, &UpdateServiceInput{ID: id, Body: ServiceInputBody{Name: "api", Resource: "small-vm"}})
+	testutil.AssertNoError(t, err, "update should succeed")
+	waitDone(t, app, updated)
+	history, err = app.ListServiceHistory(ctx, &ListServiceHistoryInput{ID: id})
+	testutil.AssertNoError(t, err, "list history should succeed")
+	testutil.AssertEqual(t, len(history.Body), 4, "an update should go through updating back to ready")
+	testutil.AssertEqual(t, history.Body[2].To, service.StatusUpdating, "an update should pass through updating")
+}
+
+func TestApp_ServiceStatusSinceCreation(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	app, projectID := newServiceTestApp(t)
+	svc := testutil.NewServiceBuilder().WithProjectID(projectID).WithName("api").Build()
+	svc.Status = service.StatusPending
+	svc.CreatedAt = time.Now().UTC()
+	testutil.AssertNoError(t, app.services.Create(ctx, svc), "create should succeed")
+
+	status, err := app.GetServiceStatus(ctx, &GetServiceStatusInput{ID: svc.ID})
+	testutil.AssertNoError(t, err, "get status should succeed")
+	testutil.AssertEqual(t, status.Body.Status, service.StatusPending, "status should be pending")
+	testutil.AssertTrue(t, status.Body.Since.Equal(svc.CreatedAt), "a service without transitions should be pending since its creation")
+	testutil.AssertEqual(t, status.Body.Actor, "", "there should be no actor yet")
+}
+
+func TestApp_ServiceStatusNotFound(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	app, _ := newServiceTestApp(t)
+
+	_, err := app.GetServiceStatus(ctx, &GetServiceStatusInput{ID: uuid.New()})
+	assertStatus(t, err, http.StatusNotFound, "status of unknown service should be not found")
+	_, err = app.ListServiceHistory(ctx, &ListServiceHistoryInput{ID: uuid.New()})
+	assertStatus(t, err, http.StatusNotFound, "history of unknown service should be not found")
+}
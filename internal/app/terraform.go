@@ -0,0 +1,335 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Bermos/Platform/internal/operation"
+	tfmodule "github.com/Bermos/Platform/internal/resource/terraform-module"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/Bermos/Platform/internal/terraform"
+	"github.com/Bermos/Platform/internal/tfstate"
+)
+
+const (
+	// moduleName is the name the root module calls a service's module by.
+	moduleName = "service"
+	// planFile is the saved plan in a service's working directory.
+	planFile = "tfplan"
+	// backendFile configures the state backend in a service's working
+	// directory.
+	backendFile = "backend.tf.json"
+)
+
+// executeModule runs an operation on a service of a Terraform module.
+func (r *operationRun) executeModule(ctx context.Context, svc *service.Service, mod *tfmodule.Resource) (map[string]any, error) {
+	ctx, err := r.app.stateCredentials(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
+	switch r.op.Kind {
+	case operation.KindCreateService, operation.KindUpdateService, operation.KindReconcileService:
+		return r.applyModule(ctx, svc, mod)
+	case operation.KindDeleteService:
+		err := r.deprovision(ctx, svc, func() (operation.StepState, string, error) {
+			dir, err := r.app.writeWorkspace(svc, mod)
+			if err != nil {
+				return "", "", err
+			}
+			if err := r.app.terraform.Init(ctx, dir, terraform.InitOptions{MigrateState: true, Log: r.logTerraform}); err != nil {
+				return "", "", err
+			}
+			changes, err := r.app.terraform.Destroy(ctx, dir, terraform.ApplyOptions{Log: r.logTerraform})
+			if err != nil {
+				return "", "", err
+			}
+			return operation.StepSucceeded, fmt.Sprintf("%d destroyed", changes.Remove), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		// The sweep removes the workspace if this fails.
+		if err := r.app.workspaces.Remove(svc.ID.String()); err != nil {
+			r.op.Logf(r.now(), "Keeping the workspace: %v", err)
+		} else {
+			r.op.Logf(r.now(), "Removed the workspace")
+		}
+		if r.app.stateURL != "" {
+			if err := r.app.states.DeleteWorkspace(ctx, svc.ID.String()); err != nil && !errors.Is(err, tfstate.ErrNotFound) {
+				r.op.Logf(r.now(), "Keeping the state workspace: %v", err)
+			} else {
+				r.op.Logf(r.now(), "Removed the state workspace")
+			}
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown operation kind %q", r.op.Kind)
+}
+
+// applyModule plans the service's configuration and applies the saved
+// plan. A plan that deletes or replaces resources is applied only once
+// the operation has been approved; until then the operation waits with
+// the plan's diff for review.
+func (r *operationRun) applyModule(ctx context.Context, svc *service.Service, mod *tfmodule.Resource) (map[string]any, error) {
+	a := r.app
+	dir := a.workspaceDir(svc)
+	// An approved plan has changes, or there would be nothing to approve.
+	hasChanges := true
+	if r.op.ApprovedAt == nil {
+		err := r.step(operation.StepPlan, func() (operation.StepState, string, error) {
+			var err error
+			if dir, err = a.writeWorkspace(svc, mod); err != nil {
+				return "", "", err
+			}
+			if err := a.terraform.Init(ctx, dir, terraform.InitOptions{MigrateState: true, Log: r.logTerraform}); err != nil {
+				return "", "", err
+			}
+			summary, err := a.terraform.Plan(ctx, dir, terraform.PlanOptions{Out: planFile, Log: r.logTerraform})
+			if err != nil {
+				return "", "", err
+			}
+			hasChanges = summary.HasChanges
+			plan, err := a.terraform.Show(ctx, dir, planFile)
+			if err != nil {
+				return "", "", err
+			}
+			if r.op.Plan, err = plan.Diff(); err != nil {
+				return "", "", err
+			}
+			r.logDiff(r.op.Plan)
+			return operation.StepSucceeded, r.op.Plan.String(), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if r.op.Plan.Destructive() {
+			r.op.Logf(r.now(), "The plan deletes or replaces resources; approve the operation to apply it or cancel it")
+			r.op.AwaitApproval(r.now())
+			return nil, errAwaitingApproval
+		}
+	} else {
+		r.op.Logf(r.now(), "Applying the plan approved at %s", r.op.ApprovedAt.Format(time.RFC3339))
+	}
+
+	changes := &terraform.ChangeSummary{}
+	err := r.step(operation.StepApply, func() (operation.StepState, string, error) {
+		if !hasChanges {
+			return operation.StepSkipped, "already up to date", nil
+		}
+		var err error
+		if changes, err = a.terraform.Apply(ctx, dir, terraform.ApplyOptions{PlanFile: planFile, Log: r.logTerraform}); err != nil {
+			return "", "", err
+		}
+		return operation.StepSucceeded, fmt.Sprintf("%d added, %d changed, %d destroyed", changes.Add, changes.Change, changes.Remove), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := r.step(operation.StepVerify, func() (operation.StepState, string, error) {
+		return operation.StepSkipped, "Terraform waits for resources while applying", nil
+	}); err != nil {
+		return nil, err
+	}
+
+	values, err := a.terraform.Output(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := moduleOutputs(values)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.recordOutputs(ctx, svc.ID, outputs); err != nil {
+		return nil, err
+	}
+
+	if err := r.settle(ctx, svc.ID, service.StatusReady); err != nil {
+		return nil, err
+	}
+	return toResult(map[string]any{"status": service.StatusReady, "changes": changes})
+}
+
+// logTerraform records the progress Terraform reports. Messages are kept
+// until the step ends.
+func (r *operationRun) logTerraform(m terraform.Message) {
+	switch {
+	case m.Diagnostic != nil:
+		r.op.Logf(r.now(), "Terraform %s: %s", m.Diagnostic.Severity, m.Diagnostic.String())
+	case m.Type == "apply_complete", m.Type == "apply_errored", m.Type == "change_summary":
+		r.op.Logf(r.now(), "Terraform: %s", m.Text)
+	}
+}
+
+func (r *operationRun) logDiff(diff *terraform.Diff) {
+	if diff.Empty() {
+		r.op.Logf(r.now(), "Planned no changes")
+		return
+	}
+	lines := make([]string, len(diff.Resources))
+	for i, rd := range diff.Resources {
+		lines[i] = fmt.Sprintf("%s %s", rd.Action, rd.Address)
+	}
+	r.op.Logf(r.now(), "Planned %s: %s", diff, strings.Join(lines, ", "))
+}
+
+// workspaceDir is the working directory of a service's Terraform runs.
+func (a *App) workspaceDir(svc *service.Service) string {
+	return a.workspaces.Dir(svc.ID.String())
+}
+
+// writeWorkspace writes the root module, which calls the service's module
+// with its configuration, and its variables to the service's workspace and
+// returns the workspace's directory. State is kept in the state backend
+// when one is configured, and in the workspace otherwise.
+func (a *App) writeWorkspace(svc *service.Service, mod *tfmodule.Resource) (string, error) {
+	root, err := rootModule(a.workspaceDir(svc), mod.Module(), svc.Config)
+	if err != nil {
+		return "", err
+	}
+	vars := svc.Config
+	if vars == nil {
+		vars = map[string]any{}
+	}
+	contents := map[string]any{"main.tf.json": root, "terraform.tfvars.json": vars}
+	if a.stateURL != "" {
+		contents[backendFile] = a.stateBackend(svc)
+	}
+	files := make(map[string][]byte, len(contents))
+	for name, v := range contents {
+		if files[name], err = json.MarshalIndent(v, "", "  "); err != nil {
+			return "", fmt.Errorf("encode %s: %w", name, err)
+		}
+	}
+	return a.workspaces.Write(svc.ID.String(), files)
+}
+
+// httpBackend is the http backend block of a service's root module. The
+// credentials are passed in the environment instead, see stateCredentials.
+type httpBackend struct {
+	Address       string `json:"address"`
+	LockAddress   string `json:"lock_address"`
+	LockMethod    string `json:"lock_method"`
+	UnlockAddress string `json:"unlock_address"`
+	UnlockMethod  string `json:"unlock_method"`
+}
+
+// backendConfig is the JSON configuration holding a backend block.
+type backendConfig struct {
+	Terraform struct {
+		Backend struct {
+			HTTP httpBackend `json:"http"`
+		} `json:"backend"`
+	} `json:"terraform"`
+}
+
+// stateBackend returns the configuration pointing Terraform at the state
+// workspace named after svc's workspace.
+func (a *App) stateBackend(svc *service.Service) *backendConfig {
+	address := a.stateURL + statePath + svc.ID.String()
+	var cfg backendConfig
+	cfg.Terraform.Backend.HTTP = httpBackend{
+		Address:       address,
+		LockAddress:   address,
+		LockMethod:    "LOCK",
+		UnlockAddress: address,
+		UnlockMethod:  "UNLOCK",
+	}
+	return &cfg
+}
+
+// stateCredentials gives the state workspace of svc a new password,
+// creating the workspace on first use, and returns ctx with the
+// credentials in the environment Terraform's http backend reads them
+// from. The password is kept nowhere else, so every run on the service
+// gets its own; runs do not overlap, see App.busy.
+func (a *App) stateCredentials(ctx context.Context, svc *service.Service) (context.Context, error) {
+	if a.stateURL == "" {
+		return ctx, nil
+	}
+	name := svc.ID.String()
+	ws, err := a.states.GetWorkspace(ctx, name)
+	var password string
+	switch {
+	case errors.Is(err, tfstate.ErrNotFound):
+		if ws, password, err = tfstate.NewWorkspace(name, time.Now().UTC()); err != nil {
+			return nil, err
+		}
+		if err := a.states.CreateWorkspace(ctx, ws); err != nil {
+			return nil, fmt.Errorf("create state workspace: %w", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("get state workspace: %w", err)
+	default:
+		if password, err = ws.ResetPassword(); err != nil {
+			return nil, err
+		}
+		if err := a.states.UpdateWorkspace(ctx, ws); err != nil {
+			return nil, fmt.Errorf("reset state workspace password: %w", err)
+		}
+	}
+	return terraform.WithEnv(ctx, "TF_HTTP_USERNAME="+name, "TF_HTTP_PASSWORD="+password), nil
+}
+
+// rootModule returns the JSON configuration of a root module in dir that
+// calls m, passing the variables set in config and exposing every output.
+// Variables not set are left to the module's defaults.
+func rootModule(dir string, m *tfmodule.Module, config map[string]any) (map[string]any, error) {
+	source, err := moduleSource(dir, m.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	call := map[string]any{"source": source}
+	variables := map[string]any{}
+	for _, v := range m.Variables {
+		if _, ok := config[v.Name]; !ok {
+			continue
+		}
+		variables[v.Name] = map[string]any{"sensitive": v.Sensitive}
+		call[v.Name] = fmt.Sprintf("${var.%s}", v.Name)
+	}
+	outputs := map[string]any{}
+	for _, o := range m.Outputs {
+		outputs[o.Name] = map[string]any{
+			"value":     fmt.Sprintf("${module.%s.%s}", moduleName, o.Name),
+			"sensitive": o.Sensitive,
+		}
+	}
+
+	root := map[string]any{"module": map[string]any{moduleName: call}}
+	if len(variables) > 0 {
+		root["variable"] = variables
+	}
+	if len(outputs) > 0 {
+		root["output"] = outputs
+	}
+	return root, nil
+}
+
+// moduleSource returns the module directory relative to dir, as Terraform
+// only reads local modules in place when their source starts with ./ or
+// ../.
+func moduleSource(dir, moduleDir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absModule, err := filepath.Abs(moduleDir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absDir, absModule)
+	if err != nil {
+		return "", err
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, "../") {
+		rel = "./" + rel
+	}
+	return rel, nil
+}
@@ -0,0 +1,365 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal"
+	"github.com/Bermos/Platform/internal/operation"
+	"github.com/Bermos/Platform/internal/resource"
+	tfmodule "github.com/Bermos/Platform/internal/resource/terraform-module"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/Bermos/Platform/internal/terraform"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+// fakeTerraform is an Executor recording the commands it runs and the
+// environment Init and Output run with. Plan and Show report the resource
+// changes set with planChanges, and refresh-only plans the drift set with
+// driftChanges. Output reports a host and a sensitive password.
+type fakeTerraform struct {
+	mu       sync.Mutex
+	commands []string
+	env      []string
+	changes  []terraform.ResourceChange
+	drift    []terraform.ResourceChange
+}
+
+var _ terraform.Executor = (*fakeTerraform)(nil)
+
+func (f *fakeTerraform) planChanges(changes ...terraform.ResourceChange) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.changes = changes
+}
+
+func (f *fakeTerraform) driftChanges(changes ...terraform.ResourceChange) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.drift = changes
+}
+
+func (f *fakeTerraform) record(command string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.commands = append(f.commands, command)
+}
+
+func (f *fakeTerraform) Commands() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.commands...)
+}
+
+// Env returns the environment added to the last Init or Output.
+func (f *fakeTerraform) Env() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.env
+}
+
+func (f *fakeTerraform) Init(ctx context.Context, dir string, opts terraform.InitOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.commands = append(f.commands, "init")
+	f.env = terraform.Env(ctx)
+	return nil
+}
+
+func (f *fakeTerraform) Plan(ctx context.Context, dir string, opts terraform.PlanOptions) (*terraform.PlanSummary, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if opts.RefreshOnly {
+		f.commands = append(f.commands, "plan -refresh-only")
+		return &terraform.PlanSummary{HasChanges: len(f.drift) > 0}, nil
+	}
+	f.commands = append(f.commands, "plan")
+	return &terraform.PlanSummary{HasChanges: len(f.changes) > 0}, nil
+}
+
+func (f *fakeTerraform) Apply(ctx context.Context, dir string, opts terraform.ApplyOptions) (*terraform.ChangeSummary, error) {
+	f.record("apply " + opts.PlanFile)
+	return &terraform.ChangeSummary{Operation: "apply"}, nil
+}
+
+func (f *fakeTerraform) Destroy(ctx context.Context, dir string, opts terraform.ApplyOptions) (*terraform.ChangeSummary, error) {
+	f.record("destroy")
+	return &terraform.ChangeSummary{Operation: "destroy", Remove: 1}, nil
+}
+
+func (f *fakeTerraform) Show(ctx context.Context, dir, planFile string) (*terraform.Plan, error) {
+	f.record("show " + planFile)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if planFile == driftPlanFile {
+		return &terraform.Plan{ResourceDrift: f.drift}, nil
+	}
+	return &terraform.Plan{ResourceChanges: f.changes}, nil
+}
+
+func (f *fakeTerraform) Output(ctx context.Context, dir string) (map[string]terraform.OutputValue, error) {
+	f.mu.Lock()
+	f.commands = append(f.commands, "output")
+	f.env = terraform.Env(ctx)
+	f.mu.Unlock()
+	return map[string]terraform.OutputValue{
+		"host":     {Type: json.RawMessage(`"string"`), Value: json.RawMessage(`"cache.internal"`)},
+		"password": {Sensitive: true, Type: json.RawMessage(`"string"`), Value: json.RawMessage(`"hunter2"`)},
+	}, nil
+}
+
+// resourceChange returns a change of a null_resource with the given
+// actions.
+func resourceChange(actions ...string) terraform.ResourceChange {
+	before, after := json.RawMessage(`{"id":"1"}`), json.RawMessage(`{"id":"2"}`)
+	return terraform.ResourceChange{
+		Address: "module.service.null_resource.main",
+		Mode:    "managed",
+		Type:    "null_resource",
+		Change:  terraform.Change{Actions: actions, Before: before, After: after},
+	}
+}
+
+// newModuleTestApp creates an App offering a Terraform module as the
+// "cache" resource, provisioned with exec, together with one project
+// called "shop". It returns the directory holding the workspaces too.
+func newModuleTestApp(t *testing.T, exec terraform.Executor, opts ...Option) (*App, uuid.UUID, string) {
+	t.Helper()
+	moduleDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(moduleDir, "main.tf"), []byte(`
+variable "size" {
+  type    = number
+  default = 1
+}
+
+output "host" {
+  value = "cache.internal"
+}
+`), 0o600)
+	testutil.AssertNoError(t, err, "writing the module should succeed")
+	m, err := tfmodule.Load(moduleDir)
+	testutil.AssertNoError(t, err, "loading the module should succeed")
+	schema, err := m.ConfigSchema()
+	testutil.AssertNoError(t, err, "generating the schema should succeed")
+	res, err := tfmodule.New("cache", m, schema, resource.Settings{})
+	testutil.AssertNoError(t, err, "creating the resource should succeed")
+
+	workDir := t.TempDir()
+	instance := &internal.Instance{Name: "Test Instance", Catalog: testutil.NewTestCatalog(res)}
+	app := NewApp(append([]Option{WithInstance(instance), WithTerraform(exec, workDir)}, opts...)...)
+	startApp(t, app)
+	proj, err := app.CreateProject(context.Background(), &CreateProjectInput{Body: ProjectInputBody{Name: "shop"}})
+	testutil.AssertNoError(t, err, "create project should succeed")
+	return app, proj.Body.ID, workDir
+}
+
+// waitState waits until the operation is in state want.
+func waitState(t *testing.T, a *App, id uuid.UUID, want operation.State) *OperationBody {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := a.GetOperation(context.Background(), &GetOperationInput{ID: id})
+		testutil.AssertNoError(t, err, "get should succeed")
+		if got.Body.State == want {
+			return got.Body
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("operation %s is still %s after 5s, want %s", id, got.Body.State, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestApp_ModuleOperations(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	exec := &fakeTerraform{}
+	exec.planChanges(resourceChange("create"))
+	app, projectID, workDir := newModuleTestApp(t, exec)
+
+	created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: ServiceInputBody{Name: "cache", Resource: "cache", Config: map[string]any{"size": 2}}})
+	testutil.AssertNoError(t, err, "create should succeed")
+	op := waitDone(t, app, created)
+	testutil.AssertEqual(t, op.State, operation.StateSucceeded, "a plan creating resources should be applied without approval")
+	testutil.AssertEqual(t, op.Plan.String(), "1 to create, 0 to update, 0 to replace, 0 to delete", "the plan should be stored")
+	testutil.AssertEqual(t, op.Plan.Resources[0].Attributes[0].Path, "id", "the plan should list the changed attributes")
+	testutil.AssertEqual(t, strings.Join(exec.Commands(), ", "), "init, plan, show tfplan, apply tfplan, output", "the saved plan should be applied and the outputs read")
+
+	dir := filepath.Join(workDir, created.Body.ServiceID.String())
+	b, err := os.ReadFile(filepath.Join(dir, "terraform.tfvars.json"))
+	testutil.AssertNoError(t, err, "the variables should be written")
+	testutil.AssertEqual(t, string(b), "{\n  \"size\": 2\n}", "the variables should be the service's configuration")
+	_, err = os.Stat(filepath.Join(dir, "main.tf.json"))
+	testutil.AssertNoError(t, err, "the root module should be written")
+
+	deleted, err := app.DeleteService(ctx, &DeleteServiceInput{ID: created.Body.ServiceID})
+	testutil.AssertNoError(t, err, "delete should succeed")
+	op = waitDeleted(t, app, deleted)
+	testutil.AssertEqual(t, op.State, operation.StateSucceeded, "deleting should succeed")
+	testutil.AssertEqual(t, op.Steps[0].Message, "1 destroyed", "the destroy step should report what it destroyed")
+	commands := exec.Commands()
+	testutil.AssertEqual(t, commands[len(commands)-1], "destroy", "the service's resources should be destroyed")
+	_, err = os.Stat(dir)
+	testutil.AssertTrue(t, os.IsNotExist(err), "the workspace should be removed")
+}
+
+func TestApp_ModuleApproval(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+
+	newAwaiting := func(t *testing.T) (*App, *fakeTerraform, *AcceptedOutput) {
+		exec := &fakeTerraform{}
+		app, projectID, _ := newModuleTestApp(t, exec)
+		exec.planChanges(resourceChange("create"))
+		created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: ServiceInputBody{Name: "cache", Resource: "cache"}})
+		testutil.AssertNoError(t, err, "create should succeed")
+		waitDone(t, app, created)
+
+		exec.planChanges(resourceChange("delete", "create"))
+		updated, err := app.UpdateService(ctx, &UpdateServiceInput{ID: created.Body.ServiceID, Body: ServiceInputBody{Name: "cache", Resource: "cache", Config: map[string]any{"size": 4}}})
+		testutil.AssertNoError(t, err, "update should succeed")
+		op := waitState(t, app, updated.Body.ID, operation.StateAwaitingApproval)
+		testutil.AssertEqual(t, op.Plan.Count(terraform.DiffReplace), 1, "the plan should be stored for review")
+		testutil.AssertFalse(t, op.Done, "an operation awaiting approval should not be done")
+		testutil.AssertEqual(t, op.Steps[0].State, operation.StepSucceeded, "the plan step should succeed")
+		testutil.AssertEqual(t, op.Steps[1].State, operation.StepPending, "nothing should be applied yet")
+		return app, exec, updated
+	}
+
+	t.Run("approve", func(t *testing.T) {
+		app, exec, updated := newAwaiting(t)
+		_, err := app.UpdateService(ctx, &UpdateServiceInput{ID: updated.Body.ServiceID, Body: ServiceInputBody{Name: "cache", Resource: "cache"}})
+		assertStatus(t, err, http.StatusConflict, "a service awaiting approval should not be updated")
+
+		approved, err := app.OperationMethod(ctx, &OperationMethodInput{ID: updated.Body.ID.String() + ":approve"})
+		testutil.AssertNoError(t, err, "approve should succeed")
+		testutil.AssertTrue(t, approved.Body.ApprovedAt != nil, "the approval should be recorded")
+
+		op := waitDone(t, app, updated)
+		testutil.AssertEqual(t, op.State, operation.StateSucceeded, "the approved plan should be applied")
+		testutil.AssertEqual(t, op.Steps[0].State, operation.StepSucceeded, "the plan step should be kept")
+		commands := exec.Commands()
+		testutil.AssertEqual(t, commands[len(commands)-2], "apply tfplan", "the approved plan should be applied as saved")
+		svc, _ := app.GetService(ctx, &GetServiceInput{ID: updated.Body.ServiceID})
+		testutil.AssertEqual(t, svc.Body.Status, service.StatusReady, "the service should be ready")
+
+		_, err = app.OperationMethod(ctx, &OperationMethodInput{ID: updated.Body.ID.String() + ":approve"})
+		assertStatus(t, err, http.StatusConflict, "operations not awaiting approval cannot be approved")
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		app, exec, updated := newAwaiting(t)
+		applies := len(exec.Commands())
+
+		got, err := app.OperationMethod(ctx, &OperationMethodInput{ID: updated.Body.ID.String() + ":cancel"})
+		testutil.AssertNoError(t, err, "cancel should succeed")
+		testutil.AssertEqual(t, got.Body.State, operation.StateCancelled, "an operation awaiting approval should be cancelled at once")
+		testutil.AssertEqual(t, len(exec.Commands()), applies, "nothing should be applied")
+		svc, _ := app.GetService(ctx, &GetServiceInput{ID: updated.Body.ServiceID})
+		testutil.AssertEqual(t, svc.Body.Status, service.StatusFailed, "the service should fail")
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		app, exec, updated := newAwaiting(t)
+		methods := []string{"approve", "approve", "cancel", "approve", "cancel"}
+		won := make([]bool, len(methods))
+		var wg sync.WaitGroup
+		for i, method := range methods {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := app.OperationMethod(ctx, &OperationMethodInput{ID: updated.Body.ID.String() + ":" + method})
+				if err != nil {
+					assertStatus(t, err, http.StatusConflict, "the requests losing the race should conflict")
+				}
+				won[i] = err == nil
+			}()
+		}
+		wg.Wait()
+
+		approvals, cancels := 0, 0
+		for i, ok := range won {
+			switch {
+			case ok && methods[i] == "approve":
+				approvals++
+			case ok:
+				cancels++
+			}
+		}
+		testutil.AssertTrue(t, approvals+cancels > 0, "a request should win")
+		op := waitDone(t, app, updated)
+		applies := 0
+		for _, c := range exec.Commands() {
+			if c == "apply tfplan" {
+				applies++
+			}
+		}
+		if approvals == 0 {
+			testutil.AssertEqual(t, op.State, operation.StateCancelled, "the operation should be cancelled")
+			testutil.AssertEqual(t, applies, 1, "a cancelled plan should not be applied")
+		} else {
+			testutil.AssertEqual(t, approvals, 1, "only one approval should win")
+			testutil.AssertTrue(t, applies <= 2, "the approved plan should be applied at most once")
+		}
+	})
+}
+
+func TestApp_ModuleStateBackend(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	exec := &fakeTerraform{}
+	exec.planChanges(resourceChange("create"))
+	app, projectID, workDir := newModuleTestApp(t, exec, WithStateBackend("http://127.0.0.1:8080/"))
+
+	created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: ServiceInputBody{Name: "cache", Resource: "cache"}})
+	testutil.AssertNoError(t, err, "create should succeed")
+	waitDone(t, app, created)
+	name := created.Body.ServiceID.String()
+
+	b, err := os.ReadFile(filepath.Join(workDir, name, backendFile))
+	testutil.AssertNoError(t, err, "the backend should be written")
+	var cfg backendConfig
+	testutil.AssertNoError(t, json.Unmarshal(b, &cfg), "the backend should be valid JSON")
+	backend := cfg.Terraform.Backend.HTTP
+	testutil.AssertEqual(t, backend.Address, "http://127.0.0.1:8080/api/v1/terraform/state/"+name, "the state should be kept in the service's state workspace")
+	testutil.AssertEqual(t, backend.LockAddress, backend.Address, "the state should be locked at its address")
+	testutil.AssertFalse(t, strings.Contains(string(b), "password"), "the credentials should not be written to the workspace")
+
+	// credentials returns the password Terraform last ran with and checks
+	// it authorizes against the state workspace.
+	credentials := func() string {
+		t.Helper()
+		env := exec.Env()
+		testutil.AssertTrue(t, slices.Contains(env, "TF_HTTP_USERNAME="+name), "the workspace's name should be the username")
+		i := slices.IndexFunc(env, func(kv string) bool { return strings.HasPrefix(kv, "TF_HTTP_PASSWORD=") })
+		testutil.AssertTrue(t, i >= 0, "the password should be passed in the environment")
+		password := strings.TrimPrefix(env[i], "TF_HTTP_PASSWORD=")
+		ws, err := app.states.GetWorkspace(ctx, name)
+		testutil.AssertNoError(t, err, "the state workspace should exist")
+		testutil.AssertTrue(t, ws.CheckPassword(password), "the password should authorize")
+		return password
+	}
+	first := credentials()
+
+	exec.planChanges(resourceChange("update"))
+	updated, err := app.UpdateService(ctx, &UpdateServiceInput{ID: created.Body.ServiceID, Body: ServiceInputBody{Name: "cache", Resource: "cache", Config: map[string]any{"size": 2}}})
+	testutil.AssertNoError(t, err, "update should succeed")
+	testutil.AssertEqual(t, waitDone(t, app, updated).State, operation.StateSucceeded, "the update should succeed")
+	second := credentials()
+	testutil.AssertTrue(t, second != first, "every run should get a new password")
+	ws, err := app.states.GetWorkspace(ctx, name)
+	testutil.AssertNoError(t, err, "the state workspace should exist")
+	testutil.AssertFalse(t, ws.CheckPassword(first), "the previous password should no longer authorize")
+
+	deleted, err := app.DeleteService(ctx, &DeleteServiceInput{ID: created.Body.ServiceID})
+	testutil.AssertNoError(t, err, "delete should succeed")
+	waitDeleted(t, app, deleted)
+	_, err = app.states.GetWorkspace(ctx, name)
+	testutil.AssertError(t, err, "the state workspace should be deleted with the service")
+}
@@ -0,0 +1,420 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Bermos/Platform/internal/tfstate"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// statePath is where Terraform's http backend finds a workspace's state.
+const statePath = "/api/v1/terraform/state/"
+
+// StateAuthInput carries the credentials Terraform's http backend sends:
+// the workspace name as username and the workspace's password.
+type StateAuthInput struct {
+	Workspace     string `path:"workspace" doc:"Workspace name"`
+	Authorization string `header:"Authorization" doc:"HTTP basic credentials of the workspace"`
+}
+
+// RawStateOutput returns state as Terraform wrote it.
+type RawStateOutput struct {
+	Status      int
+	ContentType string `header:"Content-Type"`
+	ContentMD5  string `header:"Content-MD5" doc:"Base64 MD5 of the state"`
+	Body        []byte
+}
+
+type PutStateInput struct {
+	StateAuthInput
+	LockID     string `query:"ID" doc:"ID of the lock the writer holds"`
+	ContentMD5 string `header:"Content-MD5" doc:"Base64 MD5 of the state"`
+	RawBody    []byte
+}
+
+type LockStateInput struct {
+	StateAuthInput
+	RawBody []byte
+}
+
+// LockStateOutput has the lock information of the holder when the state is
+// locked already.
+type LockStateOutput struct {
+	Status      int
+	ContentType string `header:"Content-Type"`
+	Body        []byte
+}
+
+type StateWorkspaceInputBody struct {
+	Name string `json:"name" minLength:"1" maxLength:"63" pattern:"^[a-z0-9][a-z0-9._-]*$" patternDescription:"lowercase letters, digits, dots, underscores and hyphens" doc:"Unique workspace name"`
+}
+
+type CreateStateWorkspaceInput struct {
+	Body StateWorkspaceInputBody
+}
+
+// StateWorkspaceInput addresses a workspace with its credentials, like
+// the http backend does.
+type StateWorkspaceInput struct {
+	StateAuthInput
+}
+
+type StateVersionInput struct {
+	StateAuthInput
+	Version int `path:"version" minimum:"1" doc:"State version"`
+}
+
+// StateWorkspaceBody describes a workspace.
+type StateWorkspaceBody struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Address is the backend configuration's address, lock_address and
+	// unlock_address, relative to the Mahler server.
+	Address       string         `json:"address" doc:"Path of the state for the http backend's address, lock_address and unlock_address"`
+	LatestVersion int            `json:"latestVersion" doc:"Number of the current state version, 0 without state"`
+	Lock          *StateLockBody `json:"lock,omitempty" doc:"Lock held on the state"`
+}
+
+// CreatedStateWorkspaceBody includes the workspace's password, which is not
+// shown again.
+type CreatedStateWorkspaceBody struct {
+	StateWorkspaceBody
+	Username string `json:"username" doc:"Username for the http backend"`
+	Password string `json:"password" doc:"Password for the http backend, shown once"`
+}
+
+type StateLockBody struct {
+	ID        string         `json:"id"`
+	Info      map[string]any `json:"info" doc:"Lock information sent by Terraform"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+type StateVersionBody struct {
+	Version   int       `json:"version"`
+	Serial    int64     `json:"serial"`
+	Lineage   string    `json:"lineage"`
+	MD5       string    `json:"md5" doc:"Base64 MD5 of the state"`
+	Size      int       `json:"size" doc:"Size of the state in bytes"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type CreatedStateWorkspaceOutput struct {
+	Body *CreatedStateWorkspaceBody
+}
+
+type StateWorkspaceOutput struct {
+	Body *StateWorkspaceBody
+}
+
+type StateVersionOutput struct {
+	Body *StateVersionBody
+}
+
+type ListStateVersionsOutput struct {
+	Body []*StateVersionBody
+}
+
+// GetState returns the workspace's current state, or 204 without one.
+func (a *App) GetState(ctx context.Context, i *StateAuthInput) (*RawStateOutput, error) {
+	if err := a.authorizeState(ctx, i); err != nil {
+		return nil, err
+	}
+	v, err := a.states.LatestVersion(ctx, i.Workspace)
+	if errors.Is(err, tfstate.ErrNotFound) {
+		return &RawStateOutput{Status: http.StatusNoContent}, nil
+	}
+	if err != nil {
+		return nil, stateError(err)
+	}
+	return rawState(v), nil
+}
+
+// PutState stores a new state version. While the state is locked, only the
+// lock holder can write it.
+func (a *App) PutState(ctx context.Context, i *PutStateInput) (*struct{}, error) {
+	if err := a.authorizeState(ctx, &i.StateAuthInput); err != nil {
+		return nil, err
+	}
+	if i.ContentMD5 != "" && i.ContentMD5 != tfstate.Checksum(i.RawBody) {
+		return nil, huma.Error400BadRequest("state does not match its Content-MD5")
+	}
+	if err := a.checkStateLock(ctx, i.Workspace, i.LockID); err != nil {
+		return nil, err
+	}
+	v, err := tfstate.NewVersion(i.Workspace, i.RawBody, time.Now().UTC())
+	if err != nil {
+		return nil, stateError(err)
+	}
+	if err := a.states.AddVersion(ctx, v); err != nil {
+		return nil, stateError(err)
+	}
+	return nil, nil
+}
+
+// DeleteState deletes the workspace's state with its history. The
+// workspace and its password remain.
+func (a *App) DeleteState(ctx context.Context, i *StateAuthInput) (*struct{}, error) {
+	if err := a.authorizeState(ctx, i); err != nil {
+		return nil, err
+	}
+	if err := a.checkStateLock(ctx, i.Workspace, ""); err != nil {
+		return nil, err
+	}
+	if err := a.states.DeleteVersions(ctx, i.Workspace); err != nil {
+		return nil, stateError(err)
+	}
+	return nil, nil
+}
+
+// LockState locks the state for the lock information in the body. When it
+// is locked already, it responds 423 with the holder's lock information,
+// which Terraform shows to the user.
+func (a *App) LockState(ctx context.Context, i *LockStateInput) (*LockStateOutput, error) {
+	if err := a.authorizeState(ctx, &i.StateAuthInput); err != nil {
+		return nil, err
+	}
+	lock, err := tfstate.NewLock(i.Workspace, i.RawBody, time.Now().UTC())
+	if err != nil {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+	held, err := a.states.Lock(ctx, lock)
+	if errors.Is(err, tfstate.ErrLocked) {
+		return &LockStateOutput{Status: http.StatusLocked, ContentType: "application/json", Body: held.Info}, nil
+	}
+	if err != nil {
+		return nil, stateError(err)
+	}
+	return &LockStateOutput{Status: http.StatusOK}, nil
+}
+
+// UnlockState releases the lock described by the body. An empty body, as
+// sent by terraform force-unlock, releases any lock.
+func (a *App) UnlockState(ctx context.Context, i *LockStateInput) (*struct{}, error) {
+	if err := a.authorizeState(ctx, &i.StateAuthInput); err != nil {
+		return nil, err
+	}
+	var id string
+	if len(strings.TrimSpace(string(i.RawBody))) > 0 {
+		lock, err := tfstate.NewLock(i.Workspace, i.RawBody, time.Now().UTC())
+		if err != nil {
+			return nil, huma.Error400BadRequest(err.Error())
+		}
+		id = lock.ID
+	}
+	err := a.states.Unlock(ctx, i.Workspace, id)
+	if errors.Is(err, tfstate.ErrNotFound) {
+		// Already unlocked.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, stateError(err)
+	}
+	return nil, nil
+}
+
+// authorizeState checks the workspace's credentials. Unknown workspaces
+// fail like wrong passwords, so that names cannot be probed.
+func (a *App) authorizeState(ctx context.Context, i *StateAuthInput) error {
+	denied := huma.Error401Unauthorized("invalid workspace credentials")
+	user, password, ok := parseBasicAuth(i.Authorization)
+	if !ok || user != i.Workspace {
+		return denied
+	}
+	ws, err := a.states.GetWorkspace(ctx, i.Workspace)
+	if errors.Is(err, tfstate.ErrNotFound) {
+		return denied
+	}
+	if err != nil {
+		return stateError(err)
+	}
+	if !ws.CheckPassword(password) {
+		return denied
+	}
+	return nil
+}
+
+// checkStateLock fails unless the state is unlocked or, when lockID is set,
+// locked with that ID.
+func (a *App) checkStateLock(ctx context.Context, workspace, lockID string) error {
+	held, err := a.states.GetLock(ctx, workspace)
+	switch {
+	case errors.Is(err, tfstate.ErrNotFound):
+		if lockID != "" {
+			return huma.Error409Conflict(fmt.Sprintf("lock %s is not held", lockID))
+		}
+		return nil
+	case err != nil:
+		return stateError(err)
+	case held.ID != lockID:
+		return huma.NewError(http.StatusLocked, fmt.Sprintf("state is locked by lock %s", held.ID))
+	}
+	return nil
+}
+
+func parseBasicAuth(header string) (user, password string, ok bool) {
+	scheme, encoded, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Basic") {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(decoded), ":")
+}
+
+func (a *App) CreateStateWorkspace(ctx context.Context, i *CreateStateWorkspaceInput) (*CreatedStateWorkspaceOutput, error) {
+	ws, password, err := tfstate.NewWorkspace(i.Body.Name, time.Now().UTC())
+	if err != nil {
+		return nil, huma.Error422UnprocessableEntity("validation failed", &huma.ErrorDetail{
+			Location: "body.name",
+			Message:  err.Error(),
+			Value:    i.Body.Name,
+		})
+	}
+	if err := a.states.CreateWorkspace(ctx, ws); err != nil {
+		return nil, stateError(err)
+	}
+	return &CreatedStateWorkspaceOutput{Body: &CreatedStateWorkspaceBody{
+		StateWorkspaceBody: StateWorkspaceBody{Name: ws.Name, CreatedAt: ws.CreatedAt, Address: statePath + ws.Name},
+		Username:           ws.Name,
+		Password:           password,
+	}}, nil
+}
+
+func (a *App) GetStateWorkspace(ctx context.Context, i *StateWorkspaceInput) (*StateWorkspaceOutput, error) {
+	if err := a.authorizeState(ctx, &i.StateAuthInput); err != nil {
+		return nil, err
+	}
+	ws, err := a.states.GetWorkspace(ctx, i.Workspace)
+	if err != nil {
+		return nil, stateError(err)
+	}
+	body := &StateWorkspaceBody{Name: ws.Name, CreatedAt: ws.CreatedAt, Address: statePath + ws.Name}
+	switch v, err := a.states.LatestVersion(ctx, ws.Name); {
+	case err == nil:
+		body.LatestVersion = v.Version
+	case !errors.Is(err, tfstate.ErrNotFound):
+		return nil, stateError(err)
+	}
+	switch l, err := a.states.GetLock(ctx, ws.Name); {
+	case err == nil:
+		body.Lock = newStateLockBody(l)
+	case !errors.Is(err, tfstate.ErrNotFound):
+		return nil, stateError(err)
+	}
+	return &StateWorkspaceOutput{Body: body}, nil
+}
+
+// DeleteStateWorkspace deletes a workspace with all of its state. Locked
+// workspaces are in use and cannot be deleted.
+func (a *App) DeleteStateWorkspace(ctx context.Context, i *StateWorkspaceInput) (*struct{}, error) {
+	if err := a.authorizeState(ctx, &i.StateAuthInput); err != nil {
+		return nil, err
+	}
+	if err := a.checkStateLock(ctx, i.Workspace, ""); err != nil {
+		return nil, err
+	}
+	if err := a.states.DeleteWorkspace(ctx, i.Workspace); err != nil {
+		return nil, stateError(err)
+	}
+	return nil, nil
+}
+
+func (a *App) ListStateVersions(ctx context.Context, i *StateWorkspaceInput) (*ListStateVersionsOutput, error) {
+	if err := a.authorizeState(ctx, &i.StateAuthInput); err != nil {
+		return nil, err
+	}
+	versions, err := a.states.ListVersions(ctx, i.Workspace)
+	if err != nil {
+		return nil, stateError(err)
+	}
+	list := make([]*StateVersionBody, len(versions))
+	for n, v := range versions {
+		list[n] = newStateVersionBody(v)
+	}
+	return &ListStateVersionsOutput{Body: list}, nil
+}
+
+func (a *App) GetStateVersion(ctx context.Context, i *StateVersionInput) (*RawStateOutput, error) {
+	if err := a.authorizeState(ctx, &i.StateAuthInput); err != nil {
+		return nil, err
+	}
+	v, err := a.states.GetVersion(ctx, i.Workspace, i.Version)
+	if err != nil {
+		return nil, stateError(err)
+	}
+	return rawState(v), nil
+}
+
+// RestoreStateVersion makes an earlier state current again by adding it as
+// a new version, so that the restore itself can be undone.
+func (a *App) RestoreStateVersion(ctx context.Context, i *StateVersionInput) (*StateVersionOutput, error) {
+	if err := a.authorizeState(ctx, &i.StateAuthInput); err != nil {
+		return nil, err
+	}
+	old, err := a.states.GetVersion(ctx, i.Workspace, i.Version)
+	if err != nil {
+		return nil, stateError(err)
+	}
+	if err := a.checkStateLock(ctx, i.Workspace, ""); err != nil {
+		return nil, err
+	}
+	latest, err := a.states.LatestVersion(ctx, i.Workspace)
+	if err != nil {
+		return nil, stateError(err)
+	}
+	v, err := old.Restore(latest.Serial+1, time.Now().UTC())
+	if err != nil {
+		return nil, stateError(err)
+	}
+	if err := a.states.AddVersion(ctx, v); err != nil {
+		return nil, stateError(err)
+	}
+	return &StateVersionOutput{Body: newStateVersionBody(v)}, nil
+}
+
+func rawState(v *tfstate.Version) *RawStateOutput {
+	return &RawStateOutput{Status: http.StatusOK, ContentType: "application/json", ContentMD5: v.MD5, Body: v.Data}
+}
+
+func newStateVersionBody(v *tfstate.Version) *StateVersionBody {
+	return &StateVersionBody{
+		Version:   v.Version,
+		Serial:    v.Serial,
+		Lineage:   v.Lineage,
+		MD5:       v.MD5,
+		Size:      len(v.Data),
+		CreatedAt: v.CreatedAt,
+	}
+}
+
+func newStateLockBody(l *tfstate.Lock) *StateLockBody {
+	body := &StateLockBody{ID: l.ID, CreatedAt: l.CreatedAt}
+	// The information was checked to be a JSON object when locking.
+	_ = json.Unmarshal(l.Info, &body.Info)
+	return body
+}
+
+// stateError maps repository errors to problem responses.
+func stateError(err error) error {
+	switch {
+	case errors.Is(err, tfstate.ErrNotFound):
+		return huma.Error404NotFound("workspace or state version not found")
+	case errors.Is(err, tfstate.ErrAlreadyExists):
+		return huma.Error409Conflict("workspace already exists")
+	case errors.Is(err, tfstate.ErrLocked):
+		return huma.NewError(http.StatusLocked, "state is locked")
+	case errors.Is(err, tfstate.ErrInvalidState):
+		return huma.Error400BadRequest(err.Error())
+	default:
+		return huma.Error500InternalServerError("state storage failed", err)
+	}
+}
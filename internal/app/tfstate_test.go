@@ -0,0 +1,111 @@
+package app
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+func basicAuth(user, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+}
+
+func TestApp_StateAuthorization(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	app := NewApp()
+	ws, err := app.CreateStateWorkspace(ctx, &CreateStateWorkspaceInput{Body: StateWorkspaceInputBody{Name: "shop-web"}})
+	testutil.AssertNoError(t, err, "create workspace should succeed")
+	testutil.AssertEqual(t, ws.Body.Address, "/api/v1/terraform/state/shop-web", "the address should point at the state")
+
+	tests := []struct {
+		name          string
+		workspace     string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "valid", workspace: "shop-web", authorization: basicAuth("shop-web", ws.Body.Password), wantStatus: http.StatusNoContent},
+		{name: "no_credentials", workspace: "shop-web", wantStatus: http.StatusUnauthorized},
+		{name: "bearer", workspace: "shop-web", authorization: "Bearer " + ws.Body.Password, wantStatus: http.StatusUnauthorized},
+		{name: "wrong_password", workspace: "shop-web", authorization: basicAuth("shop-web", "guess"), wantStatus: http.StatusUnauthorized},
+		{name: "other_workspace", workspace: "shop-db", authorization: basicAuth("shop-db", ws.Body.Password), wantStatus: http.StatusUnauthorized},
+		{name: "mismatched_user", workspace: "shop-web", authorization: basicAuth("admin", ws.Body.Password), wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := app.GetState(ctx, &StateAuthInput{Workspace: tt.workspace, Authorization: tt.authorization})
+			if tt.wantStatus == http.StatusUnauthorized {
+				assertStatus(t, err, tt.wantStatus, "the request should be denied")
+				return
+			}
+			testutil.AssertNoError(t, err, "the request should be authorized")
+			testutil.AssertEqual(t, got.Status, tt.wantStatus, "a new workspace should have no state")
+		})
+	}
+}
+
+func TestApp_RestoreStateVersion(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	app := NewApp()
+	ws, err := app.CreateStateWorkspace(ctx, &CreateStateWorkspaceInput{Body: StateWorkspaceInputBody{Name: "shop-web"}})
+	testutil.AssertNoError(t, err, "create workspace should succeed")
+	auth := StateAuthInput{Workspace: "shop-web", Authorization: basicAuth("shop-web", ws.Body.Password)}
+
+	for _, state := range []string{`{"serial":1,"lineage":"a"}`, `{"serial":5,"lineage":"a"}`} {
+		_, err := app.PutState(ctx, &PutStateInput{StateAuthInput: auth, RawBody: []byte(state)})
+		testutil.AssertNoError(t, err, "put state should succeed")
+	}
+	_, err = app.PutState(ctx, &PutStateInput{StateAuthInput: auth, RawBody: []byte(`not json`)})
+	assertStatus(t, err, http.StatusBadRequest, "invalid state should be rejected")
+
+	_, err = app.LockState(ctx, &LockStateInput{StateAuthInput: auth, RawBody: []byte(`{"ID":"l1"}`)})
+	testutil.AssertNoError(t, err, "lock should succeed")
+	_, err = app.RestoreStateVersion(ctx, &StateVersionInput{StateAuthInput: auth, Version: 1})
+	assertStatus(t, err, http.StatusLocked, "locked state should not be restored")
+	_, err = app.UnlockState(ctx, &LockStateInput{StateAuthInput: auth, RawBody: []byte(`{"ID":"l2"}`)})
+	assertStatus(t, err, http.StatusLocked, "another lock's ID should not unlock")
+	_, err = app.UnlockState(ctx, &LockStateInput{StateAuthInput: auth, RawBody: []byte(`{"ID":"l1"}`)})
+	testutil.AssertNoError(t, err, "unlock should succeed")
+
+	_, err = app.RestoreStateVersion(ctx, &StateVersionInput{StateAuthInput: StateAuthInput{Workspace: "shop-web"}, Version: 1})
+	assertStatus(t, err, http.StatusUnauthorized, "restoring should need the workspace's credentials")
+	got, err := app.RestoreStateVersion(ctx, &StateVersionInput{StateAuthInput: auth, Version: 1})
+	testutil.AssertNoError(t, err, "restore should succeed")
+	testutil.AssertEqual(t, got.Body.Version, 3, "the restore should add a version")
+	testutil.AssertEqual(t, got.Body.Serial, int64(6), "the serial should follow the latest")
+
+	_, err = app.RestoreStateVersion(ctx, &StateVersionInput{StateAuthInput: auth, Version: 7})
+	assertStatus(t, err, http.StatusNotFound, "unknown versions should be not found")
+}
+
+func TestApp_StateWorkspaceAuthorization(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	app := NewApp()
+	ws, err := app.CreateStateWorkspace(ctx, &CreateStateWorkspaceInput{Body: StateWorkspaceInputBody{Name: "shop-web"}})
+	testutil.AssertNoError(t, err, "create workspace should succeed")
+	auth := StateAuthInput{Workspace: "shop-web", Authorization: basicAuth("shop-web", ws.Body.Password)}
+	_, err = app.PutState(ctx, &PutStateInput{StateAuthInput: auth, RawBody: []byte(`{"serial":1,"lineage":"a"}`)})
+	testutil.AssertNoError(t, err, "put state should succeed")
+
+	for _, denied := range []StateAuthInput{
+		{Workspace: "shop-web"},
+		{Workspace: "shop-web", Authorization: basicAuth("shop-web", "guess")},
+		{Workspace: "shop-db", Authorization: basicAuth("shop-db", ws.Body.Password)},
+	} {
+		_, err = app.GetStateWorkspace(ctx, &StateWorkspaceInput{StateAuthInput: denied})
+		assertStatus(t, err, http.StatusUnauthorized, "getting the workspace should need its credentials")
+		_, err = app.ListStateVersions(ctx, &StateWorkspaceInput{StateAuthInput: denied})
+		assertStatus(t, err, http.StatusUnauthorized, "listing versions should need the workspace's credentials")
+		_, err = app.GetStateVersion(ctx, &StateVersionInput{StateAuthInput: denied, Version: 1})
+		assertStatus(t, err, http.StatusUnauthorized, "reading a version should need the workspace's credentials")
+		_, err = app.DeleteStateWorkspace(ctx, &StateWorkspaceInput{StateAuthInput: denied})
+		assertStatus(t, err, http.StatusUnauthorized, "deleting the workspace should need its credentials")
+	}
+
+	versions, err := app.ListStateVersions(ctx, &StateWorkspaceInput{StateAuthInput: auth})
+	testutil.AssertNoError(t, err, "listing versions should succeed")
+	testutil.AssertEqual(t, len(versions.Body), 1, "the version should be listed")
+	_, err = app.DeleteStateWorkspace(ctx, &StateWorkspaceInput{StateAuthInput: auth})
+	testutil.AssertNoError(t, err, "deleting the workspace should succeed")
+}
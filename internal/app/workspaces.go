@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+// WorkspaceBody describes the Terraform workspace of a service on disk.
+type WorkspaceBody struct {
+	// Name is the service ID for the workspaces Mahler creates; other
+	// directories in the working directory are listed but never swept.
+	Name       string     `json:"name" doc:"Directory name, the ID of the service it belongs to"`
+	ServiceID  *uuid.UUID `json:"serviceId,omitempty" doc:"ID of the service, unless the name is not a service ID"`
+	Orphaned   bool       `json:"orphaned" doc:"Whether the service no longer exists; the next sweep removes the workspace"`
+	SizeBytes  int64      `json:"sizeBytes" doc:"Disk usage of the workspace's files, including installed providers and state"`
+	Files      int        `json:"files"`
+	ModifiedAt time.Time  `json:"modifiedAt" doc:"When a file in the workspace last changed"`
+}
+
+type ListWorkspacesOutput struct {
+	Body []*WorkspaceBody
+}
+
+// ListWorkspaces lists the services' Terraform workspaces with their disk
+// usage. It is empty when Terraform is not configured.
+func (a *App) ListWorkspaces(ctx context.Context, i *struct{}) (*ListWorkspacesOutput, error) {
+	if a.workspaces == nil {
+		return &ListWorkspacesOutput{Body: []*WorkspaceBody{}}, nil
+	}
+	infos, err := a.workspaces.List()
+	if err != nil {
+		return nil, huma.Error500InternalServerError("listing the workspaces failed", err)
+	}
+
+	bodies := make([]*WorkspaceBody, len(infos))
+	for i, info := range infos {
+		body := &WorkspaceBody{
+			Name:       info.Name,
+			SizeBytes:  info.Size,
+			Files:      info.Files,
+			ModifiedAt: info.ModifiedAt,
+		}
+		if id, err := uuid.Parse(info.Name); err == nil {
+			body.ServiceID = &id
+			if body.Orphaned, err = a.orphaned(ctx, id); err != nil {
+				return nil, serviceError(err)
+			}
+		}
+		bodies[i] = body
+	}
+	return &ListWorkspacesOutput{Body: bodies}, nil
+}
+
+// sweepWorkspaces removes the workspaces of services that no longer exist
+// and returns their names. Directories not named after a service are left
+// alone.
+func (a *App) sweepWorkspaces(ctx context.Context) ([]string, error) {
+	infos, err := a.workspaces.List()
+	if err != nil {
+		return nil, err
+	}
+	removed := []string{}
+	for _, info := range infos {
+		id, err := uuid.Parse(info.Name)
+		if err != nil {
+			continue
+		}
+		orphaned, err := a.orphaned(ctx, id)
+		if err != nil {
+			return removed, fmt.Errorf("sweep workspace %s: %w", info.Name, err)
+		}
+		if !orphaned {
+			continue
+		}
+		if err := a.workspaces.Remove(info.Name); err != nil {
+			return removed, err
+		}
+		removed = append(removed, info.Name)
+	}
+	return removed, nil
+}
+
+// orphaned reports whether service id no longer exists.
+func (a *App) orphaned(ctx context.Context, id uuid.UUID) (bool, error) {
+	_, err := a.services.Get(ctx, id)
+	if errors.Is(err, service.ErrNotFound) {
+		return true, nil
+	}
+	return false, err
+}
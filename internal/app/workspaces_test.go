@@ -0,0 +1,72 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+func TestApp_Workspaces(t *testing.T) {
+	ctx := testutil.NewTestContext(t)
+	exec := &fakeTerraform{}
+	exec.planChanges(resourceChange("create"))
+	app, projectID, workDir := newModuleTestApp(t, exec)
+
+	created, err := app.CreateService(ctx, &CreateServiceInput{ProjectID: projectID, Body: ServiceInputBody{Name: "cache", Resource: "cache"}})
+	testutil.AssertNoError(t, err, "create should succeed")
+	waitDone(t, app, created)
+	orphan := uuid.New().String()
+	for _, dir := range []string{orphan, "manual"} {
+		testutil.AssertNoError(t, os.MkdirAll(filepath.Join(workDir, dir), 0o700), "creating "+dir+" should succeed")
+	}
+
+	list, err := app.ListWorkspaces(ctx, &struct{}{})
+	testutil.AssertNoError(t, err, "list should succeed")
+	testutil.AssertEqual(t, len(list.Body), 3, "every workspace should be listed")
+	byName := make(map[string]*WorkspaceBody)
+	for _, w := range list.Body {
+		byName[w.Name] = w
+	}
+	live := byName[created.Body.ServiceID.String()]
+	testutil.AssertFalse(t, live.Orphaned, "the service's workspace should not be orphaned")
+	testutil.AssertTrue(t, live.SizeBytes > 0, "the service's workspace should use space")
+	testutil.AssertTrue(t, byName[orphan].Orphaned, "a workspace without service should be orphaned")
+	testutil.AssertTrue(t, byName["manual"].ServiceID == nil, "directories not named after a service should have no service")
+	testutil.AssertFalse(t, byName["manual"].Orphaned, "directories not named after a service should not be orphaned")
+
+	removed, err := app.sweepWorkspaces(ctx)
+	testutil.AssertNoError(t, err, "sweep should succeed")
+	testutil.AssertEqual(t, len(removed), 1, "only the orphan should be removed")
+	testutil.AssertEqual(t, removed[0], orphan, "the orphan should be removed")
+	list, err = app.ListWorkspaces(ctx, &struct{}{})
+	testutil.AssertNoError(t, err, "list should succeed")
+	testutil.AssertEqual(t, len(list.Body), 2, "the other workspaces should be kept")
+}
+
+func TestApp_WorkspaceSweep(t *testing.T) {
+	_, _, workDir := newModuleTestApp(t, &fakeTerraform{}, WithWorkspaceSweep(time.Millisecond))
+	orphan := filepath.Join(workDir, uuid.New().String())
+	testutil.AssertNoError(t, os.MkdirAll(orphan, 0o700), "creating the orphan should succeed")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(orphan); os.IsNotExist(err) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the sweep should remove the orphan")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestApp_WorkspacesWithoutTerraform(t *testing.T) {
+	app, _ := newServiceTestApp(t)
+	list, err := app.ListWorkspaces(testutil.NewTestContext(t), &struct{}{})
+	testutil.AssertNoError(t, err, "list should succeed")
+	testutil.AssertEqual(t, len(list.Body), 0, "there should be no workspaces")
+}
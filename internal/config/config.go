@@ -0,0 +1,352 @@
+// Package config holds the server configuration.
+//
+// Settings are layered: built-in defaults, then an optional YAML file, then
+// MAHLER_* environment variables. Command-line flags are applied last by the
+// caller. Every setting has an environment variable derived from its YAML
+// path, e.g. server.shutdown_timeout is MAHLER_SERVER_SHUTDOWN_TIMEOUT.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix prefixes all environment variables read by ApplyEnv.
+const EnvPrefix = "MAHLER"
+
+// redacted replaces secret values in Redacted.
+const redacted = "REDACTED"
+
+// Config is the complete server configuration.
+type Config struct {
+	Server       ServerConfig       `yaml:"server"`
+	Storage      StorageConfig      `yaml:"storage"`
+	Logging      LoggingConfig      `yaml:"logging"`
+	Auth         AuthConfig         `yaml:"auth"`
+	Jobs         JobsConfig         `yaml:"jobs"`
+	Drift        DriftConfig        `yaml:"drift"`
+	Integrations IntegrationsConfig `yaml:"integrations"`
+	// Resources selects the resource types offered to services. All
+	// registered types are offered at their latest version when empty.
+	Resources []ResourceConfig `yaml:"resources"`
+}
+
+// ServerConfig configures the HTTP server.
+type ServerConfig struct {
+	Host            string        `yaml:"host"`
+	Port            int           `yaml:"port"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+// Addr returns the address to listen on, e.g. "127.0.0.1:8080". An empty host
+// listens on all interfaces.
+func (s ServerConfig) Addr() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// LocalURL returns the URL the server is reached at from this machine, e.g.
+// "http://127.0.0.1:8080". Hosts listening on all interfaces are reached
+// over loopback.
+func (s ServerConfig) LocalURL() string {
+	host := s.Host
+	switch host {
+	case "", "0.0.0.0":
+		host = "127.0.0.1"
+	case "::":
+		host = "::1"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(s.Port))
+}
+
+// Storage drivers.
+const (
+	StorageMemory = "memory"
+	StorageSQLite = "sqlite"
+)
+
+// StorageConfig selects where state is kept.
+type StorageConfig struct {
+	// Driver is StorageMemory or StorageSQLite.
+	Driver string `yaml:"driver"`
+	// Path is the SQLite database file.
+	Path string `yaml:"path"`
+}
+
+// LoggingConfig configures the default slog logger.
+type LoggingConfig struct {
+	// Level is one of debug, info, warn or error.
+	Level string `yaml:"level"`
+	// Format is text or json.
+	Format string `yaml:"format"`
+}
+
+// AuthConfig configures API authentication.
+type AuthConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// AdminToken is the bearer token granting full access.
+	AdminToken string `yaml:"admin_token" secret:"true"`
+}
+
+// JobsConfig configures the background job queue.
+type JobsConfig struct {
+	// Workers is the number of jobs run concurrently.
+	Workers int `yaml:"workers"`
+	// Timeout bounds a single attempt of a job.
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxAttempts is how often a failing job is tried before it is
+	// dead-lettered.
+	MaxAttempts int `yaml:"max_attempts"`
+	// Backoff is the delay before the first retry; it doubles with every
+	// further attempt up to MaxBackoff.
+	Backoff    time.Duration `yaml:"backoff"`
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+	// DrainTimeout is how long shutdown waits for running jobs before
+	// cancelling them. Cancelled jobs run again after a restart.
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
+}
+
+// DriftConfig configures drift detection, which compares the
+// infrastructure of ready services with their configuration.
+type DriftConfig struct {
+	// Interval is how often every service is checked; 0 disables drift
+	// detection.
+	Interval time.Duration `yaml:"interval"`
+}
+
+// IntegrationsConfig configures the external systems Mahler talks to.
+type IntegrationsConfig struct {
+	Kubernetes KubernetesConfig `yaml:"kubernetes"`
+	Prometheus PrometheusConfig `yaml:"prometheus"`
+	Terraform  TerraformConfig  `yaml:"terraform"`
+}
+
+// KubernetesConfig configures the Kubernetes objects rendered for services.
+type KubernetesConfig struct {
+	// Namespace is the namespace the objects are rendered into.
+	Namespace string `yaml:"namespace"`
+}
+
+// PrometheusConfig points at the Prometheus server queried for metrics.
+type PrometheusConfig struct {
+	// URL is the Prometheus base URL; metrics are disabled when empty.
+	URL         string        `yaml:"url"`
+	BearerToken string        `yaml:"bearer_token" secret:"true"`
+	Timeout     time.Duration `yaml:"timeout"`
+}
+
+// TerraformConfig configures how Terraform is run.
+type TerraformConfig struct {
+	// Binary is the terraform or tofu executable, looked up in PATH.
+	Binary string `yaml:"binary"`
+	// WorkDir holds the per-service working directories.
+	WorkDir string `yaml:"work_dir"`
+	// SweepInterval is how often working directories of services that no
+	// longer exist are removed; 0 disables the sweep.
+	SweepInterval time.Duration `yaml:"sweep_interval"`
+	// Modules are offered as resource types. They can only be set in the
+	// file.
+	Modules []TerraformModuleConfig `yaml:"modules"`
+}
+
+// TerraformModuleConfig registers a Terraform module as a resource type.
+type TerraformModuleConfig struct {
+	// Type is the resource type key of the module.
+	Type string `yaml:"type"`
+	// Source is the directory of the module, e.g. a git checkout.
+	Source string `yaml:"source"`
+}
+
+// ResourceConfig offers one resource type. It can only be set in the file.
+type ResourceConfig struct {
+	Type string `yaml:"type"`
+	// Version is the type version, 0 for the latest.
+	Version int `yaml:"version,omitempty"`
+	// Settings are passed to the resource type's factory.
+	Settings map[string]any `yaml:"settings,omitempty"`
+}
+
+// Default returns the built-in configuration.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            8080,
+			ShutdownTimeout: 5 * time.Second,
+		},
+		Storage: StorageConfig{
+			Driver: StorageMemory,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Jobs: JobsConfig{
+			Workers:      4,
+			Timeout:      30 * time.Minute,
+			MaxAttempts:  5,
+			Backoff:      5 * time.Second,
+			MaxBackoff:   5 * time.Minute,
+			DrainTimeout: 30 * time.Second,
+		},
+		Drift: DriftConfig{
+			Interval: time.Hour,
+		},
+		Integrations: IntegrationsConfig{
+			Kubernetes: KubernetesConfig{
+				Namespace: "default",
+			},
+			Prometheus: PrometheusConfig{
+				Timeout: 10 * time.Second,
+			},
+			Terraform: TerraformConfig{
+				Binary:        "terraform",
+				WorkDir:       "workspaces",
+				SweepInterval: time.Hour,
+				Modules:       []TerraformModuleConfig{},
+			},
+		},
+		Resources: []ResourceConfig{},
+	}
+}
+
+// Load returns the defaults overlaid with the YAML file at path, if path is
+// not empty, and then with the environment. The result is not validated so
+// that the caller can apply flags first.
+func Load(path string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("config file: %w", err)
+		}
+		defer f.Close()
+		if err := cfg.ApplyYAML(f); err != nil {
+			return nil, fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+	if err := cfg.ApplyEnv(lookupEnv); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ApplyYAML overlays the settings present in r. Unknown keys are an error so
+// that typos do not go unnoticed.
+func (c *Config) ApplyYAML(r io.Reader) error {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// ApplyEnv overlays every setting whose environment variable is set.
+func (c *Config) ApplyEnv(lookupEnv func(string) (string, bool)) error {
+	var errs ValidationError
+	walk(reflect.ValueOf(c).Elem(), "", func(path string, v reflect.Value, _ reflect.StructField) {
+		name := EnvName(path)
+		raw, ok := lookupEnv(name)
+		if !ok {
+			return
+		}
+		if err := setFromString(v, raw); err != nil {
+			errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf("invalid value %q from %s: %v", raw, name, err)})
+		}
+	})
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// EnvName returns the environment variable for a dotted YAML path.
+func EnvName(path string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// Redacted returns a copy of c with all secret settings replaced.
+func (c *Config) Redacted() *Config {
+	cp := *c
+	walk(reflect.ValueOf(&cp).Elem(), "", func(_ string, v reflect.Value, field reflect.StructField) {
+		if field.Tag.Get("secret") == "true" && v.Kind() == reflect.String && v.String() != "" {
+			v.SetString(redacted)
+		}
+	})
+	return &cp
+}
+
+// YAML renders c with secrets redacted.
+func (c *Config) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Redacted()); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// walk calls fn for every leaf setting with its dotted YAML path. Lists are
+// skipped as they cannot be set from the environment.
+func walk(v reflect.Value, prefix string, fn func(path string, v reflect.Value, field reflect.StructField)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			walk(v.Field(i), path, fn)
+			continue
+		case reflect.Slice:
+			continue
+		}
+		fn(path, v.Field(i), field)
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setFromString(v reflect.Value, raw string) error {
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+// env returns a lookup function backed by vars.
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mahler.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+	return path
+}
+
+func TestDefault_IsValid(t *testing.T) {
+	testutil.AssertNoError(t, Default().Validate(), "defaults should be valid")
+}
+
+func TestLoad_Layers(t *testing.T) {
+	path := writeFile(t, `
+server:
+  host: 127.0.0.1
+  port: 9000
+logging:
+  format: json
+`)
+
+	cfg, err := Load(path, env(map[string]string{
+		"MAHLER_SERVER_PORT":                 "9100",
+		"MAHLER_SERVER_SHUTDOWN_TIMEOUT":     "30s",
+		"MAHLER_AUTH_ENABLED":                "true",
+		"MAHLER_INTEGRATIONS_PROMETHEUS_URL": "http://prometheus:9090",
+	}))
+	testutil.AssertNoError(t, err, "load should succeed")
+
+	testutil.AssertEqual(t, cfg.Server.Host, "127.0.0.1", "file should override defaults")
+	testutil.AssertEqual(t, cfg.Logging.Format, "json", "file should override defaults")
+	testutil.AssertEqual(t, cfg.Logging.Level, "info", "unset settings should keep their default")
+	testutil.AssertEqual(t, cfg.Server.Port, 9100, "environment should override the file")
+	testutil.AssertEqual(t, cfg.Server.ShutdownTimeout, 30*time.Second, "durations should be parsed")
+	testutil.AssertTrue(t, cfg.Auth.Enabled, "booleans should be parsed")
+	testutil.AssertEqual(t, cfg.Integrations.Prometheus.URL, "http://prometheus:9090", "nested settings should be read")
+}
+
+func TestLoad_WithoutFile(t *testing.T) {
+	cfg, err := Load("", env(nil))
+	testutil.AssertNoError(t, err, "load without a file should succeed")
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("Load() = %+v, want defaults", cfg)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name:    "unknown_key",
+			file:    "server:\n  prot: 9000\n",
+			wantErr: "field prot not found",
+		},
+		{
+			name:    "malformed_yaml",
+			file:    "server: [",
+			wantErr: "yaml",
+		},
+		{
+			name:    "bad_int_env",
+			env:     map[string]string{"MAHLER_SERVER_PORT": "http"},
+			wantErr: "server.port: invalid value \"http\" from MAHLER_SERVER_PORT",
+		},
+		{
+			name:    "bad_duration_env",
+			env:     map[string]string{"MAHLER_INTEGRATIONS_PROMETHEUS_TIMEOUT": "10"},
+			wantErr: "integrations.prometheus.timeout",
+		},
+		{
+			name:    "bad_bool_env",
+			env:     map[string]string{"MAHLER_AUTH_ENABLED": "maybe"},
+			wantErr: "auth.enabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := ""
+			if tt.file != "" {
+				path = writeFile(t, tt.file)
+			}
+			_, err := Load(path, env(tt.env))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"), env(nil))
+	testutil.AssertTrue(t, errors.Is(err, os.ErrNotExist), "missing file should be reported")
+}
+
+func TestLoad_EmptyFile(t *testing.T) {
+	cfg, err := Load(writeFile(t, ""), env(nil))
+	testutil.AssertNoError(t, err, "empty file should be accepted")
+	testutil.AssertEqual(t, cfg.Server.Port, 8080, "empty file should keep the defaults")
+}
+
+func TestEnvName(t *testing.T) {
+	testutil.AssertEqual(t, EnvName("server.port"), "MAHLER_SERVER_PORT", "env name should be derived from the path")
+	testutil.AssertEqual(t, EnvName("integrations.terraform.work_dir"), "MAHLER_INTEGRATIONS_TERRAFORM_WORK_DIR", "nested paths should be joined")
+}
+
+func TestServerConfig_Addr(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "", want: ":8080"},
+		{host: "127.0.0.1", want: "127.0.0.1:8080"},
+		{host: "localhost", want: "localhost:8080"},
+		{host: "::1", want: "[::1]:8080"},
+	}
+
+	for _, tt := range tests {
+		got := ServerConfig{Host: tt.host, Port: 8080}.Addr()
+		testutil.AssertEqual(t, got, tt.want, "address should include the host")
+	}
+}
+
+func TestServerConfig_LocalURL(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "", want: "http://127.0.0.1:8080"},
+		{host: "0.0.0.0", want: "http://127.0.0.1:8080"},
+		{host: "::", want: "http://[::1]:8080"},
+		{host: "localhost", want: "http://localhost:8080"},
+		{host: "10.0.0.5", want: "http://10.0.0.5:8080"},
+	}
+
+	for _, tt := range tests {
+		got := ServerConfig{Host: tt.host, Port: 8080}.LocalURL()
+		testutil.AssertEqual(t, got, tt.want, "all interfaces should be reached over loopback")
+	}
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := Default()
+	cfg.Auth.AdminToken = "super-secret-admin-token"
+	cfg.Integrations.Prometheus.BearerToken = "prom-token"
+
+	red := cfg.Redacted()
+	testutil.AssertEqual(t, red.Auth.AdminToken, "REDACTED", "admin token should be redacted")
+	testutil.AssertEqual(t, red.Integrations.Prometheus.BearerToken, "REDACTED", "bearer token should be redacted")
+	testutil.AssertEqual(t, red.Server.Port, cfg.Server.Port, "other settings should be kept")
+	testutil.AssertEqual(t, cfg.Auth.AdminToken, "super-secret-admin-token", "original should not be modified")
+
+	b, err := cfg.YAML()
+	testutil.AssertNoError(t, err, "rendering should succeed")
+	testutil.AssertFalse(t, strings.Contains(string(b), "super-secret"), "YAML should not contain secrets")
+	testutil.AssertTrue(t, strings.Contains(string(b), "shutdown_timeout: 5s"), "durations should be rendered readably")
+}
+
+func TestConfig_YAMLRoundTrip(t *testing.T) {
+	cfg := Default()
+	cfg.Server.Port = 9000
+	b, err := cfg.YAML()
+	testutil.AssertNoError(t, err, "rendering should succeed")
+
+	got, err := Load(writeFile(t, string(b)), env(nil))
+	testutil.AssertNoError(t, err, "printed config should load")
+	if !reflect.DeepEqual(got, cfg) {
+		t.Errorf("loaded %+v, want %+v", got, cfg)
+	}
+}
+
+func TestLoad_Resources(t *testing.T) {
+	path := writeFile(t, `
+resources:
+  - type: k8s-pod
+    version: 1
+    settings:
+      price_per_hour: 0.25
+`)
+
+	cfg, err := Load(path, env(map[string]string{"MAHLER_RESOURCES": "ignored"}))
+	testutil.AssertNoError(t, err, "load should succeed")
+	testutil.AssertEqual(t, len(cfg.Resources), 1, "one resource should be configured")
+	testutil.AssertEqual(t, cfg.Resources[0].Type, "k8s-pod", "type should be read")
+	testutil.AssertEqual(t, cfg.Resources[0].Version, 1, "version should be read")
+	testutil.AssertEqual(t, cfg.Resources[0].Settings["price_per_hour"], any(0.25), "settings should be read")
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// MinAdminTokenLength is the shortest admin token accepted.
+const MinAdminTokenLength = 16
+
+var hostnamePattern = regexp.MustCompile(`^[A-Za-z0-9]([-.A-Za-z0-9]*[A-Za-z0-9])?$`)
+
+var namespacePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+var resourceTypePattern = regexp.MustCompile(`^[a-z][-a-z0-9]*$`)
+
+// FieldError describes one invalid setting.
+type FieldError struct {
+	// Field is the dotted YAML path, e.g. "server.port".
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError lists every invalid setting.
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return "invalid configuration:\n  " + strings.Join(msgs, "\n  ")
+}
+
+// Validate checks every setting and returns a ValidationError listing all
+// problems, or nil.
+func (c *Config) Validate() error {
+	var errs ValidationError
+	add := func(field, format string, args ...any) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		add("server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	if h := c.Server.Host; h != "" && net.ParseIP(h) == nil && !hostnamePattern.MatchString(h) {
+		add("server.host", "must be a hostname or IP address, got %q", c.Server.Host)
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		add("server.shutdown_timeout", "must be positive, got %s", c.Server.ShutdownTimeout)
+	}
+
+	switch c.Storage.Driver {
+	case StorageMemory:
+		if c.Storage.Path != "" {
+			add("storage.path", "is only used by the %s driver", StorageSQLite)
+		}
+	case StorageSQLite:
+		if c.Storage.Path == "" {
+			add("storage.path", "is required for the %s driver", StorageSQLite)
+		}
+	default:
+		add("storage.driver", "must be %s or %s, got %q", StorageMemory, StorageSQLite, c.Storage.Driver)
+	}
+
+	switch c.Logging.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		add("logging.level", "must be debug, info, warn or error, got %q", c.Logging.Level)
+	}
+	switch c.Logging.Format {
+	case "text", "json":
+	default:
+		add("logging.format", "must be text or json, got %q", c.Logging.Format)
+	}
+
+	if c.Auth.Enabled && len(c.Auth.AdminToken) < MinAdminTokenLength {
+		add("auth.admin_token", "must be at least %d characters when auth is enabled", MinAdminTokenLength)
+	}
+
+	jobs := c.Jobs
+	if jobs.Workers < 1 {
+		add("jobs.workers", "must be at least 1, got %d", jobs.Workers)
+	}
+	if jobs.Timeout <= 0 {
+		add("jobs.timeout", "must be positive, got %s", jobs.Timeout)
+	}
+	if jobs.MaxAttempts < 1 {
+		add("jobs.max_attempts", "must be at least 1, got %d", jobs.MaxAttempts)
+	}
+	if jobs.Backoff <= 0 {
+		add("jobs.backoff", "must be positive, got %s", jobs.Backoff)
+	}
+	if jobs.MaxBackoff < jobs.Backoff {
+		add("jobs.max_backoff", "must not be less than jobs.backoff, got %s", jobs.MaxBackoff)
+	}
+	if jobs.DrainTimeout <= 0 {
+		add("jobs.drain_timeout", "must be positive, got %s", jobs.DrainTimeout)
+	}
+
+	if c.Drift.Interval < 0 {
+		add("drift.interval", "must not be negative, got %s", c.Drift.Interval)
+	}
+
+	k8s := c.Integrations.Kubernetes
+	if !namespacePattern.MatchString(k8s.Namespace) || len(k8s.Namespace) > 63 {
+		add("integrations.kubernetes.namespace", "must be a valid Kubernetes namespace name, got %q", k8s.Namespace)
+	}
+
+	prom := c.Integrations.Prometheus
+	if prom.URL != "" {
+		u, err := url.Parse(prom.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("integrations.prometheus.url", "must be an absolute http or https URL, got %q", prom.URL)
+		}
+	}
+	if prom.Timeout <= 0 {
+		add("integrations.prometheus.timeout", "must be positive, got %s", prom.Timeout)
+	}
+
+	tf := c.Integrations.Terraform
+	if tf.Binary == "" {
+		add("integrations.terraform.binary", "is required")
+	}
+	if tf.WorkDir == "" {
+		add("integrations.terraform.work_dir", "is required")
+	}
+	if tf.SweepInterval < 0 {
+		add("integrations.terraform.sweep_interval", "must not be negative, got %s", tf.SweepInterval)
+	}
+	modules := make(map[string]bool)
+	for i, m := range tf.Modules {
+		field := fmt.Sprintf("integrations.terraform.modules[%d]", i)
+		switch {
+		case !resourceTypePattern.MatchString(m.Type):
+			add(field+".type", "must be lowercase letters, digits and dashes, got %q", m.Type)
+		case modules[m.Type]:
+			add(field+".type", "module type %q is listed more than once", m.Type)
+		}
+		modules[m.Type] = true
+		if m.Source == "" {
+			add(field+".source", "is required")
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, res := range c.Resources {
+		field := fmt.Sprintf("resources[%d]", i)
+		switch {
+		case res.Type == "":
+			add(field+".type", "is required")
+		case seen[res.Type]:
+			add(field+".type", "resource type %q is listed more than once", res.Type)
+		}
+		seen[res.Type] = true
+		if res.Version < 0 {
+			add(field+".version", "must not be negative, got %d", res.Version)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
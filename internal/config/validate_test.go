@@ -0,0 +1,119 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		modify    func(c *Config)
+		wantField string
+	}{
+		{name: "defaults", modify: func(c *Config) {}},
+		{name: "sqlite_with_path", modify: func(c *Config) { c.Storage = StorageConfig{Driver: StorageSQLite, Path: "mahler.db"} }},
+		{name: "ipv6_host", modify: func(c *Config) { c.Server.Host = "::1" }},
+		{name: "auth_with_token", modify: func(c *Config) { c.Auth = AuthConfig{Enabled: true, AdminToken: "0123456789abcdef"} }},
+		{name: "port_zero", modify: func(c *Config) { c.Server.Port = 0 }, wantField: "server.port"},
+		{name: "port_too_high", modify: func(c *Config) { c.Server.Port = 70000 }, wantField: "server.port"},
+		{name: "host_with_port", modify: func(c *Config) { c.Server.Host = "localhost:80" }, wantField: "server.host"},
+		{name: "no_shutdown_timeout", modify: func(c *Config) { c.Server.ShutdownTimeout = 0 }, wantField: "server.shutdown_timeout"},
+		{name: "unknown_driver", modify: func(c *Config) { c.Storage.Driver = "postgres" }, wantField: "storage.driver"},
+		{name: "sqlite_without_path", modify: func(c *Config) { c.Storage.Driver = StorageSQLite }, wantField: "storage.path"},
+		{name: "memory_with_path", modify: func(c *Config) { c.Storage.Path = "mahler.db" }, wantField: "storage.path"},
+		{name: "unknown_level", modify: func(c *Config) { c.Logging.Level = "verbose" }, wantField: "logging.level"},
+		{name: "unknown_format", modify: func(c *Config) { c.Logging.Format = "xml" }, wantField: "logging.format"},
+		{name: "auth_without_token", modify: func(c *Config) { c.Auth.Enabled = true }, wantField: "auth.admin_token"},
+		{name: "auth_short_token", modify: func(c *Config) { c.Auth = AuthConfig{Enabled: true, AdminToken: "short"} }, wantField: "auth.admin_token"},
+		{name: "no_job_workers", modify: func(c *Config) { c.Jobs.Workers = 0 }, wantField: "jobs.workers"},
+		{name: "no_job_timeout", modify: func(c *Config) { c.Jobs.Timeout = 0 }, wantField: "jobs.timeout"},
+		{name: "no_job_attempts", modify: func(c *Config) { c.Jobs.MaxAttempts = 0 }, wantField: "jobs.max_attempts"},
+		{name: "no_job_backoff", modify: func(c *Config) { c.Jobs.Backoff = 0; c.Jobs.MaxBackoff = 0 }, wantField: "jobs.backoff"},
+		{name: "max_backoff_below_backoff", modify: func(c *Config) { c.Jobs.MaxBackoff = time.Second }, wantField: "jobs.max_backoff"},
+		{name: "no_drain_timeout", modify: func(c *Config) { c.Jobs.DrainTimeout = 0 }, wantField: "jobs.drain_timeout"},
+		{name: "bad_namespace", modify: func(c *Config) { c.Integrations.Kubernetes.Namespace = "Prod" }, wantField: "integrations.kubernetes.namespace"},
+		{name: "relative_prometheus_url", modify: func(c *Config) { c.Integrations.Prometheus.URL = "prometheus:9090" }, wantField: "integrations.prometheus.url"},
+		{name: "no_prometheus_timeout", modify: func(c *Config) { c.Integrations.Prometheus.Timeout = -time.Second }, wantField: "integrations.prometheus.timeout"},
+		{name: "no_terraform_binary", modify: func(c *Config) { c.Integrations.Terraform.Binary = "" }, wantField: "integrations.terraform.binary"},
+		{name: "no_work_dir", modify: func(c *Config) { c.Integrations.Terraform.WorkDir = "" }, wantField: "integrations.terraform.work_dir"},
+		{name: "negative_drift_interval", modify: func(c *Config) { c.Drift.Interval = -time.Minute }, wantField: "drift.interval"},
+		{name: "negative_sweep_interval", modify: func(c *Config) { c.Integrations.Terraform.SweepInterval = -time.Minute }, wantField: "integrations.terraform.sweep_interval"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantField == "" {
+				testutil.AssertNoError(t, err, "config should be valid")
+				return
+			}
+
+			var verr ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() error = %v, want ValidationError", err)
+			}
+			testutil.AssertEqual(t, len(verr), 1, "exactly one field should be invalid")
+			testutil.AssertEqual(t, verr[0].Field, tt.wantField, "invalid field should be reported")
+		})
+	}
+}
+
+func TestConfig_ValidateReportsAllFields(t *testing.T) {
+	cfg := Default()
+	cfg.Server.Port = -1
+	cfg.Logging.Level = "loud"
+
+	err := cfg.Validate()
+	testutil.AssertError(t, err, "invalid config should fail")
+	msg := err.Error()
+	testutil.AssertTrue(t, strings.Contains(msg, "server.port: must be between 1 and 65535, got -1"), "port error should be listed")
+	testutil.AssertTrue(t, strings.Contains(msg, `logging.level: must be debug, info, warn or error, got "loud"`), "level error should be listed")
+}
+
+func TestConfig_ValidateResources(t *testing.T) {
+	cfg := Default()
+	cfg.Resources = []ResourceConfig{
+		{Type: "k8s-pod", Settings: map[string]any{"price_per_hour": 0.5}},
+		{Type: "k8s-pod", Version: 2},
+		{Version: -1},
+	}
+
+	var verr ValidationError
+	if !errors.As(cfg.Validate(), &verr) {
+		t.Fatalf("Validate() should return a ValidationError")
+	}
+	fields := make([]string, len(verr))
+	for i, fe := range verr {
+		fields[i] = fe.Field
+	}
+	testutil.AssertEqual(t, strings.Join(fields, ","), "resources[1].type,resources[2].type,resources[2].version", "each invalid entry should be reported")
+}
+
+func TestConfig_ValidateTerraformModules(t *testing.T) {
+	cfg := Default()
+	cfg.Integrations.Terraform.Modules = []TerraformModuleConfig{
+		{Type: "postgres", Source: "modules/postgres"},
+		{Type: "postgres", Source: "modules/postgres-ha"},
+		{Type: "Redis"},
+	}
+
+	var verr ValidationError
+	if !errors.As(cfg.Validate(), &verr) {
+		t.Fatalf("Validate() should return a ValidationError")
+	}
+	fields := make([]string, len(verr))
+	for i, fe := range verr {
+		fields[i] = fe.Field
+	}
+	testutil.AssertEqual(t, strings.Join(fields, ","),
+		"integrations.terraform.modules[1].type,integrations.terraform.modules[2].type,integrations.terraform.modules[2].source",
+		"each invalid module should be reported")
+}
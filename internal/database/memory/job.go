@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/Bermos/Platform/internal/jobs"
+	"github.com/google/uuid"
+)
+
+// JobRepository is an in-memory job repository. Jobs do not survive a
+// restart.
+type JobRepository struct {
+	mu   sync.RWMutex
+	jobs map[uuid.UUID]jobs.Job
+}
+
+// NewJobRepository creates an empty in-memory job repository.
+func NewJobRepository() *JobRepository {
+	return &JobRepository{jobs: make(map[uuid.UUID]jobs.Job)}
+}
+
+// Create stores a copy of job.
+func (r *JobRepository) Create(ctx context.Context, job *jobs.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.jobs[job.ID]; exists {
+		return jobs.ErrAlreadyExists
+	}
+	r.jobs[job.ID] = cloneJob(*job)
+	return nil
+}
+
+// Get returns a copy of the job with the given ID.
+func (r *JobRepository) Get(ctx context.Context, id uuid.UUID) (*jobs.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, exists := r.jobs[id]
+	if !exists {
+		return nil, jobs.ErrNotFound
+	}
+	job = cloneJob(job)
+	return &job, nil
+}
+
+// Update replaces the stored job with a copy of job.
+func (r *JobRepository) Update(ctx context.Context, job *jobs.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.jobs[job.ID]; !exists {
+		return jobs.ErrNotFound
+	}
+	r.jobs[job.ID] = cloneJob(*job)
+	return nil
+}
+
+// List returns copies of the jobs in any of the given states, or of all
+// jobs, ordered by creation time.
+func (r *JobRepository) List(ctx context.Context, states ...jobs.State) ([]*jobs.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]*jobs.Job, 0)
+	for _, job := range r.jobs {
+		if len(states) > 0 && !slices.Contains(states, job.State) {
+			continue
+		}
+		job := cloneJob(job)
+		list = append(list, &job)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return bytes.Compare(list[i].ID[:], list[j].ID[:]) < 0
+	})
+	return list, nil
+}
+
+// cloneJob returns a copy of job that shares no payload with it.
+func cloneJob(job jobs.Job) jobs.Job {
+	job.Payload = bytes.Clone(job.Payload)
+	return job
+}
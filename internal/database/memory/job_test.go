@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/Bermos/Platform/internal/jobs"
+	"github.com/Bermos/Platform/internal/jobs/jobstest"
+)
+
+func TestJobRepository_Conformance(t *testing.T) {
+	jobstest.RunRepositoryTests(t, func(t *testing.T) jobs.Repository {
+		return NewJobRepository()
+	})
+}
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/Bermos/Platform/internal/operation"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/google/uuid"
+)
+
+// OperationRepository is an in-memory operation repository.
+type OperationRepository struct {
+	mu         sync.RWMutex
+	operations map[uuid.UUID]operation.Operation
+}
+
+// NewOperationRepository creates an empty in-memory operation repository.
+func NewOperationRepository() *OperationRepository {
+	return &OperationRepository{operations: make(map[uuid.UUID]operation.Operation)}
+}
+
+// Create stores a copy of op.
+func (r *OperationRepository) Create(ctx context.Context, op *operation.Operation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.operations[op.ID]; exists {
+		return operation.ErrAlreadyExists
+	}
+	r.operations[op.ID] = cloneOperation(*op)
+	return nil
+}
+
+// Get returns a copy of the operation with the given ID.
+func (r *OperationRepository) Get(ctx context.Context, id uuid.UUID) (*operation.Operation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	op, exists := r.operations[id]
+	if !exists {
+		return nil, operation.ErrNotFound
+	}
+	op = cloneOperation(op)
+	return &op, nil
+}
+
+// Update replaces the stored operation with a copy of op.
+func (r *OperationRepository) Update(ctx context.Context, op *operation.Operation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.operations[op.ID]; !exists {
+		return operation.ErrNotFound
+	}
+	r.operations[op.ID] = cloneOperation(*op)
+	return nil
+}
+
+// UpdateFrom replaces the stored operation with a copy of op if it is still
+// in state from.
+func (r *OperationRepository) UpdateFrom(ctx context.Context, op *operation.Operation, from operation.State) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.operations[op.ID]
+	if !exists {
+		return operation.ErrNotFound
+	}
+	if stored.State != from {
+		return operation.ErrStateConflict
+	}
+	r.operations[op.ID] = cloneOperation(*op)
+	return nil
+}
+
+// ListByService returns copies of the operations on one service, oldest
+// first.
+func (r *OperationRepository) ListByService(ctx context.Context, serviceID uuid.UUID) ([]*operation.Operation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ops := make([]*operation.Operation, 0)
+	for _, op := range r.operations {
+		if op.ServiceID != serviceID {
+			continue
+		}
+		op := cloneOperation(op)
+		ops = append(ops, &op)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].CreatedAt.Before(ops[j].CreatedAt)
+	})
+	return ops, nil
+}
+
+// cloneOperation returns a copy of op that shares no slices or maps with it.
+func cloneOperation(op operation.Operation) operation.Operation {
+	op.Steps = slices.Clone(op.Steps)
+	op.Logs = slices.Clone(op.Logs)
+	op.Result = service.CloneConfig(op.Result)
+	op.Plan = op.Plan.Clone()
+	return op
+}
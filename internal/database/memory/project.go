@@ -0,0 +1,109 @@
+// Package memory provides thread-safe in-memory repositories. They are used
+// for tests and local development when no durable storage is configured.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Bermos/Platform/internal/project"
+	"github.com/google/uuid"
+)
+
+// ProjectRepository is an in-memory project repository.
+type ProjectRepository struct {
+	mu       sync.RWMutex
+	projects map[uuid.UUID]project.Project
+}
+
+// NewProjectRepository creates an empty in-memory project repository.
+func NewProjectRepository() *ProjectRepository {
+	return &ProjectRepository{
+		projects: make(map[uuid.UUID]project.Project),
+	}
+}
+
+// Create stores a copy of proj. It fails with project.ErrAlreadyExists when
+// the ID or name is already taken.
+func (r *ProjectRepository) Create(ctx context.Context, proj *project.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.projects[proj.ID]; exists {
+		return project.ErrAlreadyExists
+	}
+	if r.nameTaken(proj.Name, proj.ID) {
+		return project.ErrAlreadyExists
+	}
+
+	r.projects[proj.ID] = *proj
+	return nil
+}
+
+// Get returns a copy of the project with the given ID.
+func (r *ProjectRepository) Get(ctx context.Context, id uuid.UUID) (*project.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	proj, exists := r.projects[id]
+	if !exists {
+		return nil, project.ErrNotFound
+	}
+	return &proj, nil
+}
+
+// Update replaces the stored project with a copy of proj.
+func (r *ProjectRepository) Update(ctx context.Context, proj *project.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.projects[proj.ID]; !exists {
+		return project.ErrNotFound
+	}
+	if r.nameTaken(proj.Name, proj.ID) {
+		return project.ErrAlreadyExists
+	}
+
+	r.projects[proj.ID] = *proj
+	return nil
+}
+
+// Delete removes the project with the given ID.
+func (r *ProjectRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.projects[id]; !exists {
+		return project.ErrNotFound
+	}
+	delete(r.projects, id)
+	return nil
+}
+
+// List returns copies of all projects ordered by name.
+func (r *ProjectRepository) List(ctx context.Context) ([]*project.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	projects := make([]*project.Project, 0, len(r.projects))
+	for _, proj := range r.projects {
+		proj := proj
+		projects = append(projects, &proj)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Name < projects[j].Name
+	})
+	return projects, nil
+}
+
+// nameTaken reports whether a project other than id already uses name.
+// The caller must hold r.mu.
+func (r *ProjectRepository) nameTaken(name string, id uuid.UUID) bool {
+	for otherID, other := range r.projects {
+		if otherID != id && other.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/project"
+	"github.com/Bermos/Platform/internal/project/projecttest"
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+func TestProjectRepository_Conformance(t *testing.T) {
+	projecttest.RunRepositoryTests(t, func(t *testing.T) project.Repository {
+		return NewProjectRepository()
+	})
+}
+
+func TestProjectRepository_StoresCopies(t *testing.T) {
+	repo := NewProjectRepository()
+	ctx := context.Background()
+	proj := testutil.NewTestProject()
+
+	testutil.AssertNoError(t, repo.Create(ctx, proj), "create should succeed")
+	proj.Name = "mutated"
+
+	got, err := repo.Get(ctx, proj.ID)
+	testutil.AssertNoError(t, err, "get should succeed")
+	testutil.AssertEqual(t, got.Name, "test-project", "stored project should not change with caller's copy")
+
+	got.Name = "mutated-again"
+	again, err := repo.Get(ctx, proj.ID)
+	testutil.AssertNoError(t, err, "get should succeed")
+	testutil.AssertEqual(t, again.Name, "test-project", "stored project should not change with returned copy")
+}
@@ -0,0 +1,206 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/google/uuid"
+)
+
+// ServiceRepository is an in-memory service repository.
+type ServiceRepository struct {
+	mu       sync.RWMutex
+	services map[uuid.UUID]service.Service
+	history  map[uuid.UUID][]service.Transition
+}
+
+// NewServiceRepository creates an empty in-memory service repository.
+func NewServiceRepository() *ServiceRepository {
+	return &ServiceRepository{
+		services: make(map[uuid.UUID]service.Service),
+		history:  make(map[uuid.UUID][]service.Transition),
+	}
+}
+
+// Create stores a copy of svc. It fails with service.ErrAlreadyExists when
+// the ID is taken or the project already has a service with the same name.
+func (r *ServiceRepository) Create(ctx context.Context, svc *service.Service) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.services[svc.ID]; exists {
+		return service.ErrAlreadyExists
+	}
+	if r.nameTaken(svc) {
+		return service.ErrAlreadyExists
+	}
+	if err := r.checkDependencies(svc); err != nil {
+		return err
+	}
+
+	r.services[svc.ID] = cloneService(*svc)
+	return nil
+}
+
+// Get returns a copy of the service with the given ID.
+func (r *ServiceRepository) Get(ctx context.Context, id uuid.UUID) (*service.Service, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	svc, exists := r.services[id]
+	if !exists {
+		return nil, service.ErrNotFound
+	}
+	svc = cloneService(svc)
+	return &svc, nil
+}
+
+// Update replaces the stored service with a copy of svc, keeping its
+// status.
+func (r *ServiceRepository) Update(ctx context.Context, svc *service.Service) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.services[svc.ID]
+	if !exists {
+		return service.ErrNotFound
+	}
+	if r.nameTaken(svc) {
+		return service.ErrAlreadyExists
+	}
+	if err := r.checkDependencies(svc); err != nil {
+		return err
+	}
+
+	updated := cloneService(*svc)
+	updated.Status = stored.Status
+	r.services[svc.ID] = updated
+	return nil
+}
+
+// UpdateDrift replaces the drift of the stored service with a copy of
+// drift.
+func (r *ServiceRepository) UpdateDrift(ctx context.Context, id uuid.UUID, drift *service.Drift) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.services[id]
+	if !exists {
+		return service.ErrNotFound
+	}
+	stored.Drift = drift.Clone()
+	r.services[id] = stored
+	return nil
+}
+
+// Delete removes the service with the given ID. It fails with
+// service.ErrHasDependents while other services depend on it.
+func (r *ServiceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.services[id]; !exists {
+		return service.ErrNotFound
+	}
+	for _, other := range r.services {
+		if slices.Contains(other.DependsOn, id) {
+			return service.ErrHasDependents
+		}
+	}
+	delete(r.services, id)
+	delete(r.history, id)
+	return nil
+}
+
+// ListByProject returns copies of the services of one project ordered by name.
+func (r *ServiceRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*service.Service, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	services := make([]*service.Service, 0)
+	for _, svc := range r.services {
+		if svc.ProjectID != projectID {
+			continue
+		}
+		svc := cloneService(svc)
+		services = append(services, &svc)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+	return services, nil
+}
+
+// Transition changes the status of a service and records t.
+func (r *ServiceRepository) Transition(ctx context.Context, t *service.Transition) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	svc, exists := r.services[t.ServiceID]
+	if !exists {
+		return service.ErrNotFound
+	}
+	if svc.Status != t.From {
+		return service.ErrStatusConflict
+	}
+	svc.Status = t.To
+	r.services[t.ServiceID] = svc
+	r.history[t.ServiceID] = append(r.history[t.ServiceID], *t)
+	return nil
+}
+
+// History returns copies of the transitions of a service, oldest first.
+func (r *ServiceRepository) History(ctx context.Context, id uuid.UUID) ([]*service.Transition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, exists := r.services[id]; !exists {
+		return nil, service.ErrNotFound
+	}
+	history := make([]*service.Transition, 0, len(r.history[id]))
+	for _, t := range r.history[id] {
+		history = append(history, &t)
+	}
+	return history, nil
+}
+
+// nameTaken reports whether another service in the same project already uses
+// the name of svc. The caller must hold r.mu.
+func (r *ServiceRepository) nameTaken(svc *service.Service) bool {
+	for id, other := range r.services {
+		if id != svc.ID && other.ProjectID == svc.ProjectID && other.Name == svc.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDependencies reports service.ErrInvalidDependency unless svc
+// depends on distinct other services of its project. The caller must hold
+// r.mu.
+func (r *ServiceRepository) checkDependencies(svc *service.Service) error {
+	seen := make(map[uuid.UUID]bool, len(svc.DependsOn))
+	for _, id := range svc.DependsOn {
+		dep, exists := r.services[id]
+		if !exists || id == svc.ID || dep.ProjectID != svc.ProjectID || seen[id] {
+			return service.ErrInvalidDependency
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// cloneService returns a copy of svc that shares no maps with it.
+func cloneService(svc service.Service) service.Service {
+	svc.Config = service.CloneConfig(svc.Config)
+	svc.Drift = svc.Drift.Clone()
+	svc.Outputs = service.CloneOutputs(svc.Outputs)
+	svc.DependsOn = slices.Clone(svc.DependsOn)
+	return svc
+}
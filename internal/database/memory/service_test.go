@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/Bermos/Platform/internal/service/servicetest"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+func TestServiceRepository_Conformance(t *testing.T) {
+	servicetest.RunRepositoryTests(t,
+		func(t *testing.T) service.Repository {
+			return NewServiceRepository()
+		},
+		func(t *testing.T, repo service.Repository) uuid.UUID {
+			return uuid.New()
+		},
+	)
+}
+
+func TestServiceRepository_StoresCopies(t *testing.T) {
+	repo := NewServiceRepository()
+	ctx := context.Background()
+	svc := testutil.NewServiceBuilder().WithProjectID(uuid.New()).WithName("api").Build()
+	svc.Config = map[string]any{"limits": map[string]any{"cpu": "1"}}
+
+	testutil.AssertNoError(t, repo.Create(ctx, svc), "create should succeed")
+	svc.Name = "mutated"
+	svc.Config["limits"].(map[string]any)["cpu"] = "2"
+
+	got, err := repo.Get(ctx, svc.ID)
+	testutil.AssertNoError(t, err, "get should succeed")
+	testutil.AssertEqual(t, got.Name, "api", "stored service should not change with caller's copy")
+	testutil.AssertEqual(t, got.Config["limits"].(map[string]any)["cpu"], any("1"), "stored config should not change with caller's copy")
+
+	got.Config["limits"].(map[string]any)["cpu"] = "3"
+	again, err := repo.Get(ctx, svc.ID)
+	testutil.AssertNoError(t, err, "get should succeed")
+	testutil.AssertEqual(t, again.Config["limits"].(map[string]any)["cpu"], any("1"), "stored config should not change with returned copies")
+}
@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/Bermos/Platform/internal/tfstate"
+)
+
+// StateRepository is an in-memory Terraform state repository. State does
+// not survive a restart.
+type StateRepository struct {
+	mu         sync.RWMutex
+	workspaces map[string]tfstate.Workspace
+	versions   map[string][]tfstate.Version
+	locks      map[string]tfstate.Lock
+}
+
+// NewStateRepository creates an empty in-memory state repository.
+func NewStateRepository() *StateRepository {
+	return &StateRepository{
+		workspaces: make(map[string]tfstate.Workspace),
+		versions:   make(map[string][]tfstate.Version),
+		locks:      make(map[string]tfstate.Lock),
+	}
+}
+
+// CreateWorkspace stores a copy of w.
+func (r *StateRepository) CreateWorkspace(ctx context.Context, w *tfstate.Workspace) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.workspaces[w.Name]; exists {
+		return tfstate.ErrAlreadyExists
+	}
+	ws := *w
+	ws.PasswordHash = bytes.Clone(w.PasswordHash)
+	r.workspaces[w.Name] = ws
+	return nil
+}
+
+// GetWorkspace returns a copy of the named workspace.
+func (r *StateRepository) GetWorkspace(ctx context.Context, name string) (*tfstate.Workspace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ws, exists := r.workspaces[name]
+	if !exists {
+		return nil, tfstate.ErrNotFound
+	}
+	ws.PasswordHash = bytes.Clone(ws.PasswordHash)
+	return &ws, nil
+}
+
+// UpdateWorkspace stores a copy of w's password hash.
+func (r *StateRepository) UpdateWorkspace(ctx context.Context, w *tfstate.Workspace) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ws, exists := r.workspaces[w.Name]
+	if !exists {
+		return tfstate.ErrNotFound
+	}
+	ws.PasswordHash = bytes.Clone(w.PasswordHash)
+	r.workspaces[w.Name] = ws
+	return nil
+}
+
+func (r *StateRepository) DeleteWorkspace(ctx context.Context, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.workspaces[name]; !exists {
+		return tfstate.ErrNotFound
+	}
+	delete(r.workspaces, name)
+	delete(r.versions, name)
+	delete(r.locks, name)
+	return nil
+}
+
+// AddVersion numbers v after the workspace's newest version and stores a
+// copy of it.
+func (r *StateRepository) AddVersion(ctx context.Context, v *tfstate.Version) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.workspaces[v.Workspace]; !exists {
+		return tfstate.ErrNotFound
+	}
+	versions := r.versions[v.Workspace]
+	v.Version = 1
+	if len(versions) > 0 {
+		v.Version = versions[len(versions)-1].Version + 1
+	}
+	r.versions[v.Workspace] = append(versions, cloneVersion(*v))
+	return nil
+}
+
+func (r *StateRepository) LatestVersion(ctx context.Context, workspace string) (*tfstate.Version, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions := r.versions[workspace]
+	if len(versions) == 0 {
+		return nil, tfstate.ErrNotFound
+	}
+	v := cloneVersion(versions[len(versions)-1])
+	return &v, nil
+}
+
+func (r *StateRepository) GetVersion(ctx context.Context, workspace string, version int) (*tfstate.Version, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, v := range r.versions[workspace] {
+		if v.Version == version {
+			v = cloneVersion(v)
+			return &v, nil
+		}
+	}
+	return nil, tfstate.ErrNotFound
+}
+
+func (r *StateRepository) ListVersions(ctx context.Context, workspace string) ([]*tfstate.Version, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]*tfstate.Version, 0, len(r.versions[workspace]))
+	for _, v := range r.versions[workspace] {
+		v = cloneVersion(v)
+		list = append(list, &v)
+	}
+	return list, nil
+}
+
+func (r *StateRepository) DeleteVersions(ctx context.Context, workspace string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.versions, workspace)
+	return nil
+}
+
+func (r *StateRepository) Lock(ctx context.Context, l *tfstate.Lock) (*tfstate.Lock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.workspaces[l.Workspace]; !exists {
+		return nil, tfstate.ErrNotFound
+	}
+	if held, locked := r.locks[l.Workspace]; locked {
+		held.Info = slices.Clone(held.Info)
+		return &held, tfstate.ErrLocked
+	}
+	lock := *l
+	lock.Info = slices.Clone(l.Info)
+	r.locks[l.Workspace] = lock
+	return nil, nil
+}
+
+func (r *StateRepository) GetLock(ctx context.Context, workspace string) (*tfstate.Lock, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	held, locked := r.locks[workspace]
+	if !locked {
+		return nil, tfstate.ErrNotFound
+	}
+	held.Info = slices.Clone(held.Info)
+	return &held, nil
+}
+
+func (r *StateRepository) Unlock(ctx context.Context, workspace, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	held, locked := r.locks[workspace]
+	switch {
+	case !locked:
+		return tfstate.ErrNotFound
+	case id != "" && held.ID != id:
+		return tfstate.ErrLocked
+	}
+	delete(r.locks, workspace)
+	return nil
+}
+
+func cloneVersion(v tfstate.Version) tfstate.Version {
+	v.Data = bytes.Clone(v.Data)
+	return v
+}
@@ -0,0 +1 @@
+ALTER TABLE services ADD COLUMN config TEXT NOT NULL DEFAULT '{}';
@@ -0,0 +1,3 @@
+DROP TABLE service_transitions;
+
+ALTER TABLE services DROP COLUMN status;
@@ -0,0 +1,13 @@
+ALTER TABLE services ADD COLUMN status TEXT NOT NULL DEFAULT 'pending';
+
+CREATE TABLE service_transitions (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    service_id  TEXT NOT NULL REFERENCES services (id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL,
+    to_status   TEXT NOT NULL,
+    actor       TEXT NOT NULL,
+    reason      TEXT NOT NULL DEFAULT '',
+    at          TEXT NOT NULL
+);
+
+CREATE INDEX service_transitions_service_id ON service_transitions (service_id, id);
@@ -0,0 +1,15 @@
+CREATE TABLE jobs (
+    id           TEXT PRIMARY KEY,
+    kind         TEXT NOT NULL,
+    payload      BLOB,
+    state        TEXT NOT NULL,
+    attempts     INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    timeout_ns   INTEGER NOT NULL,
+    last_error   TEXT NOT NULL DEFAULT '',
+    run_at       TEXT NOT NULL,
+    created_at   TEXT NOT NULL,
+    updated_at   TEXT NOT NULL
+);
+
+CREATE INDEX jobs_state ON jobs (state, created_at);
@@ -0,0 +1,19 @@
+-- Operations outlive the services they change, so service_id is not a
+-- foreign key.
+CREATE TABLE operations (
+    id          TEXT PRIMARY KEY,
+    kind        TEXT NOT NULL,
+    project_id  TEXT NOT NULL,
+    service_id  TEXT NOT NULL,
+    job_id      TEXT NOT NULL,
+    state       TEXT NOT NULL,
+    steps       TEXT NOT NULL DEFAULT '[]',
+    logs        TEXT NOT NULL DEFAULT '[]',
+    result      TEXT,
+    error       TEXT NOT NULL DEFAULT '',
+    created_at  TEXT NOT NULL,
+    updated_at  TEXT NOT NULL,
+    finished_at TEXT
+);
+
+CREATE INDEX operations_service_id ON operations (service_id, created_at);
@@ -0,0 +1,3 @@
+DROP TABLE terraform_locks;
+DROP TABLE terraform_state_versions;
+DROP TABLE terraform_workspaces;
@@ -0,0 +1,23 @@
+CREATE TABLE terraform_workspaces (
+    name          TEXT PRIMARY KEY,
+    password_hash BLOB NOT NULL,
+    created_at    TEXT NOT NULL
+);
+
+CREATE TABLE terraform_state_versions (
+    workspace  TEXT NOT NULL REFERENCES terraform_workspaces (name) ON DELETE CASCADE,
+    version    INTEGER NOT NULL,
+    serial     INTEGER NOT NULL,
+    lineage    TEXT NOT NULL,
+    md5        TEXT NOT NULL,
+    data       BLOB NOT NULL,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (workspace, version)
+);
+
+CREATE TABLE terraform_locks (
+    workspace  TEXT PRIMARY KEY REFERENCES terraform_workspaces (name) ON DELETE CASCADE,
+    id         TEXT NOT NULL,
+    info       BLOB NOT NULL,
+    created_at TEXT NOT NULL
+);
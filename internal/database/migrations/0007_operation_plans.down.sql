@@ -0,0 +1,2 @@
+ALTER TABLE operations DROP COLUMN approved_at;
+ALTER TABLE operations DROP COLUMN plan;
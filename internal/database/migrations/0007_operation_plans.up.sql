@@ -0,0 +1,2 @@
+ALTER TABLE operations ADD COLUMN plan TEXT;
+ALTER TABLE operations ADD COLUMN approved_at TEXT;
@@ -0,0 +1,8 @@
+CREATE TABLE service_dependencies (
+    service_id    TEXT NOT NULL REFERENCES services (id) ON DELETE CASCADE,
+    depends_on_id TEXT NOT NULL REFERENCES services (id),
+    position      INTEGER NOT NULL,
+    PRIMARY KEY (service_id, depends_on_id)
+);
+
+CREATE INDEX service_dependencies_depends_on ON service_dependencies (depends_on_id);
@@ -0,0 +1,342 @@
+// Package migrations manages the versioned database schema.
+//
+// Migrations are pairs of SQL files named NNNN_description.up.sql and
+// NNNN_description.down.sql, embedded in the binary. Applied migrations are
+// recorded in the schema_migrations table together with a checksum of their
+// up script, so a binary can tell whether the database matches the schema it
+// was built for.
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed *.sql
+var embedded embed.FS
+
+var (
+	// ErrSchemaBehind is returned by Check when migrations are pending.
+	ErrSchemaBehind = errors.New("database schema is behind")
+	// ErrSchemaAhead is returned by Check when the database has migrations
+	// this binary does not know about, usually because a newer release ran.
+	ErrSchemaAhead = errors.New("database schema is ahead of this binary")
+	// ErrChecksumMismatch is returned when an applied migration differs from
+	// the embedded one.
+	ErrChecksumMismatch = errors.New("migration checksum mismatch")
+)
+
+var fileRegexp = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Status describes whether a migration has been applied.
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	// Modified is set when the applied checksum differs from the embedded one.
+	Modified bool
+	// Unknown is set for applied migrations the binary has no file for.
+	Unknown bool
+}
+
+// Embedded returns the migrations compiled into the binary.
+func Embedded() []Migration {
+	ms, err := Load(embedded)
+	if err != nil {
+		// The embedded files are fixed at build time; the tests catch this.
+		panic(err)
+	}
+	return ms
+}
+
+// Load reads migrations from the root of fsys, ordered by version. Every
+// version needs both an up and a down script.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileRegexp.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: name must look like 0001_description.up.sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		if version == 0 {
+			return nil, fmt.Errorf("migration %s: version must be positive", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d: conflicting names %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	ms := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s: both up and down scripts are required", m.Version, m.Name)
+		}
+		sum := sha256.Sum256([]byte(m.Up))
+		m.Checksum = hex.EncodeToString(sum[:])
+		ms = append(ms, *m)
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Version < ms[j].Version })
+	return ms, nil
+}
+
+// Migrator applies and rolls back migrations on a database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New creates a Migrator for db. migrations must be ordered by version, as
+// returned by Load.
+func New(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+type appliedMigration struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// Up applies all pending migrations in order and returns the ones applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.verifyChecksums(applied); err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		err := m.inTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx,
+				`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`,
+				mig.Version, mig.Name, mig.Checksum, time.Now().UTC().Format(time.RFC3339Nano))
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("apply migration %04d_%s: %w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// Down rolls back the n most recently applied migrations, newest first, and
+// returns the ones rolled back.
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("number of migrations to roll back must be positive, got %d", n)
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]int, 0, len(applied))
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+	if n > len(versions) {
+		return nil, fmt.Errorf("cannot roll back %d migrations, only %d applied", n, len(versions))
+	}
+
+	var done []Migration
+	for _, version := range versions[:n] {
+		mig, ok := m.find(version)
+		if !ok {
+			return done, fmt.Errorf("migration %d: %w", version, ErrSchemaAhead)
+		}
+		if applied[version].checksum != mig.Checksum {
+			return done, fmt.Errorf("migration %04d_%s: %w", mig.Version, mig.Name, ErrChecksumMismatch)
+		}
+		err := m.inTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, mig.Version)
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("roll back migration %04d_%s: %w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// Status lists every known migration and every applied migration unknown to
+// this binary, ordered by version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name}
+		if a, ok := applied[mig.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = a.appliedAt
+			s.Modified = a.checksum != mig.Checksum
+			delete(applied, mig.Version)
+		}
+		statuses = append(statuses, s)
+	}
+	for version, a := range applied {
+		statuses = append(statuses, Status{
+			Version:   version,
+			Name:      a.name,
+			Applied:   true,
+			AppliedAt: a.appliedAt,
+			Unknown:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Check returns nil when the database schema matches the known migrations
+// exactly, and an error wrapping ErrSchemaBehind, ErrSchemaAhead or
+// ErrChecksumMismatch otherwise.
+func (m *Migrator) Check(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, s := range statuses {
+		switch {
+		case s.Unknown:
+			return fmt.Errorf("migration %04d_%s: %w", s.Version, s.Name, ErrSchemaAhead)
+		case s.Modified:
+			return fmt.Errorf("migration %04d_%s: %w", s.Version, s.Name, ErrChecksumMismatch)
+		case !s.Applied:
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w: %d pending migration(s)", ErrSchemaBehind, pending)
+	}
+	return nil
+}
+
+// applied creates the bookkeeping table if needed and returns the recorded
+// migrations by version.
+func (m *Migrator) applied(ctx context.Context) (map[int]appliedMigration, error) {
+	_, err := m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		checksum   TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	rows, err := m.db.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedMigration)
+	for rows.Next() {
+		var (
+			version   int
+			a         appliedMigration
+			appliedAt string
+		)
+		if err := rows.Scan(&version, &a.name, &a.checksum, &appliedAt); err != nil {
+			return nil, fmt.Errorf("read schema_migrations: %w", err)
+		}
+		if a.appliedAt, err = time.Parse(time.RFC3339Nano, appliedAt); err != nil {
+			return nil, fmt.Errorf("migration %d: invalid applied_at %q: %w", version, appliedAt, err)
+		}
+		applied[version] = a
+	}
+	return applied, rows.Err()
+}
+
+func (m *Migrator) verifyChecksums(applied map[int]appliedMigration) error {
+	for _, mig := range m.migrations {
+		if a, ok := applied[mig.Version]; ok && a.checksum != mig.Checksum {
+			return fmt.Errorf("migration %04d_%s: %w", mig.Version, mig.Name, ErrChecksumMismatch)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) find(version int) (Migration, bool) {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return mig, true
+		}
+	}
+	return Migration{}, false
+}
+
+func (m *Migrator) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,240 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Bermos/Platform/internal/database/sqlite"
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sqlite.Open(context.Background(), filepath.Join(t.TempDir(), "platform.db"))
+	if err != nil {
+		t.Fatalf("Open: unexpected error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// testMigrations returns two small migrations creating tables a and b.
+func testMigrations(t *testing.T) []Migration {
+	t.Helper()
+	ms, err := Load(fstest.MapFS{
+		"0001_create_a.up.sql":   {Data: []byte("CREATE TABLE a (id INTEGER);")},
+		"0001_create_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"0002_create_b.up.sql":   {Data: []byte("CREATE TABLE b (id INTEGER);")},
+		"0002_create_b.down.sql": {Data: []byte("DROP TABLE b;")},
+	})
+	if err != nil {
+		t.Fatalf("Load: unexpected error: %v", err)
+	}
+	return ms
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&count)
+	if err != nil {
+		t.Fatalf("query sqlite_master: %v", err)
+	}
+	return count == 1
+}
+
+func TestEmbedded(t *testing.T) {
+	ms := Embedded()
+
+	testutil.AssertTrue(t, len(ms) > 0, "there should be embedded migrations")
+	for i, m := range ms {
+		testutil.AssertEqual(t, m.Version, i+1, "embedded versions should be contiguous")
+		testutil.AssertEqual(t, len(m.Checksum), 64, "checksum should be a hex SHA-256")
+	}
+}
+
+func TestEmbedded_UpAndDown(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := New(db, Embedded())
+
+	applied, err := m.Up(ctx)
+	testutil.AssertNoError(t, err, "up should succeed")
+	testutil.AssertEqual(t, len(applied), len(Embedded()), "all migrations should be applied")
+	testutil.AssertNoError(t, m.Check(ctx), "schema should be current after up")
+
+	_, err = m.Down(ctx, len(applied))
+	testutil.AssertNoError(t, err, "rolling everything back should succeed")
+	testutil.AssertFalse(t, tableExists(t, db, "projects"), "projects table should be dropped")
+	testutil.AssertFalse(t, tableExists(t, db, "services"), "services table should be dropped")
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		wantErr string
+	}{
+		{
+			name: "orders_by_version",
+			files: fstest.MapFS{
+				"0010_later.up.sql":     {Data: []byte("SELECT 1;")},
+				"0010_later.down.sql":   {Data: []byte("SELECT 1;")},
+				"0002_earlier.up.sql":   {Data: []byte("SELECT 1;")},
+				"0002_earlier.down.sql": {Data: []byte("SELECT 1;")},
+			},
+		},
+		{
+			name: "missing_down",
+			files: fstest.MapFS{
+				"0001_init.up.sql": {Data: []byte("SELECT 1;")},
+			},
+			wantErr: "both up and down",
+		},
+		{
+			name: "bad_file_name",
+			files: fstest.MapFS{
+				"init.sql": {Data: []byte("SELECT 1;")},
+			},
+			wantErr: "name must look like",
+		},
+		{
+			name: "zero_version",
+			files: fstest.MapFS{
+				"0000_init.up.sql":   {Data: []byte("SELECT 1;")},
+				"0000_init.down.sql": {Data: []byte("SELECT 1;")},
+			},
+			wantErr: "positive",
+		},
+		{
+			name: "conflicting_names",
+			files: fstest.MapFS{
+				"0001_one.up.sql":   {Data: []byte("SELECT 1;")},
+				"0001_two.down.sql": {Data: []byte("SELECT 1;")},
+			},
+			wantErr: "conflicting names",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, err := Load(tt.files)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Load() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			testutil.AssertNoError(t, err, "load should succeed")
+			testutil.AssertEqual(t, ms[0].Version, 2, "lowest version should come first")
+			testutil.AssertEqual(t, ms[1].Version, 10, "highest version should come last")
+		})
+	}
+}
+
+func TestMigrator_UpIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := New(db, testMigrations(t))
+
+	applied, err := m.Up(ctx)
+	testutil.AssertNoError(t, err, "first up should succeed")
+	testutil.AssertEqual(t, len(applied), 2, "both migrations should be applied")
+	testutil.AssertTrue(t, tableExists(t, db, "a"), "table a should exist")
+	testutil.AssertTrue(t, tableExists(t, db, "b"), "table b should exist")
+
+	applied, err = m.Up(ctx)
+	testutil.AssertNoError(t, err, "second up should succeed")
+	testutil.AssertEqual(t, len(applied), 0, "nothing should be applied twice")
+}
+
+func TestMigrator_Down(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := New(db, testMigrations(t))
+	_, err := m.Up(ctx)
+	testutil.AssertNoError(t, err, "up should succeed")
+
+	rolledBack, err := m.Down(ctx, 1)
+	testutil.AssertNoError(t, err, "down 1 should succeed")
+	testutil.AssertEqual(t, len(rolledBack), 1, "one migration should be rolled back")
+	testutil.AssertEqual(t, rolledBack[0].Version, 2, "the newest migration should be rolled back first")
+	testutil.AssertTrue(t, tableExists(t, db, "a"), "table a should still exist")
+	testutil.AssertFalse(t, tableExists(t, db, "b"), "table b should be dropped")
+
+	_, err = m.Down(ctx, 5)
+	testutil.AssertError(t, err, "rolling back more than applied should fail")
+
+	_, err = m.Down(ctx, 0)
+	testutil.AssertError(t, err, "rolling back zero migrations should fail")
+}
+
+func TestMigrator_StatusAndCheck(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	ms := testMigrations(t)
+
+	older := New(db, ms[:1])
+	_, err := older.Up(ctx)
+	testutil.AssertNoError(t, err, "up with first migration should succeed")
+	testutil.AssertNoError(t, older.Check(ctx), "schema should match the older binary")
+
+	newer := New(db, ms)
+	err = newer.Check(ctx)
+	testutil.AssertTrue(t, errors.Is(err, ErrSchemaBehind), "newer binary should see a schema that is behind")
+
+	statuses, err := newer.Status(ctx)
+	testutil.AssertNoError(t, err, "status should succeed")
+	testutil.AssertEqual(t, len(statuses), 2, "status should list both migrations")
+	testutil.AssertTrue(t, statuses[0].Applied, "first migration should be applied")
+	testutil.AssertFalse(t, statuses[1].Applied, "second migration should be pending")
+
+	_, err = newer.Up(ctx)
+	testutil.AssertNoError(t, err, "up should succeed")
+	err = older.Check(ctx)
+	testutil.AssertTrue(t, errors.Is(err, ErrSchemaAhead), "older binary should see a schema that is ahead")
+
+	statuses, err = older.Status(ctx)
+	testutil.AssertNoError(t, err, "status should succeed")
+	testutil.AssertTrue(t, statuses[1].Unknown, "second migration should be unknown to the older binary")
+}
+
+func TestMigrator_ChecksumMismatch(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	ms := testMigrations(t)
+	_, err := New(db, ms).Up(ctx)
+	testutil.AssertNoError(t, err, "up should succeed")
+
+	edited := append([]Migration(nil), ms...)
+	edited[0].Up = "CREATE TABLE a (id INTEGER, name TEXT);"
+	edited[0].Checksum = "edited"
+	m := New(db, edited)
+
+	testutil.AssertTrue(t, errors.Is(m.Check(ctx), ErrChecksumMismatch), "check should detect edited migrations")
+	_, err = m.Up(ctx)
+	testutil.AssertTrue(t, errors.Is(err, ErrChecksumMismatch), "up should refuse to run over edited migrations")
+
+	statuses, err := m.Status(ctx)
+	testutil.AssertNoError(t, err, "status should succeed")
+	testutil.AssertTrue(t, statuses[0].Modified, "edited migration should be flagged")
+}
+
+func TestMigrator_FailedMigrationIsRolledBack(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	ms, err := Load(fstest.MapFS{
+		"0001_broken.up.sql":   {Data: []byte("CREATE TABLE c (id INTEGER); THIS IS NOT SQL;")},
+		"0001_broken.down.sql": {Data: []byte("DROP TABLE c;")},
+	})
+	testutil.AssertNoError(t, err, "load should succeed")
+
+	_, err = New(db, ms).Up(ctx)
+	testutil.AssertError(t, err, "broken migration should fail")
+	testutil.AssertFalse(t, tableExists(t, db, "c"), "partial changes should be rolled back")
+}
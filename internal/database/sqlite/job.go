@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Bermos/Platform/internal/jobs"
+	"github.com/google/uuid"
+)
+
+// JobRepository stores jobs in the jobs table.
+type JobRepository struct {
+	db *sql.DB
+}
+
+// NewJobRepository creates a job repository on an opened database.
+func NewJobRepository(db *sql.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+const jobColumns = `id, kind, payload, state, attempts, max_attempts, timeout_ns, last_error, run_at, created_at, updated_at`
+
+func (r *JobRepository) Create(ctx context.Context, job *jobs.Job) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO jobs (`+jobColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		job.ID.String(), job.Kind, []byte(job.Payload), string(job.State), job.Attempts, job.MaxAttempts,
+		int64(job.Timeout), job.LastError, formatTime(job.RunAt), formatTime(job.CreatedAt), formatTime(job.UpdatedAt))
+	if isConstraintError(err) {
+		return fmt.Errorf("job %s: %w", job.ID, jobs.ErrAlreadyExists)
+	}
+	if err != nil {
+		return fmt.Errorf("insert job: %w", err)
+	}
+	return nil
+}
+
+func (r *JobRepository) Get(ctx context.Context, id uuid.UUID) (*jobs.Job, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = ?`, id.String())
+	job, err := scanJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("job %s: %w", id, jobs.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select job: %w", err)
+	}
+	return job, nil
+}
+
+// Update writes every column but kind, payload and created_at, which do not
+// change after a job is enqueued.
+func (r *JobRepository) Update(ctx context.Context, job *jobs.Job) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE jobs SET state = ?, attempts = ?, max_attempts = ?, timeout_ns = ?, last_error = ?, run_at = ?, updated_at = ? WHERE id = ?`,
+		string(job.State), job.Attempts, job.MaxAttempts, int64(job.Timeout), job.LastError,
+		formatTime(job.RunAt), formatTime(job.UpdatedAt), job.ID.String())
+	if err != nil {
+		return fmt.Errorf("update job: %w", err)
+	}
+	return expectOneRow(res, fmt.Errorf("job %s: %w", job.ID, jobs.ErrNotFound))
+}
+
+func (r *JobRepository) List(ctx context.Context, states ...jobs.State) ([]*jobs.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs`
+	args := make([]any, len(states))
+	if len(states) > 0 {
+		for i, s := range states {
+			args[i] = string(s)
+		}
+		query += ` WHERE state IN (?` + strings.Repeat(`, ?`, len(states)-1) + `)`
+	}
+	rows, err := r.db.QueryContext(ctx, query+` ORDER BY created_at, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	list := make([]*jobs.Job, 0)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan job: %w", err)
+		}
+		list = append(list, job)
+	}
+	return list, rows.Err()
+}
+
+func scanJob(s scanner) (*jobs.Job, error) {
+	var (
+		job                         jobs.Job
+		id, state                   string
+		payload                     []byte
+		timeout                     int64
+		runAt, createdAt, updatedAt string
+	)
+	if err := s.Scan(&id, &job.Kind, &payload, &state, &job.Attempts, &job.MaxAttempts,
+		&timeout, &job.LastError, &runAt, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if job.ID, err = uuid.Parse(id); err != nil {
+		return nil, err
+	}
+	if len(payload) > 0 {
+		job.Payload = payload
+	}
+	job.State = jobs.State(state)
+	job.Timeout = time.Duration(timeout)
+	if job.RunAt, err = parseTime(runAt); err != nil {
+		return nil, err
+	}
+	if job.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if job.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
@@ -0,0 +1,14 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/Bermos/Platform/internal/jobs"
+	"github.com/Bermos/Platform/internal/jobs/jobstest"
+)
+
+func TestJobRepository_Conformance(t *testing.T) {
+	jobstest.RunRepositoryTests(t, func(t *testing.T) jobs.Repository {
+		return NewJobRepository(openTestDB(t))
+	})
+}
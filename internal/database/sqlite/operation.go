@@ -0,0 +1,233 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Bermos/Platform/internal/operation"
+	"github.com/google/uuid"
+)
+
+// OperationRepository stores operations in the operations table, with their
+// steps, logs, plan and result as JSON.
+type OperationRepository struct {
+	db *sql.DB
+}
+
+// NewOperationRepository creates an operation repository on an opened
+// database.
+func NewOperationRepository(db *sql.DB) *OperationRepository {
+	return &OperationRepository{db: db}
+}
+
+const operationColumns = `id, kind, project_id, service_id, job_id, state, steps, logs, plan, approved_at, result, error, created_at, updated_at, finished_at`
+
+func (r *OperationRepository) Create(ctx context.Context, op *operation.Operation) error {
+	steps, logs, plan, result, err := marshalOperation(op)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO operations (`+operationColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		op.ID.String(), string(op.Kind), op.ProjectID.String(), op.ServiceID.String(), op.JobID.String(),
+		string(op.State), steps, logs, plan, formatOptionalTime(op.ApprovedAt), result, op.Error,
+		formatTime(op.CreatedAt), formatTime(op.UpdatedAt), formatOptionalTime(op.FinishedAt))
+	if isConstraintError(err) {
+		return fmt.Errorf("operation %s: %w", op.ID, operation.ErrAlreadyExists)
+	}
+	if err != nil {
+		return fmt.Errorf("insert operation: %w", err)
+	}
+	return nil
+}
+
+func (r *OperationRepository) Get(ctx context.Context, id uuid.UUID) (*operation.Operation, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+operationColumns+` FROM operations WHERE id = ?`, id.String())
+	op, err := scanOperation(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("operation %s: %w", id, operation.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select operation: %w", err)
+	}
+	return op, nil
+}
+
+// Update writes the progress of an operation. Its kind and the project and
+// service it refers to do not change.
+func (r *OperationRepository) Update(ctx context.Context, op *operation.Operation) error {
+	steps, logs, plan, result, err := marshalOperation(op)
+	if err != nil {
+		return err
+	}
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE operations SET job_id = ?, state = ?, steps = ?, logs = ?, plan = ?, approved_at = ?, result = ?, error = ?, updated_at = ?, finished_at = ? WHERE id = ?`,
+		op.JobID.String(), string(op.State), steps, logs, plan, formatOptionalTime(op.ApprovedAt), result, op.Error,
+		formatTime(op.UpdatedAt), formatOptionalTime(op.FinishedAt), op.ID.String())
+	if err != nil {
+		return fmt.Errorf("update operation: %w", err)
+	}
+	return expectOneRow(res, fmt.Errorf("operation %s: %w", op.ID, operation.ErrNotFound))
+}
+
+// UpdateFrom writes the progress of an operation, provided its state column
+// still holds from.
+func (r *OperationRepository) UpdateFrom(ctx context.Context, op *operation.Operation, from operation.State) error {
+	steps, logs, plan, result, err := marshalOperation(op)
+	if err != nil {
+		return err
+	}
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE operations SET job_id = ?, state = ?, steps = ?, logs = ?, plan = ?, approved_at = ?, result = ?, error = ?, updated_at = ?, finished_at = ? WHERE id = ? AND state = ?`,
+		op.JobID.String(), string(op.State), steps, logs, plan, formatOptionalTime(op.ApprovedAt), result, op.Error,
+		formatTime(op.UpdatedAt), formatOptionalTime(op.FinishedAt), op.ID.String(), string(from))
+	if err != nil {
+		return fmt.Errorf("update operation: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		var state string
+		err := r.db.QueryRowContext(ctx, `SELECT state FROM operations WHERE id = ?`, op.ID.String()).Scan(&state)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("operation %s: %w", op.ID, operation.ErrNotFound)
+		}
+		if err != nil {
+			return fmt.Errorf("select operation state: %w", err)
+		}
+		return fmt.Errorf("operation %s is %s: %w", op.ID, state, operation.ErrStateConflict)
+	}
+	return nil
+}
+
+func (r *OperationRepository) ListByService(ctx context.Context, serviceID uuid.UUID) ([]*operation.Operation, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+operationColumns+` FROM operations WHERE service_id = ? ORDER BY created_at, id`, serviceID.String())
+	if err != nil {
+		return nil, fmt.Errorf("list operations: %w", err)
+	}
+	defer rows.Close()
+
+	ops := make([]*operation.Operation, 0)
+	for rows.Next() {
+		op, err := scanOperation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan operation: %w", err)
+		}
+		ops = append(ops, op)
+	}
+	return ops, rows.Err()
+}
+
+func scanOperation(s scanner) (*operation.Operation, error) {
+	var (
+		op                       operation.Operation
+		id, projectID, serviceID string
+		jobID, kind, state       string
+		steps, logs              string
+		plan, approvedAt         sql.NullString
+		result, finishedAt       sql.NullString
+		createdAt, updatedAt     string
+	)
+	if err := s.Scan(&id, &kind, &projectID, &serviceID, &jobID, &state, &steps, &logs, &plan, &approvedAt,
+		&result, &op.Error, &createdAt, &updatedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	for _, f := range []struct {
+		dst *uuid.UUID
+		src string
+	}{{&op.ID, id}, {&op.ProjectID, projectID}, {&op.ServiceID, serviceID}, {&op.JobID, jobID}} {
+		if *f.dst, err = uuid.Parse(f.src); err != nil {
+			return nil, err
+		}
+	}
+	op.Kind, op.State = operation.Kind(kind), operation.State(state)
+	if err := json.Unmarshal([]byte(steps), &op.Steps); err != nil {
+		return nil, fmt.Errorf("decode operation steps: %w", err)
+	}
+	if err := json.Unmarshal([]byte(logs), &op.Logs); err != nil {
+		return nil, fmt.Errorf("decode operation logs: %w", err)
+	}
+	if plan.Valid {
+		if err := json.Unmarshal([]byte(plan.String), &op.Plan); err != nil {
+			return nil, fmt.Errorf("decode operation plan: %w", err)
+		}
+	}
+	if approvedAt.Valid {
+		t, err := parseTime(approvedAt.String)
+		if err != nil {
+			return nil, err
+		}
+		op.ApprovedAt = &t
+	}
+	if result.Valid {
+		if err := json.Unmarshal([]byte(result.String), &op.Result); err != nil {
+			return nil, fmt.Errorf("decode operation result: %w", err)
+		}
+	}
+	if op.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if op.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	if finishedAt.Valid {
+		t, err := parseTime(finishedAt.String)
+		if err != nil {
+			return nil, err
+		}
+		op.FinishedAt = &t
+	}
+	return &op, nil
+}
+
+// marshalOperation encodes the JSON columns of op. A nil plan or result is
+// stored as NULL.
+func marshalOperation(op *operation.Operation) (steps, logs string, plan, result sql.NullString, err error) {
+	encode := func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("encode operation: %w", err)
+		}
+		return string(b), nil
+	}
+	if steps, err = encode(nonNil(op.Steps)); err != nil {
+		return
+	}
+	if logs, err = encode(nonNil(op.Logs)); err != nil {
+		return
+	}
+	if op.Plan != nil {
+		plan.Valid = true
+		if plan.String, err = encode(op.Plan); err != nil {
+			return
+		}
+	}
+	if op.Result != nil {
+		result.Valid = true
+		result.String, err = encode(op.Result)
+	}
+	return
+}
+
+// nonNil returns s, or an empty slice when s is nil, so that it encodes as
+// [] rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+func formatOptionalTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(*t), Valid: true}
+}
@@ -0,0 +1,14 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/Bermos/Platform/internal/operation"
+	"github.com/Bermos/Platform/internal/operation/operationtest"
+)
+
+func TestOperationRepository_Conformance(t *testing.T) {
+	operationtest.RunRepositoryTests(t, func(t *testing.T) operation.Repository {
+		return NewOperationRepository(openTestDB(t))
+	})
+}
@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Bermos/Platform/internal/project"
+	"github.com/google/uuid"
+)
+
+// ProjectRepository stores projects in the projects table.
+type ProjectRepository struct {
+	db *sql.DB
+}
+
+// NewProjectRepository creates a project repository on an opened database.
+func NewProjectRepository(db *sql.DB) *ProjectRepository {
+	return &ProjectRepository{db: db}
+}
+
+func (r *ProjectRepository) Create(ctx context.Context, proj *project.Project) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO projects (id, name, description, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		proj.ID.String(), proj.Name, proj.Description, formatTime(proj.CreatedAt), formatTime(proj.UpdatedAt))
+	if isConstraintError(err) {
+		return fmt.Errorf("project %s: %w", proj.Name, project.ErrAlreadyExists)
+	}
+	if err != nil {
+		return fmt.Errorf("insert project: %w", err)
+	}
+	return nil
+}
+
+func (r *ProjectRepository) Get(ctx context.Context, id uuid.UUID) (*project.Project, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT id, name, description, created_at, updated_at FROM projects WHERE id = ?`, id.String())
+	proj, err := scanProject(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("project %s: %w", id, project.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select project: %w", err)
+	}
+	return proj, nil
+}
+
+func (r *ProjectRepository) Update(ctx context.Context, proj *project.Project) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE projects SET name = ?, description = ?, created_at = ?, updated_at = ? WHERE id = ?`,
+		proj.Name, proj.Description, formatTime(proj.CreatedAt), formatTime(proj.UpdatedAt), proj.ID.String())
+	if isConstraintError(err) {
+		return fmt.Errorf("project %s: %w", proj.Name, project.ErrAlreadyExists)
+	}
+	if err != nil {
+		return fmt.Errorf("update project: %w", err)
+	}
+	return expectOneRow(res, fmt.Errorf("project %s: %w", proj.ID, project.ErrNotFound))
+}
+
+func (r *ProjectRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM projects WHERE id = ?`, id.String())
+	if err != nil {
+		return fmt.Errorf("delete project: %w", err)
+	}
+	return expectOneRow(res, fmt.Errorf("project %s: %w", id, project.ErrNotFound))
+}
+
+func (r *ProjectRepository) List(ctx context.Context) ([]*project.Project, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, name, description, created_at, updated_at FROM projects ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := make([]*project.Project, 0)
+	for rows.Next() {
+		proj, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan project: %w", err)
+		}
+		projects = append(projects, proj)
+	}
+	return projects, rows.Err()
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanProject(s scanner) (*project.Project, error) {
+	var (
+		proj                 project.Project
+		id                   string
+		createdAt, updatedAt string
+	)
+	if err := s.Scan(&id, &proj.Name, &proj.Description, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if proj.ID, err = uuid.Parse(id); err != nil {
+		return nil, err
+	}
+	if proj.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if proj.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &proj, nil
+}
+
+// expectOneRow returns notFound when res affected no rows.
+func expectOneRow(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/Bermos/Platform/internal/project"
+	"github.com/Bermos/Platform/internal/project/projecttest"
+)
+
+func TestProjectRepository_Conformance(t *testing.T) {
+	projecttest.RunRepositoryTests(t, func(t *testing.T) project.Repository {
+		return NewProjectRepository(openTestDB(t))
+	})
+}
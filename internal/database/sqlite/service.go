@@ -0,0 +1,385 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/google/uuid"
+)
+
+// ServiceRepository stores services in the services table, with their
+// configuration, drift and outputs as JSON, and their dependencies in
+// service_dependencies. Only the resource key is persisted;
+// returned services have a nil Resource.
+type ServiceRepository struct {
+	db *sql.DB
+}
+
+// NewServiceRepository creates a service repository on an opened database.
+func NewServiceRepository(db *sql.DB) *ServiceRepository {
+	return &ServiceRepository{db: db}
+}
+
+const serviceColumns = `id, project_id, name, description, resource_key, config, status, drift, outputs, created_at, updated_at`
+
+func (r *ServiceRepository) Create(ctx context.Context, svc *service.Service) error {
+	config, drift, outputs, err := marshalService(svc)
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin create service: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO services (`+serviceColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		svc.ID.String(), svc.ProjectID.String(), svc.Name, svc.Description, svc.ResourceKey, config,
+		string(svc.Status), drift, outputs, formatTime(svc.CreatedAt), formatTime(svc.UpdatedAt))
+	if isConstraintError(err) {
+		return fmt.Errorf("service %s: %w", svc.Name, service.ErrAlreadyExists)
+	}
+	if err != nil {
+		return fmt.Errorf("insert service: %w", err)
+	}
+	if err := writeDependencies(ctx, tx, svc); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *ServiceRepository) Get(ctx context.Context, id uuid.UUID) (*service.Service, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+serviceColumns+` FROM services WHERE id = ?`, id.String())
+	svc, err := scanService(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("service %s: %w", id, service.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select service: %w", err)
+	}
+	if svc.DependsOn, err = r.dependencies(ctx, id); err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
+
+// Update writes every column but status, which only Transition changes,
+// and replaces the dependencies.
+func (r *ServiceRepository) Update(ctx context.Context, svc *service.Service) error {
+	config, drift, outputs, err := marshalService(svc)
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin update service: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE services SET project_id = ?, name = ?, description = ?, resource_key = ?, config = ?, drift = ?, outputs = ?, created_at = ?, updated_at = ? WHERE id = ?`,
+		svc.ProjectID.String(), svc.Name, svc.Description, svc.ResourceKey, config, drift, outputs,
+		formatTime(svc.CreatedAt), formatTime(svc.UpdatedAt), svc.ID.String())
+	if isConstraintError(err) {
+		return fmt.Errorf("service %s: %w", svc.Name, service.ErrAlreadyExists)
+	}
+	if err != nil {
+		return fmt.Errorf("update service: %w", err)
+	}
+	if err := expectOneRow(res, fmt.Errorf("service %s: %w", svc.ID, service.ErrNotFound)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM service_dependencies WHERE service_id = ?`, svc.ID.String()); err != nil {
+		return fmt.Errorf("delete service dependencies: %w", err)
+	}
+	if err := writeDependencies(ctx, tx, svc); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateDrift writes the drift column alone.
+func (r *ServiceRepository) UpdateDrift(ctx context.Context, id uuid.UUID, drift *service.Drift) error {
+	var value sql.NullString
+	if drift != nil {
+		b, err := json.Marshal(drift)
+		if err != nil {
+			return fmt.Errorf("encode service drift: %w", err)
+		}
+		value = sql.NullString{String: string(b), Valid: true}
+	}
+	res, err := r.db.ExecContext(ctx, `UPDATE services SET drift = ? WHERE id = ?`, value, id.String())
+	if err != nil {
+		return fmt.Errorf("update service drift: %w", err)
+	}
+	return expectOneRow(res, fmt.Errorf("service %s: %w", id, service.ErrNotFound))
+}
+
+func (r *ServiceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM services WHERE id = ?`, id.String())
+	if isForeignKeyError(err) {
+		return fmt.Errorf("service %s: %w", id, service.ErrHasDependents)
+	}
+	if err != nil {
+		return fmt.Errorf("delete service: %w", err)
+	}
+	return expectOneRow(res, fmt.Errorf("service %s: %w", id, service.ErrNotFound))
+}
+
+func (r *ServiceRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*service.Service, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+serviceColumns+` FROM services WHERE project_id = ? ORDER BY name`, projectID.String())
+	if err != nil {
+		return nil, fmt.Errorf("list services: %w", err)
+	}
+	defer rows.Close()
+
+	services := make([]*service.Service, 0)
+	for rows.Next() {
+		svc, err := scanService(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan service: %w", err)
+		}
+		services = append(services, svc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	deps, err := r.db.QueryContext(ctx,
+		`SELECT d.service_id, d.depends_on_id FROM service_dependencies d JOIN services s ON s.id = d.service_id
+		 WHERE s.project_id = ? ORDER BY d.service_id, d.position`, projectID.String())
+	if err != nil {
+		return nil, fmt.Errorf("list service dependencies: %w", err)
+	}
+	defer deps.Close()
+	dependsOn := map[uuid.UUID][]uuid.UUID{}
+	for deps.Next() {
+		var serviceID, dependsOnID string
+		if err := deps.Scan(&serviceID, &dependsOnID); err != nil {
+			return nil, fmt.Errorf("scan service dependency: %w", err)
+		}
+		id, err := uuid.Parse(serviceID)
+		if err != nil {
+			return nil, err
+		}
+		dep, err := uuid.Parse(dependsOnID)
+		if err != nil {
+			return nil, err
+		}
+		dependsOn[id] = append(dependsOn[id], dep)
+	}
+	for _, svc := range services {
+		svc.DependsOn = dependsOn[svc.ID]
+	}
+	return services, deps.Err()
+}
+
+// dependencies returns the IDs of the services service id depends on, in
+// order.
+func (r *ServiceRepository) dependencies(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT depends_on_id FROM service_dependencies WHERE service_id = ? ORDER BY position`, id.String())
+	if err != nil {
+		return nil, fmt.Errorf("list service dependencies: %w", err)
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, fmt.Errorf("scan service dependency: %w", err)
+		}
+		dep, err := uuid.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, dep)
+	}
+	return ids, rows.Err()
+}
+
+// writeDependencies inserts the dependencies of svc. Each must be another
+// service of its project, listed once.
+func writeDependencies(ctx context.Context, tx *sql.Tx, svc *service.Service) error {
+	for i, id := range svc.DependsOn {
+		res, err := tx.ExecContext(ctx,
+			`INSERT INTO service_dependencies (service_id, depends_on_id, position)
+			 SELECT ?, id, ? FROM services WHERE id = ? AND project_id = ? AND id != ?`,
+			svc.ID.String(), i, id.String(), svc.ProjectID.String(), svc.ID.String())
+		if isConstraintError(err) {
+			return fmt.Errorf("service %s depends on %s twice: %w", svc.Name, id, service.ErrInvalidDependency)
+		}
+		if err != nil {
+			return fmt.Errorf("insert service dependency: %w", err)
+		}
+		if err := expectOneRow(res, fmt.Errorf("service %s cannot depend on %s: %w", svc.Name, id, service.ErrInvalidDependency)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Transition updates the status column, provided it still holds t.From,
+// and inserts t into service_transitions in one transaction.
+func (r *ServiceRepository) Transition(ctx context.Context, t *service.Transition) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transition: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE services SET status = ? WHERE id = ? AND status = ?`,
+		string(t.To), t.ServiceID.String(), string(t.From))
+	if err != nil {
+		return fmt.Errorf("update service status: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		var status string
+		err := tx.QueryRowContext(ctx, `SELECT status FROM services WHERE id = ?`, t.ServiceID.String()).Scan(&status)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("service %s: %w", t.ServiceID, service.ErrNotFound)
+		}
+		if err != nil {
+			return fmt.Errorf("select service status: %w", err)
+		}
+		return fmt.Errorf("service %s is %s: %w", t.ServiceID, status, service.ErrStatusConflict)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO service_transitions (service_id, from_status, to_status, actor, reason, at) VALUES (?, ?, ?, ?, ?, ?)`,
+		t.ServiceID.String(), string(t.From), string(t.To), t.Actor, t.Reason, formatTime(t.At)); err != nil {
+		return fmt.Errorf("insert service transition: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *ServiceRepository) History(ctx context.Context, id uuid.UUID) ([]*service.Transition, error) {
+	if _, err := r.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT from_status, to_status, actor, reason, at FROM service_transitions WHERE service_id = ? ORDER BY id`, id.String())
+	if err != nil {
+		return nil, fmt.Errorf("list service transitions: %w", err)
+	}
+	defer rows.Close()
+
+	history := make([]*service.Transition, 0)
+	for rows.Next() {
+		var (
+			t        = service.Transition{ServiceID: id}
+			from, to string
+			at       string
+		)
+		if err := rows.Scan(&from, &to, &t.Actor, &t.Reason, &at); err != nil {
+			return nil, fmt.Errorf("scan service transition: %w", err)
+		}
+		t.From, t.To = service.Status(from), service.Status(to)
+		if t.At, err = parseTime(at); err != nil {
+			return nil, fmt.Errorf("scan service transition: %w", err)
+		}
+		history = append(history, &t)
+	}
+	return history, rows.Err()
+}
+
+func scanService(s scanner) (*service.Service, error) {
+	var (
+		svc                  service.Service
+		id, projectID        string
+		config, status       string
+		drift, outputs       sql.NullString
+		createdAt, updatedAt string
+	)
+	if err := s.Scan(&id, &projectID, &svc.Name, &svc.Description, &svc.ResourceKey, &config, &status, &drift, &outputs, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if svc.ID, err = uuid.Parse(id); err != nil {
+		return nil, err
+	}
+	if svc.ProjectID, err = uuid.Parse(projectID); err != nil {
+		return nil, err
+	}
+	if svc.Config, err = unmarshalConfig(config); err != nil {
+		return nil, err
+	}
+	svc.Status = service.Status(status)
+	if drift.Valid {
+		if err := json.Unmarshal([]byte(drift.String), &svc.Drift); err != nil {
+			return nil, fmt.Errorf("decode service drift: %w", err)
+		}
+	}
+	if outputs.Valid {
+		if err := json.Unmarshal([]byte(outputs.String), &svc.Outputs); err != nil {
+			return nil, fmt.Errorf("decode service outputs: %w", err)
+		}
+	}
+	if svc.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if svc.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &svc, nil
+}
+
+// marshalService encodes the JSON columns of svc. A nil drift and nil
+// outputs are stored as NULL.
+func marshalService(svc *service.Service) (config string, drift, outputs sql.NullString, err error) {
+	if config, err = marshalConfig(svc.Config); err != nil {
+		return "", drift, outputs, err
+	}
+	if svc.Drift != nil {
+		b, err := json.Marshal(svc.Drift)
+		if err != nil {
+			return "", drift, outputs, fmt.Errorf("encode service drift: %w", err)
+		}
+		drift = sql.NullString{String: string(b), Valid: true}
+	}
+	if svc.Outputs != nil {
+		b, err := json.Marshal(svc.Outputs)
+		if err != nil {
+			return "", drift, outputs, fmt.Errorf("encode service outputs: %w", err)
+		}
+		outputs = sql.NullString{String: string(b), Valid: true}
+	}
+	return config, drift, outputs, nil
+}
+
+// marshalConfig encodes a service configuration for the config column.
+func marshalConfig(config map[string]any) (string, error) {
+	if len(config) == 0 {
+		return "{}", nil
+	}
+	b, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("encode service config: %w", err)
+	}
+	return string(b), nil
+}
+
+// unmarshalConfig decodes the config column. An empty object becomes nil.
+func unmarshalConfig(s string) (map[string]any, error) {
+	var config map[string]any
+	if err := json.Unmarshal([]byte(s), &config); err != nil {
+		return nil, fmt.Errorf("decode service config: %w", err)
+	}
+	if len(config) == 0 {
+		return nil, nil
+	}
+	return config, nil
+}
@@ -0,0 +1,39 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/Bermos/Platform/internal/service/servicetest"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+func TestServiceRepository_Conformance(t *testing.T) {
+	dbs := make(map[service.Repository]*sql.DB)
+	servicetest.RunRepositoryTests(t,
+		func(t *testing.T) service.Repository {
+			db := openTestDB(t)
+			repo := NewServiceRepository(db)
+			dbs[repo] = db
+			return repo
+		},
+		func(t *testing.T, repo service.Repository) uuid.UUID {
+			proj := testutil.NewProjectBuilder().WithName("project-" + uuid.NewString()[:8]).Build()
+			if err := NewProjectRepository(dbs[repo]).Create(context.Background(), proj); err != nil {
+				t.Fatalf("create project: %v", err)
+			}
+			return proj.ID
+		},
+	)
+}
+
+func TestServiceRepository_RequiresProject(t *testing.T) {
+	repo := NewServiceRepository(openTestDB(t))
+	svc := testutil.NewServiceBuilder().WithProjectID(uuid.New()).Build()
+
+	err := repo.Create(context.Background(), svc)
+	testutil.AssertError(t, err, "services of unknown projects should be rejected")
+}
@@ -0,0 +1,70 @@
+// Package sqlite provides repositories backed by a SQLite database file. It
+// uses a pure-Go driver, so the Platform binary stays statically linked.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// timeFormat is how timestamps are stored. Fixed-width UTC keeps them
+// sortable as text.
+const timeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// Open opens the database at path, creating the file if needed. It does not
+// touch the schema; use the migrations package for that.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	dsn := "file:" + path + "?" + url.Values{
+		"_pragma": {"foreign_keys(1)", "busy_timeout(5000)", "journal_mode(WAL)"},
+	}.Encode()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database %s: %w", path, err)
+	}
+	// SQLite allows a single writer. One connection avoids SQLITE_BUSY errors
+	// between our own goroutines; the workload is small enough not to mind.
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open sqlite database %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// isConstraintError reports whether err is a primary key or unique
+// constraint violation.
+func isConstraintError(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+		return true
+	}
+	return false
+}
+
+// isForeignKeyError reports whether err is a foreign key constraint
+// violation.
+func isForeignKeyError(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(timeFormat, s)
+}
@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/database/migrations"
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+// openTestDB opens a fresh, fully migrated database in a temporary directory.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	ctx := context.Background()
+	db, err := Open(ctx, filepath.Join(t.TempDir(), "platform.db"))
+	if err != nil {
+		t.Fatalf("Open: unexpected error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := migrations.New(db, migrations.Embedded()).Up(ctx); err != nil {
+		t.Fatalf("migrate: unexpected error: %v", err)
+	}
+	return db
+}
+
+func TestOpen_IsDurable(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "platform.db")
+
+	db, err := Open(ctx, path)
+	testutil.AssertNoError(t, err, "first open should succeed")
+	_, err = migrations.New(db, migrations.Embedded()).Up(ctx)
+	testutil.AssertNoError(t, err, "migrate should succeed")
+	proj := testutil.NewTestProject()
+	testutil.AssertNoError(t, NewProjectRepository(db).Create(ctx, proj), "create should succeed")
+	testutil.AssertNoError(t, db.Close(), "close should succeed")
+
+	db, err = Open(ctx, path)
+	testutil.AssertNoError(t, err, "reopening should succeed")
+	defer db.Close()
+
+	got, err := NewProjectRepository(db).Get(ctx, proj.ID)
+	testutil.AssertNoError(t, err, "project should survive a restart")
+	testutil.AssertEqual(t, got.Name, proj.Name, "name should survive a restart")
+}
+
+func TestOpen_InvalidPath(t *testing.T) {
+	_, err := Open(context.Background(), filepath.Join(t.TempDir(), "missing", "dir", "platform.db"))
+	testutil.AssertError(t, err, "opening a database in a missing directory should fail")
+}
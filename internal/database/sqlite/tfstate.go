@@ -0,0 +1,218 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Bermos/Platform/internal/tfstate"
+)
+
+// StateRepository stores Terraform state in the terraform_workspaces,
+// terraform_state_versions and terraform_locks tables.
+type StateRepository struct {
+	db *sql.DB
+}
+
+// NewStateRepository creates a state repository on an opened database.
+func NewStateRepository(db *sql.DB) *StateRepository {
+	return &StateRepository{db: db}
+}
+
+func (r *StateRepository) CreateWorkspace(ctx context.Context, w *tfstate.Workspace) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO terraform_workspaces (name, password_hash, created_at) VALUES (?, ?, ?)`,
+		w.Name, w.PasswordHash, formatTime(w.CreatedAt))
+	if isConstraintError(err) {
+		return fmt.Errorf("workspace %s: %w", w.Name, tfstate.ErrAlreadyExists)
+	}
+	if err != nil {
+		return fmt.Errorf("insert workspace: %w", err)
+	}
+	return nil
+}
+
+func (r *StateRepository) GetWorkspace(ctx context.Context, name string) (*tfstate.Workspace, error) {
+	var (
+		w         = tfstate.Workspace{Name: name}
+		createdAt string
+	)
+	err := r.db.QueryRowContext(ctx,
+		`SELECT password_hash, created_at FROM terraform_workspaces WHERE name = ?`, name).
+		Scan(&w.PasswordHash, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("workspace %s: %w", name, tfstate.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select workspace: %w", err)
+	}
+	if w.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (r *StateRepository) UpdateWorkspace(ctx context.Context, w *tfstate.Workspace) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE terraform_workspaces SET password_hash = ? WHERE name = ?`, w.PasswordHash, w.Name)
+	if err != nil {
+		return fmt.Errorf("update workspace: %w", err)
+	}
+	return expectOneRow(res, fmt.Errorf("workspace %s: %w", w.Name, tfstate.ErrNotFound))
+}
+
+// DeleteWorkspace relies on the foreign keys to delete the versions and
+// lock along with the workspace.
+func (r *StateRepository) DeleteWorkspace(ctx context.Context, name string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM terraform_workspaces WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("delete workspace: %w", err)
+	}
+	return expectOneRow(res, fmt.Errorf("workspace %s: %w", name, tfstate.ErrNotFound))
+}
+
+const versionColumns = `workspace, version, serial, lineage, md5, data, created_at`
+
+// AddVersion numbers the version in the same statement that inserts it, so
+// concurrent writers cannot take the same number.
+func (r *StateRepository) AddVersion(ctx context.Context, v *tfstate.Version) error {
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO terraform_state_versions (`+versionColumns+`)
+		 SELECT ?, COALESCE(MAX(version), 0) + 1, ?, ?, ?, ?, ? FROM terraform_state_versions WHERE workspace = ?
+		 RETURNING version`,
+		v.Workspace, v.Serial, v.Lineage, v.MD5, v.Data, formatTime(v.CreatedAt), v.Workspace).
+		Scan(&v.Version)
+	if isForeignKeyError(err) {
+		return fmt.Errorf("workspace %s: %w", v.Workspace, tfstate.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("insert state version: %w", err)
+	}
+	return nil
+}
+
+func (r *StateRepository) LatestVersion(ctx context.Context, workspace string) (*tfstate.Version, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+versionColumns+` FROM terraform_state_versions WHERE workspace = ? ORDER BY version DESC LIMIT 1`, workspace)
+	v, err := scanVersion(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("workspace %s has no state: %w", workspace, tfstate.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select state version: %w", err)
+	}
+	return v, nil
+}
+
+func (r *StateRepository) GetVersion(ctx context.Context, workspace string, version int) (*tfstate.Version, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+versionColumns+` FROM terraform_state_versions WHERE workspace = ? AND version = ?`, workspace, version)
+	v, err := scanVersion(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("workspace %s version %d: %w", workspace, version, tfstate.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select state version: %w", err)
+	}
+	return v, nil
+}
+
+func (r *StateRepository) ListVersions(ctx context.Context, workspace string) ([]*tfstate.Version, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+versionColumns+` FROM terraform_state_versions WHERE workspace = ? ORDER BY version`, workspace)
+	if err != nil {
+		return nil, fmt.Errorf("list state versions: %w", err)
+	}
+	defer rows.Close()
+
+	list := make([]*tfstate.Version, 0)
+	for rows.Next() {
+		v, err := scanVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan state version: %w", err)
+		}
+		list = append(list, v)
+	}
+	return list, rows.Err()
+}
+
+func (r *StateRepository) DeleteVersions(ctx context.Context, workspace string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM terraform_state_versions WHERE workspace = ?`, workspace); err != nil {
+		return fmt.Errorf("delete state versions: %w", err)
+	}
+	return nil
+}
+
+// Lock relies on the primary key to let a single lock in.
+func (r *StateRepository) Lock(ctx context.Context, l *tfstate.Lock) (*tfstate.Lock, error) {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO terraform_locks (workspace, id, info, created_at) VALUES (?, ?, ?, ?)`,
+		l.Workspace, l.ID, []byte(l.Info), formatTime(l.CreatedAt))
+	switch {
+	case isForeignKeyError(err):
+		return nil, fmt.Errorf("workspace %s: %w", l.Workspace, tfstate.ErrNotFound)
+	case isConstraintError(err):
+		held, err := r.GetLock(ctx, l.Workspace)
+		if err != nil {
+			return nil, err
+		}
+		return held, tfstate.ErrLocked
+	case err != nil:
+		return nil, fmt.Errorf("insert lock: %w", err)
+	}
+	return nil, nil
+}
+
+func (r *StateRepository) GetLock(ctx context.Context, workspace string) (*tfstate.Lock, error) {
+	var (
+		l         = tfstate.Lock{Workspace: workspace}
+		info      []byte
+		createdAt string
+	)
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, info, created_at FROM terraform_locks WHERE workspace = ?`, workspace).
+		Scan(&l.ID, &info, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("workspace %s is not locked: %w", workspace, tfstate.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select lock: %w", err)
+	}
+	l.Info = info
+	if l.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *StateRepository) Unlock(ctx context.Context, workspace, id string) error {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM terraform_locks WHERE workspace = ? AND (? = '' OR id = ?)`, workspace, id, id)
+	if err != nil {
+		return fmt.Errorf("delete lock: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 1 {
+		return nil
+	}
+	if _, err := r.GetLock(ctx, workspace); err != nil {
+		return err
+	}
+	return fmt.Errorf("workspace %s: %w", workspace, tfstate.ErrLocked)
+}
+
+func scanVersion(s scanner) (*tfstate.Version, error) {
+	var (
+		v         tfstate.Version
+		createdAt string
+	)
+	if err := s.Scan(&v.Workspace, &v.Version, &v.Serial, &v.Lineage, &v.MD5, &v.Data, &createdAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if v.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
@@ -0,0 +1,14 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/Bermos/Platform/internal/tfstate"
+	"github.com/Bermos/Platform/internal/tfstate/tfstatetest"
+)
+
+func TestStateRepository_Conformance(t *testing.T) {
+	tfstatetest.RunRepositoryTests(t, func(t *testing.T) tfstate.Repository {
+		return NewStateRepository(openTestDB(t))
+	})
+}
@@ -6,9 +6,12 @@ import (
 )
 
 type Instance struct {
-	Name               string
-	Projects           []*project.Project
-	AvailableResources []resource.Resource
+	Name     string
+	Projects []*project.Project
+	// Catalog lists the resources services can run on.
+	Catalog *resource.Catalog
+	// Namespace is the Kubernetes namespace services are deployed to.
+	Namespace string
 }
 
 func (i *Instance) AddProject(p *project.Project) {
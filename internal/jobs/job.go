@@ -0,0 +1,95 @@
+// Package jobs runs background work in-process. Jobs are persisted through a
+// Repository so that queued work survives a restart when the repository is
+// durable.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// State is where a job is in its lifecycle.
+type State string
+
+// Job states. Succeeded, cancelled and dead are final.
+const (
+	// StateQueued jobs wait for a worker until RunAt.
+	StateQueued State = "queued"
+	// StateRunning jobs are being handled by a worker.
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateCancelled State = "cancelled"
+	// StateDead jobs failed permanently or ran out of attempts. They stay
+	// in the repository, the dead-letter queue, until retried.
+	StateDead State = "dead"
+)
+
+// Final reports whether no worker will pick up a job in state s again.
+func (s State) Final() bool {
+	switch s {
+	case StateSucceeded, StateCancelled, StateDead:
+		return true
+	}
+	return false
+}
+
+var (
+	// ErrNotFound is returned by a Repository when a job does not exist.
+	ErrNotFound = errors.New("job not found")
+	// ErrAlreadyExists is returned by a Repository when a job with the same
+	// ID exists.
+	ErrAlreadyExists = errors.New("job already exists")
+	// ErrFinished is returned when cancelling a job that already finished.
+	ErrFinished = errors.New("job already finished")
+	// ErrNotDead is returned when retrying a job that is not dead.
+	ErrNotDead = errors.New("job is not dead")
+	// ErrUnknownKind is returned when enqueuing a kind without a handler.
+	ErrUnknownKind = errors.New("no handler for job kind")
+	// ErrCancelled is the cause of a running job's context when the job is
+	// cancelled.
+	ErrCancelled = errors.New("job cancelled")
+)
+
+// Job is one unit of background work.
+type Job struct {
+	ID uuid.UUID `json:"id"`
+	// Kind selects the Handler.
+	Kind    string          `json:"kind"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	State   State           `json:"state"`
+	// Attempts counts started attempts, including the running one.
+	Attempts    int `json:"attempts"`
+	MaxAttempts int `json:"maxAttempts"`
+	// Timeout bounds a single attempt.
+	Timeout time.Duration `json:"timeout"`
+	// LastError is the error of the last failed attempt.
+	LastError string `json:"lastError,omitempty"`
+	// RunAt is the earliest time the next attempt may start.
+	RunAt     time.Time `json:"runAt"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Decode unmarshals the job's payload into v.
+func (j *Job) Decode(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// Repository persists jobs.
+//
+// Implementations must return errors that match ErrNotFound and
+// ErrAlreadyExists with errors.Is. The jobstest package contains a
+// conformance suite every implementation is expected to pass.
+type Repository interface {
+	Create(ctx context.Context, job *Job) error
+	Get(ctx context.Context, id uuid.UUID) (*Job, error)
+	Update(ctx context.Context, job *Job) error
+	// List returns the jobs in any of the given states ordered by creation
+	// time, or all jobs when no state is given. It returns an empty, non-nil
+	// slice when nothing matches.
+	List(ctx context.Context, states ...State) ([]*Job, error)
+}
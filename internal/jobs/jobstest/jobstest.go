@@ -0,0 +1,209 @@
+// Package jobstest provides a conformance suite for jobs.Repository
+// implementations.
+package jobstest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/jobs"
+	"github.com/google/uuid"
+)
+
+// RunRepositoryTests runs the conformance suite against repositories returned
+// by newRepo. newRepo is called once per subtest and must return an empty
+// repository.
+func RunRepositoryTests(t *testing.T, newRepo func(t *testing.T) jobs.Repository) {
+	t.Helper()
+
+	t.Run("create_and_get", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		job := newJob("deploy", 0)
+
+		if err := repo.Create(ctx, job); err != nil {
+			t.Fatalf("Create: unexpected error: %v", err)
+		}
+		got, err := repo.Get(ctx, job.ID)
+		if err != nil {
+			t.Fatalf("Get: unexpected error: %v", err)
+		}
+		assertJob(t, got, job)
+	})
+
+	t.Run("create_without_payload", func(t *testing.T) {
+		repo := newRepo(t)
+		job := newJob("deploy", 0)
+		job.Payload = nil
+		mustCreate(t, repo, job)
+
+		got, err := repo.Get(context.Background(), job.ID)
+		if err != nil {
+			t.Fatalf("Get: unexpected error: %v", err)
+		}
+		if len(got.Payload) != 0 {
+			t.Errorf("Payload: got %s, want empty", got.Payload)
+		}
+	})
+
+	t.Run("create_duplicate_id", func(t *testing.T) {
+		repo := newRepo(t)
+		job := newJob("deploy", 0)
+		mustCreate(t, repo, job)
+
+		if err := repo.Create(context.Background(), job); !errors.Is(err, jobs.ErrAlreadyExists) {
+			t.Errorf("Create with duplicate ID: got %v, want ErrAlreadyExists", err)
+		}
+	})
+
+	t.Run("get_not_found", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if _, err := repo.Get(context.Background(), uuid.New()); !errors.Is(err, jobs.ErrNotFound) {
+			t.Errorf("Get of unknown ID: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		job := newJob("deploy", 0)
+		mustCreate(t, repo, job)
+
+		job.State = jobs.StateDead
+		job.Attempts = 3
+		job.LastError = "connection refused"
+		job.RunAt = job.RunAt.Add(time.Minute)
+		job.UpdatedAt = job.UpdatedAt.Add(time.Hour)
+		if err := repo.Update(ctx, job); err != nil {
+			t.Fatalf("Update: unexpected error: %v", err)
+		}
+		got, err := repo.Get(ctx, job.ID)
+		if err != nil {
+			t.Fatalf("Get: unexpected error: %v", err)
+		}
+		assertJob(t, got, job)
+	})
+
+	t.Run("update_not_found", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if err := repo.Update(context.Background(), newJob("deploy", 0)); !errors.Is(err, jobs.ErrNotFound) {
+			t.Errorf("Update of unknown ID: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("list_filters_and_orders", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		second := newJob("second", time.Second)
+		first := newJob("first", 0)
+		third := newJob("third", 2*time.Second)
+		third.State = jobs.StateRunning
+		done := newJob("done", 3*time.Second)
+		done.State = jobs.StateSucceeded
+		for _, job := range []*jobs.Job{second, first, third, done} {
+			mustCreate(t, repo, job)
+		}
+
+		got, err := repo.List(ctx, jobs.StateQueued, jobs.StateRunning)
+		if err != nil {
+			t.Fatalf("List: unexpected error: %v", err)
+		}
+		assertKinds(t, got, "first", "second", "third")
+
+		all, err := repo.List(ctx)
+		if err != nil {
+			t.Fatalf("List: unexpected error: %v", err)
+		}
+		assertKinds(t, all, "first", "second", "third", "done")
+	})
+
+	t.Run("list_empty", func(t *testing.T) {
+		repo := newRepo(t)
+		mustCreate(t, repo, newJob("deploy", 0))
+
+		got, err := repo.List(context.Background(), jobs.StateDead)
+		if err != nil {
+			t.Fatalf("List: unexpected error: %v", err)
+		}
+		if got == nil || len(got) != 0 {
+			t.Errorf("List without matches: got %v, want empty non-nil slice", got)
+		}
+	})
+}
+
+// newJob returns a queued job created offset after a fixed base time.
+func newJob(kind string, offset time.Duration) *jobs.Job {
+	now := time.Now().UTC().Truncate(time.Microsecond).Add(offset)
+	return &jobs.Job{
+		ID:          uuid.New(),
+		Kind:        kind,
+		Payload:     []byte(`{"service":"api"}`),
+		State:       jobs.StateQueued,
+		MaxAttempts: 5,
+		Timeout:     90 * time.Second,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func mustCreate(t *testing.T, repo jobs.Repository, job *jobs.Job) {
+	t.Helper()
+	if err := repo.Create(context.Background(), job); err != nil {
+		t.Fatalf("Create %q: unexpected error: %v", job.Kind, err)
+	}
+}
+
+func assertKinds(t *testing.T, got []*jobs.Job, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("List: got %d jobs, want %d", len(got), len(want))
+	}
+	for i, job := range got {
+		if job.Kind != want[i] {
+			t.Errorf("List[%d]: got %q, want %q", i, job.Kind, want[i])
+		}
+	}
+}
+
+func assertJob(t *testing.T, got, want *jobs.Job) {
+	t.Helper()
+	if got.ID != want.ID {
+		t.Errorf("ID: got %s, want %s", got.ID, want.ID)
+	}
+	if got.Kind != want.Kind {
+		t.Errorf("Kind: got %q, want %q", got.Kind, want.Kind)
+	}
+	if !bytes.Equal(got.Payload, want.Payload) {
+		t.Errorf("Payload: got %s, want %s", got.Payload, want.Payload)
+	}
+	if got.State != want.State {
+		t.Errorf("State: got %q, want %q", got.State, want.State)
+	}
+	if got.Attempts != want.Attempts {
+		t.Errorf("Attempts: got %d, want %d", got.Attempts, want.Attempts)
+	}
+	if got.MaxAttempts != want.MaxAttempts {
+		t.Errorf("MaxAttempts: got %d, want %d", got.MaxAttempts, want.MaxAttempts)
+	}
+	if got.Timeout != want.Timeout {
+		t.Errorf("Timeout: got %s, want %s", got.Timeout, want.Timeout)
+	}
+	if got.LastError != want.LastError {
+		t.Errorf("LastError: got %q, want %q", got.LastError, want.LastError)
+	}
+	if !got.RunAt.Equal(want.RunAt) {
+		t.Errorf("RunAt: got %v, want %v", got.RunAt, want.RunAt)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("CreatedAt: got %v, want %v", got.CreatedAt, want.CreatedAt)
+	}
+	if !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Errorf("UpdatedAt: got %v, want %v", got.UpdatedAt, want.UpdatedAt)
+	}
+}
@@ -0,0 +1,572 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Default queue options.
+const (
+	DefaultWorkers     = 4
+	DefaultTimeout     = 10 * time.Minute
+	DefaultMaxAttempts = 5
+	DefaultBackoff     = time.Second
+	DefaultMaxBackoff  = 5 * time.Minute
+)
+
+// errShutdown is the cause of running jobs' contexts when Stop gives up
+// waiting for them.
+var errShutdown = errors.New("job queue shutting down")
+
+// Handler does the work of one job attempt. It must return soon after ctx
+// is done; context.Cause(ctx) tells a timeout, cancellation and shutdown
+// apart. Returning an error schedules a retry unless the error is Permanent
+// or the job has no attempts left; a Snooze error puts the job back without
+// counting the attempt.
+type Handler func(ctx context.Context, job *Job) error
+
+// permanentError marks an error that retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that the job is dead-lettered without further
+// attempts. It returns nil for a nil err.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped by Permanent.
+func IsPermanent(err error) bool {
+	var perm *permanentError
+	return errors.As(err, &perm)
+}
+
+// snoozeError defers a job that cannot make progress yet.
+type snoozeError struct {
+	delay time.Duration
+}
+
+func (e *snoozeError) Error() string { return fmt.Sprintf("snoozed for %s", e.delay) }
+
+// Snooze returns an error that queues the job again after d, without
+// counting the attempt, e.g. while it waits for other jobs to finish.
+func Snooze(d time.Duration) error {
+	return &snoozeError{delay: d}
+}
+
+// IsSnoozed reports whether err was returned by Snooze.
+func IsSnoozed(err error) bool {
+	var snooze *snoozeError
+	return errors.As(err, &snooze)
+}
+
+// Options configure a Queue. Zero values select the defaults.
+type Options struct {
+	// Workers is the number of jobs handled concurrently.
+	Workers int
+	// Timeout bounds each attempt of jobs enqueued without WithTimeout.
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts of jobs enqueued without
+	// WithMaxAttempts before they are dead-lettered.
+	MaxAttempts int
+	// Backoff is the delay before the first retry. It doubles with every
+	// further attempt, up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.Workers <= 0 {
+		o.Workers = DefaultWorkers
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = DefaultMaxAttempts
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = DefaultBackoff
+	}
+	if o.MaxBackoff < o.Backoff {
+		o.MaxBackoff = max(DefaultMaxBackoff, o.Backoff)
+	}
+	return o
+}
+
+// EnqueueOption overrides a queue option for one job.
+type EnqueueOption func(*Job)
+
+// WithTimeout bounds each attempt of the job by d.
+func WithTimeout(d time.Duration) EnqueueOption {
+	return func(j *Job) { j.Timeout = d }
+}
+
+// WithMaxAttempts sets how often the job is attempted before it is
+// dead-lettered.
+func WithMaxAttempts(n int) EnqueueOption {
+	return func(j *Job) { j.MaxAttempts = n }
+}
+
+// WithID sets the job's ID instead of generating one, so that the caller
+// can refer to the job before it is enqueued.
+func WithID(id uuid.UUID) EnqueueOption {
+	return func(j *Job) { j.ID = id }
+}
+
+// WithDelay defers the first attempt by d.
+func WithDelay(d time.Duration) EnqueueOption {
+	return func(j *Job) { j.RunAt = j.RunAt.Add(d) }
+}
+
+// Queue hands persisted jobs to a pool of workers.
+//
+// Every state change is written to the repository before it takes effect,
+// so a queue started on the same durable repository after a restart picks
+// up where the previous one stopped. Jobs that were running when the
+// process died are attempted again.
+type Queue struct {
+	repo Repository
+	opts Options
+	now  func() time.Time
+
+	// mu serialises state changes of jobs, so that a worker claiming a job
+	// and Cancel never both win.
+	mu       sync.Mutex
+	handlers map[string]Handler
+	// pending holds the RunAt of the queued jobs known to the dispatcher.
+	pending map[uuid.UUID]time.Time
+	// running holds the cancel functions of the jobs being handled.
+	running map[uuid.UUID]context.CancelCauseFunc
+	started bool
+	stopped bool
+
+	base     context.Context
+	abort    context.CancelCauseFunc
+	stopping chan struct{}
+	wake     chan struct{}
+	work     chan uuid.UUID
+	wg       sync.WaitGroup
+}
+
+// NewQueue creates a stopped queue storing jobs in repo.
+func NewQueue(repo Repository, opts Options) *Queue {
+	base, abort := context.WithCancelCause(context.Background())
+	return &Queue{
+		repo:     repo,
+		opts:     opts.withDefaults(),
+		now:      time.Now,
+		handlers: make(map[string]Handler),
+		pending:  make(map[uuid.UUID]time.Time),
+		running:  make(map[uuid.UUID]context.CancelCauseFunc),
+		base:     base,
+		abort:    abort,
+		stopping: make(chan struct{}),
+		wake:     make(chan struct{}, 1),
+		work:     make(chan uuid.UUID),
+	}
+}
+
+// Handle registers the handler of a job kind. Handlers should be
+// registered before Start so that recovered jobs find them.
+func (q *Queue) Handle(kind string, h Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[kind] = h
+}
+
+// Enqueue persists a new job of the given kind with payload encoded as
+// JSON. It may be called before Start; the job then runs once the queue is
+// started.
+func (q *Queue) Enqueue(ctx context.Context, kind string, payload any, opts ...EnqueueOption) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.handlers[kind]; !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKind, kind)
+	}
+	var raw json.RawMessage
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("encode job payload: %w", err)
+		}
+		raw = b
+	}
+
+	now := q.now()
+	job := &Job{
+		ID:          uuid.New(),
+		Kind:        kind,
+		Payload:     raw,
+		State:       StateQueued,
+		MaxAttempts: q.opts.MaxAttempts,
+		Timeout:     q.opts.Timeout,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	if err := q.repo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	q.schedule(job)
+	return job, nil
+}
+
+// Get returns the job with the given ID.
+func (q *Queue) Get(ctx context.Context, id uuid.UUID) (*Job, error) {
+	return q.repo.Get(ctx, id)
+}
+
+// List returns the jobs in any of the given states, or all jobs.
+func (q *Queue) List(ctx context.Context, states ...State) ([]*Job, error) {
+	return q.repo.List(ctx, states...)
+}
+
+// Cancel stops a job. A queued job is cancelled immediately. A running
+// job's context is cancelled with cause ErrCancelled and the job becomes
+// cancelled once its handler returns, unless the handler succeeded anyway.
+// Cancelling a finished job fails with ErrFinished.
+func (q *Queue) Cancel(ctx context.Context, id uuid.UUID) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, err := q.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.State.Final() {
+		return job, fmt.Errorf("job %s is %s: %w", id, job.State, ErrFinished)
+	}
+	if cancel, ok := q.running[id]; ok {
+		cancel(ErrCancelled)
+		return job, nil
+	}
+
+	job.State = StateCancelled
+	job.UpdatedAt = q.now()
+	if err := q.repo.Update(ctx, job); err != nil {
+		return nil, err
+	}
+	delete(q.pending, id)
+	return job, nil
+}
+
+// Retry queues a dead job again with a fresh set of attempts. It fails with
+// ErrNotDead for jobs in any other state.
+func (q *Queue) Retry(ctx context.Context, id uuid.UUID) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, err := q.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.State != StateDead {
+		return job, fmt.Errorf("job %s is %s: %w", id, job.State, ErrNotDead)
+	}
+
+	now := q.now()
+	job.State = StateQueued
+	job.Attempts = 0
+	job.LastError = ""
+	job.RunAt = now
+	job.UpdatedAt = now
+	if err := q.repo.Update(ctx, job); err != nil {
+		return nil, err
+	}
+	q.schedule(job)
+	return job, nil
+}
+
+// Start recovers the queued jobs from the repository and starts the
+// workers. Jobs left running by a previous process are queued again, or
+// dead-lettered when that was their last attempt.
+func (q *Queue) Start(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.started {
+		return errors.New("job queue already started")
+	}
+	jobs, err := q.repo.List(ctx, StateQueued, StateRunning)
+	if err != nil {
+		return fmt.Errorf("recover jobs: %w", err)
+	}
+	now := q.now()
+	for _, job := range jobs {
+		if job.State == StateRunning {
+			job.LastError = "interrupted by a restart"
+			job.UpdatedAt = now
+			if job.Attempts >= job.MaxAttempts {
+				job.State = StateDead
+			} else {
+				job.State = StateQueued
+				job.RunAt = now
+			}
+			if err := q.repo.Update(ctx, job); err != nil {
+				return fmt.Errorf("recover job %s: %w", job.ID, err)
+			}
+		}
+		q.schedule(job)
+	}
+
+	q.started = true
+	q.wg.Add(1 + q.opts.Workers)
+	go q.dispatch()
+	for range q.opts.Workers {
+		go q.worker()
+	}
+	return nil
+}
+
+// Stop stops handing out jobs and waits for the running ones to finish.
+// When ctx is done first, the running jobs are cancelled and queued again
+// without using up an attempt, and Stop returns ctx.Err() once their
+// handlers have returned. A stopped queue cannot be restarted.
+func (q *Queue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.started || q.stopped {
+		q.mu.Unlock()
+		return nil
+	}
+	q.stopped = true
+	close(q.stopping)
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		q.abort(errShutdown)
+		<-done
+		return ctx.Err()
+	}
+}
+
+// schedule makes a queued job known to the dispatcher. The caller holds
+// q.mu.
+func (q *Queue) schedule(job *Job) {
+	if job.State != StateQueued {
+		return
+	}
+	q.pending[job.ID] = job.RunAt
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next removes and returns the earliest due job. Otherwise it reports how
+// long until the earliest job is due, if there is one.
+func (q *Queue) next() (id uuid.UUID, wait time.Duration, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var earliest time.Time
+	for pid, runAt := range q.pending {
+		if !ok || runAt.Before(earliest) {
+			id, earliest, ok = pid, runAt, true
+		}
+	}
+	if !ok {
+		return uuid.Nil, 0, false
+	}
+	wait = earliest.Sub(q.now())
+	if wait <= 0 {
+		delete(q.pending, id)
+	}
+	return id, wait, true
+}
+
+// dispatch sends due jobs to the workers until the queue stops. Jobs
+// dropped on the way stay queued in the repository.
+func (q *Queue) dispatch() {
+	defer q.wg.Done()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		id, wait, ok := q.next()
+		if ok && wait <= 0 {
+			select {
+			case q.work <- id:
+				continue
+			case <-q.stopping:
+				return
+			}
+		}
+
+		var due <-chan time.Time
+		if ok {
+			timer.Reset(wait)
+			due = timer.C
+		}
+		select {
+		case <-due:
+		case <-q.wake:
+		case <-q.stopping:
+			return
+		}
+		timer.Stop()
+	}
+}
+
+func (q *Queue) worker() {
+	defer q.wg.Done()
+	for {
+		select {
+		case id := <-q.work:
+			q.run(id)
+		case <-q.stopping:
+			return
+		}
+	}
+}
+
+// run makes one attempt at a job and records its outcome.
+func (q *Queue) run(id uuid.UUID) {
+	job, handler, ctx, ok := q.claim(id)
+	if !ok {
+		return
+	}
+	err := q.call(ctx, handler, job)
+	q.finish(ctx, job, err)
+}
+
+// claim marks a queued job as running. It reports false when the job is no
+// longer queued, e.g. because it was cancelled.
+func (q *Queue) claim(id uuid.UUID) (*Job, Handler, context.Context, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, err := q.repo.Get(context.Background(), id)
+	if err != nil {
+		slog.Error("Failed to load job", "job", id, "error", err)
+		return nil, nil, nil, false
+	}
+	if job.State != StateQueued {
+		return nil, nil, nil, false
+	}
+
+	job.State = StateRunning
+	job.Attempts++
+	job.UpdatedAt = q.now()
+	if err := q.repo.Update(context.Background(), job); err != nil {
+		slog.Error("Failed to start job", "job", id, "error", err)
+		job.State = StateQueued
+		job.RunAt = q.now().Add(q.opts.Backoff)
+		q.schedule(job)
+		return nil, nil, nil, false
+	}
+
+	ctx, cancel := context.WithCancelCause(q.base)
+	q.running[id] = cancel
+	return job, q.handlers[job.Kind], ctx, true
+}
+
+// call runs handler within the job's timeout. Panics become errors.
+func (q *Queue) call(ctx context.Context, handler Handler, job *Job) (err error) {
+	if handler == nil {
+		return Permanent(fmt.Errorf("%w %q", ErrUnknownKind, job.Kind))
+	}
+	timeout := job.Timeout
+	if timeout <= 0 {
+		timeout = q.opts.Timeout
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	err = handler(attemptCtx, job)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("attempt timed out after %s: %w", timeout, err)
+	}
+	return err
+}
+
+// finish records the outcome of an attempt: success, cancellation, a
+// retry after backoff, or the dead-letter queue.
+func (q *Queue) finish(ctx context.Context, job *Job, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.running[job.ID](nil)
+	delete(q.running, job.ID)
+
+	now := q.now()
+	job.UpdatedAt = now
+	cause := context.Cause(ctx)
+	var snooze *snoozeError
+	switch {
+	case err == nil:
+		job.State = StateSucceeded
+		job.LastError = ""
+	case errors.Is(cause, ErrCancelled):
+		job.State = StateCancelled
+		job.LastError = err.Error()
+	case errors.Is(cause, errShutdown):
+		// The attempt did not fail on its own; give it back.
+		job.State = StateQueued
+		job.Attempts--
+		job.RunAt = now
+		job.LastError = err.Error()
+	case errors.As(err, &snooze):
+		job.State = StateQueued
+		job.Attempts--
+		job.RunAt = now.Add(snooze.delay)
+		job.LastError = ""
+	case IsPermanent(err) || job.Attempts >= job.MaxAttempts:
+		job.State = StateDead
+		job.LastError = err.Error()
+	default:
+		job.State = StateQueued
+		job.RunAt = now.Add(q.backoff(job.Attempts))
+		job.LastError = err.Error()
+	}
+
+	if err := q.repo.Update(context.Background(), job); err != nil {
+		slog.Error("Failed to record job outcome", "job", job.ID, "state", job.State, "error", err)
+		return
+	}
+	if job.State == StateDead {
+		slog.Warn("Job dead-lettered", "job", job.ID, "kind", job.Kind, "attempts", job.Attempts, "error", job.LastError)
+	}
+	q.schedule(job)
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (q *Queue) backoff(attempts int) time.Duration {
+	d := q.opts.Backoff
+	for i := 1; i < attempts && d < q.opts.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, q.opts.MaxBackoff)
+}
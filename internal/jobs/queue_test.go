@@ -0,0 +1,326 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/database/memory"
+	"github.com/Bermos/Platform/internal/jobs"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+// fastOptions keep retries quick enough for tests.
+var fastOptions = jobs.Options{Workers: 2, Timeout: time.Second, MaxAttempts: 3, Backoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+
+// startQueue starts a queue with handlers on repo and stops it at the end of
+// the test.
+func startQueue(t *testing.T, repo jobs.Repository, opts jobs.Options, handlers map[string]jobs.Handler) *jobs.Queue {
+	t.Helper()
+	q := jobs.NewQueue(repo, opts)
+	for kind, h := range handlers {
+		q.Handle(kind, h)
+	}
+	testutil.AssertNoError(t, q.Start(context.Background()), "start should succeed")
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		q.Stop(ctx)
+	})
+	return q
+}
+
+// waitForState polls until the job reaches state and returns it.
+func waitForState(t *testing.T, q *jobs.Queue, id uuid.UUID, state jobs.State) *jobs.Job {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		job, err := q.Get(context.Background(), id)
+		testutil.AssertNoError(t, err, "get should succeed")
+		if job.State == state {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %s after 2s, want %s (last error %q)", id, job.State, state, job.LastError)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestQueue_RunsJobs(t *testing.T) {
+	got := make(chan string, 1)
+	q := startQueue(t, memory.NewJobRepository(), fastOptions, map[string]jobs.Handler{
+		"greet": func(ctx context.Context, job *jobs.Job) error {
+			var payload struct{ Name string }
+			if err := job.Decode(&payload); err != nil {
+				return err
+			}
+			got <- "hello " + payload.Name
+			return nil
+		},
+	})
+
+	job, err := q.Enqueue(context.Background(), "greet", map[string]string{"name": "mahler"})
+	testutil.AssertNoError(t, err, "enqueue should succeed")
+	testutil.AssertEqual(t, job.State, jobs.StateQueued, "new jobs should be queued")
+
+	done := waitForState(t, q, job.ID, jobs.StateSucceeded)
+	testutil.AssertEqual(t, <-got, "hello mahler", "handler should receive the payload")
+	testutil.AssertEqual(t, done.Attempts, 1, "one attempt should be recorded")
+}
+
+func TestQueue_EnqueueUnknownKind(t *testing.T) {
+	q := jobs.NewQueue(memory.NewJobRepository(), fastOptions)
+
+	_, err := q.Enqueue(context.Background(), "missing", nil)
+	testutil.AssertTrue(t, errors.Is(err, jobs.ErrUnknownKind), "kinds without handler should be rejected")
+}
+
+func TestQueue_RetriesWithBackoff(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts []time.Time
+	)
+	q := startQueue(t, memory.NewJobRepository(), fastOptions, map[string]jobs.Handler{
+		"flaky": func(ctx context.Context, job *jobs.Job) error {
+			mu.Lock()
+			defer mu.Unlock()
+			attempts = append(attempts, time.Now())
+			if len(attempts) < 3 {
+				return errors.New("not yet")
+			}
+			return nil
+		},
+	})
+
+	job, err := q.Enqueue(context.Background(), "flaky", nil)
+	testutil.AssertNoError(t, err, "enqueue should succeed")
+	done := waitForState(t, q, job.ID, jobs.StateSucceeded)
+
+	testutil.AssertEqual(t, done.Attempts, 3, "the job should succeed on its third attempt")
+	testutil.AssertEqual(t, done.LastError, "", "success should clear the last error")
+	mu.Lock()
+	defer mu.Unlock()
+	testutil.AssertTrue(t, attempts[1].Sub(attempts[0]) >= fastOptions.Backoff, "first retry should wait the backoff")
+	testutil.AssertTrue(t, attempts[2].Sub(attempts[1]) >= 2*fastOptions.Backoff, "second retry should wait twice the backoff")
+}
+
+func TestQueue_DeadLetters(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	q := startQueue(t, memory.NewJobRepository(), fastOptions, map[string]jobs.Handler{
+		"broken": func(ctx context.Context, job *jobs.Job) error {
+			if fail.Load() {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+		"invalid": func(ctx context.Context, job *jobs.Job) error {
+			return jobs.Permanent(errors.New("bad payload"))
+		},
+	})
+	ctx := context.Background()
+
+	broken, err := q.Enqueue(ctx, "broken", nil)
+	testutil.AssertNoError(t, err, "enqueue should succeed")
+	dead := waitForState(t, q, broken.ID, jobs.StateDead)
+	testutil.AssertEqual(t, dead.Attempts, fastOptions.MaxAttempts, "every attempt should be used")
+	testutil.AssertEqual(t, dead.LastError, "connection refused", "the last error should be kept")
+
+	invalid, err := q.Enqueue(ctx, "invalid", nil)
+	testutil.AssertNoError(t, err, "enqueue should succeed")
+	dead = waitForState(t, q, invalid.ID, jobs.StateDead)
+	testutil.AssertEqual(t, dead.Attempts, 1, "permanent errors should not be retried")
+
+	letters, err := q.List(ctx, jobs.StateDead)
+	testutil.AssertNoError(t, err, "list should succeed")
+	testutil.AssertEqual(t, len(letters), 2, "both jobs should be dead-lettered")
+
+	fail.Store(false)
+	_, err = q.Retry(ctx, broken.ID)
+	testutil.AssertNoError(t, err, "retrying a dead job should succeed")
+	done := waitForState(t, q, broken.ID, jobs.StateSucceeded)
+	testutil.AssertEqual(t, done.Attempts, 1, "retrying should reset the attempts")
+
+	_, err = q.Retry(ctx, broken.ID)
+	testutil.AssertTrue(t, errors.Is(err, jobs.ErrNotDead), "only dead jobs should be retried")
+}
+
+func TestQueue_Snooze(t *testing.T) {
+	var calls atomic.Int32
+	q := startQueue(t, memory.NewJobRepository(), fastOptions, map[string]jobs.Handler{
+		"waiting": func(ctx context.Context, job *jobs.Job) error {
+			if calls.Add(1) <= int32(fastOptions.MaxAttempts) {
+				return jobs.Snooze(time.Millisecond)
+			}
+			return nil
+		},
+	})
+
+	job, err := q.Enqueue(context.Background(), "waiting", nil)
+	testutil.AssertNoError(t, err, "enqueue should succeed")
+	done := waitForState(t, q, job.ID, jobs.StateSucceeded)
+	testutil.AssertEqual(t, int(calls.Load()), fastOptions.MaxAttempts+1, "the job should run again after every snooze")
+	testutil.AssertEqual(t, done.Attempts, 1, "snoozing should not count as an attempt")
+	testutil.AssertEqual(t, done.LastError, "", "snoozing should not be recorded as an error")
+}
+
+func TestQueue_Timeout(t *testing.T) {
+	q := startQueue(t, memory.NewJobRepository(), fastOptions, map[string]jobs.Handler{
+		"slow": func(ctx context.Context, job *jobs.Job) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	job, err := q.Enqueue(context.Background(), "slow", nil, jobs.WithTimeout(20*time.Millisecond), jobs.WithMaxAttempts(1))
+	testutil.AssertNoError(t, err, "enqueue should succeed")
+	dead := waitForState(t, q, job.ID, jobs.StateDead)
+	testutil.AssertTrue(t, strings.Contains(dead.LastError, "timed out after 20ms"), "the timeout should be reported, got "+dead.LastError)
+}
+
+func TestQueue_Cancel(t *testing.T) {
+	started := make(chan struct{})
+	cause := make(chan error, 1)
+	q := startQueue(t, memory.NewJobRepository(), fastOptions, map[string]jobs.Handler{
+		"wait": func(ctx context.Context, job *jobs.Job) error {
+			close(started)
+			<-ctx.Done()
+			cause <- context.Cause(ctx)
+			return ctx.Err()
+		},
+		"noop": func(ctx context.Context, job *jobs.Job) error { return nil },
+	})
+	ctx := context.Background()
+
+	delayed, err := q.Enqueue(ctx, "noop", nil, jobs.WithDelay(time.Hour))
+	testutil.AssertNoError(t, err, "enqueue should succeed")
+	cancelled, err := q.Cancel(ctx, delayed.ID)
+	testutil.AssertNoError(t, err, "cancelling a queued job should succeed")
+	testutil.AssertEqual(t, cancelled.State, jobs.StateCancelled, "queued jobs should be cancelled at once")
+
+	running, err := q.Enqueue(ctx, "wait", nil)
+	testutil.AssertNoError(t, err, "enqueue should succeed")
+	<-started
+	_, err = q.Cancel(ctx, running.ID)
+	testutil.AssertNoError(t, err, "cancelling a running job should succeed")
+	testutil.AssertTrue(t, errors.Is(<-cause, jobs.ErrCancelled), "the handler should see the cancellation cause")
+	waitForState(t, q, running.ID, jobs.StateCancelled)
+
+	_, err = q.Cancel(ctx, running.ID)
+	testutil.AssertTrue(t, errors.Is(err, jobs.ErrFinished), "finished jobs cannot be cancelled")
+	_, err = q.Cancel(ctx, uuid.New())
+	testutil.AssertTrue(t, errors.Is(err, jobs.ErrNotFound), "unknown jobs should not be found")
+}
+
+func TestQueue_WorkerPool(t *testing.T) {
+	var running, peak atomic.Int32
+	q := startQueue(t, memory.NewJobRepository(), fastOptions, map[string]jobs.Handler{
+		"work": func(ctx context.Context, job *jobs.Job) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		},
+	})
+
+	ids := make([]uuid.UUID, 6)
+	for i := range ids {
+		job, err := q.Enqueue(context.Background(), "work", nil)
+		testutil.AssertNoError(t, err, "enqueue should succeed")
+		ids[i] = job.ID
+	}
+	for _, id := range ids {
+		waitForState(t, q, id, jobs.StateSucceeded)
+	}
+	testutil.AssertEqual(t, peak.Load(), int32(fastOptions.Workers), "no more jobs than workers should run at once")
+}
+
+func TestQueue_StopDrainsRunningJobs(t *testing.T) {
+	repo := memory.NewJobRepository()
+	started := make(chan struct{})
+	q := jobs.NewQueue(repo, fastOptions)
+	q.Handle("drain", func(ctx context.Context, job *jobs.Job) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	testutil.AssertNoError(t, q.Start(context.Background()), "start should succeed")
+
+	job, err := q.Enqueue(context.Background(), "drain", nil)
+	testutil.AssertNoError(t, err, "enqueue should succeed")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	testutil.AssertNoError(t, q.Stop(ctx), "stop should wait for the running job")
+
+	got, err := repo.Get(context.Background(), job.ID)
+	testutil.AssertNoError(t, err, "get should succeed")
+	testutil.AssertEqual(t, got.State, jobs.StateSucceeded, "the running job should finish before Stop returns")
+}
+
+func TestQueue_StopRequeuesAbortedJobs(t *testing.T) {
+	repo := memory.NewJobRepository()
+	started := make(chan struct{})
+	q := jobs.NewQueue(repo, fastOptions)
+	q.Handle("stuck", func(ctx context.Context, job *jobs.Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	testutil.AssertNoError(t, q.Start(context.Background()), "start should succeed")
+
+	job, err := q.Enqueue(context.Background(), "stuck", nil)
+	testutil.AssertNoError(t, err, "enqueue should succeed")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = q.Stop(ctx)
+	testutil.AssertTrue(t, errors.Is(err, context.DeadlineExceeded), "stop should report that it gave up waiting")
+
+	got, err := repo.Get(context.Background(), job.ID)
+	testutil.AssertNoError(t, err, "get should succeed")
+	testutil.AssertEqual(t, got.State, jobs.StateQueued, "aborted jobs should be queued again")
+	testutil.AssertEqual(t, got.Attempts, 0, "aborted attempts should not count")
+}
+
+func TestQueue_RecoversAfterRestart(t *testing.T) {
+	repo := memory.NewJobRepository()
+	ctx := context.Background()
+	now := time.Now()
+
+	// A job the previous process was running when it died, and one it never
+	// got to.
+	interrupted := &jobs.Job{ID: uuid.New(), Kind: "resume", State: jobs.StateRunning, Attempts: 1, MaxAttempts: 3, RunAt: now, CreatedAt: now, UpdatedAt: now}
+	exhausted := &jobs.Job{ID: uuid.New(), Kind: "resume", State: jobs.StateRunning, Attempts: 3, MaxAttempts: 3, RunAt: now, CreatedAt: now, UpdatedAt: now}
+	testutil.AssertNoError(t, repo.Create(ctx, interrupted), "create should succeed")
+	testutil.AssertNoError(t, repo.Create(ctx, exhausted), "create should succeed")
+
+	before := jobs.NewQueue(repo, fastOptions)
+	before.Handle("resume", func(ctx context.Context, job *jobs.Job) error { return nil })
+	waiting, err := before.Enqueue(ctx, "resume", nil)
+	testutil.AssertNoError(t, err, "enqueue should succeed")
+
+	q := startQueue(t, repo, fastOptions, map[string]jobs.Handler{
+		"resume": func(ctx context.Context, job *jobs.Job) error { return nil },
+	})
+
+	done := waitForState(t, q, interrupted.ID, jobs.StateSucceeded)
+	testutil.AssertEqual(t, done.Attempts, 2, "the interrupted attempt should count")
+	waitForState(t, q, waiting.ID, jobs.StateSucceeded)
+	dead := waitForState(t, q, exhausted.ID, jobs.StateDead)
+	testutil.AssertEqual(t, dead.LastError, "interrupted by a restart", "the interruption should be recorded")
+}
@@ -0,0 +1,85 @@
+package k8s
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// Deployment is an apps/v1 Deployment.
+type Deployment struct {
+	TypeMeta `json:",inline"`
+	Metadata ObjectMeta     `json:"metadata"`
+	Spec     DeploymentSpec `json:"spec"`
+}
+
+// NewDeployment returns a Deployment with its type fields set.
+func NewDeployment(meta ObjectMeta, spec DeploymentSpec) *Deployment {
+	return &Deployment{TypeMeta: TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}, Metadata: meta, Spec: spec}
+}
+
+func (d *Deployment) GetObjectMeta() ObjectMeta {
+	return d.Metadata
+}
+
+// DeploymentSpec describes the desired Pods of a Deployment.
+type DeploymentSpec struct {
+	Replicas int                `json:"replicas"`
+	Selector LabelSelector      `json:"selector"`
+	Strategy DeploymentStrategy `json:"strategy"`
+	Template PodTemplateSpec    `json:"template"`
+}
+
+// LabelSelector selects objects carrying all of MatchLabels.
+type LabelSelector struct {
+	MatchLabels map[string]string `json:"matchLabels"`
+}
+
+// Deployment strategy types.
+const (
+	RollingUpdate = "RollingUpdate"
+	Recreate      = "Recreate"
+)
+
+// DeploymentStrategy is how a Deployment replaces old Pods.
+type DeploymentStrategy struct {
+	Type          string                   `json:"type"`
+	RollingUpdate *RollingUpdateDeployment `json:"rollingUpdate,omitempty"`
+}
+
+// RollingUpdateDeployment bounds the Pods above and below the desired
+// count during a rolling update.
+type RollingUpdateDeployment struct {
+	MaxSurge       *IntOrString `json:"maxSurge,omitempty"`
+	MaxUnavailable *IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// PodTemplateSpec is the template Pods are created from.
+type PodTemplateSpec struct {
+	Metadata TemplateMeta `json:"metadata"`
+	Spec     PodSpec      `json:"spec"`
+}
+
+// TemplateMeta is the metadata of a Pod template, which has no name.
+type TemplateMeta struct {
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// IntOrString is a number or a string such as a percentage, e.g. "25%".
+type IntOrString string
+
+// ParseIntOrString returns s as an IntOrString, or nil if s is empty.
+func ParseIntOrString(s string) *IntOrString {
+	if s == "" {
+		return nil
+	}
+	v := IntOrString(s)
+	return &v
+}
+
+// MarshalJSON encodes values that are whole numbers as JSON numbers.
+func (v IntOrString) MarshalJSON() ([]byte, error) {
+	if n, err := strconv.Atoi(string(v)); err == nil {
+		return json.Marshal(n)
+	}
+	return json.Marshal(string(v))
+}
@@ -0,0 +1,64 @@
+package k8s
+
+// Ingress path types.
+const (
+	PathPrefix = "Prefix"
+	PathExact  = "Exact"
+)
+
+// Ingress is a networking.k8s.io/v1 Ingress.
+type Ingress struct {
+	TypeMeta `json:",inline"`
+	Metadata ObjectMeta  `json:"metadata"`
+	Spec     IngressSpec `json:"spec"`
+}
+
+// NewIngress returns an Ingress with its type fields set.
+func NewIngress(meta ObjectMeta, spec IngressSpec) *Ingress {
+	return &Ingress{TypeMeta: TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"}, Metadata: meta, Spec: spec}
+}
+
+func (i *Ingress) GetObjectMeta() ObjectMeta {
+	return i.Metadata
+}
+
+// IngressSpec routes HTTP requests to Services.
+type IngressSpec struct {
+	IngressClassName string        `json:"ingressClassName,omitempty"`
+	Rules            []IngressRule `json:"rules"`
+}
+
+// IngressRule routes the requests for one host, or for any host when Host
+// is empty.
+type IngressRule struct {
+	Host string               `json:"host,omitempty"`
+	HTTP HTTPIngressRuleValue `json:"http"`
+}
+
+// HTTPIngressRuleValue lists the paths of a rule.
+type HTTPIngressRuleValue struct {
+	Paths []HTTPIngressPath `json:"paths"`
+}
+
+// HTTPIngressPath routes one path to a backend.
+type HTTPIngressPath struct {
+	Path     string         `json:"path"`
+	PathType string         `json:"pathType"`
+	Backend  IngressBackend `json:"backend"`
+}
+
+// IngressBackend is the Service requests are sent to.
+type IngressBackend struct {
+	Service IngressServiceBackend `json:"service"`
+}
+
+// IngressServiceBackend names a Service and one of its ports.
+type IngressServiceBackend struct {
+	Name string             `json:"name"`
+	Port ServiceBackendPort `json:"port"`
+}
+
+// ServiceBackendPort is a Service port by number.
+type ServiceBackendPort struct {
+	Number int `json:"number"`
+}
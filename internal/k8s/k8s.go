@@ -0,0 +1,189 @@
+// Package k8s contains the subset of the Kubernetes object model Mahler
+// renders, and the labels it uses to mark objects it owns.
+//
+// The types mirror the Kubernetes API field names so that rendered objects
+// can be applied as-is, without depending on the Kubernetes client libraries.
+package k8s
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// Ownership labels set on every rendered object.
+const (
+	LabelName      = "app.kubernetes.io/name"
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+	LabelProjectID = "mahler.io/project-id"
+	LabelServiceID = "mahler.io/service-id"
+
+	// ManagedBy is the value of LabelManagedBy.
+	ManagedBy = "mahler"
+
+	// ReservedLabelPrefix marks labels only Mahler may set.
+	ReservedLabelPrefix = "mahler.io/"
+)
+
+var (
+	labelNameRegexp   = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	labelPrefixRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+)
+
+// Owner identifies the service rendered objects belong to.
+type Owner struct {
+	ProjectID   uuid.UUID
+	ServiceID   uuid.UUID
+	ServiceName string
+	// Namespace is set on namespaced objects when not empty.
+	Namespace string
+}
+
+// Labels returns the ownership labels for o.
+func (o Owner) Labels() map[string]string {
+	return map[string]string{
+		LabelName:      o.ServiceName,
+		LabelManagedBy: ManagedBy,
+		LabelProjectID: o.ProjectID.String(),
+		LabelServiceID: o.ServiceID.String(),
+	}
+}
+
+// Selector returns the labels that select the objects of o's service.
+func (o Owner) Selector() map[string]string {
+	return map[string]string{
+		LabelServiceID: o.ServiceID.String(),
+	}
+}
+
+// WorkloadSelector returns the labels that select the Pods of the service
+// called name in o's project. Service names are unique within a project.
+func (o Owner) WorkloadSelector(name string) map[string]string {
+	return map[string]string{
+		LabelProjectID: o.ProjectID.String(),
+		LabelName:      name,
+	}
+}
+
+// Meta returns object metadata named after the service, carrying extra
+// labels merged with the ownership labels. Extra labels must be valid and
+// must not use ReservedLabelPrefix.
+func (o Owner) Meta(extra map[string]string) (ObjectMeta, error) {
+	labels := o.Labels()
+	for key, value := range extra {
+		if err := ValidateLabel(key, value); err != nil {
+			return ObjectMeta{}, err
+		}
+		if strings.HasPrefix(key, ReservedLabelPrefix) || key == LabelManagedBy {
+			return ObjectMeta{}, fmt.Errorf("label %q is reserved for Mahler", key)
+		}
+		labels[key] = value
+	}
+	return ObjectMeta{Name: o.ServiceName, Namespace: o.Namespace, Labels: labels}, nil
+}
+
+// ValidateLabel checks a label against the Kubernetes syntax rules.
+func ValidateLabel(key, value string) error {
+	prefix, name, found := strings.Cut(key, "/")
+	if !found {
+		prefix, name = "", key
+	}
+	if found && (len(prefix) > 253 || !labelPrefixRegexp.MatchString(prefix)) {
+		return fmt.Errorf("label %q: prefix must be a DNS subdomain", key)
+	}
+	if len(name) > 63 || !labelNameRegexp.MatchString(name) {
+		return fmt.Errorf("label %q: name must be at most 63 alphanumeric characters, '-', '_' or '.'", key)
+	}
+	if value != "" && (len(value) > 63 || !labelNameRegexp.MatchString(value)) {
+		return fmt.Errorf("label %q: value %q must be at most 63 alphanumeric characters, '-', '_' or '.'", key, value)
+	}
+	return nil
+}
+
+// Renderer is implemented by resources that deploy as Kubernetes objects.
+type Renderer interface {
+	// Render returns the objects for a service with the given configuration,
+	// in the order they should be applied.
+	Render(owner Owner, config map[string]any) ([]Object, error)
+}
+
+// Object is a renderable Kubernetes object.
+type Object interface {
+	GetTypeMeta() TypeMeta
+	GetObjectMeta() ObjectMeta
+}
+
+// TypeMeta holds the API version and kind of an object.
+type TypeMeta struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+}
+
+// GetTypeMeta returns t. Objects embed TypeMeta and so implement it.
+func (t TypeMeta) GetTypeMeta() TypeMeta {
+	return t
+}
+
+// ObjectMeta is the metadata of an object.
+type ObjectMeta struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// List is a v1 List wrapping several objects so that they can be applied
+// from a single JSON document.
+type List struct {
+	TypeMeta `json:",inline"`
+	Items    []Object `json:"items"`
+}
+
+// JSON renders objects as an indented v1 List.
+func JSON(objects ...Object) ([]byte, error) {
+	if objects == nil {
+		objects = []Object{}
+	}
+	return json.MarshalIndent(List{TypeMeta: TypeMeta{APIVersion: "v1", Kind: "List"}, Items: objects}, "", "  ")
+}
+
+// YAML renders objects as a multi-document YAML stream in the order given,
+// keeping the field order of the JSON encoding.
+func YAML(objects ...Object) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, obj := range objects {
+		b, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		// JSON is YAML; decoding into a node keeps the key order.
+		var node yaml.Node
+		if err := yaml.Unmarshal(b, &node); err != nil {
+			return nil, err
+		}
+		plain(&node)
+		if err := enc.Encode(&node); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// plain drops the JSON quoting and flow styles so the output reads like
+// hand-written YAML. The encoder still quotes strings that need it.
+func plain(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		plain(child)
+	}
+}
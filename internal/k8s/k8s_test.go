@@ -0,0 +1,77 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidateLabel(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{name: "simple", key: "team", value: "web"},
+		{name: "prefixed", key: "example.com/tier", value: "frontend"},
+		{name: "empty value", key: "team", value: ""},
+		{name: "empty key", key: "", value: "web", wantErr: true},
+		{name: "space in value", key: "team", value: "a b", wantErr: true},
+		{name: "value too long", key: "team", value: strings.Repeat("a", 64), wantErr: true},
+		{name: "uppercase prefix", key: "Example.com/tier", value: "x", wantErr: true},
+		{name: "empty name", key: "example.com/", value: "x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLabel(tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLabel(%q, %q) error = %v, wantErr %v", tt.key, tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOwner_Meta(t *testing.T) {
+	owner := Owner{ProjectID: uuid.New(), ServiceID: uuid.New(), ServiceName: "web", Namespace: "shop"}
+
+	meta, err := owner.Meta(map[string]string{"team": "web"})
+	if err != nil {
+		t.Fatalf("Meta() error = %v", err)
+	}
+	if meta.Name != "web" || meta.Namespace != "shop" {
+		t.Errorf("Meta() = %s/%s, want shop/web", meta.Namespace, meta.Name)
+	}
+	if meta.Labels[LabelServiceID] != owner.ServiceID.String() || meta.Labels["team"] != "web" {
+		t.Errorf("Meta() labels = %v", meta.Labels)
+	}
+
+	if _, err := owner.Meta(map[string]string{LabelProjectID: "x"}); err == nil {
+		t.Error("Meta() should reject reserved labels")
+	}
+}
+
+func TestYAML_MultipleDocuments(t *testing.T) {
+	a := NewPod(ObjectMeta{Name: "a"}, PodSpec{Containers: []Container{{Name: "main", Image: "nginx"}}})
+	b := NewPod(ObjectMeta{Name: "b", Labels: map[string]string{"enabled": "true"}}, PodSpec{})
+
+	out, err := YAML(a, b)
+	if err != nil {
+		t.Fatalf("YAML() error = %v", err)
+	}
+	got := string(out)
+	if n := strings.Count(got, "kind: Pod"); n != 2 {
+		t.Errorf("YAML() has %d Pods, want 2:\n%s", n, got)
+	}
+	if !strings.Contains(got, "\n---\n") {
+		t.Errorf("YAML() should separate documents:\n%s", got)
+	}
+	if !strings.HasPrefix(got, "apiVersion: v1\nkind: Pod\n") {
+		t.Errorf("YAML() should keep field order:\n%s", got)
+	}
+	if !strings.Contains(got, `enabled: "true"`) {
+		t.Errorf("YAML() should quote strings that look like booleans:\n%s", got)
+	}
+}
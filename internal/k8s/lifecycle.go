@@ -0,0 +1,181 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Bermos/Platform/internal/resource"
+)
+
+// Lifecycle implements resource.Lifecycle for a Renderer by storing the
+// rendered objects in the provider. Resources embed it:
+//
+//	p := &Pod{}
+//	p.Lifecycle = k8s.NewLifecycle(p)
+type Lifecycle struct {
+	renderer Renderer
+}
+
+var _ resource.Lifecycle = Lifecycle{}
+
+// NewLifecycle returns the lifecycle of the objects r renders.
+func NewLifecycle(r Renderer) Lifecycle {
+	return Lifecycle{renderer: r}
+}
+
+// Plan creates the rendered objects that are missing, updates those whose
+// manifest changed and deletes the service's objects no longer rendered.
+// The plan carries the outputs of the rendered objects, see Outputs.
+func (l Lifecycle) Plan(ctx context.Context, p resource.Provider, req resource.Request) (*resource.Plan, error) {
+	plan, _, err := l.plan(ctx, p, req)
+	return plan, err
+}
+
+// Apply makes the changes of Plan, in order.
+func (l Lifecycle) Apply(ctx context.Context, p resource.Provider, req resource.Request) (*resource.Result, error) {
+	plan, desired, err := l.plan(ctx, p, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &resource.Result{Changes: []resource.Change{}}
+	for _, change := range plan.Changes {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		switch change.Action {
+		case resource.ActionCreate, resource.ActionUpdate:
+			err = p.Put(ctx, desired[change.Object])
+		case resource.ActionDelete:
+			err = p.Delete(ctx, change.Object)
+			if errors.Is(err, resource.ErrObjectNotFound) {
+				err = nil
+			}
+		}
+		if err != nil {
+			return result, fmt.Errorf("%s %s: %w", change.Action, change.Object, err)
+		}
+		result.Changes = append(result.Changes, change)
+	}
+	return result, nil
+}
+
+// Status is missing without objects, failed if any object failed, ready
+// if all are ready and progressing otherwise.
+func (l Lifecycle) Status(ctx context.Context, p resource.Provider, svc resource.ServiceRef) (*resource.Status, error) {
+	objects, err := p.List(ctx, svc.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return &resource.Status{Phase: resource.PhaseMissing}, nil
+	}
+
+	status := &resource.Status{Phase: resource.PhaseReady}
+	for _, obj := range objects {
+		switch obj.Status.Phase {
+		case resource.PhaseFailed:
+			return &resource.Status{Phase: resource.PhaseFailed, Message: objectMessage(obj)}, nil
+		case resource.PhaseReady:
+		default:
+			if status.Phase == resource.PhaseReady {
+				status = &resource.Status{Phase: resource.PhaseProgressing, Message: objectMessage(obj)}
+			}
+		}
+	}
+	return status, nil
+}
+
+// Destroy deletes every object of the service.
+func (l Lifecycle) Destroy(ctx context.Context, p resource.Provider, svc resource.ServiceRef) error {
+	objects, err := p.List(ctx, svc.ServiceID)
+	if err != nil {
+		return err
+	}
+	for i := len(objects) - 1; i >= 0; i-- {
+		key := objects[i].Key
+		if err := p.Delete(ctx, key); err != nil && !errors.Is(err, resource.ErrObjectNotFound) {
+			return fmt.Errorf("delete %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// plan returns the plan for req together with the desired objects by key.
+func (l Lifecycle) plan(ctx context.Context, p resource.Provider, req resource.Request) (*resource.Plan, map[resource.ObjectKey]*resource.Object, error) {
+	if l.renderer == nil {
+		return nil, nil, errors.New("k8s: lifecycle has no renderer")
+	}
+	rendered, err := l.renderer.Render(OwnerOf(req.Service), req.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+	existing, err := p.List(ctx, req.Service.ServiceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	current := make(map[resource.ObjectKey]*resource.Object, len(existing))
+	for _, obj := range existing {
+		current[obj.Key] = obj
+	}
+
+	plan := &resource.Plan{Changes: []resource.Change{}, Outputs: Outputs(rendered)}
+	desired := make(map[resource.ObjectKey]*resource.Object, len(rendered))
+	for _, r := range rendered {
+		obj, err := toObject(req.Service, r)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, dup := desired[obj.Key]; dup {
+			return nil, nil, fmt.Errorf("k8s: %s is rendered more than once", obj.Key)
+		}
+		desired[obj.Key] = obj
+
+		old, ok := current[obj.Key]
+		switch {
+		case !ok:
+			plan.Changes = append(plan.Changes, resource.Change{Action: resource.ActionCreate, Object: obj.Key})
+		case !bytes.Equal(old.Spec, obj.Spec):
+			plan.Changes = append(plan.Changes, resource.Change{Action: resource.ActionUpdate, Object: obj.Key})
+		}
+	}
+	for _, obj := range existing {
+		if _, ok := desired[obj.Key]; !ok {
+			plan.Changes = append(plan.Changes, resource.Change{Action: resource.ActionDelete, Object: obj.Key})
+		}
+	}
+	return plan, desired, nil
+}
+
+// OwnerOf returns the ownership of the objects rendered for svc.
+func OwnerOf(svc resource.ServiceRef) Owner {
+	return Owner{
+		ProjectID:   svc.ProjectID,
+		ServiceID:   svc.ServiceID,
+		ServiceName: svc.Name,
+		Namespace:   svc.Namespace,
+	}
+}
+
+func toObject(svc resource.ServiceRef, obj Object) (*resource.Object, error) {
+	spec, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	meta := obj.GetObjectMeta()
+	return &resource.Object{
+		Key:       resource.ObjectKey{Kind: obj.GetTypeMeta().Kind, Namespace: meta.Namespace, Name: meta.Name},
+		ServiceID: svc.ServiceID,
+		Spec:      spec,
+	}, nil
+}
+
+func objectMessage(obj *resource.Object) string {
+	if obj.Status.Message == "" {
+		return fmt.Sprintf("%s is %s", obj.Key, obj.Status.Phase)
+	}
+	return fmt.Sprintf("%s: %s", obj.Key, obj.Status.Message)
+}
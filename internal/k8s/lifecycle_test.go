@@ -0,0 +1,167 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/provider/fake"
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/google/uuid"
+)
+
+// podsRenderer renders one Pod per name listed in config["pods"], running
+// config["image"].
+type podsRenderer struct{}
+
+func (podsRenderer) Render(owner Owner, config map[string]any) ([]Object, error) {
+	var objects []Object
+	for _, name := range config["pods"].([]string) {
+		meta, err := owner.Meta(nil)
+		if err != nil {
+			return nil, err
+		}
+		meta.Name = name
+		objects = append(objects, NewPod(meta, PodSpec{Containers: []Container{{Name: "main", Image: config["image"].(string)}}}))
+	}
+	return objects, nil
+}
+
+func request(svc resource.ServiceRef, image string, pods ...string) resource.Request {
+	return resource.Request{Service: svc, Config: map[string]any{"image": image, "pods": pods}}
+}
+
+func actions(changes []resource.Change) []string {
+	out := make([]string, len(changes))
+	for i, c := range changes {
+		out[i] = string(c.Action) + " " + c.Object.Name
+	}
+	return out
+}
+
+func assertActions(t *testing.T, got []resource.Change, want ...string) {
+	t.Helper()
+	gotActions := actions(got)
+	if len(gotActions) != len(want) {
+		t.Fatalf("changes = %v, want %v", gotActions, want)
+	}
+	for i := range want {
+		if gotActions[i] != want[i] {
+			t.Errorf("changes = %v, want %v", gotActions, want)
+			return
+		}
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	ctx := context.Background()
+	p := fake.NewProvider()
+	l := NewLifecycle(podsRenderer{})
+	svc := resource.ServiceRef{ProjectID: uuid.New(), ServiceID: uuid.New(), Name: "web", Namespace: "shop"}
+
+	status, err := l.Status(ctx, p, svc)
+	if err != nil || status.Phase != resource.PhaseMissing {
+		t.Fatalf("Status() before apply = %v, %v, want missing", status, err)
+	}
+
+	plan, err := l.Plan(ctx, p, request(svc, "nginx:1", "a", "b"))
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	assertActions(t, plan.Changes, "create a", "create b")
+	if p.Len() != 0 {
+		t.Errorf("Plan() stored %d objects, want none", p.Len())
+	}
+
+	result, err := l.Apply(ctx, p, request(svc, "nginx:1", "a", "b"))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	assertActions(t, result.Changes, "create a", "create b")
+	if status, _ := l.Status(ctx, p, svc); status.Phase != resource.PhaseReady {
+		t.Errorf("Status() after apply = %v, want ready", status)
+	}
+
+	plan, _ = l.Plan(ctx, p, request(svc, "nginx:1", "a", "b"))
+	if !plan.Empty() {
+		t.Errorf("Plan() without changes = %v, want empty", actions(plan.Changes))
+	}
+
+	result, err = l.Apply(ctx, p, request(svc, "nginx:2", "a"))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	assertActions(t, result.Changes, "update a", "delete b")
+
+	if err := l.Destroy(ctx, p, svc); err != nil {
+		t.Fatalf("Destroy() error = %v", err)
+	}
+	if p.Len() != 0 {
+		t.Errorf("Destroy() left %d objects", p.Len())
+	}
+	if err := l.Destroy(ctx, p, svc); err != nil {
+		t.Errorf("Destroy() of a destroyed service error = %v", err)
+	}
+}
+
+func TestLifecycle_Status(t *testing.T) {
+	ctx := context.Background()
+	svc := resource.ServiceRef{ProjectID: uuid.New(), ServiceID: uuid.New(), Name: "web"}
+	key := func(name string) resource.ObjectKey { return resource.ObjectKey{Kind: "Pod", Name: name} }
+
+	tests := []struct {
+		name   string
+		phases map[string]resource.Phase
+		want   resource.Phase
+	}{
+		{name: "all ready", phases: map[string]resource.Phase{"a": resource.PhaseReady, "b": resource.PhaseReady}, want: resource.PhaseReady},
+		{name: "one progressing", phases: map[string]resource.Phase{"a": resource.PhaseReady, "b": resource.PhaseProgressing}, want: resource.PhaseProgressing},
+		{name: "one failed", phases: map[string]resource.Phase{"a": resource.PhaseProgressing, "b": resource.PhaseFailed}, want: resource.PhaseFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := fake.NewProvider()
+			l := NewLifecycle(podsRenderer{})
+			if _, err := l.Apply(ctx, p, request(svc, "nginx", "a", "b")); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			for name, phase := range tt.phases {
+				if err := p.SetStatus(key(name), resource.Status{Phase: phase}); err != nil {
+					t.Fatalf("SetStatus() error = %v", err)
+				}
+			}
+
+			status, err := l.Status(ctx, p, svc)
+			if err != nil {
+				t.Fatalf("Status() error = %v", err)
+			}
+			if status.Phase != tt.want {
+				t.Errorf("Status() = %v, want %s", status, tt.want)
+			}
+		})
+	}
+}
+
+func TestLifecycle_ApplyFailure(t *testing.T) {
+	ctx := context.Background()
+	p := fake.NewProvider()
+	l := NewLifecycle(podsRenderer{})
+	svc := resource.ServiceRef{ServiceID: uuid.New(), Name: "web"}
+	boom := errors.New("cluster unreachable")
+
+	p.FailOn(fake.OpPut, boom)
+	result, err := l.Apply(ctx, p, request(svc, "nginx", "a"))
+	if !errors.Is(err, boom) {
+		t.Fatalf("Apply() error = %v, want %v", err, boom)
+	}
+	if len(result.Changes) != 0 {
+		t.Errorf("Apply() reported %v as made", actions(result.Changes))
+	}
+}
+
+func TestLifecycle_ZeroValue(t *testing.T) {
+	if _, err := (Lifecycle{}).Plan(context.Background(), fake.NewProvider(), resource.Request{}); err == nil {
+		t.Error("Plan() without a renderer should fail")
+	}
+}
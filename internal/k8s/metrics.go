@@ -0,0 +1,17 @@
+package k8s
+
+// Query templates of the resource usage of a service's Pods, for
+// resource.MetricsQuery. cAdvisor's container metrics carry the Pod's name
+// but not its labels, so they are joined with kube-state-metrics'
+// kube_pod_labels on LabelServiceID. kube-state-metrics only exports that
+// label when allowed, e.g. with
+// --metric-labels-allowlist=pods=[mahler.io/service-id].
+const (
+	// CPUQuery is the CPU usage of the service's containers in cores.
+	CPUQuery = `sum(rate(container_cpu_usage_seconds_total{namespace="{{.Namespace}}",container!="",container!="POD"}[5m]) * on(namespace, pod) group_left() ` + servicePods + `)`
+	// MemoryQuery is the working set of the service's containers in bytes.
+	MemoryQuery = `sum(container_memory_working_set_bytes{namespace="{{.Namespace}}",container!="",container!="POD"} * on(namespace, pod) group_left() ` + servicePods + `)`
+
+	// servicePods is 1 for every Pod of the service.
+	servicePods = `max by (namespace, pod) (kube_pod_labels{namespace="{{.Namespace}}",label_mahler_io_service_id="{{.ServiceID}}"})`
+)
@@ -0,0 +1,49 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Outputs returns the values objects expose to their users, named like the
+// outputs of the capabilities the rendering resources provide: the name
+// and namespace of a workload, the in-cluster address of a Service and the
+// address of the first rule of an Ingress that names a host.
+func Outputs(objects []Object) map[string]any {
+	outputs := map[string]any{}
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *Pod, *Deployment:
+			meta := obj.GetObjectMeta()
+			outputs["name"] = meta.Labels[LabelName]
+			outputs["namespace"] = meta.Namespace
+		case *Service:
+			host := o.Metadata.Name
+			if o.Metadata.Namespace != "" {
+				host = fmt.Sprintf("%s.%s.svc.cluster.local", host, o.Metadata.Namespace)
+			}
+			outputs["host"] = host
+			if len(o.Spec.Ports) > 0 {
+				port := o.Spec.Ports[0].Port
+				outputs["port"] = port
+				outputs["url"] = fmt.Sprintf("http://%s:%d", host, port)
+			}
+		case *Ingress:
+			for _, rule := range o.Spec.Rules {
+				// Wildcard hosts name no single address.
+				if rule.Host == "" || strings.HasPrefix(rule.Host, "*") {
+					continue
+				}
+				path := "/"
+				if len(rule.HTTP.Paths) > 0 {
+					path = rule.HTTP.Paths[0].Path
+				}
+				outputs["host"] = rule.Host
+				outputs["port"] = 80
+				outputs["url"] = "http://" + rule.Host + path
+				break
+			}
+		}
+	}
+	return outputs
+}
@@ -0,0 +1,52 @@
+package k8s
+
+import (
+	"testing"
+)
+
+func TestOutputs(t *testing.T) {
+	workload := ObjectMeta{Name: "web", Namespace: "shop", Labels: map[string]string{LabelName: "web"}}
+	tests := []struct {
+		name    string
+		objects []Object
+		want    map[string]any
+	}{
+		{
+			name:    "pod",
+			objects: []Object{NewPod(workload, PodSpec{})},
+			want:    map[string]any{"name": "web", "namespace": "shop"},
+		},
+		{
+			name:    "service",
+			objects: []Object{NewService(ObjectMeta{Name: "api", Namespace: "shop"}, ServiceSpec{Ports: []ServicePort{{Port: 80}, {Port: 443}}})},
+			want:    map[string]any{"host": "api.shop.svc.cluster.local", "port": 80, "url": "http://api.shop.svc.cluster.local:80"},
+		},
+		{
+			name: "ingress",
+			objects: []Object{NewIngress(ObjectMeta{Name: "edge"}, IngressSpec{Rules: []IngressRule{
+				{HTTP: HTTPIngressRuleValue{Paths: []HTTPIngressPath{{Path: "/"}}}},
+				{Host: "*.example.com"},
+				{Host: "shop.example.com", HTTP: HTTPIngressRuleValue{Paths: []HTTPIngressPath{{Path: "/api"}}}},
+			}})},
+			want: map[string]any{"host": "shop.example.com", "port": 80, "url": "http://shop.example.com/api"},
+		},
+		{
+			name:    "ingress_without_host",
+			objects: []Object{NewIngress(ObjectMeta{Name: "edge"}, IngressSpec{Rules: []IngressRule{{}}})},
+			want:    map[string]any{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Outputs(tt.objects)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Outputs() = %v, want %v", got, tt.want)
+			}
+			for name, want := range tt.want {
+				if got[name] != want {
+					t.Errorf("Outputs()[%q] = %v, want %v", name, got[name], want)
+				}
+			}
+		})
+	}
+}
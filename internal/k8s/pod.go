@@ -0,0 +1,53 @@
+package k8s
+
+// Pod is a core/v1 Pod.
+type Pod struct {
+	TypeMeta `json:",inline"`
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     PodSpec    `json:"spec"`
+}
+
+// NewPod returns a Pod with its type fields set.
+func NewPod(meta ObjectMeta, spec PodSpec) *Pod {
+	return &Pod{TypeMeta: TypeMeta{APIVersion: "v1", Kind: "Pod"}, Metadata: meta, Spec: spec}
+}
+
+func (p *Pod) GetObjectMeta() ObjectMeta {
+	return p.Metadata
+}
+
+// PodSpec describes the containers of a Pod.
+type PodSpec struct {
+	Containers    []Container `json:"containers"`
+	RestartPolicy string      `json:"restartPolicy,omitempty"`
+}
+
+// Container is one container of a Pod.
+type Container struct {
+	Name      string                `json:"name"`
+	Image     string                `json:"image"`
+	Command   []string              `json:"command,omitempty"`
+	Env       []EnvVar              `json:"env,omitempty"`
+	Ports     []ContainerPort       `json:"ports,omitempty"`
+	Resources *ResourceRequirements `json:"resources,omitempty"`
+}
+
+// EnvVar is an environment variable of a container.
+type EnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ContainerPort is a port a container listens on.
+type ContainerPort struct {
+	Name          string `json:"name,omitempty"`
+	ContainerPort int    `json:"containerPort"`
+	Protocol      string `json:"protocol,omitempty"`
+}
+
+// ResourceRequirements are the requests and limits of a container, keyed by
+// resource name, e.g. "cpu" or "memory".
+type ResourceRequirements struct {
+	Limits   map[string]string `json:"limits,omitempty"`
+	Requests map[string]string `json:"requests,omitempty"`
+}
@@ -0,0 +1,39 @@
+package k8s
+
+// Service types.
+const (
+	ClusterIP    = "ClusterIP"
+	NodePort     = "NodePort"
+	LoadBalancer = "LoadBalancer"
+)
+
+// Service is a core/v1 Service.
+type Service struct {
+	TypeMeta `json:",inline"`
+	Metadata ObjectMeta  `json:"metadata"`
+	Spec     ServiceSpec `json:"spec"`
+}
+
+// NewService returns a Service with its type fields set.
+func NewService(meta ObjectMeta, spec ServiceSpec) *Service {
+	return &Service{TypeMeta: TypeMeta{APIVersion: "v1", Kind: "Service"}, Metadata: meta, Spec: spec}
+}
+
+func (s *Service) GetObjectMeta() ObjectMeta {
+	return s.Metadata
+}
+
+// ServiceSpec routes the ports to the Pods matching Selector.
+type ServiceSpec struct {
+	Type     string            `json:"type"`
+	Selector map[string]string `json:"selector"`
+	Ports    []ServicePort     `json:"ports"`
+}
+
+// ServicePort maps a Service port to a container port.
+type ServicePort struct {
+	Name       string `json:"name,omitempty"`
+	Port       int    `json:"port"`
+	TargetPort int    `json:"targetPort"`
+	Protocol   string `json:"protocol"`
+}
@@ -0,0 +1,291 @@
+// Package operation tracks long-running changes to services, such as
+// provisioning, so that clients can poll or wait for them.
+package operation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Bermos/Platform/internal/terraform"
+	"github.com/google/uuid"
+)
+
+// Kind is what an operation does.
+type Kind string
+
+const (
+	KindCreateService Kind = "service.create"
+	KindUpdateService Kind = "service.update"
+	KindDeleteService Kind = "service.delete"
+	// KindReconcileService re-applies a service's unchanged configuration,
+	// e.g. to undo drift.
+	KindReconcileService Kind = "service.reconcile"
+)
+
+// State is where an operation is in its lifecycle.
+type State string
+
+// Operation states. Succeeded, failed and cancelled are final.
+const (
+	StatePending State = "pending"
+	StateRunning State = "running"
+	// StateAwaitingApproval operations have a plan that deletes or replaces
+	// something. They continue once approved or end when cancelled.
+	StateAwaitingApproval State = "awaiting_approval"
+	StateSucceeded        State = "succeeded"
+	StateFailed           State = "failed"
+	StateCancelled        State = "cancelled"
+)
+
+// Done reports whether an operation in state s has finished.
+func (s State) Done() bool {
+	switch s {
+	case StateSucceeded, StateFailed, StateCancelled:
+		return true
+	}
+	return false
+}
+
+// StepState is where a step of an operation is.
+type StepState string
+
+const (
+	StepPending   StepState = "pending"
+	StepRunning   StepState = "running"
+	StepSucceeded StepState = "succeeded"
+	StepFailed    StepState = "failed"
+	// StepSkipped steps had nothing to do.
+	StepSkipped StepState = "skipped"
+)
+
+// Step names.
+const (
+	StepPlan    = "plan"
+	StepApply   = "apply"
+	StepVerify  = "verify"
+	StepDestroy = "destroy"
+	StepRemove  = "remove"
+)
+
+// Steps returns the names of the steps an operation of kind k goes
+// through, in order.
+func Steps(k Kind) []string {
+	switch k {
+	case KindCreateService, KindUpdateService, KindReconcileService:
+		return []string{StepPlan, StepApply, StepVerify}
+	case KindDeleteService:
+		return []string{StepDestroy, StepRemove}
+	}
+	return nil
+}
+
+var (
+	// ErrNotFound is returned by a Repository when an operation does not
+	// exist.
+	ErrNotFound = errors.New("operation not found")
+	// ErrAlreadyExists is returned by a Repository when an operation with
+	// the same ID exists.
+	ErrAlreadyExists = errors.New("operation already exists")
+	// ErrUnknownStep is returned when starting or finishing a step the
+	// operation does not have.
+	ErrUnknownStep = errors.New("unknown step")
+	// ErrNotAwaitingApproval is returned when approving an operation that
+	// does not wait for approval.
+	ErrNotAwaitingApproval = errors.New("operation is not awaiting approval")
+	// ErrStateConflict is returned by a Repository when an operation is no
+	// longer in the state it was read in.
+	ErrStateConflict = errors.New("operation state changed concurrently")
+)
+
+// Step is one stage of an operation.
+type Step struct {
+	Name       string     `json:"name"`
+	State      StepState  `json:"state" enum:"pending,running,succeeded,failed,skipped"`
+	Message    string     `json:"message,omitempty"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// LogEntry is one line of an operation's log.
+type LogEntry struct {
+	At      time.Time `json:"at"`
+	Message string    `json:"message"`
+}
+
+// Operation is a change to a service that runs in the background.
+type Operation struct {
+	ID        uuid.UUID
+	Kind      Kind
+	ProjectID uuid.UUID
+	ServiceID uuid.UUID
+	// JobID is the background job running the operation.
+	JobID uuid.UUID
+	State State
+	Steps []Step
+	Logs  []LogEntry
+	// Plan is the diff of the Terraform plan the operation applies, for
+	// services of Terraform modules.
+	Plan *terraform.Diff
+	// ApprovedAt is set when the plan was approved.
+	ApprovedAt *time.Time
+	// Result is set when the operation succeeds. It holds JSON values only.
+	Result map[string]any
+	// Error is set when the operation fails or is cancelled.
+	Error      string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	FinishedAt *time.Time
+}
+
+// New returns a pending operation of kind on a service, with its steps.
+func New(kind Kind, projectID, serviceID uuid.UUID, now time.Time) *Operation {
+	names := Steps(kind)
+	steps := make([]Step, len(names))
+	for i, name := range names {
+		steps[i] = Step{Name: name, State: StepPending}
+	}
+	return &Operation{
+		ID:        uuid.New(),
+		Kind:      kind,
+		ProjectID: projectID,
+		ServiceID: serviceID,
+		JobID:     uuid.New(),
+		State:     StatePending,
+		Steps:     steps,
+		Logs:      []LogEntry{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Progress returns the percentage of steps that succeeded or were skipped.
+func (o *Operation) Progress() int {
+	if o.State == StateSucceeded {
+		return 100
+	}
+	if len(o.Steps) == 0 {
+		return 0
+	}
+	done := 0
+	for _, s := range o.Steps {
+		if s.State == StepSucceeded || s.State == StepSkipped {
+			done++
+		}
+	}
+	return done * 100 / len(o.Steps)
+}
+
+// Logf appends a line to the log.
+func (o *Operation) Logf(at time.Time, format string, args ...any) {
+	o.Logs = append(o.Logs, LogEntry{At: at, Message: fmt.Sprintf(format, args...)})
+	o.UpdatedAt = at
+}
+
+// Start marks the operation as running and resets its steps, so that an
+// interrupted operation starts over. An approved operation keeps its plan
+// step, as the approved plan is what it applies.
+func (o *Operation) Start(at time.Time) {
+	o.State = StateRunning
+	for i := range o.Steps {
+		if o.ApprovedAt != nil && o.Steps[i].Name == StepPlan {
+			continue
+		}
+		o.Steps[i] = Step{Name: o.Steps[i].Name, State: StepPending}
+	}
+	o.UpdatedAt = at
+}
+
+// AwaitApproval stops the operation until its plan is approved.
+func (o *Operation) AwaitApproval(at time.Time) {
+	o.State = StateAwaitingApproval
+	o.UpdatedAt = at
+}
+
+// Approve lets an operation awaiting approval continue. It is pending
+// again, with a new job to run it.
+func (o *Operation) Approve(at time.Time) error {
+	if o.State != StateAwaitingApproval {
+		return fmt.Errorf("%w: it is %s", ErrNotAwaitingApproval, o.State)
+	}
+	o.State = StatePending
+	o.JobID = uuid.New()
+	o.ApprovedAt = &at
+	o.UpdatedAt = at
+	return nil
+}
+
+// StartStep marks a step as running.
+func (o *Operation) StartStep(name string, at time.Time) error {
+	s, err := o.step(name)
+	if err != nil {
+		return err
+	}
+	s.State, s.Message, s.StartedAt, s.FinishedAt = StepRunning, "", &at, nil
+	o.UpdatedAt = at
+	return nil
+}
+
+// FinishStep records the outcome of a step, which need not have been
+// started, e.g. when it is skipped.
+func (o *Operation) FinishStep(name string, state StepState, message string, at time.Time) error {
+	s, err := o.step(name)
+	if err != nil {
+		return err
+	}
+	s.State, s.Message, s.FinishedAt = state, message, &at
+	if s.StartedAt == nil {
+		s.StartedAt = &at
+	}
+	o.UpdatedAt = at
+	return nil
+}
+
+// Finish ends the operation in a final state. A failed or cancelled
+// operation keeps err as its error; steps still pending stay so.
+func (o *Operation) Finish(state State, result map[string]any, err error, at time.Time) {
+	o.State = state
+	o.Result = result
+	o.Error = ""
+	if err != nil {
+		o.Error = err.Error()
+	}
+	for i := range o.Steps {
+		if o.Steps[i].State == StepRunning {
+			o.Steps[i].State = StepFailed
+			o.Steps[i].FinishedAt = &at
+		}
+	}
+	o.FinishedAt = &at
+	o.UpdatedAt = at
+}
+
+func (o *Operation) step(name string) (*Step, error) {
+	for i := range o.Steps {
+		if o.Steps[i].Name == name {
+			return &o.Steps[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q in %s operation", ErrUnknownStep, name, o.Kind)
+}
+
+// Repository persists operations.
+//
+// Implementations must return errors that match ErrNotFound,
+// ErrAlreadyExists and ErrStateConflict with errors.Is. Operations outlive
+// the services they change. Update writes everything but the kind and the
+// project and service IDs, which do not change. The operationtest package
+// contains a conformance suite every implementation is expected to pass.
+type Repository interface {
+	Create(ctx context.Context, op *Operation) error
+	Get(ctx context.Context, id uuid.UUID) (*Operation, error)
+	Update(ctx context.Context, op *Operation) error
+	// UpdateFrom writes op like Update, provided the stored operation is
+	// still in state from, atomically. It fails with ErrStateConflict
+	// otherwise.
+	UpdateFrom(ctx context.Context, op *Operation, from State) error
+	// ListByService returns the operations on one service, oldest first. It
+	// returns an empty, non-nil slice when there are none.
+	ListByService(ctx context.Context, serviceID uuid.UUID) ([]*Operation, error)
+}
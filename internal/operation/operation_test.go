@@ -0,0 +1,80 @@
+package operation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+func TestOperation_Lifecycle(t *testing.T) {
+	now := time.Now()
+	op := New(KindCreateService, uuid.New(), uuid.New(), now)
+	testutil.AssertEqual(t, op.State, StatePending, "new operations should be pending")
+	testutil.AssertEqual(t, len(op.Steps), 3, "service creation should have three steps")
+	testutil.AssertEqual(t, op.Progress(), 0, "nothing should be done yet")
+
+	op.Start(now)
+	testutil.AssertNoError(t, op.StartStep(StepPlan, now), "starting a step should succeed")
+	testutil.AssertNoError(t, op.FinishStep(StepPlan, StepSucceeded, "2 changes", now), "finishing a step should succeed")
+	testutil.AssertNoError(t, op.FinishStep(StepApply, StepSkipped, "", now), "skipping a step should succeed")
+	testutil.AssertEqual(t, op.Progress(), 66, "skipped steps should count as done")
+	testutil.AssertNotNil(t, op.Steps[1].StartedAt, "skipped steps should get a start time")
+
+	testutil.AssertNoError(t, op.StartStep(StepVerify, now), "starting a step should succeed")
+	op.Finish(StateFailed, nil, errors.New("not ready"), now)
+	testutil.AssertEqual(t, op.Steps[2].State, StepFailed, "the running step should fail with the operation")
+	testutil.AssertEqual(t, op.Error, "not ready", "the error should be recorded")
+	testutil.AssertTrue(t, op.State.Done(), "failed operations should be done")
+
+	op.Start(now)
+	testutil.AssertEqual(t, op.Steps[0].State, StepPending, "restarting should reset the steps")
+	testutil.AssertTrue(t, op.Steps[0].StartedAt == nil, "restarting should clear step times")
+	op.Finish(StateSucceeded, map[string]any{"ok": true}, nil, now)
+	testutil.AssertEqual(t, op.Progress(), 100, "succeeded operations should be complete")
+	testutil.AssertEqual(t, op.Error, "", "success should clear the error")
+}
+
+func TestOperation_Approval(t *testing.T) {
+	now := time.Now()
+	op := New(KindUpdateService, uuid.New(), uuid.New(), now)
+	jobID := op.JobID
+	testutil.AssertTrue(t, errors.Is(op.Approve(now), ErrNotAwaitingApproval), "pending operations should not be approved")
+
+	op.Start(now)
+	testutil.AssertNoError(t, op.FinishStep(StepPlan, StepSucceeded, "1 to replace", now), "finishing a step should succeed")
+	op.AwaitApproval(now)
+	testutil.AssertEqual(t, op.State, StateAwaitingApproval, "the operation should wait")
+	testutil.AssertFalse(t, op.State.Done(), "waiting operations should not be done")
+
+	testutil.AssertNoError(t, op.Approve(now), "approval should succeed")
+	testutil.AssertEqual(t, op.State, StatePending, "approved operations should be pending")
+	testutil.AssertNotEqual(t, op.JobID, jobID, "approved operations should get a new job")
+	testutil.AssertNotNil(t, op.ApprovedAt, "the approval should be recorded")
+
+	op.Start(now)
+	testutil.AssertEqual(t, op.Steps[0].State, StepSucceeded, "restarting an approved operation should keep its plan")
+	testutil.AssertEqual(t, op.Steps[1].State, StepPending, "restarting should reset the other steps")
+}
+
+func TestOperation_UnknownStep(t *testing.T) {
+	op := New(KindDeleteService, uuid.New(), uuid.New(), time.Now())
+
+	err := op.StartStep(StepPlan, time.Now())
+	testutil.AssertTrue(t, errors.Is(err, ErrUnknownStep), "deletions should have no plan step")
+}
+
+func TestState_Done(t *testing.T) {
+	for state, want := range map[State]bool{
+		StatePending:          false,
+		StateRunning:          false,
+		StateAwaitingApproval: false,
+		StateSucceeded:        true,
+		StateFailed:           true,
+		StateCancelled:        true,
+	} {
+		testutil.AssertEqual(t, state.Done(), want, "Done of "+string(state))
+	}
+}
@@ -0,0 +1,251 @@
+// Package operationtest provides a conformance suite for
+// operation.Repository implementations.
+package operationtest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/operation"
+	"github.com/Bermos/Platform/internal/terraform"
+	"github.com/google/uuid"
+)
+
+// RunRepositoryTests runs the conformance suite against repositories returned
+// by newRepo. newRepo is called once per subtest and must return an empty
+// repository.
+func RunRepositoryTests(t *testing.T, newRepo func(t *testing.T) operation.Repository) {
+	t.Helper()
+
+	t.Run("create_and_get", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		op := newOperation(uuid.New(), 0)
+
+		if err := repo.Create(ctx, op); err != nil {
+			t.Fatalf("Create: unexpected error: %v", err)
+		}
+		got, err := repo.Get(ctx, op.ID)
+		if err != nil {
+			t.Fatalf("Get: unexpected error: %v", err)
+		}
+		assertOperation(t, got, op)
+	})
+
+	t.Run("create_duplicate_id", func(t *testing.T) {
+		repo := newRepo(t)
+		op := newOperation(uuid.New(), 0)
+		mustCreate(t, repo, op)
+
+		if err := repo.Create(context.Background(), op); !errors.Is(err, operation.ErrAlreadyExists) {
+			t.Errorf("Create with duplicate ID: got %v, want ErrAlreadyExists", err)
+		}
+	})
+
+	t.Run("get_not_found", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if _, err := repo.Get(context.Background(), uuid.New()); !errors.Is(err, operation.ErrNotFound) {
+			t.Errorf("Get of unknown ID: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		op := newOperation(uuid.New(), 0)
+		mustCreate(t, repo, op)
+
+		at := op.CreatedAt.Add(time.Second)
+		op.Start(at)
+		if err := op.FinishStep(operation.StepPlan, operation.StepSucceeded, "1 change", at); err != nil {
+			t.Fatalf("FinishStep: unexpected error: %v", err)
+		}
+		op.Logf(at, "planned %d changes", 1)
+		op.Finish(operation.StateSucceeded, map[string]any{"changes": []any{"create Pod/default/api"}}, nil, at)
+		if err := repo.Update(ctx, op); err != nil {
+			t.Fatalf("Update: unexpected error: %v", err)
+		}
+		got, err := repo.Get(ctx, op.ID)
+		if err != nil {
+			t.Fatalf("Get: unexpected error: %v", err)
+		}
+		assertOperation(t, got, op)
+	})
+
+	t.Run("update_plan_and_approval", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		op := newOperation(uuid.New(), 0)
+		mustCreate(t, repo, op)
+
+		at := op.CreatedAt.Add(time.Second)
+		op.Start(at)
+		op.Plan = &terraform.Diff{Resources: []terraform.ResourceDiff{{
+			Address: "module.service.aws_db_instance.main",
+			Type:    "aws_db_instance",
+			Action:  terraform.DiffReplace,
+			Attributes: []terraform.AttributeChange{
+				{Path: "engine_version", Before: "15.4", After: "16.1", ForcesReplacement: true},
+				{Path: "password", Sensitive: true},
+			},
+		}}}
+		op.AwaitApproval(at)
+		if err := repo.Update(ctx, op); err != nil {
+			t.Fatalf("Update: unexpected error: %v", err)
+		}
+		if err := op.Approve(at.Add(time.Second)); err != nil {
+			t.Fatalf("Approve: unexpected error: %v", err)
+		}
+		if err := repo.Update(ctx, op); err != nil {
+			t.Fatalf("Update: unexpected error: %v", err)
+		}
+		got, err := repo.Get(ctx, op.ID)
+		if err != nil {
+			t.Fatalf("Get: unexpected error: %v", err)
+		}
+		assertOperation(t, got, op)
+	})
+
+	t.Run("update_not_found", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if err := repo.Update(context.Background(), newOperation(uuid.New(), 0)); !errors.Is(err, operation.ErrNotFound) {
+			t.Errorf("Update of unknown ID: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("update_from", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		op := newOperation(uuid.New(), 0)
+		mustCreate(t, repo, op)
+
+		stale := *op
+		op.Start(op.CreatedAt.Add(time.Second))
+		if err := repo.UpdateFrom(ctx, op, operation.StatePending); err != nil {
+			t.Fatalf("UpdateFrom: unexpected error: %v", err)
+		}
+		stale.Finish(operation.StateCancelled, nil, nil, op.CreatedAt.Add(2*time.Second))
+		if err := repo.UpdateFrom(ctx, &stale, operation.StatePending); !errors.Is(err, operation.ErrStateConflict) {
+			t.Errorf("UpdateFrom a stale state: got %v, want ErrStateConflict", err)
+		}
+		got, err := repo.Get(ctx, op.ID)
+		if err != nil {
+			t.Fatalf("Get: unexpected error: %v", err)
+		}
+		assertOperation(t, got, op)
+
+		if err := repo.UpdateFrom(ctx, newOperation(uuid.New(), 0), operation.StatePending); !errors.Is(err, operation.ErrNotFound) {
+			t.Errorf("UpdateFrom of unknown ID: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("list_by_service_filters_and_orders", func(t *testing.T) {
+		repo := newRepo(t)
+		serviceID := uuid.New()
+		second := newOperation(serviceID, time.Second)
+		first := newOperation(serviceID, 0)
+		mustCreate(t, repo, second)
+		mustCreate(t, repo, first)
+		mustCreate(t, repo, newOperation(uuid.New(), 0))
+
+		got, err := repo.ListByService(context.Background(), serviceID)
+		if err != nil {
+			t.Fatalf("ListByService: unexpected error: %v", err)
+		}
+		if len(got) != 2 || got[0].ID != first.ID || got[1].ID != second.ID {
+			t.Errorf("ListByService: got %d operations, want the service's two oldest first", len(got))
+		}
+	})
+
+	t.Run("list_by_service_empty", func(t *testing.T) {
+		repo := newRepo(t)
+
+		got, err := repo.ListByService(context.Background(), uuid.New())
+		if err != nil {
+			t.Fatalf("ListByService: unexpected error: %v", err)
+		}
+		if got == nil || len(got) != 0 {
+			t.Errorf("ListByService of service without operations: got %v, want empty non-nil slice", got)
+		}
+	})
+}
+
+// newOperation returns a pending service creation created offset after now.
+func newOperation(serviceID uuid.UUID, offset time.Duration) *operation.Operation {
+	now := time.Now().UTC().Truncate(time.Microsecond).Add(offset)
+	return operation.New(operation.KindCreateService, uuid.New(), serviceID, now)
+}
+
+func mustCreate(t *testing.T, repo operation.Repository, op *operation.Operation) {
+	t.Helper()
+	if err := repo.Create(context.Background(), op); err != nil {
+		t.Fatalf("Create: unexpected error: %v", err)
+	}
+}
+
+func assertOperation(t *testing.T, got, want *operation.Operation) {
+	t.Helper()
+	if got.ID != want.ID {
+		t.Errorf("ID: got %s, want %s", got.ID, want.ID)
+	}
+	if got.Kind != want.Kind {
+		t.Errorf("Kind: got %q, want %q", got.Kind, want.Kind)
+	}
+	if got.ProjectID != want.ProjectID || got.ServiceID != want.ServiceID || got.JobID != want.JobID {
+		t.Errorf("IDs: got project %s service %s job %s, want %s %s %s",
+			got.ProjectID, got.ServiceID, got.JobID, want.ProjectID, want.ServiceID, want.JobID)
+	}
+	if got.State != want.State {
+		t.Errorf("State: got %q, want %q", got.State, want.State)
+	}
+	if len(got.Steps) != len(want.Steps) {
+		t.Fatalf("Steps: got %d, want %d", len(got.Steps), len(want.Steps))
+	}
+	for i := range want.Steps {
+		g, w := got.Steps[i], want.Steps[i]
+		if g.Name != w.Name || g.State != w.State || g.Message != w.Message || !sameTime(g.StartedAt, w.StartedAt) || !sameTime(g.FinishedAt, w.FinishedAt) {
+			t.Errorf("Steps[%d]: got %+v, want %+v", i, g, w)
+		}
+	}
+	if len(got.Logs) != len(want.Logs) {
+		t.Fatalf("Logs: got %d, want %d", len(got.Logs), len(want.Logs))
+	}
+	for i := range want.Logs {
+		if got.Logs[i].Message != want.Logs[i].Message || !got.Logs[i].At.Equal(want.Logs[i].At) {
+			t.Errorf("Logs[%d]: got %+v, want %+v", i, got.Logs[i], want.Logs[i])
+		}
+	}
+	if !reflect.DeepEqual(got.Plan, want.Plan) {
+		t.Errorf("Plan: got %+v, want %+v", got.Plan, want.Plan)
+	}
+	if !sameTime(got.ApprovedAt, want.ApprovedAt) {
+		t.Errorf("ApprovedAt: got %v, want %v", got.ApprovedAt, want.ApprovedAt)
+	}
+	if !reflect.DeepEqual(got.Result, want.Result) {
+		t.Errorf("Result: got %v, want %v", got.Result, want.Result)
+	}
+	if got.Error != want.Error {
+		t.Errorf("Error: got %q, want %q", got.Error, want.Error)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("CreatedAt: got %v, want %v", got.CreatedAt, want.CreatedAt)
+	}
+	if !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Errorf("UpdatedAt: got %v, want %v", got.UpdatedAt, want.UpdatedAt)
+	}
+	if !sameTime(got.FinishedAt, want.FinishedAt) {
+		t.Errorf("FinishedAt: got %v, want %v", got.FinishedAt, want.FinishedAt)
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -1,12 +1,49 @@
 package project
 
 import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
 	"github.com/Bermos/Platform/internal/service"
 	"github.com/google/uuid"
 )
 
+// MaxNameLength is the longest name a project may have. Names are used as
+// Kubernetes namespaces and labels, which are limited to 63 characters.
+const MaxNameLength = 63
+
+// NamePattern is the pattern every project name must match: lowercase
+// letters, digits and hyphens, starting with a letter and not ending with a
+// hyphen.
+const NamePattern = `^[a-z]([-a-z0-9]*[a-z0-9])?$`
+
+var nameRegexp = regexp.MustCompile(NamePattern)
+
+// ErrInvalidName is returned when a project name does not match NamePattern.
+var ErrInvalidName = errors.New("invalid project name")
+
 type Project struct {
-	ID       uuid.UUID          `json:"id"`
-	Name     string             `json:"name"`
-	Services []*service.Service `json:"services"`
+	ID          uuid.UUID          `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt"`
+	Services    []*service.Service `json:"services,omitempty"`
+}
+
+// ValidateName reports whether name is usable as a project name. The
+// returned error wraps ErrInvalidName.
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: name must not be empty", ErrInvalidName)
+	}
+	if len(name) > MaxNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidName, MaxNameLength)
+	}
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("%w: name must consist of lowercase letters, digits and hyphens, start with a letter and not end with a hyphen", ErrInvalidName)
+	}
+	return nil
 }
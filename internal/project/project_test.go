@@ -0,0 +1,40 @@
+package project
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "simple_name", input: "web", wantErr: false},
+		{name: "name_with_hyphens_and_digits", input: "team-a-2", wantErr: false},
+		{name: "single_letter", input: "a", wantErr: false},
+		{name: "max_length", input: strings.Repeat("a", MaxNameLength), wantErr: false},
+		{name: "empty", input: "", wantErr: true},
+		{name: "too_long", input: strings.Repeat("a", MaxNameLength+1), wantErr: true},
+		{name: "uppercase", input: "Web", wantErr: true},
+		{name: "starts_with_digit", input: "1web", wantErr: true},
+		{name: "ends_with_hyphen", input: "web-", wantErr: true},
+		{name: "contains_space", input: "my project", wantErr: true},
+		{name: "contains_underscore", input: "my_project", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateName(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidName) {
+					t.Errorf("ValidateName(%q) = %v, want ErrInvalidName", tt.input, err)
+				}
+			} else if err != nil {
+				t.Errorf("ValidateName(%q) unexpected error: %v", tt.input, err)
+			}
+		})
+	}
+}
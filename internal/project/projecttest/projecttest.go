@@ -0,0 +1,198 @@
+// Package projecttest provides a conformance suite for project.Repository
+// implementations.
+package projecttest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/project"
+	"github.com/google/uuid"
+)
+
+// RunRepositoryTests runs the conformance suite against repositories returned
+// by newRepo. newRepo is called once per subtest and must return an empty
+// repository.
+func RunRepositoryTests(t *testing.T, newRepo func(t *testing.T) project.Repository) {
+	t.Helper()
+
+	t.Run("create_and_get", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		proj := newProject("shop")
+
+		if err := repo.Create(ctx, proj); err != nil {
+			t.Fatalf("Create: unexpected error: %v", err)
+		}
+		got, err := repo.Get(ctx, proj.ID)
+		if err != nil {
+			t.Fatalf("Get: unexpected error: %v", err)
+		}
+		assertProject(t, got, proj)
+	})
+
+	t.Run("create_duplicate_id", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		proj := newProject("shop")
+		mustCreate(t, repo, proj)
+
+		dup := newProject("other")
+		dup.ID = proj.ID
+		if err := repo.Create(ctx, dup); !errors.Is(err, project.ErrAlreadyExists) {
+			t.Errorf("Create with duplicate ID: got %v, want ErrAlreadyExists", err)
+		}
+	})
+
+	t.Run("create_duplicate_name", func(t *testing.T) {
+		repo := newRepo(t)
+		mustCreate(t, repo, newProject("shop"))
+
+		if err := repo.Create(context.Background(), newProject("shop")); !errors.Is(err, project.ErrAlreadyExists) {
+			t.Errorf("Create with duplicate name: got %v, want ErrAlreadyExists", err)
+		}
+	})
+
+	t.Run("get_not_found", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if _, err := repo.Get(context.Background(), uuid.New()); !errors.Is(err, project.ErrNotFound) {
+			t.Errorf("Get of unknown ID: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		proj := newProject("shop")
+		mustCreate(t, repo, proj)
+
+		changed := *proj
+		changed.Name = "storefront"
+		changed.Description = "Renamed"
+		changed.UpdatedAt = proj.UpdatedAt.Add(time.Minute)
+		if err := repo.Update(ctx, &changed); err != nil {
+			t.Fatalf("Update: unexpected error: %v", err)
+		}
+		got, err := repo.Get(ctx, proj.ID)
+		if err != nil {
+			t.Fatalf("Get: unexpected error: %v", err)
+		}
+		assertProject(t, got, &changed)
+	})
+
+	t.Run("update_not_found", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if err := repo.Update(context.Background(), newProject("shop")); !errors.Is(err, project.ErrNotFound) {
+			t.Errorf("Update of unknown ID: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("update_to_taken_name", func(t *testing.T) {
+		repo := newRepo(t)
+		mustCreate(t, repo, newProject("shop"))
+		other := newProject("other")
+		mustCreate(t, repo, other)
+
+		renamed := *other
+		renamed.Name = "shop"
+		if err := repo.Update(context.Background(), &renamed); !errors.Is(err, project.ErrAlreadyExists) {
+			t.Errorf("Update to a taken name: got %v, want ErrAlreadyExists", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		proj := newProject("shop")
+		mustCreate(t, repo, proj)
+
+		if err := repo.Delete(ctx, proj.ID); err != nil {
+			t.Fatalf("Delete: unexpected error: %v", err)
+		}
+		if _, err := repo.Get(ctx, proj.ID); !errors.Is(err, project.ErrNotFound) {
+			t.Errorf("Get after Delete: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("delete_not_found", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if err := repo.Delete(context.Background(), uuid.New()); !errors.Is(err, project.ErrNotFound) {
+			t.Errorf("Delete of unknown ID: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("list_empty", func(t *testing.T) {
+		repo := newRepo(t)
+
+		got, err := repo.List(context.Background())
+		if err != nil {
+			t.Fatalf("List: unexpected error: %v", err)
+		}
+		if got == nil || len(got) != 0 {
+			t.Errorf("List of empty repository: got %v, want empty non-nil slice", got)
+		}
+	})
+
+	t.Run("list_ordered_by_name", func(t *testing.T) {
+		repo := newRepo(t)
+		for _, name := range []string{"charlie", "alpha", "bravo"} {
+			mustCreate(t, repo, newProject(name))
+		}
+
+		got, err := repo.List(context.Background())
+		if err != nil {
+			t.Fatalf("List: unexpected error: %v", err)
+		}
+		want := []string{"alpha", "bravo", "charlie"}
+		if len(got) != len(want) {
+			t.Fatalf("List: got %d projects, want %d", len(got), len(want))
+		}
+		for i, name := range want {
+			if got[i].Name != name {
+				t.Errorf("List[%d]: got %q, want %q", i, got[i].Name, name)
+			}
+		}
+	})
+}
+
+func newProject(name string) *project.Project {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	return &project.Project{
+		ID:          uuid.New(),
+		Name:        name,
+		Description: "Description of " + name,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func mustCreate(t *testing.T, repo project.Repository, proj *project.Project) {
+	t.Helper()
+	if err := repo.Create(context.Background(), proj); err != nil {
+		t.Fatalf("Create %q: unexpected error: %v", proj.Name, err)
+	}
+}
+
+func assertProject(t *testing.T, got, want *project.Project) {
+	t.Helper()
+	if got.ID != want.ID {
+		t.Errorf("ID: got %s, want %s", got.ID, want.ID)
+	}
+	if got.Name != want.Name {
+		t.Errorf("Name: got %q, want %q", got.Name, want.Name)
+	}
+	if got.Description != want.Description {
+		t.Errorf("Description: got %q, want %q", got.Description, want.Description)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("CreatedAt: got %v, want %v", got.CreatedAt, want.CreatedAt)
+	}
+	if !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Errorf("UpdatedAt: got %v, want %v", got.UpdatedAt, want.UpdatedAt)
+	}
+}
@@ -0,0 +1,32 @@
+package project
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotFound is returned by a Repository when a project does not exist.
+	ErrNotFound = errors.New("project not found")
+	// ErrAlreadyExists is returned by a Repository when a project with the
+	// same ID or name exists.
+	ErrAlreadyExists = errors.New("project already exists")
+)
+
+// Repository persists projects.
+//
+// Implementations must return errors that match ErrNotFound and
+// ErrAlreadyExists with errors.Is, so callers can tell them apart from
+// storage failures. Project names are unique across the repository. The
+// projecttest package contains a conformance suite every implementation is
+// expected to pass.
+type Repository interface {
+	Create(ctx context.Context, proj *Project) error
+	Get(ctx context.Context, id uuid.UUID) (*Project, error)
+	Update(ctx context.Context, proj *Project) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// List returns all projects ordered by name.
+	List(ctx context.Context) ([]*Project, error)
+}
@@ -0,0 +1,123 @@
+// Package fake provides a fake Prometheus server for tests. It answers
+// range queries with the series it was given, whatever the query, and
+// records the queries it received.
+package fake
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Bermos/Platform/internal/prometheus"
+)
+
+// Query is a range query the Server received.
+type Query struct {
+	Query string
+	Start time.Time
+	End   time.Time
+	Step  time.Duration
+}
+
+// Server is a fake Prometheus HTTP API listening on a local address.
+type Server struct {
+	// URL is the base URL of the server, for prometheus.New.
+	URL string
+
+	server  *httptest.Server
+	mu      sync.Mutex
+	series  []prometheus.Series
+	failure *prometheus.Error
+	queries []Query
+}
+
+// NewServer starts a Server answering with no series. Close stops it.
+func NewServer() *Server {
+	s := &Server{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/query_range", s.queryRange)
+	s.server = httptest.NewServer(mux)
+	s.URL = s.server.URL
+	return s
+}
+
+// Close stops the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// SetSeries sets the series range queries are answered with.
+func (s *Server) SetSeries(series ...prometheus.Series) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.series = series
+}
+
+// FailWith makes queries fail with err until it is called with nil.
+func (s *Server) FailWith(err *prometheus.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failure = err
+}
+
+// Queries returns the range queries received so far, oldest first.
+func (s *Server) Queries() []Query {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Query(nil), s.queries...)
+}
+
+func (s *Server) queryRange(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "errorType": "bad_data", "error": err.Error()})
+		return
+	}
+	start, errStart := parseTime(r.Form.Get("start"))
+	end, errEnd := parseTime(r.Form.Get("end"))
+	step, errStep := strconv.ParseFloat(r.Form.Get("step"), 64)
+	if errStart != nil || errEnd != nil || errStep != nil || r.Form.Get("query") == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "errorType": "bad_data", "error": "invalid parameters"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries = append(s.queries, Query{
+		Query: r.Form.Get("query"),
+		Start: start,
+		End:   end,
+		Step:  time.Duration(step * float64(time.Second)),
+	})
+	if s.failure != nil {
+		writeJSON(w, s.failure.StatusCode, map[string]any{"status": "error", "errorType": s.failure.Type, "error": s.failure.Message})
+		return
+	}
+
+	result := make([]map[string]any, len(s.series))
+	for i, series := range s.series {
+		values := make([][2]any, len(series.Points))
+		for j, p := range series.Points {
+			values[j] = [2]any{float64(p.Time.UnixMilli()) / 1000, strconv.FormatFloat(p.Value, 'f', -1, 64)}
+		}
+		result[i] = map[string]any{"metric": series.Labels, "values": values}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": "success", "data": map[string]any{"resultType": "matrix", "result": result}})
+}
+
+func parseTime(s string) (time.Time, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(int64(math.Round(f * 1000))).UTC(), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,51 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/prometheus"
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+func TestServer(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client, err := prometheus.New(server.URL, prometheus.Options{})
+	testutil.AssertNoError(t, err, "creating the client should succeed")
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := prometheus.Range{Start: start, End: start.Add(time.Hour), Step: 30 * time.Second}
+	series, err := client.QueryRange(context.Background(), "up", r)
+	testutil.AssertNoError(t, err, "the query should succeed")
+	testutil.AssertEqual(t, len(series), 0, "no series should be returned by default")
+
+	server.SetSeries(prometheus.Series{
+		Labels: map[string]string{"pod": "web"},
+		Points: []prometheus.Point{{Time: start, Value: 0.5}, {Time: start.Add(30 * time.Second), Value: math.NaN()}},
+	})
+	series, err = client.QueryRange(context.Background(), "sum(up)", r)
+	testutil.AssertNoError(t, err, "the query should succeed")
+	testutil.AssertEqual(t, len(series), 1, "the series should be returned")
+	testutil.AssertEqual(t, series[0].Labels["pod"], "web", "labels should be returned")
+	testutil.AssertTrue(t, series[0].Points[0].Time.Equal(start), "times should be returned")
+	testutil.AssertEqual(t, series[0].Points[0].Value, 0.5, "values should be returned")
+	testutil.AssertTrue(t, math.IsNaN(series[0].Points[1].Value), "NaN should be returned")
+
+	queries := server.Queries()
+	testutil.AssertEqual(t, len(queries), 2, "every query should be recorded")
+	testutil.AssertEqual(t, queries[1].Query, "sum(up)", "the query should be recorded")
+	testutil.AssertTrue(t, queries[1].Start.Equal(r.Start), "the start should be recorded")
+	testutil.AssertTrue(t, queries[1].End.Equal(r.End), "the end should be recorded")
+	testutil.AssertEqual(t, queries[1].Step, r.Step, "the step should be recorded")
+
+	server.FailWith(&prometheus.Error{StatusCode: http.StatusServiceUnavailable, Type: "unavailable", Message: "overloaded"})
+	_, err = client.QueryRange(context.Background(), "up", r)
+	var perr *prometheus.Error
+	testutil.AssertTrue(t, errors.As(err, &perr), "the failure should be returned")
+	testutil.AssertEqual(t, perr.Type, "unavailable", "the error type should be returned")
+}
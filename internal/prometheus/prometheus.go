@@ -0,0 +1,208 @@
+// Package prometheus is a client for the Prometheus HTTP API, covering the
+// range queries service metrics are graphed with.
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds each request unless Options say otherwise.
+const DefaultTimeout = 10 * time.Second
+
+// errorBodyLimit is how much of a response that is not an API response is
+// kept for its Error.
+const errorBodyLimit = 512
+
+// Client queries a Prometheus server, or one with a compatible API such as
+// Thanos or Mimir.
+type Client struct {
+	base *url.URL
+	opts Options
+}
+
+type Options struct {
+	// BearerToken is sent with every request when set.
+	BearerToken string
+	// Timeout bounds each request; 0 means DefaultTimeout.
+	Timeout time.Duration
+	// HTTPClient sends the requests; http.DefaultClient when nil.
+	HTTPClient *http.Client
+}
+
+// New returns a Client for the server at baseURL, e.g.
+// http://prometheus:9090.
+func New(baseURL string, opts Options) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("prometheus URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("prometheus URL must be an absolute http or https URL, got %q", baseURL)
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &Client{base: u, opts: opts}, nil
+}
+
+// Range is the time range of a range query, evaluated every Step from
+// Start to End.
+type Range struct {
+	Start time.Time
+	End   time.Time
+	Step  time.Duration
+}
+
+// Series is one time series of a query result.
+type Series struct {
+	Labels map[string]string
+	Points []Point
+}
+
+// Point is the value of a series at one time. Value may be NaN or infinite.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Error is an error reported by the server.
+type Error struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Type is the API's error type, e.g. bad_data or timeout, or empty
+	// when the response was not an API response.
+	Type    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("prometheus: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+	}
+	return fmt.Sprintf("prometheus: %s: %s", e.Type, e.Message)
+}
+
+// QueryRange evaluates query over r and returns the resulting series.
+func (c *Client) QueryRange(ctx context.Context, query string, r Range) ([]Series, error) {
+	form := url.Values{
+		"query": {query},
+		"start": {formatTime(r.Start)},
+		"end":   {formatTime(r.End)},
+		"step":  {strconv.FormatFloat(r.Step.Seconds(), 'f', -1, 64)},
+	}
+	var data struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string    `json:"metric"`
+			Values [][2]json.RawMessage `json:"values"`
+		} `json:"result"`
+	}
+	if err := c.post(ctx, "api/v1/query_range", form, &data); err != nil {
+		return nil, err
+	}
+	if data.ResultType != "matrix" {
+		return nil, fmt.Errorf("prometheus: range query returned a %s, not a matrix", data.ResultType)
+	}
+
+	series := make([]Series, len(data.Result))
+	for i, res := range data.Result {
+		s := Series{Labels: res.Metric, Points: make([]Point, len(res.Values))}
+		if s.Labels == nil {
+			s.Labels = map[string]string{}
+		}
+		for j, v := range res.Values {
+			p, err := parsePoint(v)
+			if err != nil {
+				return nil, fmt.Errorf("prometheus: decoding sample: %w", err)
+			}
+			s.Points[j] = p
+		}
+		series[i] = s
+	}
+	return series, nil
+}
+
+// post sends form to the API endpoint at path and decodes the data of a
+// successful response into out.
+func (c *Client) post(ctx context.Context, path string, form url.Values, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base.JoinPath(path).String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.opts.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.opts.BearerToken)
+	}
+	resp, err := c.opts.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("prometheus: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("prometheus: reading response: %w", err)
+	}
+
+	var apiResp struct {
+		Status    string          `json:"status"`
+		Data      json.RawMessage `json:"data"`
+		ErrorType string          `json:"errorType"`
+		Error     string          `json:"error"`
+	}
+	if err := json.Unmarshal(body, &apiResp); err != nil || apiResp.Status == "" {
+		// Proxies in front of the server answer in their own format.
+		msg := strings.TrimSpace(string(body))
+		if len(msg) > errorBodyLimit {
+			msg = msg[:errorBodyLimit]
+		}
+		return &Error{StatusCode: resp.StatusCode, Message: msg}
+	}
+	if apiResp.Status != "success" {
+		return &Error{StatusCode: resp.StatusCode, Type: apiResp.ErrorType, Message: apiResp.Error}
+	}
+	if err := json.Unmarshal(apiResp.Data, out); err != nil {
+		return fmt.Errorf("prometheus: decoding response: %w", err)
+	}
+	return nil
+}
+
+// parsePoint decodes a sample, a Unix time in seconds and the value as a
+// string, e.g. [1700000000.5, "0.25"].
+func parsePoint(v [2]json.RawMessage) (Point, error) {
+	var ts float64
+	if err := json.Unmarshal(v[0], &ts); err != nil {
+		return Point{}, err
+	}
+	var s string
+	if err := json.Unmarshal(v[1], &s); err != nil {
+		return Point{}, err
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return Point{}, err
+	}
+	// Samples have millisecond precision.
+	return Point{Time: time.UnixMilli(int64(math.Round(ts * 1000))).UTC(), Value: value}, nil
+}
+
+// formatTime formats t as a Unix time in seconds, the API's most precise
+// format.
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixMilli())/1000, 'f', -1, 64)
+}
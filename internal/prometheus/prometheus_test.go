@@ -0,0 +1,104 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+func TestNew_InvalidURL(t *testing.T) {
+	for _, raw := range []string{"prometheus:9090", "ftp://prometheus", "/api", "http://%zz"} {
+		_, err := New(raw, Options{})
+		testutil.AssertError(t, err, "a URL that is not absolute http should be rejected: "+raw)
+	}
+}
+
+func TestClient_QueryRange(t *testing.T) {
+	var got http.Header
+	var form map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testutil.AssertEqual(t, r.URL.Path, "/prom/api/v1/query_range", "the range query endpoint should be used below the base path")
+		testutil.AssertNoError(t, r.ParseForm(), "the form should parse")
+		got = r.Header
+		form = map[string]string{}
+		for k := range r.PostForm {
+			form[k] = r.PostForm.Get(k)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"pod":"web"},"values":[[1700000000,"0.25"],[1700000030.5,"NaN"]]},
+			{"metric":{},"values":[]}
+		]}}`))
+	}))
+	defer server.Close()
+
+	client, err := New(server.URL+"/prom", Options{BearerToken: "secret"})
+	testutil.AssertNoError(t, err, "creating the client should succeed")
+	start := time.Unix(1700000000, 0)
+	series, err := client.QueryRange(context.Background(), "up", Range{Start: start, End: start.Add(time.Hour), Step: 30 * time.Second})
+	testutil.AssertNoError(t, err, "the query should succeed")
+
+	testutil.AssertEqual(t, got.Get("Authorization"), "Bearer secret", "the bearer token should be sent")
+	testutil.AssertEqual(t, form["query"], "up", "the query should be sent")
+	testutil.AssertEqual(t, form["start"], "1700000000", "the start should be a Unix time")
+	testutil.AssertEqual(t, form["end"], "1700003600", "the end should be a Unix time")
+	testutil.AssertEqual(t, form["step"], "30", "the step should be in seconds")
+
+	testutil.AssertEqual(t, len(series), 2, "every series should be returned")
+	testutil.AssertEqual(t, series[0].Labels["pod"], "web", "labels should be kept")
+	testutil.AssertEqual(t, len(series[0].Points), 2, "every sample should be returned")
+	testutil.AssertEqual(t, series[0].Points[0].Value, 0.25, "values should be parsed")
+	testutil.AssertTrue(t, series[0].Points[0].Time.Equal(start), "times should be parsed")
+	testutil.AssertTrue(t, series[0].Points[1].Time.Equal(start.Add(30500*time.Millisecond)), "fractional times should be parsed")
+	testutil.AssertTrue(t, math.IsNaN(series[0].Points[1].Value), "NaN should be parsed")
+	testutil.AssertNotNil(t, series[1].Labels, "labels should never be nil")
+}
+
+func TestClient_QueryRangeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantType string
+	}{
+		{name: "api_error", status: http.StatusBadRequest, body: `{"status":"error","errorType":"bad_data","error":"parse error"}`, wantType: "bad_data"},
+		{name: "proxy_error", status: http.StatusBadGateway, body: "<html>bad gateway</html>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, err := New(server.URL, Options{})
+			testutil.AssertNoError(t, err, "creating the client should succeed")
+			_, err = client.QueryRange(context.Background(), "up", Range{Start: time.Now().Add(-time.Hour), End: time.Now(), Step: time.Minute})
+			var perr *Error
+			testutil.AssertTrue(t, errors.As(err, &perr), "the server's error should be returned")
+			testutil.AssertEqual(t, perr.StatusCode, tt.status, "the status should be kept")
+			testutil.AssertEqual(t, perr.Type, tt.wantType, "the error type should be kept")
+		})
+	}
+}
+
+func TestClient_QueryRangeTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := New(server.URL, Options{Timeout: 10 * time.Millisecond})
+	testutil.AssertNoError(t, err, "creating the client should succeed")
+	_, err = client.QueryRange(context.Background(), "up", Range{Start: time.Now().Add(-time.Hour), End: time.Now(), Step: time.Minute})
+	testutil.AssertTrue(t, errors.Is(err, context.DeadlineExceeded), "a slow server should time out")
+}
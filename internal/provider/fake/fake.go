@@ -0,0 +1,148 @@
+// Package fake provides an in-memory resource.Provider for tests and local
+// development. Objects become ready as soon as they are stored unless
+// configured otherwise.
+package fake
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/google/uuid"
+)
+
+// Operations a Provider can be told to fail with FailOn.
+const (
+	OpGet    = "get"
+	OpPut    = "put"
+	OpDelete = "delete"
+	OpList   = "list"
+)
+
+// Provider keeps objects in memory.
+type Provider struct {
+	mu      sync.Mutex
+	objects map[resource.ObjectKey]*resource.Object
+	// initial is the status given to stored objects.
+	initial  resource.Status
+	failures map[string]error
+}
+
+var _ resource.Provider = (*Provider)(nil)
+
+// NewProvider returns an empty Provider whose objects are ready when stored.
+func NewProvider() *Provider {
+	return &Provider{
+		objects:  make(map[resource.ObjectKey]*resource.Object),
+		initial:  resource.Status{Phase: resource.PhaseReady},
+		failures: make(map[string]error),
+	}
+}
+
+// SetInitialStatus sets the status of objects stored from now on, e.g.
+// PhaseProgressing to simulate a slow rollout.
+func (p *Provider) SetInitialStatus(s resource.Status) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.initial = s
+}
+
+// SetStatus changes the status of a stored object, or returns
+// resource.ErrObjectNotFound.
+func (p *Provider) SetStatus(key resource.ObjectKey, s resource.Status) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	obj, ok := p.objects[key]
+	if !ok {
+		return resource.ErrObjectNotFound
+	}
+	obj.Status = s
+	return nil
+}
+
+// FailOn makes every call of op return err until it is reset with a nil err.
+func (p *Provider) FailOn(op string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil {
+		delete(p.failures, op)
+		return
+	}
+	p.failures[op] = err
+}
+
+// Len returns the number of stored objects.
+func (p *Provider) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.objects)
+}
+
+func (p *Provider) Get(_ context.Context, key resource.ObjectKey) (*resource.Object, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.failures[OpGet]; err != nil {
+		return nil, err
+	}
+	obj, ok := p.objects[key]
+	if !ok {
+		return nil, resource.ErrObjectNotFound
+	}
+	return cloneObject(obj), nil
+}
+
+// Put stores a copy of obj. Replacing an object with an identical spec keeps
+// its status; any other change resets it to the initial status.
+func (p *Provider) Put(_ context.Context, obj *resource.Object) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.failures[OpPut]; err != nil {
+		return err
+	}
+	stored := cloneObject(obj)
+	stored.Status = p.initial
+	if old, ok := p.objects[obj.Key]; ok && bytes.Equal(old.Spec, obj.Spec) {
+		stored.Status = old.Status
+	}
+	p.objects[obj.Key] = stored
+	return nil
+}
+
+func (p *Provider) Delete(_ context.Context, key resource.ObjectKey) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.failures[OpDelete]; err != nil {
+		return err
+	}
+	if _, ok := p.objects[key]; !ok {
+		return resource.ErrObjectNotFound
+	}
+	delete(p.objects, key)
+	return nil
+}
+
+func (p *Provider) List(_ context.Context, serviceID uuid.UUID) ([]*resource.Object, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.failures[OpList]; err != nil {
+		return nil, err
+	}
+	objects := []*resource.Object{}
+	for _, obj := range p.objects {
+		if obj.ServiceID == serviceID {
+			objects = append(objects, cloneObject(obj))
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Key.String() < objects[j].Key.String()
+	})
+	return objects, nil
+}
+
+func cloneObject(obj *resource.Object) *resource.Object {
+	cp := *obj
+	cp.Spec = bytes.Clone(obj.Spec)
+	return &cp
+}
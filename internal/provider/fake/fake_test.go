@@ -0,0 +1,92 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+func newObject(serviceID uuid.UUID, name, spec string) *resource.Object {
+	return &resource.Object{
+		Key:       resource.ObjectKey{Kind: "Pod", Namespace: "default", Name: name},
+		ServiceID: serviceID,
+		Spec:      []byte(spec),
+	}
+}
+
+func TestProvider_CRUD(t *testing.T) {
+	ctx := context.Background()
+	p := NewProvider()
+	svc, other := uuid.New(), uuid.New()
+
+	testutil.AssertNoError(t, p.Put(ctx, newObject(svc, "b", `{}`)), "put should succeed")
+	testutil.AssertNoError(t, p.Put(ctx, newObject(svc, "a", `{}`)), "put should succeed")
+	testutil.AssertNoError(t, p.Put(ctx, newObject(other, "c", `{}`)), "put should succeed")
+
+	got, err := p.Get(ctx, resource.ObjectKey{Kind: "Pod", Namespace: "default", Name: "a"})
+	testutil.AssertNoError(t, err, "get should succeed")
+	testutil.AssertEqual(t, got.Status.Phase, resource.PhaseReady, "objects should be ready when stored")
+
+	list, err := p.List(ctx, svc)
+	testutil.AssertNoError(t, err, "list should succeed")
+	testutil.AssertEqual(t, len(list), 2, "list should only return the service's objects")
+	testutil.AssertEqual(t, list[0].Key.Name, "a", "list should be ordered by key")
+
+	testutil.AssertNoError(t, p.Delete(ctx, got.Key), "delete should succeed")
+	_, err = p.Get(ctx, got.Key)
+	testutil.AssertTrue(t, errors.Is(err, resource.ErrObjectNotFound), "deleted object should be gone")
+	testutil.AssertTrue(t, errors.Is(p.Delete(ctx, got.Key), resource.ErrObjectNotFound), "deleting twice should be not found")
+	testutil.AssertEqual(t, p.Len(), 2, "two objects should remain")
+}
+
+func TestProvider_Status(t *testing.T) {
+	ctx := context.Background()
+	p := NewProvider()
+	p.SetInitialStatus(resource.Status{Phase: resource.PhaseProgressing})
+	obj := newObject(uuid.New(), "web", `{"v":1}`)
+
+	testutil.AssertNoError(t, p.Put(ctx, obj), "put should succeed")
+	got, _ := p.Get(ctx, obj.Key)
+	testutil.AssertEqual(t, got.Status.Phase, resource.PhaseProgressing, "initial status should be applied")
+
+	testutil.AssertNoError(t, p.SetStatus(obj.Key, resource.Status{Phase: resource.PhaseReady}), "set status should succeed")
+	testutil.AssertNoError(t, p.Put(ctx, obj), "put should succeed")
+	got, _ = p.Get(ctx, obj.Key)
+	testutil.AssertEqual(t, got.Status.Phase, resource.PhaseReady, "an unchanged spec should keep its status")
+
+	obj.Spec = []byte(`{"v":2}`)
+	testutil.AssertNoError(t, p.Put(ctx, obj), "put should succeed")
+	got, _ = p.Get(ctx, obj.Key)
+	testutil.AssertEqual(t, got.Status.Phase, resource.PhaseProgressing, "a changed spec should reset the status")
+
+	err := p.SetStatus(resource.ObjectKey{Kind: "Pod", Name: "missing"}, resource.Status{Phase: resource.PhaseReady})
+	testutil.AssertTrue(t, errors.Is(err, resource.ErrObjectNotFound), "setting the status of a missing object should fail")
+}
+
+func TestProvider_FailOn(t *testing.T) {
+	ctx := context.Background()
+	p := NewProvider()
+	boom := errors.New("boom")
+
+	p.FailOn(OpPut, boom)
+	testutil.AssertTrue(t, errors.Is(p.Put(ctx, newObject(uuid.New(), "web", `{}`)), boom), "put should fail")
+	testutil.AssertEqual(t, p.Len(), 0, "failed put should store nothing")
+
+	p.FailOn(OpPut, nil)
+	testutil.AssertNoError(t, p.Put(ctx, newObject(uuid.New(), "web", `{}`)), "put should succeed after reset")
+}
+
+func TestProvider_StoresCopies(t *testing.T) {
+	ctx := context.Background()
+	p := NewProvider()
+	obj := newObject(uuid.New(), "web", `{"v":1}`)
+	testutil.AssertNoError(t, p.Put(ctx, obj), "put should succeed")
+
+	obj.Spec[0] = 'x'
+	got, _ := p.Get(ctx, obj.Key)
+	testutil.AssertEqual(t, string(got.Spec), `{"v":1}`, "stored spec should not change with caller's copy")
+}
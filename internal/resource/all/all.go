@@ -0,0 +1,11 @@
+// Package all registers every built-in resource type with
+// resource.DefaultRegistry. Import it for its side effects; new resource
+// packages are added here.
+package all
+
+import (
+	_ "github.com/Bermos/Platform/internal/resource/k8s-deployment"
+	_ "github.com/Bermos/Platform/internal/resource/k8s-ingress"
+	_ "github.com/Bermos/Platform/internal/resource/k8s-pod"
+	_ "github.com/Bermos/Platform/internal/resource/k8s-service"
+)
@@ -0,0 +1,119 @@
+package resource
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// CapabilityType names something a resource offers to other services, e.g.
+// an HTTP endpoint or a Postgres connection.
+type CapabilityType string
+
+const (
+	HTTPEndpoint       CapabilityType = "http-endpoint"
+	PostgresConnection CapabilityType = "postgres-connection"
+	ObjectBucket       CapabilityType = "object-bucket"
+	// KubernetesWorkload is a set of Pods in the cluster, such as those of a
+	// Deployment, that a Kubernetes Service can route to.
+	KubernetesWorkload CapabilityType = "k8s-workload"
+)
+
+// OutputType is the type of a capability output's value.
+type OutputType string
+
+const (
+	OutputString  OutputType = "string"
+	OutputNumber  OutputType = "number"
+	OutputBoolean OutputType = "boolean"
+)
+
+// Output is one named value a capability exposes, e.g. the host of a
+// database.
+type Output struct {
+	Name        string     `json:"name"`
+	Type        OutputType `json:"type" enum:"string,number,boolean"`
+	Sensitive   bool       `json:"sensitive,omitempty" doc:"Whether the value is a secret"`
+	Description string     `json:"description,omitempty"`
+}
+
+// Capability is something a resource provides, together with the outputs a
+// consumer can read from it.
+type Capability struct {
+	Type    CapabilityType `json:"type"`
+	Outputs []Output       `json:"outputs"`
+}
+
+// Requirement is a capability a resource needs another service in the same
+// project to provide.
+type Requirement struct {
+	Type CapabilityType `json:"type"`
+	// Optional requirements are used when available but do not block
+	// creating the service.
+	Optional bool `json:"optional,omitempty"`
+}
+
+var standardOutputs = map[CapabilityType][]Output{
+	HTTPEndpoint: {
+		{Name: "url", Type: OutputString, Description: "Base URL including scheme and port"},
+		{Name: "host", Type: OutputString},
+		{Name: "port", Type: OutputNumber},
+	},
+	PostgresConnection: {
+		{Name: "host", Type: OutputString},
+		{Name: "port", Type: OutputNumber},
+		{Name: "database", Type: OutputString},
+		{Name: "username", Type: OutputString},
+		{Name: "password", Type: OutputString, Sensitive: true},
+	},
+	ObjectBucket: {
+		{Name: "endpoint", Type: OutputString},
+		{Name: "bucket", Type: OutputString},
+		{Name: "region", Type: OutputString},
+		{Name: "access_key_id", Type: OutputString},
+		{Name: "secret_access_key", Type: OutputString, Sensitive: true},
+	},
+	KubernetesWorkload: {
+		{Name: "name", Type: OutputString, Description: "Value of the app.kubernetes.io/name label of the Pods"},
+		{Name: "namespace", Type: OutputString},
+	},
+}
+
+// Provide returns the capability of a well-known type with its standard
+// outputs. It panics for types without standard outputs; construct those
+// capabilities directly.
+func Provide(t CapabilityType) Capability {
+	outputs, ok := standardOutputs[t]
+	if !ok {
+		panic(fmt.Sprintf("resource: no standard outputs for capability %q", t))
+	}
+	return Capability{Type: t, Outputs: append([]Output(nil), outputs...)}
+}
+
+// StandardTypes lists the capability types with standard outputs, ordered
+// by type.
+func StandardTypes() []CapabilityType {
+	return slices.Sorted(maps.Keys(standardOutputs))
+}
+
+// Require returns a mandatory requirement for t.
+func Require(t CapabilityType) Requirement {
+	return Requirement{Type: t}
+}
+
+// Unsatisfied returns the mandatory requirements none of the available
+// capabilities fulfils.
+func Unsatisfied(requires []Requirement, available []Capability) []Requirement {
+	provided := make(map[CapabilityType]bool, len(available))
+	for _, c := range available {
+		provided[c.Type] = true
+	}
+
+	var missing []Requirement
+	for _, req := range requires {
+		if !req.Optional && !provided[req.Type] {
+			missing = append(missing, req)
+		}
+	}
+	return missing
+}
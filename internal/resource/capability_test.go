@@ -0,0 +1,98 @@
+package resource_test
+
+import (
+	"testing"
+
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+func TestProvide(t *testing.T) {
+	tests := []struct {
+		typ           resource.CapabilityType
+		wantOutputs   int
+		wantSensitive string
+	}{
+		{typ: resource.HTTPEndpoint, wantOutputs: 3},
+		{typ: resource.PostgresConnection, wantOutputs: 5, wantSensitive: "password"},
+		{typ: resource.ObjectBucket, wantOutputs: 5, wantSensitive: "secret_access_key"},
+		{typ: resource.KubernetesWorkload, wantOutputs: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.typ), func(t *testing.T) {
+			c := resource.Provide(tt.typ)
+			testutil.AssertEqual(t, c.Type, tt.typ, "type should match")
+			testutil.AssertEqual(t, len(c.Outputs), tt.wantOutputs, "standard outputs should be included")
+
+			sensitive := ""
+			for _, o := range c.Outputs {
+				if o.Sensitive {
+					sensitive = o.Name
+				}
+			}
+			testutil.AssertEqual(t, sensitive, tt.wantSensitive, "secrets should be marked sensitive")
+		})
+	}
+}
+
+func TestProvide_ReturnsCopies(t *testing.T) {
+	c := resource.Provide(resource.HTTPEndpoint)
+	c.Outputs[0].Name = "changed"
+
+	testutil.AssertEqual(t, resource.Provide(resource.HTTPEndpoint).Outputs[0].Name, "url", "standard outputs should not be shared")
+}
+
+func TestProvide_PanicsForUnknownType(t *testing.T) {
+	defer func() {
+		testutil.AssertNotNil(t, recover(), "unknown capability type should panic")
+	}()
+	resource.Provide("teleporter")
+}
+
+func TestStandardTypes(t *testing.T) {
+	types := resource.StandardTypes()
+	testutil.AssertEqual(t, len(types), 4, "every well-known type should be listed")
+	testutil.AssertEqual(t, types[0], resource.HTTPEndpoint, "types should be ordered")
+	for _, typ := range types {
+		testutil.AssertEqual(t, resource.Provide(typ).Type, typ, "listed types should have standard outputs")
+	}
+}
+
+func TestUnsatisfied(t *testing.T) {
+	available := []resource.Capability{
+		resource.Provide(resource.HTTPEndpoint),
+		resource.Provide(resource.PostgresConnection),
+	}
+
+	tests := []struct {
+		name     string
+		requires []resource.Requirement
+		want     []resource.CapabilityType
+	}{
+		{name: "nothing_required"},
+		{
+			name:     "all_provided",
+			requires: []resource.Requirement{resource.Require(resource.PostgresConnection), resource.Require(resource.HTTPEndpoint)},
+		},
+		{
+			name:     "missing",
+			requires: []resource.Requirement{resource.Require(resource.PostgresConnection), resource.Require(resource.ObjectBucket)},
+			want:     []resource.CapabilityType{resource.ObjectBucket},
+		},
+		{
+			name:     "optional_missing",
+			requires: []resource.Requirement{{Type: resource.ObjectBucket, Optional: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resource.Unsatisfied(tt.requires, available)
+			testutil.AssertEqual(t, len(got), len(tt.want), "number of unsatisfied requirements should match")
+			for i, typ := range tt.want {
+				testutil.AssertEqual(t, got[i].Type, typ, "unsatisfied requirement should match")
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package resource
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Catalog is the set of resources an instance offers, at most one per type.
+// A nil Catalog is empty.
+type Catalog struct {
+	entries []catalogItem
+}
+
+type catalogItem struct {
+	resource Resource
+	version  int
+}
+
+// NewCatalog returns a catalog offering resources, each as version 1.
+func NewCatalog(resources ...Resource) (*Catalog, error) {
+	c := &Catalog{}
+	for _, res := range resources {
+		if err := c.Add(res, 1); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Add offers res at the given type version. Its key must not be offered yet.
+func (c *Catalog) Add(res Resource, version int) error {
+	if c.Get(res.Key()) != nil {
+		return fmt.Errorf("resource type %s is already in the catalog", res.Key())
+	}
+	c.entries = append(c.entries, catalogItem{resource: res, version: version})
+	sort.Slice(c.entries, func(i, j int) bool {
+		return c.entries[i].resource.Key() < c.entries[j].resource.Key()
+	})
+	return nil
+}
+
+// Get returns the resource with the given key, or nil.
+func (c *Catalog) Get(key string) Resource {
+	if c == nil {
+		return nil
+	}
+	for _, e := range c.entries {
+		if e.resource.Key() == key {
+			return e.resource
+		}
+	}
+	return nil
+}
+
+// Version returns the type version the resource with the given key is
+// offered at, or 0 if it is not offered.
+func (c *Catalog) Version(key string) int {
+	if c == nil {
+		return 0
+	}
+	for _, e := range c.entries {
+		if e.resource.Key() == key {
+			return e.version
+		}
+	}
+	return 0
+}
+
+// Resources lists the offered resources ordered by key.
+func (c *Catalog) Resources() []Resource {
+	if c == nil {
+		return []Resource{}
+	}
+	resources := make([]Resource, len(c.entries))
+	for i, e := range c.entries {
+		resources[i] = e.resource
+	}
+	return resources
+}
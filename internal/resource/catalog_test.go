@@ -0,0 +1,35 @@
+package resource_test
+
+import (
+	"testing"
+
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+func TestCatalog(t *testing.T) {
+	catalog, err := resource.NewCatalog(
+		testutil.NewMockResource().WithKey("vm"),
+		testutil.NewMockResource().WithKey("db"),
+	)
+	testutil.AssertNoError(t, err, "catalog should build")
+
+	resources := catalog.Resources()
+	testutil.AssertEqual(t, len(resources), 2, "both resources should be offered")
+	testutil.AssertEqual(t, resources[0].Key(), "db", "resources should be ordered by key")
+	testutil.AssertEqual(t, catalog.Get("vm").Key(), "vm", "get should find offered resources")
+	testutil.AssertNil(t, catalog.Get("mainframe"), "get should return nil for unknown keys")
+	testutil.AssertEqual(t, catalog.Version("vm"), 1, "NewCatalog should offer version 1")
+	testutil.AssertEqual(t, catalog.Version("mainframe"), 0, "unknown keys have no version")
+
+	err = catalog.Add(testutil.NewMockResource().WithKey("vm"), 2)
+	testutil.AssertError(t, err, "adding a key twice should fail")
+}
+
+func TestCatalog_Nil(t *testing.T) {
+	var catalog *resource.Catalog
+
+	testutil.AssertNil(t, catalog.Get("vm"), "nil catalog should offer nothing")
+	testutil.AssertEqual(t, catalog.Version("vm"), 0, "nil catalog should have no versions")
+	testutil.AssertEqual(t, len(catalog.Resources()), 0, "nil catalog should list nothing")
+}
@@ -1,12 +1,31 @@
 package resource
 
-import "time"
+import (
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
 
 type Resource interface {
+	// Key is a stable identifier for the resource, used to persist which
+	// resource a service runs on. It must not change between releases.
+	Key() string
 	Name() string
 	Description() string
-	Provides() []interface{}
+	// Provides lists the capabilities the resource offers to other services.
+	Provides() []Capability
+	// Requires lists the capabilities other services in the same project
+	// must provide for this resource to work.
+	Requires() []Requirement
+	// ConfigSchema is the JSON Schema of the configuration a service of this
+	// resource accepts, or nil if it takes none. See SchemaFor.
+	ConfigSchema() *huma.Schema
 	Price(duration time.Duration) float64
+	// MetricsCPU is a PromQL query template of a service's CPU usage in
+	// cores, scoped to the service by MetricsQuery, or empty if the
+	// resource has none.
 	MetricsCPU() string
+	// MetricsMemory is a PromQL query template of a service's memory usage
+	// in bytes, like MetricsCPU.
 	MetricsMemory() string
 }
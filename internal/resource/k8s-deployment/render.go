@@ -0,0 +1,71 @@
+package k8s_deployment
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/resource"
+)
+
+// Render returns the Deployment manifest of a service configured with
+// config.
+func (d *Deployment) Render(owner k8s.Owner, config map[string]any) ([]k8s.Object, error) {
+	var cfg Config
+	if err := resource.Settings(config).Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("decoding config: %w", err)
+	}
+	deployment, err := RenderDeployment(owner, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return []k8s.Object{deployment}, nil
+}
+
+// RenderDeployment builds the Deployment for cfg, labelled as owned by
+// owner. Its Pods carry the same labels and are selected by service ID.
+func RenderDeployment(owner k8s.Owner, cfg Config) (*k8s.Deployment, error) {
+	meta, err := owner.Meta(cfg.Labels)
+	if err != nil {
+		return nil, err
+	}
+	strategy, err := cfg.Strategy.render()
+	if err != nil {
+		return nil, err
+	}
+	replicas := 1
+	if cfg.Replicas != nil {
+		replicas = *cfg.Replicas
+	}
+
+	return k8s.NewDeployment(meta, k8s.DeploymentSpec{
+		Replicas: replicas,
+		Selector: k8s.LabelSelector{MatchLabels: owner.Selector()},
+		Strategy: strategy,
+		Template: k8s.PodTemplateSpec{
+			Metadata: k8s.TemplateMeta{Labels: meta.Labels},
+			Spec:     k8s.PodSpec{Containers: []k8s.Container{cfg.Container()}},
+		},
+	}), nil
+}
+
+func (s Strategy) render() (k8s.DeploymentStrategy, error) {
+	switch s.Type {
+	case k8s.Recreate:
+		if s.MaxSurge != "" || s.MaxUnavailable != "" {
+			return k8s.DeploymentStrategy{}, errors.New("strategy: maxSurge and maxUnavailable only apply to RollingUpdate")
+		}
+		return k8s.DeploymentStrategy{Type: k8s.Recreate}, nil
+	case "", k8s.RollingUpdate:
+		strategy := k8s.DeploymentStrategy{Type: k8s.RollingUpdate}
+		if s.MaxSurge != "" || s.MaxUnavailable != "" {
+			strategy.RollingUpdate = &k8s.RollingUpdateDeployment{
+				MaxSurge:       k8s.ParseIntOrString(s.MaxSurge),
+				MaxUnavailable: k8s.ParseIntOrString(s.MaxUnavailable),
+			}
+		}
+		return strategy, nil
+	default:
+		return k8s.DeploymentStrategy{}, fmt.Errorf("strategy: unknown type %q", s.Type)
+	}
+}
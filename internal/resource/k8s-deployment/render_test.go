@@ -0,0 +1,87 @@
+package k8s_deployment
+
+import (
+	"testing"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+var testOwner = k8s.Owner{
+	ProjectID:   uuid.MustParse("6a1e7c1e-35a4-4c36-a0a4-5b7e2a0f2d11"),
+	ServiceID:   uuid.MustParse("5d2b8e10-7c4a-4f7e-8a61-0e9d3b2c1f53"),
+	ServiceName: "api",
+	Namespace:   "shop",
+}
+
+func TestDeployment_Render(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]any
+	}{
+		{name: "minimal", config: map[string]any{"image": "nginx:1.27"}},
+		{name: "rolling_update", config: map[string]any{
+			"image":    "ghcr.io/example/api:3.0.0",
+			"replicas": 3,
+			"ports":    []any{map[string]any{"name": "http", "containerPort": 8080}},
+			"env":      []any{map[string]any{"name": "PORT", "value": "8080"}},
+			"resources": map[string]any{
+				"requests": map[string]any{"cpu": "100m", "memory": "64Mi"},
+			},
+			"labels":   map[string]any{"team": "checkout"},
+			"strategy": map[string]any{"maxSurge": "25%", "maxUnavailable": "0"},
+		}},
+		{name: "recreate", config: map[string]any{
+			"image":    "ghcr.io/example/worker:1.4.2",
+			"replicas": 1,
+			"strategy": map[string]any{"type": "Recreate"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := (&Deployment{}).Render(testOwner, tt.config)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			testutil.AssertManifestsGolden(t, tt.name, objects)
+		})
+	}
+}
+
+func TestDeployment_RenderErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]any
+	}{
+		{name: "recreate with surge", config: map[string]any{"image": "nginx", "strategy": map[string]any{"type": "Recreate", "maxSurge": "1"}}},
+		{name: "reserved label", config: map[string]any{"image": "nginx", "labels": map[string]any{k8s.LabelProjectID: "x"}}},
+		{name: "unknown field", config: map[string]any{"image": "nginx", "replica": 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := (&Deployment{}).Render(testOwner, tt.config); err == nil {
+				t.Errorf("Render(%v) should fail", tt.config)
+			}
+		})
+	}
+}
+
+func TestRenderDeployment_SelectorMatchesTemplate(t *testing.T) {
+	d, err := RenderDeployment(testOwner, Config{})
+	if err != nil {
+		t.Fatalf("RenderDeployment() error = %v", err)
+	}
+	for key, value := range d.Spec.Selector.MatchLabels {
+		if got := d.Spec.Template.Metadata.Labels[key]; got != value {
+			t.Errorf("template label %s = %q, want %q to match the selector", key, got, value)
+		}
+	}
+	for key, value := range testOwner.WorkloadSelector(testOwner.ServiceName) {
+		if got := d.Spec.Template.Metadata.Labels[key]; got != value {
+			t.Errorf("template label %s = %q, want %q so that Services can select the Pods", key, got, value)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package k8s_deployment
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/resource"
+	k8s_pod "github.com/Bermos/Platform/internal/resource/k8s-pod"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Type is the registry key of the Deployment resource.
+const Type = "k8s-deployment"
+
+func init() {
+	resource.Register(Type, 1, New)
+}
+
+// Settings are the operator options for the Deployment resource.
+type Settings struct {
+	// PricePerHour is charged per replica.
+	PricePerHour float64 `json:"price_per_hour"`
+}
+
+// Config is the per-service configuration of a Deployment: the container
+// of its Pods, how many of them to run and how to roll out changes.
+type Config struct {
+	k8s_pod.Config
+	Replicas *int     `json:"replicas,omitempty" minimum:"0" maximum:"100" doc:"Number of Pods to run, 1 when not set"`
+	Strategy Strategy `json:"strategy,omitempty" doc:"How Pods are replaced when the configuration changes"`
+}
+
+// Strategy is the rollout strategy of a Deployment.
+type Strategy struct {
+	Type           string `json:"type,omitempty" enum:"RollingUpdate,Recreate" doc:"RollingUpdate replaces Pods gradually, Recreate stops all old Pods first. Defaults to RollingUpdate"`
+	MaxSurge       string `json:"maxSurge,omitempty" pattern:"^[0-9]+%?$" patternDescription:"a number or percentage, e.g. 1 or 25%" doc:"Pods allowed above the replica count during a rolling update"`
+	MaxUnavailable string `json:"maxUnavailable,omitempty" pattern:"^[0-9]+%?$" patternDescription:"a number or percentage, e.g. 0 or 25%" doc:"Pods allowed to be unavailable during a rolling update"`
+}
+
+var configSchema = resource.SchemaFor[Config]()
+
+// New creates a Deployment resource from its settings.
+func New(settings resource.Settings) (resource.Resource, error) {
+	var s Settings
+	if err := settings.Decode(&s); err != nil {
+		return nil, err
+	}
+	if s.PricePerHour < 0 {
+		return nil, fmt.Errorf("price_per_hour must not be negative, got %v", s.PricePerHour)
+	}
+	d := &Deployment{pricePerHour: s.PricePerHour}
+	d.Lifecycle = k8s.NewLifecycle(d)
+	return d, nil
+}
+
+type Deployment struct {
+	k8s.Lifecycle
+	pricePerHour float64
+}
+
+func (d *Deployment) Key() string {
+	return Type
+}
+
+func (d *Deployment) Name() string {
+	return "Kubernetes Deployment"
+}
+
+func (d *Deployment) Description() string {
+	return "A Kubernetes Deployment runs a number of identical Pods and replaces them with a rolling update when their configuration changes."
+}
+
+func (d *Deployment) Provides() []resource.Capability {
+	return []resource.Capability{resource.Provide(resource.KubernetesWorkload)}
+}
+
+func (d *Deployment) Requires() []resource.Requirement {
+	return []resource.Requirement{}
+}
+
+func (d *Deployment) ConfigSchema() *huma.Schema {
+	return configSchema
+}
+
+// Price is the price of a single replica.
+func (d *Deployment) Price(interval time.Duration) float64 {
+	return d.pricePerHour * interval.Hours()
+}
+
+// MetricsCPU is the CPU usage of the service's containers in cores.
+func (d *Deployment) MetricsCPU() string {
+	return k8s.CPUQuery
+}
+
+// MetricsMemory is the working set of the service's containers in bytes.
+func (d *Deployment) MetricsMemory() string {
+	return k8s.MemoryQuery
+}
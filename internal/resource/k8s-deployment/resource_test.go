@@ -0,0 +1,97 @@
+package k8s_deployment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/resource"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		settings  resource.Settings
+		wantPrice float64
+		wantErr   bool
+	}{
+		{name: "no settings", settings: nil, wantPrice: 0},
+		{name: "price per hour", settings: resource.Settings{"price_per_hour": 0.5}, wantPrice: 0.5},
+		{name: "negative price", settings: resource.Settings{"price_per_hour": -1.0}, wantErr: true},
+		{name: "unknown setting", settings: resource.Settings{"replicas": 3}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := New(tt.settings)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("New() should fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+			if got := res.Price(time.Hour); got != tt.wantPrice {
+				t.Errorf("Price(1h) = %v, want %v", got, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestDeployment_IsRegistered(t *testing.T) {
+	res, err := resource.DefaultRegistry.New(Type, 1, nil)
+	if err != nil {
+		t.Fatalf("DefaultRegistry.New(%q) unexpected error: %v", Type, err)
+	}
+	if _, ok := res.(*Deployment); !ok {
+		t.Errorf("DefaultRegistry.New(%q) = %T, want *Deployment", Type, res)
+	}
+	if _, ok := res.(k8s.Renderer); !ok {
+		t.Errorf("%T should implement k8s.Renderer", res)
+	}
+}
+
+func TestDeployment_Capabilities(t *testing.T) {
+	d := &Deployment{}
+	if provides := d.Provides(); len(provides) != 1 || provides[0].Type != resource.KubernetesWorkload {
+		t.Errorf("Provides() = %v, want a %s", provides, resource.KubernetesWorkload)
+	}
+	if requires := d.Requires(); requires == nil || len(requires) != 0 {
+		t.Errorf("Requires() = %v, want empty slice", requires)
+	}
+}
+
+func TestDeployment_ConfigSchema(t *testing.T) {
+	schema := (&Deployment{}).ConfigSchema()
+	if schema == nil {
+		t.Fatal("ConfigSchema() should return a schema")
+	}
+
+	tests := []struct {
+		name      string
+		config    map[string]any
+		wantValid bool
+	}{
+		{name: "image only", config: map[string]any{"image": "nginx:1.27"}, wantValid: true},
+		{name: "replicas", config: map[string]any{"image": "nginx:1.27", "replicas": 3}, wantValid: true},
+		{name: "zero replicas", config: map[string]any{"image": "nginx:1.27", "replicas": 0}, wantValid: true},
+		{name: "rolling update", config: map[string]any{"image": "nginx:1.27", "strategy": map[string]any{"type": "RollingUpdate", "maxSurge": "25%", "maxUnavailable": "0"}}, wantValid: true},
+		{name: "recreate", config: map[string]any{"image": "nginx:1.27", "strategy": map[string]any{"type": "Recreate"}}, wantValid: true},
+		{name: "pod fields", config: map[string]any{"image": "nginx:1.27", "ports": []any{map[string]any{"containerPort": 80}}}, wantValid: true},
+		{name: "missing image", config: map[string]any{"replicas": 3}, wantValid: false},
+		{name: "negative replicas", config: map[string]any{"image": "nginx:1.27", "replicas": -1}, wantValid: false},
+		{name: "unknown strategy", config: map[string]any{"image": "nginx:1.27", "strategy": map[string]any{"type": "BlueGreen"}}, wantValid: false},
+		{name: "bad surge", config: map[string]any{"image": "nginx:1.27", "strategy": map[string]any{"maxSurge": "a lot"}}, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := resource.ValidateConfig(schema, tt.config, "config")
+			if valid := len(errs) == 0; valid != tt.wantValid {
+				t.Errorf("ValidateConfig(%v) valid = %v, want %v (errors: %v)", tt.config, valid, tt.wantValid, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package k8s_ingress
+
+import (
+	"fmt"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/resource"
+)
+
+// Render returns the Ingress manifest of a service configured with config.
+func (i *Ingress) Render(owner k8s.Owner, config map[string]any) ([]k8s.Object, error) {
+	var cfg Config
+	if err := resource.Settings(config).Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("decoding config: %w", err)
+	}
+	if cfg.ClassName == "" {
+		cfg.ClassName = i.className
+	}
+	ingress, err := RenderIngress(owner, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return []k8s.Object{ingress}, nil
+}
+
+// RenderIngress builds the Ingress for cfg, labelled as owned by owner.
+// Paths name Kubernetes Services by the Mahler service rendering them.
+func RenderIngress(owner k8s.Owner, cfg Config) (*k8s.Ingress, error) {
+	meta, err := owner.Meta(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := k8s.IngressSpec{IngressClassName: cfg.ClassName, Rules: make([]k8s.IngressRule, 0, len(cfg.Rules))}
+	hosts := make(map[string]bool, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		if hosts[r.Host] {
+			return nil, fmt.Errorf("rules: host %q is listed more than once", r.Host)
+		}
+		hosts[r.Host] = true
+
+		rule := k8s.IngressRule{Host: r.Host, HTTP: k8s.HTTPIngressRuleValue{Paths: make([]k8s.HTTPIngressPath, 0, len(r.Paths))}}
+		for _, p := range r.Paths {
+			if p.Service == owner.ServiceName {
+				return nil, fmt.Errorf("rules: path %s cannot route to the ingress itself", p.Path)
+			}
+			pathType := p.PathType
+			if pathType == "" {
+				pathType = k8s.PathPrefix
+			}
+			rule.HTTP.Paths = append(rule.HTTP.Paths, k8s.HTTPIngressPath{
+				Path:     p.Path,
+				PathType: pathType,
+				Backend: k8s.IngressBackend{Service: k8s.IngressServiceBackend{
+					Name: p.Service,
+					Port: k8s.ServiceBackendPort{Number: p.Port},
+				}},
+			})
+		}
+		spec.Rules = append(spec.Rules, rule)
+	}
+	return k8s.NewIngress(meta, spec), nil
+}
@@ -0,0 +1,75 @@
+package k8s_ingress
+
+import (
+	"testing"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+var testOwner = k8s.Owner{
+	ProjectID:   uuid.MustParse("6a1e7c1e-35a4-4c36-a0a4-5b7e2a0f2d11"),
+	ServiceID:   uuid.MustParse("e3a1f6b2-4c5d-4e8f-9a0b-1c2d3e4f5a67"),
+	ServiceName: "public",
+	Namespace:   "shop",
+}
+
+func TestIngress_Render(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings Ingress
+		config   map[string]any
+	}{
+		{name: "any_host", config: map[string]any{"rules": []any{
+			map[string]any{"paths": []any{map[string]any{"path": "/", "service": "web-svc", "port": 80}}},
+		}}},
+		{name: "hosts_and_paths", settings: Ingress{className: "nginx"}, config: map[string]any{"rules": []any{
+			map[string]any{"host": "shop.example.com", "paths": []any{
+				map[string]any{"path": "/api", "service": "api-svc", "port": 8080},
+				map[string]any{"path": "/healthz", "pathType": "Exact", "service": "api-svc", "port": 8080},
+				map[string]any{"path": "/", "service": "web-svc", "port": 80},
+			}},
+			map[string]any{"host": "admin.example.com", "paths": []any{
+				map[string]any{"path": "/", "service": "admin-svc", "port": 80},
+			}},
+		}}},
+		{name: "class_override", settings: Ingress{className: "nginx"}, config: map[string]any{"className": "traefik", "rules": []any{
+			map[string]any{"host": "shop.example.com", "paths": []any{map[string]any{"path": "/", "service": "web-svc", "port": 80}}},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := tt.settings.Render(testOwner, tt.config)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			testutil.AssertManifestsGolden(t, tt.name, objects)
+		})
+	}
+}
+
+func TestIngress_RenderErrors(t *testing.T) {
+	path := map[string]any{"path": "/", "service": "web-svc", "port": 80}
+	tests := []struct {
+		name   string
+		config map[string]any
+	}{
+		{name: "duplicate host", config: map[string]any{"rules": []any{
+			map[string]any{"host": "example.com", "paths": []any{path}},
+			map[string]any{"host": "example.com", "paths": []any{path}},
+		}}},
+		{name: "routes to itself", config: map[string]any{"rules": []any{
+			map[string]any{"paths": []any{map[string]any{"path": "/", "service": "public", "port": 80}}},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := (&Ingress{}).Render(testOwner, tt.config); err == nil {
+				t.Errorf("Render(%v) should fail", tt.config)
+			}
+		})
+	}
+}
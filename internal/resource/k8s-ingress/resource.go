@@ -0,0 +1,104 @@
+package k8s_ingress
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Type is the registry key of the Ingress resource.
+const Type = "k8s-ingress"
+
+func init() {
+	resource.Register(Type, 1, New)
+}
+
+// Settings are the operator options for the Ingress resource.
+type Settings struct {
+	PricePerHour float64 `json:"price_per_hour"`
+	// ClassName is the ingress class used when a service does not set one.
+	ClassName string `json:"class_name"`
+}
+
+// Config is the per-service configuration of an Ingress.
+type Config struct {
+	ClassName string `json:"className,omitempty" doc:"Ingress class, the instance default when not set"`
+	Rules     []Rule `json:"rules" minItems:"1" doc:"Routing rules, matched by host"`
+}
+
+// Rule routes the requests for one host.
+type Rule struct {
+	Host  string `json:"host,omitempty" maxLength:"253" pattern:"^(\\*\\.)?[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$" patternDescription:"a lowercase DNS name, optionally starting with *." doc:"Host to match, any host when not set"`
+	Paths []Path `json:"paths" minItems:"1"`
+}
+
+// Path routes requests below a path to a Kubernetes Service.
+type Path struct {
+	Path     string `json:"path" pattern:"^/" patternDescription:"an absolute path, e.g. /api" doc:"Request path to match"`
+	PathType string `json:"pathType,omitempty" enum:"Prefix,Exact" doc:"How path is matched, Prefix when not set"`
+	Service  string `json:"service" minLength:"1" maxLength:"63" pattern:"^[a-z]([-a-z0-9]*[a-z0-9])?$" patternDescription:"a service name" doc:"Name of the Kubernetes Service service in the same project to route to"`
+	Port     int    `json:"port" minimum:"1" maximum:"65535" doc:"Port of that Service"`
+}
+
+var configSchema = resource.SchemaFor[Config]()
+
+// New creates an Ingress resource from its settings.
+func New(settings resource.Settings) (resource.Resource, error) {
+	var s Settings
+	if err := settings.Decode(&s); err != nil {
+		return nil, err
+	}
+	if s.PricePerHour < 0 {
+		return nil, fmt.Errorf("price_per_hour must not be negative, got %v", s.PricePerHour)
+	}
+	i := &Ingress{pricePerHour: s.PricePerHour, className: s.ClassName}
+	i.Lifecycle = k8s.NewLifecycle(i)
+	return i, nil
+}
+
+type Ingress struct {
+	k8s.Lifecycle
+	pricePerHour float64
+	className    string
+}
+
+func (i *Ingress) Key() string {
+	return Type
+}
+
+func (i *Ingress) Name() string {
+	return "Kubernetes Ingress"
+}
+
+func (i *Ingress) Description() string {
+	return "A Kubernetes Ingress routes HTTP requests from outside the cluster to Services by host and path."
+}
+
+func (i *Ingress) Provides() []resource.Capability {
+	return []resource.Capability{resource.Provide(resource.HTTPEndpoint)}
+}
+
+func (i *Ingress) Requires() []resource.Requirement {
+	return []resource.Requirement{resource.Require(resource.HTTPEndpoint)}
+}
+
+func (i *Ingress) ConfigSchema() *huma.Schema {
+	return configSchema
+}
+
+func (i *Ingress) Price(interval time.Duration) float64 {
+	return i.pricePerHour * interval.Hours()
+}
+
+// MetricsCPU is empty as an Ingress runs no containers of its own.
+func (i *Ingress) MetricsCPU() string {
+	return ""
+}
+
+// MetricsMemory is empty as an Ingress runs no containers of its own.
+func (i *Ingress) MetricsMemory() string {
+	return ""
+}
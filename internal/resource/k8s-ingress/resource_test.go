@@ -0,0 +1,77 @@
+package k8s_ingress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/resource"
+)
+
+func TestNew(t *testing.T) {
+	res, err := New(resource.Settings{"price_per_hour": 0.25, "class_name": "nginx"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	if got := res.Price(2 * time.Hour); got != 0.5 {
+		t.Errorf("Price(2h) = %v, want 0.5", got)
+	}
+	if got := res.(*Ingress).className; got != "nginx" {
+		t.Errorf("className = %q, want %q", got, "nginx")
+	}
+	if _, err := New(resource.Settings{"price_per_hour": -1.0}); err == nil {
+		t.Error("New() should reject a negative price")
+	}
+}
+
+func TestIngress_IsRegistered(t *testing.T) {
+	res, err := resource.DefaultRegistry.New(Type, 1, nil)
+	if err != nil {
+		t.Fatalf("DefaultRegistry.New(%q) unexpected error: %v", Type, err)
+	}
+	if _, ok := res.(*Ingress); !ok {
+		t.Errorf("DefaultRegistry.New(%q) = %T, want *Ingress", Type, res)
+	}
+	if _, ok := res.(k8s.Renderer); !ok {
+		t.Errorf("%T should implement k8s.Renderer", res)
+	}
+}
+
+func TestIngress_Capabilities(t *testing.T) {
+	i := &Ingress{}
+	if provides := i.Provides(); len(provides) != 1 || provides[0].Type != resource.HTTPEndpoint {
+		t.Errorf("Provides() = %v, want a %s", provides, resource.HTTPEndpoint)
+	}
+	if requires := i.Requires(); len(requires) != 1 || requires[0].Type != resource.HTTPEndpoint {
+		t.Errorf("Requires() = %v, want a %s", requires, resource.HTTPEndpoint)
+	}
+}
+
+func TestIngress_ConfigSchema(t *testing.T) {
+	schema := (&Ingress{}).ConfigSchema()
+	path := map[string]any{"path": "/", "service": "api-svc", "port": 80}
+
+	tests := []struct {
+		name      string
+		config    map[string]any
+		wantValid bool
+	}{
+		{name: "any host", config: map[string]any{"rules": []any{map[string]any{"paths": []any{path}}}}, wantValid: true},
+		{name: "host", config: map[string]any{"rules": []any{map[string]any{"host": "shop.example.com", "paths": []any{path}}}}, wantValid: true},
+		{name: "wildcard host", config: map[string]any{"rules": []any{map[string]any{"host": "*.example.com", "paths": []any{path}}}}, wantValid: true},
+		{name: "no rules", config: map[string]any{"rules": []any{}}, wantValid: false},
+		{name: "no paths", config: map[string]any{"rules": []any{map[string]any{"host": "example.com", "paths": []any{}}}}, wantValid: false},
+		{name: "bad host", config: map[string]any{"rules": []any{map[string]any{"host": "https://example.com", "paths": []any{path}}}}, wantValid: false},
+		{name: "relative path", config: map[string]any{"rules": []any{map[string]any{"paths": []any{map[string]any{"path": "api", "service": "api-svc", "port": 80}}}}}, wantValid: false},
+		{name: "bad path type", config: map[string]any{"rules": []any{map[string]any{"paths": []any{map[string]any{"path": "/", "pathType": "Regex", "service": "api-svc", "port": 80}}}}}, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := resource.ValidateConfig(schema, tt.config, "config")
+			if valid := len(errs) == 0; valid != tt.wantValid {
+				t.Errorf("ValidateConfig(%v) valid = %v, want %v (errors: %v)", tt.config, valid, tt.wantValid, errs)
+			}
+		})
+	}
+}
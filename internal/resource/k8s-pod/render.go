@@ -0,0 +1,72 @@
+package k8s_pod
+
+import (
+	"fmt"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/resource"
+)
+
+// containerName is the name of the single container of a rendered Pod.
+const containerName = "main"
+
+// Render returns the Pod manifest of a service configured with config.
+func (p *Pod) Render(owner k8s.Owner, config map[string]any) ([]k8s.Object, error) {
+	var cfg Config
+	if err := resource.Settings(config).Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("decoding config: %w", err)
+	}
+	pod, err := RenderPod(owner, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return []k8s.Object{pod}, nil
+}
+
+// RenderPod builds the Pod for cfg, labelled as owned by owner.
+func RenderPod(owner k8s.Owner, cfg Config) (*k8s.Pod, error) {
+	meta, err := owner.Meta(cfg.Labels)
+	if err != nil {
+		return nil, err
+	}
+	return k8s.NewPod(meta, k8s.PodSpec{Containers: []k8s.Container{cfg.Container()}}), nil
+}
+
+// Container returns the container described by cfg.
+func (cfg Config) Container() k8s.Container {
+	c := k8s.Container{
+		Name:    containerName,
+		Image:   cfg.Image,
+		Command: cfg.Command,
+	}
+	for _, env := range cfg.Env {
+		c.Env = append(c.Env, k8s.EnvVar{Name: env.Name, Value: env.Value})
+	}
+	for _, port := range cfg.Ports {
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = "TCP"
+		}
+		c.Ports = append(c.Ports, k8s.ContainerPort{Name: port.Name, ContainerPort: port.ContainerPort, Protocol: protocol})
+	}
+	requests, limits := cfg.Resources.Requests.list(), cfg.Resources.Limits.list()
+	if requests != nil || limits != nil {
+		c.Resources = &k8s.ResourceRequirements{Requests: requests, Limits: limits}
+	}
+	return c
+}
+
+// list returns q keyed by Kubernetes resource name, or nil if q is empty.
+func (q Quantities) list() map[string]string {
+	if q.CPU == "" && q.Memory == "" {
+		return nil
+	}
+	list := make(map[string]string, 2)
+	if q.CPU != "" {
+		list["cpu"] = q.CPU
+	}
+	if q.Memory != "" {
+		list["memory"] = q.Memory
+	}
+	return list
+}
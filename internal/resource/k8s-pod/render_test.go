@@ -0,0 +1,107 @@
+package k8s_pod
+
+import (
+	"testing"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+var testOwner = k8s.Owner{
+	ProjectID:   uuid.MustParse("6a1e7c1e-35a4-4c36-a0a4-5b7e2a0f2d11"),
+	ServiceID:   uuid.MustParse("0c9f3a52-8f0e-4d0c-9b55-3c2f1e6d7a42"),
+	ServiceName: "web",
+	Namespace:   "shop",
+}
+
+func TestPod_Render(t *testing.T) {
+	t.Helper()
+
+	tests := []struct {
+		name   string
+		config map[string]any
+	}{
+		{name: "minimal", config: map[string]any{"image": "nginx:1.27"}},
+		{name: "full", config: map[string]any{
+			"image":   "ghcr.io/example/web:2.1.0",
+			"command": []any{"/bin/web", "--listen", ":8080"},
+			"env": []any{
+				map[string]any{"name": "LOG_LEVEL", "value": "debug"},
+				map[string]any{"name": "FEATURE_FLAG", "value": "true"},
+			},
+			"ports": []any{
+				map[string]any{"name": "http", "containerPort": 8080},
+				map[string]any{"name": "metrics", "containerPort": 9090, "protocol": "TCP"},
+				map[string]any{"containerPort": 5353, "protocol": "UDP"},
+			},
+			"resources": map[string]any{
+				"requests": map[string]any{"cpu": "250m", "memory": "128Mi"},
+				"limits":   map[string]any{"cpu": "1", "memory": "512Mi"},
+			},
+			"labels": map[string]any{"team": "storefront", "example.com/tier": "frontend"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Helper()
+
+			objects, err := (&Pod{}).Render(testOwner, tt.config)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+
+			testutil.AssertManifestsGolden(t, tt.name, objects)
+		})
+	}
+}
+
+func TestPod_RenderErrors(t *testing.T) {
+	t.Helper()
+
+	tests := []struct {
+		name   string
+		config map[string]any
+	}{
+		{name: "reserved label", config: map[string]any{"image": "nginx", "labels": map[string]any{"mahler.io/service-id": "other"}}},
+		{name: "managed-by label", config: map[string]any{"image": "nginx", "labels": map[string]any{k8s.LabelManagedBy: "helm"}}},
+		{name: "invalid label value", config: map[string]any{"image": "nginx", "labels": map[string]any{"team": "a b"}}},
+		{name: "unknown field", config: map[string]any{"image": "nginx", "replicas": 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Helper()
+
+			if _, err := (&Pod{}).Render(testOwner, tt.config); err == nil {
+				t.Errorf("Render(%v) should fail", tt.config)
+			}
+		})
+	}
+}
+
+func TestPod_RenderOwnershipLabels(t *testing.T) {
+	t.Helper()
+
+	pod, err := RenderPod(testOwner, Config{Image: "nginx", Labels: map[string]string{"team": "web"}})
+	if err != nil {
+		t.Fatalf("RenderPod() error = %v", err)
+	}
+
+	want := map[string]string{
+		k8s.LabelProjectID: testOwner.ProjectID.String(),
+		k8s.LabelServiceID: testOwner.ServiceID.String(),
+		k8s.LabelManagedBy: k8s.ManagedBy,
+		k8s.LabelName:      "web",
+		"team":             "web",
+	}
+	for key, value := range want {
+		if got := pod.Metadata.Labels[key]; got != value {
+			t.Errorf("label %s = %q, want %q", key, got, value)
+		}
+	}
+	if pod.Metadata.Namespace != "shop" {
+		t.Errorf("namespace = %q, want %q", pod.Metadata.Namespace, "shop")
+	}
+}
@@ -1,18 +1,93 @@
 package k8s_pod
 
 import (
-	"github.com/Bermos/Platform/internal/resource"
+	"fmt"
 	"time"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/danielgtaylor/huma/v2"
 )
 
+// Type is the registry key of the Pod resource.
+const Type = "k8s-pod"
+
+func init() {
+	resource.Register(Type, 1, New)
+}
+
+// Settings are the operator options for the Pod resource.
+type Settings struct {
+	PricePerHour float64 `json:"price_per_hour"`
+}
+
+// Config is the per-service configuration of a Pod.
+type Config struct {
+	Image   string   `json:"image" minLength:"1" doc:"Container image, e.g. nginx:1.27"`
+	Command []string `json:"command,omitempty" doc:"Overrides the image entrypoint"`
+	Env     []EnvVar `json:"env,omitempty" doc:"Environment variables of the container"`
+	Ports   []Port   `json:"ports,omitempty" doc:"Ports the container listens on"`
+	// Resources are the container's resource requests and limits.
+	Resources Resources         `json:"resources,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty" doc:"Extra labels for the Pod; the mahler.io/ prefix is reserved"`
+}
+
+// EnvVar is one environment variable of the container.
+type EnvVar struct {
+	Name  string `json:"name" pattern:"^[A-Za-z_][A-Za-z0-9_]*$" patternDescription:"a C identifier, e.g. LOG_LEVEL"`
+	Value string `json:"value"`
+}
+
+// Port is a port the container listens on.
+type Port struct {
+	Name          string `json:"name,omitempty" maxLength:"15" pattern:"^[a-z0-9]([-a-z0-9]*[a-z0-9])?$" patternDescription:"a lowercase name, e.g. http"`
+	ContainerPort int    `json:"containerPort" minimum:"1" maximum:"65535"`
+	Protocol      string `json:"protocol,omitempty" enum:"TCP,UDP,SCTP" doc:"Defaults to TCP"`
+}
+
+// Resources holds the requests and limits of the container.
+type Resources struct {
+	Requests Quantities `json:"requests,omitempty" doc:"Resources reserved for the container"`
+	Limits   Quantities `json:"limits,omitempty" doc:"Resources the container may not exceed"`
+}
+
+// Quantities are CPU and memory amounts in Kubernetes notation.
+type Quantities struct {
+	CPU    string `json:"cpu,omitempty" pattern:"^([0-9]+m|[0-9]+(\\.[0-9]+)?)$" patternDescription:"Kubernetes CPU quantity, e.g. 500m or 2"`
+	Memory string `json:"memory,omitempty" pattern:"^[0-9]+(Ki|Mi|Gi|Ti|K|M|G|T)?$" patternDescription:"Kubernetes memory quantity, e.g. 256Mi"`
+}
+
+var configSchema = resource.SchemaFor[Config]()
+
 func Setup() resource.Resource {
-	return &Pod{}
+	p := &Pod{}
+	p.Lifecycle = k8s.NewLifecycle(p)
+	return p
+}
+
+// New creates a Pod resource from its settings.
+func New(settings resource.Settings) (resource.Resource, error) {
+	var s Settings
+	if err := settings.Decode(&s); err != nil {
+		return nil, err
+	}
+	if s.PricePerHour < 0 {
+		return nil, fmt.Errorf("price_per_hour must not be negative, got %v", s.PricePerHour)
+	}
+	p := &Pod{pricePerHour: s.PricePerHour}
+	p.Lifecycle = k8s.NewLifecycle(p)
+	return p, nil
 }
 
 type Pod struct {
+	k8s.Lifecycle
 	pricePerHour float64
 }
 
+func (p *Pod) Key() string {
+	return Type
+}
+
 func (p *Pod) Name() string {
 	return "Kubernetes Pod"
 }
@@ -21,20 +96,28 @@ func (p *Pod) Description() string {
 	return "A Kubernetes Pod is a group of one or more containers, with shared storage/network resources, and a specification for how to run the containers."
 }
 
-func (p *Pod) Provides() []interface{} {
-	return []interface{}{}
+func (p *Pod) Provides() []resource.Capability {
+	return []resource.Capability{resource.Provide(resource.KubernetesWorkload)}
+}
+
+func (p *Pod) Requires() []resource.Requirement {
+	return []resource.Requirement{}
+}
+
+func (p *Pod) ConfigSchema() *huma.Schema {
+	return configSchema
 }
 
 func (p *Pod) Price(interval time.Duration) float64 {
 	return p.pricePerHour * interval.Hours()
 }
 
+// MetricsCPU is the CPU usage of the service's containers in cores.
 func (p *Pod) MetricsCPU() string {
-	//TODO: implement actual Prometheus query for CPU metrics
-	return "container_cpu_usage_seconds_total"
+	return k8s.CPUQuery
 }
 
+// MetricsMemory is the working set of the service's containers in bytes.
 func (p *Pod) MetricsMemory() string {
-	//TODO: implement actual Prometheus query for memory metrics
-	return "container_memory_usage_bytes"
+	return k8s.MemoryQuery
 }
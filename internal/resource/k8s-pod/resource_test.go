@@ -1,10 +1,14 @@
 package k8s_pod
 
 import (
+	"context"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/Bermos/Platform/internal/provider/fake"
 	"github.com/Bermos/Platform/internal/resource"
+	"github.com/google/uuid"
 )
 
 func TestSetup(t *testing.T) {
@@ -50,6 +54,18 @@ func TestSetup_ReturnsNewInstance(t *testing.T) {
 	}
 }
 
+func TestPod_Key(t *testing.T) {
+	t.Helper()
+
+	pod := &Pod{}
+	key := pod.Key()
+
+	expectedKey := "k8s-pod"
+	if key != expectedKey {
+		t.Errorf("Key() = %q, want %q", key, expectedKey)
+	}
+}
+
 func TestPod_Name(t *testing.T) {
 	t.Helper()
 
@@ -109,9 +125,8 @@ func TestPod_Provides(t *testing.T) {
 				t.Error("Provides() should return non-nil slice")
 			}
 
-			// Currently returns empty slice
-			if len(provides) != 0 {
-				t.Errorf("Provides() = %v, want empty slice", provides)
+			if len(provides) != 1 || provides[0].Type != resource.KubernetesWorkload {
+				t.Errorf("Provides() = %v, want a %s", provides, resource.KubernetesWorkload)
 			}
 		})
 	}
@@ -200,72 +215,40 @@ func TestPod_Price_ZeroDuration(t *testing.T) {
 func TestPod_MetricsCPU(t *testing.T) {
 	t.Helper()
 
-	tests := []struct {
-		name string
-		pod  *Pod
-	}{
-		{
-			name: "default pod",
-			pod:  &Pod{},
-		},
-		{
-			name: "pod with price",
-			pod:  &Pod{pricePerHour: 5.0},
-		},
+	svc := resource.ServiceRef{ServiceID: uuid.New(), Name: "web", Namespace: "shop"}
+	query, err := resource.MetricsQuery((&Pod{}).MetricsCPU(), svc)
+	if err != nil {
+		t.Fatalf("MetricsQuery() error = %v", err)
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Helper()
-
-			metrics := tt.pod.MetricsCPU()
-
-			if metrics == "" {
-				t.Error("MetricsCPU() should return non-empty string")
-			}
-
-			// Should return a Prometheus metric name
-			expectedMetric := "container_cpu_usage_seconds_total"
-			if metrics != expectedMetric {
-				t.Errorf("MetricsCPU() = %q, want %q", metrics, expectedMetric)
-			}
-		})
+	for _, want := range []string{
+		"rate(container_cpu_usage_seconds_total{",
+		`namespace="shop"`,
+		`label_mahler_io_service_id="` + svc.ServiceID.String() + `"`,
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("MetricsCPU() query = %q, want it to contain %q", query, want)
+		}
 	}
 }
 
 func TestPod_MetricsMemory(t *testing.T) {
 	t.Helper()
 
-	tests := []struct {
-		name string
-		pod  *Pod
-	}{
-		{
-			name: "default pod",
-			pod:  &Pod{},
-		},
-		{
-			name: "pod with price",
-			pod:  &Pod{pricePerHour: 5.0},
-		},
+	svc := resource.ServiceRef{ServiceID: uuid.New(), Name: "web", Namespace: "shop"}
+	query, err := resource.MetricsQuery((&Pod{}).MetricsMemory(), svc)
+	if err != nil {
+		t.Fatalf("MetricsQuery() error = %v", err)
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Helper()
-
-			metrics := tt.pod.MetricsMemory()
-
-			if metrics == "" {
-				t.Error("MetricsMemory() should return non-empty string")
-			}
-
-			// Should return a Prometheus metric name
-			expectedMetric := "container_memory_usage_bytes"
-			if metrics != expectedMetric {
-				t.Errorf("MetricsMemory() = %q, want %q", metrics, expectedMetric)
-			}
-		})
+	for _, want := range []string{
+		"container_memory_working_set_bytes{",
+		`namespace="shop"`,
+		`label_mahler_io_service_id="` + svc.ServiceID.String() + `"`,
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("MetricsMemory() query = %q, want it to contain %q", query, want)
+		}
 	}
 }
 
@@ -284,10 +267,154 @@ func TestPod_AllMethods(t *testing.T) {
 	pod := Setup()
 
 	// Test all methods don't panic
+	_ = pod.Key()
 	_ = pod.Name()
 	_ = pod.Description()
 	_ = pod.Provides()
+	_ = pod.Requires()
 	_ = pod.Price(1 * time.Hour)
 	_ = pod.MetricsCPU()
 	_ = pod.MetricsMemory()
 }
+
+func TestNew(t *testing.T) {
+	t.Helper()
+
+	tests := []struct {
+		name      string
+		settings  resource.Settings
+		wantPrice float64
+		wantErr   bool
+	}{
+		{name: "no settings", settings: nil, wantPrice: 0},
+		{name: "price per hour", settings: resource.Settings{"price_per_hour": 0.5}, wantPrice: 0.5},
+		{name: "negative price", settings: resource.Settings{"price_per_hour": -1.0}, wantErr: true},
+		{name: "unknown setting", settings: resource.Settings{"cores": 4}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Helper()
+
+			res, err := New(tt.settings)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("New() should fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+			if got := res.Price(time.Hour); got != tt.wantPrice {
+				t.Errorf("Price(1h) = %v, want %v", got, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestPod_IsRegistered(t *testing.T) {
+	t.Helper()
+
+	res, err := resource.DefaultRegistry.New(Type, 1, nil)
+	if err != nil {
+		t.Fatalf("DefaultRegistry.New(%q) unexpected error: %v", Type, err)
+	}
+	if _, ok := res.(*Pod); !ok {
+		t.Errorf("DefaultRegistry.New(%q) = %T, want *Pod", Type, res)
+	}
+}
+
+func TestPod_Requires(t *testing.T) {
+	t.Helper()
+
+	requires := (&Pod{}).Requires()
+	if requires == nil {
+		t.Error("Requires() should return non-nil slice")
+	}
+	if len(requires) != 0 {
+		t.Errorf("Requires() = %v, want empty slice", requires)
+	}
+}
+
+func TestPod_ConfigSchema(t *testing.T) {
+	t.Helper()
+
+	schema := (&Pod{}).ConfigSchema()
+	if schema == nil {
+		t.Fatal("ConfigSchema() should return a schema")
+	}
+
+	tests := []struct {
+		name      string
+		config    map[string]any
+		wantValid bool
+	}{
+		{name: "image only", config: map[string]any{"image": "nginx:1.27"}, wantValid: true},
+		{name: "with resources", config: map[string]any{"image": "nginx:1.27", "resources": map[string]any{"requests": map[string]any{"cpu": "500m", "memory": "256Mi"}, "limits": map[string]any{"cpu": "1"}}}, wantValid: true},
+		{name: "fractional cpu", config: map[string]any{"image": "nginx:1.27", "resources": map[string]any{"limits": map[string]any{"cpu": "0.5"}}}, wantValid: true},
+		{name: "command env ports labels", config: map[string]any{
+			"image":   "nginx:1.27",
+			"command": []any{"nginx", "-g", "daemon off;"},
+			"env":     []any{map[string]any{"name": "LOG_LEVEL", "value": "debug"}},
+			"ports":   []any{map[string]any{"name": "http", "containerPort": 80}},
+			"labels":  map[string]any{"team": "web"},
+		}, wantValid: true},
+		{name: "missing image", config: map[string]any{}, wantValid: false},
+		{name: "bad cpu", config: map[string]any{"image": "nginx:1.27", "resources": map[string]any{"requests": map[string]any{"cpu": "half"}}}, wantValid: false},
+		{name: "bad memory", config: map[string]any{"image": "nginx:1.27", "resources": map[string]any{"limits": map[string]any{"memory": "256MB"}}}, wantValid: false},
+		{name: "bad env name", config: map[string]any{"image": "nginx:1.27", "env": []any{map[string]any{"name": "LOG-LEVEL", "value": "x"}}}, wantValid: false},
+		{name: "port out of range", config: map[string]any{"image": "nginx:1.27", "ports": []any{map[string]any{"containerPort": 70000}}}, wantValid: false},
+		{name: "bad protocol", config: map[string]any{"image": "nginx:1.27", "ports": []any{map[string]any{"containerPort": 80, "protocol": "HTTP"}}}, wantValid: false},
+		{name: "unknown field", config: map[string]any{"image": "nginx:1.27", "cpu": "1"}, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Helper()
+
+			errs := resource.ValidateConfig(schema, tt.config, "config")
+			if valid := len(errs) == 0; valid != tt.wantValid {
+				t.Errorf("ValidateConfig(%v) valid = %v, want %v (errors: %v)", tt.config, valid, tt.wantValid, errs)
+			}
+		})
+	}
+}
+
+func TestPod_Lifecycle(t *testing.T) {
+	t.Helper()
+
+	res, err := New(nil)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	lc, ok := res.(resource.Lifecycle)
+	if !ok {
+		t.Fatalf("%T should implement resource.Lifecycle", res)
+	}
+
+	ctx := context.Background()
+	p := fake.NewProvider()
+	req := resource.Request{
+		Service: resource.ServiceRef{ProjectID: uuid.New(), ServiceID: uuid.New(), Name: "web", Namespace: "shop"},
+		Config:  map[string]any{"image": "nginx:1.27"},
+	}
+
+	result, err := lc.Apply(ctx, p, req)
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	want := resource.ObjectKey{Kind: "Pod", Namespace: "shop", Name: "web"}
+	if len(result.Changes) != 1 || result.Changes[0].Object != want {
+		t.Errorf("Apply() changes = %v, want create %s", result.Changes, want)
+	}
+	if status, _ := lc.Status(ctx, p, req.Service); status.Phase != resource.PhaseReady {
+		t.Errorf("Status() = %v, want ready", status)
+	}
+	if err := lc.Destroy(ctx, p, req.Service); err != nil {
+		t.Fatalf("Destroy() unexpected error: %v", err)
+	}
+	if status, _ := lc.Status(ctx, p, req.Service); status.Phase != resource.PhaseMissing {
+		t.Errorf("Status() after Destroy = %v, want missing", status)
+	}
+}
@@ -0,0 +1,58 @@
+package k8s_service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/resource"
+)
+
+// Render returns the Service manifest of a service configured with config.
+func (s *Service) Render(owner k8s.Owner, config map[string]any) ([]k8s.Object, error) {
+	var cfg Config
+	if err := resource.Settings(config).Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("decoding config: %w", err)
+	}
+	svc, err := RenderService(owner, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return []k8s.Object{svc}, nil
+}
+
+// RenderService builds the Service for cfg, labelled as owned by owner. It
+// selects the Pods of the target service in owner's project.
+func RenderService(owner k8s.Owner, cfg Config) (*k8s.Service, error) {
+	if cfg.Target == owner.ServiceName {
+		return nil, errors.New("target: a service cannot route to itself")
+	}
+	meta, err := owner.Meta(nil)
+	if err != nil {
+		return nil, err
+	}
+	typ := cfg.Type
+	if typ == "" {
+		typ = k8s.ClusterIP
+	}
+
+	spec := k8s.ServiceSpec{
+		Type:     typ,
+		Selector: owner.WorkloadSelector(cfg.Target),
+		Ports:    make([]k8s.ServicePort, 0, len(cfg.Ports)),
+	}
+	for _, p := range cfg.Ports {
+		if len(cfg.Ports) > 1 && p.Name == "" {
+			return nil, errors.New("ports: every port needs a name when there is more than one")
+		}
+		port := k8s.ServicePort{Name: p.Name, Port: p.Port, TargetPort: p.TargetPort, Protocol: p.Protocol}
+		if port.TargetPort == 0 {
+			port.TargetPort = p.Port
+		}
+		if port.Protocol == "" {
+			port.Protocol = "TCP"
+		}
+		spec.Ports = append(spec.Ports, port)
+	}
+	return k8s.NewService(meta, spec), nil
+}
@@ -0,0 +1,74 @@
+package k8s_service
+
+import (
+	"testing"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+var testOwner = k8s.Owner{
+	ProjectID:   uuid.MustParse("6a1e7c1e-35a4-4c36-a0a4-5b7e2a0f2d11"),
+	ServiceID:   uuid.MustParse("9b7e4c21-2d3f-4a8b-b1c6-7f0e5d4a3c92"),
+	ServiceName: "api-svc",
+	Namespace:   "shop",
+}
+
+func TestService_Render(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]any
+	}{
+		{name: "cluster_ip", config: map[string]any{"target": "api", "ports": []any{map[string]any{"port": 80, "targetPort": 8080}}}},
+		{name: "load_balancer", config: map[string]any{
+			"target": "api",
+			"type":   "LoadBalancer",
+			"ports": []any{
+				map[string]any{"name": "http", "port": 80, "targetPort": 8080},
+				map[string]any{"name": "dns", "port": 53, "protocol": "UDP"},
+			},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := (&Service{}).Render(testOwner, tt.config)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			testutil.AssertManifestsGolden(t, tt.name, objects)
+		})
+	}
+}
+
+func TestService_RenderErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]any
+	}{
+		{name: "targets itself", config: map[string]any{"target": "api-svc", "ports": []any{map[string]any{"port": 80}}}},
+		{name: "unnamed ports", config: map[string]any{"target": "api", "ports": []any{map[string]any{"port": 80}, map[string]any{"port": 443}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := (&Service{}).Render(testOwner, tt.config); err == nil {
+				t.Errorf("Render(%v) should fail", tt.config)
+			}
+		})
+	}
+}
+
+func TestRenderService_SelectsTargetPods(t *testing.T) {
+	svc, err := RenderService(testOwner, Config{Target: "api", Ports: []Port{{Port: 80}}})
+	if err != nil {
+		t.Fatalf("RenderService() error = %v", err)
+	}
+	target := k8s.Owner{ProjectID: testOwner.ProjectID, ServiceID: uuid.New(), ServiceName: "api"}
+	for key, value := range svc.Spec.Selector {
+		if got := target.Labels()[key]; got != value {
+			t.Errorf("selector %s = %q, but the target's Pods are labelled %q", key, value, got)
+		}
+	}
+}
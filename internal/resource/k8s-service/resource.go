@@ -0,0 +1,96 @@
+package k8s_service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Type is the registry key of the Kubernetes Service resource.
+const Type = "k8s-service"
+
+func init() {
+	resource.Register(Type, 1, New)
+}
+
+// Settings are the operator options for the Service resource.
+type Settings struct {
+	PricePerHour float64 `json:"price_per_hour"`
+}
+
+// Config is the per-service configuration of a Kubernetes Service.
+type Config struct {
+	Target string `json:"target" minLength:"1" maxLength:"63" pattern:"^[a-z]([-a-z0-9]*[a-z0-9])?$" patternDescription:"a service name" doc:"Name of the Pod or Deployment service in the same project to route to"`
+	Type   string `json:"type,omitempty" enum:"ClusterIP,NodePort,LoadBalancer" doc:"How the Service is exposed, ClusterIP when not set"`
+	Ports  []Port `json:"ports" minItems:"1" doc:"Ports the Service listens on"`
+}
+
+// Port maps a Service port to a container port of the target.
+type Port struct {
+	Name       string `json:"name,omitempty" maxLength:"15" pattern:"^[a-z0-9]([-a-z0-9]*[a-z0-9])?$" patternDescription:"a lowercase name, e.g. http"`
+	Port       int    `json:"port" minimum:"1" maximum:"65535"`
+	TargetPort int    `json:"targetPort,omitempty" minimum:"1" maximum:"65535" doc:"Container port to forward to, the same as port when not set"`
+	Protocol   string `json:"protocol,omitempty" enum:"TCP,UDP,SCTP" doc:"Defaults to TCP"`
+}
+
+var configSchema = resource.SchemaFor[Config]()
+
+// New creates a Service resource from its settings.
+func New(settings resource.Settings) (resource.Resource, error) {
+	var s Settings
+	if err := settings.Decode(&s); err != nil {
+		return nil, err
+	}
+	if s.PricePerHour < 0 {
+		return nil, fmt.Errorf("price_per_hour must not be negative, got %v", s.PricePerHour)
+	}
+	svc := &Service{pricePerHour: s.PricePerHour}
+	svc.Lifecycle = k8s.NewLifecycle(svc)
+	return svc, nil
+}
+
+type Service struct {
+	k8s.Lifecycle
+	pricePerHour float64
+}
+
+func (s *Service) Key() string {
+	return Type
+}
+
+func (s *Service) Name() string {
+	return "Kubernetes Service"
+}
+
+func (s *Service) Description() string {
+	return "A Kubernetes Service gives the Pods of a workload a stable address and load-balances requests across them."
+}
+
+func (s *Service) Provides() []resource.Capability {
+	return []resource.Capability{resource.Provide(resource.HTTPEndpoint)}
+}
+
+func (s *Service) Requires() []resource.Requirement {
+	return []resource.Requirement{resource.Require(resource.KubernetesWorkload)}
+}
+
+func (s *Service) ConfigSchema() *huma.Schema {
+	return configSchema
+}
+
+func (s *Service) Price(interval time.Duration) float64 {
+	return s.pricePerHour * interval.Hours()
+}
+
+// MetricsCPU is empty as a Service runs no containers.
+func (s *Service) MetricsCPU() string {
+	return ""
+}
+
+// MetricsMemory is empty as a Service runs no containers.
+func (s *Service) MetricsMemory() string {
+	return ""
+}
@@ -0,0 +1,71 @@
+package k8s_service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/k8s"
+	"github.com/Bermos/Platform/internal/resource"
+)
+
+func TestNew(t *testing.T) {
+	res, err := New(resource.Settings{"price_per_hour": 0.25})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	if got := res.Price(2 * time.Hour); got != 0.5 {
+		t.Errorf("Price(2h) = %v, want 0.5", got)
+	}
+	if _, err := New(resource.Settings{"price_per_hour": -1.0}); err == nil {
+		t.Error("New() should reject a negative price")
+	}
+}
+
+func TestService_IsRegistered(t *testing.T) {
+	res, err := resource.DefaultRegistry.New(Type, 1, nil)
+	if err != nil {
+		t.Fatalf("DefaultRegistry.New(%q) unexpected error: %v", Type, err)
+	}
+	if _, ok := res.(*Service); !ok {
+		t.Errorf("DefaultRegistry.New(%q) = %T, want *Service", Type, res)
+	}
+	if _, ok := res.(k8s.Renderer); !ok {
+		t.Errorf("%T should implement k8s.Renderer", res)
+	}
+}
+
+func TestService_Capabilities(t *testing.T) {
+	s := &Service{}
+	if provides := s.Provides(); len(provides) != 1 || provides[0].Type != resource.HTTPEndpoint {
+		t.Errorf("Provides() = %v, want a %s", provides, resource.HTTPEndpoint)
+	}
+	if requires := s.Requires(); len(requires) != 1 || requires[0].Type != resource.KubernetesWorkload {
+		t.Errorf("Requires() = %v, want a %s", requires, resource.KubernetesWorkload)
+	}
+}
+
+func TestService_ConfigSchema(t *testing.T) {
+	schema := (&Service{}).ConfigSchema()
+
+	tests := []struct {
+		name      string
+		config    map[string]any
+		wantValid bool
+	}{
+		{name: "minimal", config: map[string]any{"target": "api", "ports": []any{map[string]any{"port": 80}}}, wantValid: true},
+		{name: "load balancer", config: map[string]any{"target": "api", "type": "LoadBalancer", "ports": []any{map[string]any{"name": "http", "port": 80, "targetPort": 8080}}}, wantValid: true},
+		{name: "missing target", config: map[string]any{"ports": []any{map[string]any{"port": 80}}}, wantValid: false},
+		{name: "no ports", config: map[string]any{"target": "api", "ports": []any{}}, wantValid: false},
+		{name: "bad type", config: map[string]any{"target": "api", "type": "ExternalName", "ports": []any{map[string]any{"port": 80}}}, wantValid: false},
+		{name: "bad target", config: map[string]any{"target": "API", "ports": []any{map[string]any{"port": 80}}}, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := resource.ValidateConfig(schema, tt.config, "config")
+			if valid := len(errs) == 0; valid != tt.wantValid {
+				t.Errorf("ValidateConfig(%v) valid = %v, want %v (errors: %v)", tt.config, valid, tt.wantValid, errs)
+			}
+		})
+	}
+}
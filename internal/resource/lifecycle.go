@@ -0,0 +1,139 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrObjectNotFound is returned by a Provider for objects it does not hold.
+var ErrObjectNotFound = errors.New("object not found")
+
+// Lifecycle is implemented by resources Mahler can provision. Resources
+// without it are only described in the catalog.
+//
+// Every method receives the Provider to act on. Implementations keep no
+// state of their own, so that a service can be provisioned by any server.
+type Lifecycle interface {
+	// Plan returns the changes Apply would make, without making them.
+	Plan(ctx context.Context, p Provider, req Request) (*Plan, error)
+	// Apply makes the service's objects match its configuration.
+	Apply(ctx context.Context, p Provider, req Request) (*Result, error)
+	// Status reports how far the service's objects are from being ready.
+	Status(ctx context.Context, p Provider, svc ServiceRef) (*Status, error)
+	// Destroy removes every object of the service. Destroying a service
+	// without objects is not an error.
+	Destroy(ctx context.Context, p Provider, svc ServiceRef) error
+}
+
+// ServiceRef identifies the service a resource is provisioned for.
+type ServiceRef struct {
+	ProjectID uuid.UUID
+	ServiceID uuid.UUID
+	Name      string
+	// Namespace is the Kubernetes namespace of resources deployed to a
+	// cluster.
+	Namespace string
+}
+
+// Request is the desired state of a service.
+type Request struct {
+	Service ServiceRef
+	// Config has been validated against the resource's ConfigSchema.
+	Config map[string]any
+}
+
+// Action is what a Change does to an object.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Change is one object Apply creates, updates or deletes.
+type Change struct {
+	Action Action    `json:"action" enum:"create,update,delete"`
+	Object ObjectKey `json:"object"`
+}
+
+// Plan lists the changes needed to reach the desired state, in the order
+// they are made.
+type Plan struct {
+	Changes []Change `json:"changes"`
+	// Outputs are the values the service exposes once the plan is
+	// applied, named like the outputs of the capabilities its resource
+	// provides, e.g. "url".
+	Outputs map[string]any `json:"outputs,omitempty"`
+}
+
+// Empty reports whether the service is already up to date.
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Result is the outcome of Apply.
+type Result struct {
+	// Changes were made in this order.
+	Changes []Change `json:"changes"`
+}
+
+// Phase summarises the state of a service's objects.
+type Phase string
+
+const (
+	// PhaseMissing means the service has no objects.
+	PhaseMissing Phase = "missing"
+	// PhaseProgressing means some objects are not ready yet.
+	PhaseProgressing Phase = "progressing"
+	PhaseReady       Phase = "ready"
+	PhaseFailed      Phase = "failed"
+)
+
+// Status is the observed state of a service or one of its objects.
+type Status struct {
+	Phase   Phase  `json:"phase" enum:"missing,progressing,ready,failed"`
+	Message string `json:"message,omitempty"`
+}
+
+// Provider is the handle to the system resources are provisioned into, e.g.
+// a Kubernetes cluster. It stores objects by kind, namespace and name and
+// reports their status. Implementations must be safe for concurrent use.
+type Provider interface {
+	// Get returns the object stored under key, or ErrObjectNotFound.
+	Get(ctx context.Context, key ObjectKey) (*Object, error)
+	// Put creates or replaces an object. Its status is set by the provider.
+	Put(ctx context.Context, obj *Object) error
+	// Delete removes an object, or returns ErrObjectNotFound.
+	Delete(ctx context.Context, key ObjectKey) error
+	// List returns the objects of a service, ordered by key.
+	List(ctx context.Context, serviceID uuid.UUID) ([]*Object, error)
+}
+
+// ObjectKey identifies an object within a provider.
+type ObjectKey struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+func (k ObjectKey) String() string {
+	if k.Namespace == "" {
+		return k.Kind + "/" + k.Name
+	}
+	return fmt.Sprintf("%s/%s/%s", k.Kind, k.Namespace, k.Name)
+}
+
+// Object is something a provider runs on behalf of a service.
+type Object struct {
+	Key       ObjectKey
+	ServiceID uuid.UUID
+	// Spec is the desired state, e.g. a Kubernetes manifest as JSON.
+	Spec json.RawMessage
+	// Status is reported by the provider and ignored by Put.
+	Status Status
+}
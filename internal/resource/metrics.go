@@ -0,0 +1,38 @@
+package resource
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// MetricsQuery renders tmpl, a PromQL query template returned by
+// Resource.MetricsCPU or MetricsMemory, for svc. The template refers to the
+// service's identity as {{.ProjectID}}, {{.ServiceID}}, {{.Name}} and
+// {{.Namespace}}, escaped for use within double-quoted label values, e.g.
+//
+//	kube_pod_labels{label_mahler_io_service_id="{{.ServiceID}}"}
+func MetricsQuery(tmpl string, svc ServiceRef) (string, error) {
+	t, err := template.New("query").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parsing metrics query: %w", err)
+	}
+	data := struct {
+		ProjectID, ServiceID, Name, Namespace string
+	}{
+		ProjectID: labelValue(svc.ProjectID.String()),
+		ServiceID: labelValue(svc.ServiceID.String()),
+		Name:      labelValue(svc.Name),
+		Namespace: labelValue(svc.Namespace),
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("rendering metrics query: %w", err)
+	}
+	return b.String(), nil
+}
+
+// labelValue escapes s for a double-quoted PromQL string.
+func labelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,46 @@
+package resource_test
+
+import (
+	"testing"
+
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+func TestMetricsQuery(t *testing.T) {
+	svc := resource.ServiceRef{
+		ProjectID: uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		ServiceID: uuid.MustParse("22222222-2222-2222-2222-222222222222"),
+		Name:      `we"b`,
+		Namespace: "shop",
+	}
+
+	tests := []struct {
+		name    string
+		tmpl    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "identity",
+			tmpl: `up{project="{{.ProjectID}}",service="{{.ServiceID}}",namespace="{{.Namespace}}"}`,
+			want: `up{project="11111111-1111-1111-1111-111111111111",service="22222222-2222-2222-2222-222222222222",namespace="shop"}`,
+		},
+		{name: "escaped", tmpl: `up{name="{{.Name}}"}`, want: `up{name="we\"b"}`},
+		{name: "plain", tmpl: "up", want: "up"},
+		{name: "unknown_field", tmpl: "{{.Pod}}", wantErr: true},
+		{name: "invalid", tmpl: "{{", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resource.MetricsQuery(tt.tmpl, svc)
+			if tt.wantErr {
+				testutil.AssertError(t, err, "rendering should fail")
+				return
+			}
+			testutil.AssertNoError(t, err, "rendering should succeed")
+			testutil.AssertEqual(t, got, tt.want, "the identity should be filled in")
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Settings are the operator-provided options for a resource type, as read
+// from the configuration file.
+type Settings map[string]any
+
+// Decode stores the settings in the struct pointed to by v, using its json
+// tags. Unknown settings are an error.
+func (s Settings) Decode(v any) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// Factory creates a resource from its settings. Settings may be nil.
+type Factory func(settings Settings) (Resource, error)
+
+// TypeVersion identifies one registered version of a resource type.
+type TypeVersion struct {
+	Type    string
+	Version int
+}
+
+// Registry holds the factories of all known resource types.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]map[int]Factory
+}
+
+// DefaultRegistry is the registry resource packages add themselves to from
+// their init functions.
+var DefaultRegistry = NewRegistry()
+
+// Register adds a factory to DefaultRegistry and panics if the type and
+// version are already registered.
+func Register(typ string, version int, factory Factory) {
+	if err := DefaultRegistry.Register(typ, version, factory); err != nil {
+		panic(err)
+	}
+}
+
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]map[int]Factory)}
+}
+
+// Register adds a factory for version of typ. Versions start at 1.
+func (r *Registry) Register(typ string, version int, factory Factory) error {
+	if typ == "" {
+		return fmt.Errorf("resource type must not be empty")
+	}
+	if version < 1 {
+		return fmt.Errorf("resource type %s: version must be positive, got %d", typ, version)
+	}
+	if factory == nil {
+		return fmt.Errorf("resource type %s v%d: factory must not be nil", typ, version)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	versions, ok := r.factories[typ]
+	if !ok {
+		versions = make(map[int]Factory)
+		r.factories[typ] = versions
+	}
+	if _, ok := versions[version]; ok {
+		return fmt.Errorf("resource type %s v%d is already registered", typ, version)
+	}
+	versions[version] = factory
+	return nil
+}
+
+// Types lists every registered type and version, ordered by type and version.
+func (r *Registry) Types() []TypeVersion {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var types []TypeVersion
+	for typ, versions := range r.factories {
+		for version := range versions {
+			types = append(types, TypeVersion{Type: typ, Version: version})
+		}
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if types[i].Type != types[j].Type {
+			return types[i].Type < types[j].Type
+		}
+		return types[i].Version < types[j].Version
+	})
+	return types
+}
+
+// Latest returns the highest registered version of typ, or 0 if typ is
+// unknown.
+func (r *Registry) Latest(typ string) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	latest := 0
+	for version := range r.factories[typ] {
+		latest = max(latest, version)
+	}
+	return latest
+}
+
+// New creates a resource of the given type. Version 0 selects the latest
+// version.
+func (r *Registry) New(typ string, version int, settings Settings) (Resource, error) {
+	if version == 0 {
+		if version = r.Latest(typ); version == 0 {
+			return nil, fmt.Errorf("resource type %s is not registered", typ)
+		}
+	}
+
+	r.mu.RLock()
+	factory, ok := r.factories[typ][version]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("resource type %s v%d is not registered", typ, version)
+	}
+
+	res, err := factory(settings)
+	if err != nil {
+		return nil, fmt.Errorf("resource type %s v%d: %w", typ, version, err)
+	}
+	if res.Key() != typ {
+		return nil, fmt.Errorf("resource type %s v%d: factory returned resource with key %q", typ, version, res.Key())
+	}
+	return res, nil
+}
+
+// CatalogEntry selects a resource type for a Catalog.
+type CatalogEntry struct {
+	Type string
+	// Version is the type version to use, 0 for the latest.
+	Version  int
+	Settings Settings
+}
+
+// Catalog builds a catalog from entries. With no entries, the catalog offers
+// the latest version of every registered type with default settings.
+func (r *Registry) Catalog(entries []CatalogEntry) (*Catalog, error) {
+	if len(entries) == 0 {
+		seen := make(map[string]bool)
+		for _, tv := range r.Types() {
+			if !seen[tv.Type] {
+				seen[tv.Type] = true
+				entries = append(entries, CatalogEntry{Type: tv.Type})
+			}
+		}
+	}
+
+	catalog := &Catalog{}
+	for _, entry := range entries {
+		res, err := r.New(entry.Type, entry.Version, entry.Settings)
+		if err != nil {
+			return nil, err
+		}
+		version := entry.Version
+		if version == 0 {
+			version = r.Latest(entry.Type)
+		}
+		if err := catalog.Add(res, version); err != nil {
+			return nil, err
+		}
+	}
+	return catalog, nil
+}
@@ -0,0 +1,131 @@
+package resource_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+// mockFactory returns a factory creating mock resources with the given key,
+// priced from the "price" setting.
+func mockFactory(key string) resource.Factory {
+	return func(settings resource.Settings) (resource.Resource, error) {
+		var s struct {
+			Price float64 `json:"price"`
+		}
+		if err := settings.Decode(&s); err != nil {
+			return nil, err
+		}
+		return testutil.NewMockResource().WithKey(key).WithPrice(s.Price), nil
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := resource.NewRegistry()
+	testutil.AssertNoError(t, r.Register("vm", 1, mockFactory("vm")), "first registration should succeed")
+	testutil.AssertNoError(t, r.Register("vm", 2, mockFactory("vm")), "new version should be accepted")
+
+	tests := []struct {
+		name    string
+		typ     string
+		version int
+		factory resource.Factory
+	}{
+		{name: "duplicate", typ: "vm", version: 1, factory: mockFactory("vm")},
+		{name: "empty_type", typ: "", version: 1, factory: mockFactory("")},
+		{name: "zero_version", typ: "db", version: 0, factory: mockFactory("db")},
+		{name: "nil_factory", typ: "db", version: 1, factory: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.AssertError(t, r.Register(tt.typ, tt.version, tt.factory), "registration should fail")
+		})
+	}
+}
+
+func TestRegistry_TypesAndLatest(t *testing.T) {
+	r := resource.NewRegistry()
+	testutil.AssertNoError(t, r.Register("vm", 2, mockFactory("vm")), "register should succeed")
+	testutil.AssertNoError(t, r.Register("db", 1, mockFactory("db")), "register should succeed")
+	testutil.AssertNoError(t, r.Register("vm", 1, mockFactory("vm")), "register should succeed")
+
+	types := r.Types()
+	testutil.AssertEqual(t, len(types), 3, "all versions should be listed")
+	testutil.AssertEqual(t, types[0], resource.TypeVersion{Type: "db", Version: 1}, "types should be sorted by type")
+	testutil.AssertEqual(t, types[1], resource.TypeVersion{Type: "vm", Version: 1}, "versions should be sorted")
+	testutil.AssertEqual(t, r.Latest("vm"), 2, "latest should be the highest version")
+	testutil.AssertEqual(t, r.Latest("unknown"), 0, "unknown types have no latest version")
+}
+
+func TestRegistry_New(t *testing.T) {
+	r := resource.NewRegistry()
+	testutil.AssertNoError(t, r.Register("vm", 1, mockFactory("vm")), "register should succeed")
+	testutil.AssertNoError(t, r.Register("broken", 1, mockFactory("vm")), "register should succeed")
+	testutil.AssertNoError(t, r.Register("failing", 1, func(resource.Settings) (resource.Resource, error) {
+		return nil, errors.New("boom")
+	}), "register should succeed")
+
+	res, err := r.New("vm", 0, resource.Settings{"price": 2.5})
+	testutil.AssertNoError(t, err, "new should succeed")
+	testutil.AssertEqual(t, res.Key(), "vm", "resource should have the type key")
+	testutil.AssertEqual(t, res.Price(0), 2.5, "settings should reach the factory")
+
+	tests := []struct {
+		name     string
+		typ      string
+		version  int
+		settings resource.Settings
+		wantErr  string
+	}{
+		{name: "unknown_type", typ: "db", wantErr: "not registered"},
+		{name: "unknown_version", typ: "vm", version: 3, wantErr: "vm v3 is not registered"},
+		{name: "unknown_setting", typ: "vm", settings: resource.Settings{"colour": "red"}, wantErr: "unknown field"},
+		{name: "factory_error", typ: "failing", wantErr: "boom"},
+		{name: "key_mismatch", typ: "broken", wantErr: "key \"vm\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := r.New(tt.typ, tt.version, tt.settings)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistry_Catalog(t *testing.T) {
+	r := resource.NewRegistry()
+	testutil.AssertNoError(t, r.Register("vm", 1, mockFactory("vm")), "register should succeed")
+	testutil.AssertNoError(t, r.Register("vm", 2, mockFactory("vm")), "register should succeed")
+	testutil.AssertNoError(t, r.Register("db", 1, mockFactory("db")), "register should succeed")
+
+	t.Run("defaults_to_all_latest", func(t *testing.T) {
+		catalog, err := r.Catalog(nil)
+		testutil.AssertNoError(t, err, "catalog should build")
+		testutil.AssertEqual(t, len(catalog.Resources()), 2, "every type should be offered once")
+		testutil.AssertEqual(t, catalog.Version("vm"), 2, "latest version should be offered")
+	})
+
+	t.Run("uses_entries", func(t *testing.T) {
+		catalog, err := r.Catalog([]resource.CatalogEntry{
+			{Type: "vm", Version: 1, Settings: resource.Settings{"price": 1.0}},
+		})
+		testutil.AssertNoError(t, err, "catalog should build")
+		testutil.AssertEqual(t, len(catalog.Resources()), 1, "only configured types should be offered")
+		testutil.AssertEqual(t, catalog.Version("vm"), 1, "configured version should be offered")
+		testutil.AssertNil(t, catalog.Get("db"), "unconfigured type should not be offered")
+	})
+
+	t.Run("rejects_duplicates", func(t *testing.T) {
+		_, err := r.Catalog([]resource.CatalogEntry{{Type: "vm"}, {Type: "vm", Version: 1}})
+		testutil.AssertError(t, err, "type offered twice should fail")
+	})
+
+	t.Run("rejects_unknown_types", func(t *testing.T) {
+		_, err := r.Catalog([]resource.CatalogEntry{{Type: "mainframe"}})
+		testutil.AssertError(t, err, "unknown type should fail")
+	})
+}
@@ -0,0 +1,86 @@
+package resource
+
+import (
+	"reflect"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// SchemaFor returns the JSON Schema of T, with every nested type inlined so
+// the schema is self-contained. Field tags such as doc, minimum or pattern
+// are honoured like in API request bodies.
+func SchemaFor[T any]() *huma.Schema {
+	registry := huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
+	schema := huma.SchemaFromType(registry, reflect.TypeFor[T]())
+	return inline(registry, schema, map[string]bool{})
+}
+
+// inline replaces references with copies of the referenced schemas.
+// Recursive types keep their reference at the point of recursion.
+func inline(registry huma.Registry, s *huma.Schema, resolving map[string]bool) *huma.Schema {
+	if s == nil {
+		return nil
+	}
+	ref := s.Ref
+	if ref != "" {
+		if resolving[ref] {
+			return s
+		}
+		resolving[ref] = true
+		defer delete(resolving, ref)
+		s = registry.SchemaFromRef(ref)
+	}
+
+	cp := *s
+	if s.Properties != nil {
+		cp.Properties = make(map[string]*huma.Schema, len(s.Properties))
+		for name, prop := range s.Properties {
+			cp.Properties[name] = inline(registry, prop, resolving)
+		}
+	}
+	cp.Items = inline(registry, s.Items, resolving)
+	if extra, ok := s.AdditionalProperties.(*huma.Schema); ok {
+		cp.AdditionalProperties = inline(registry, extra, resolving)
+	}
+	cp.OneOf = inlineAll(registry, s.OneOf, resolving)
+	cp.AnyOf = inlineAll(registry, s.AnyOf, resolving)
+	cp.AllOf = inlineAll(registry, s.AllOf, resolving)
+	cp.Not = inline(registry, s.Not, resolving)
+	return &cp
+}
+
+func inlineAll(registry huma.Registry, schemas []*huma.Schema, resolving map[string]bool) []*huma.Schema {
+	if schemas == nil {
+		return nil
+	}
+	out := make([]*huma.Schema, len(schemas))
+	for i, s := range schemas {
+		out[i] = inline(registry, s, resolving)
+	}
+	return out
+}
+
+// ValidateConfig checks config against schema and returns one
+// huma.ErrorDetail per problem, located below prefix, e.g. "body.config".
+// A nil schema means the resource takes no configuration.
+func ValidateConfig(schema *huma.Schema, config map[string]any, prefix string) []error {
+	if schema == nil {
+		if len(config) == 0 {
+			return nil
+		}
+		return []error{&huma.ErrorDetail{
+			Location: prefix,
+			Message:  "resource does not take any configuration",
+			Value:    config,
+		}}
+	}
+	if config == nil {
+		config = map[string]any{}
+	}
+
+	registry := huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
+	pb := huma.NewPathBuffer([]byte(prefix), len(prefix))
+	res := &huma.ValidateResult{}
+	huma.Validate(registry, schema, pb, huma.ModeWriteToServer, config, res)
+	return res.Errors
+}
@@ -0,0 +1,99 @@
+package resource_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+type testLimits struct {
+	CPU string `json:"cpu" pattern:"^[0-9]+m$"`
+}
+
+type testConfig struct {
+	Image    string       `json:"image" minLength:"1"`
+	Replicas int          `json:"replicas,omitempty" minimum:"1" maximum:"10"`
+	Limits   *testLimits  `json:"limits,omitempty"`
+	Sidecars []testLimits `json:"sidecars,omitempty"`
+}
+
+func TestSchemaFor_InlinesNestedTypes(t *testing.T) {
+	schema := resource.SchemaFor[testConfig]()
+
+	testutil.AssertEqual(t, schema.Type, "object", "config schema should be an object")
+	testutil.AssertEqual(t, schema.Ref, "", "top-level schema should not be a reference")
+	testutil.AssertEqual(t, schema.Properties["limits"].Ref, "", "nested struct should be inlined")
+	testutil.AssertNotNil(t, schema.Properties["limits"].Properties["cpu"], "nested properties should be present")
+	testutil.AssertEqual(t, schema.Properties["sidecars"].Items.Ref, "", "array items should be inlined")
+}
+
+func TestValidateConfig(t *testing.T) {
+	schema := resource.SchemaFor[testConfig]()
+
+	tests := []struct {
+		name          string
+		schema        *huma.Schema
+		config        map[string]any
+		wantLocations []string
+	}{
+		{
+			name:   "valid",
+			schema: schema,
+			config: map[string]any{"image": "nginx", "replicas": 3, "limits": map[string]any{"cpu": "500m"}},
+		},
+		{
+			name:          "missing_required",
+			schema:        schema,
+			config:        nil,
+			wantLocations: []string{"body.config"},
+		},
+		{
+			name:          "out_of_range",
+			schema:        schema,
+			config:        map[string]any{"image": "nginx", "replicas": float64(11)},
+			wantLocations: []string{"body.config.replicas"},
+		},
+		{
+			name:          "nested_pattern",
+			schema:        schema,
+			config:        map[string]any{"image": "nginx", "limits": map[string]any{"cpu": "lots"}},
+			wantLocations: []string{"body.config.limits.cpu"},
+		},
+		{
+			name:          "unknown_field",
+			schema:        schema,
+			config:        map[string]any{"image": "nginx", "colour": "red"},
+			wantLocations: []string{"body.config.colour"},
+		},
+		{
+			name:   "no_schema_no_config",
+			schema: nil,
+			config: map[string]any{},
+		},
+		{
+			name:          "no_schema_with_config",
+			schema:        nil,
+			config:        map[string]any{"image": "nginx"},
+			wantLocations: []string{"body.config"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := resource.ValidateConfig(tt.schema, tt.config, "body.config")
+
+			var locations []string
+			for _, err := range errs {
+				detail, ok := err.(*huma.ErrorDetail)
+				if !ok {
+					t.Fatalf("error %v is a %T, want *huma.ErrorDetail", err, err)
+				}
+				locations = append(locations, detail.Location)
+			}
+			testutil.AssertEqual(t, strings.Join(locations, ","), strings.Join(tt.wantLocations, ","), "error locations should match")
+		})
+	}
+}
@@ -0,0 +1,310 @@
+// Package terraform_module offers existing Terraform modules as resource
+// types. The configuration schema is generated from a module's variables and
+// its capabilities from its outputs, so no Go code is needed per module.
+package terraform_module
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// Module is what Mahler reads from a module's configuration.
+type Module struct {
+	// Dir is the directory holding the module's .tf files.
+	Dir string
+	// Title and Description are taken from the heading and first paragraph
+	// of the module's README.md, if there is one.
+	Title       string
+	Description string
+	// Variables and Outputs are ordered by name.
+	Variables []Variable
+	Outputs   []Output
+}
+
+// Variable is an input variable of a module.
+type Variable struct {
+	Name        string
+	Description string
+	// Type is cty.DynamicPseudoType for variables declared without a type.
+	Type cty.Type
+	// Defaults holds the defaults of optional object attributes in Type.
+	Defaults *typeexpr.Defaults
+	// Default is cty.NilVal for required variables.
+	Default   cty.Value
+	Sensitive bool
+}
+
+// Required reports whether the variable has no default.
+func (v *Variable) Required() bool {
+	return v.Default == cty.NilVal
+}
+
+// Output is an output value of a module. Terraform does not declare output
+// types, so Type is inferred from the value expression: conversions with
+// tonumber or tobool and number or boolean literals give their type, all
+// other values are strings.
+type Output struct {
+	Name        string
+	Description string
+	Type        resource.OutputType
+	Sensitive   bool
+}
+
+var (
+	fileSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "variable", LabelNames: []string{"name"}},
+			{Type: "output", LabelNames: []string{"name"}},
+		},
+	}
+	variableSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "description"},
+			{Name: "type"},
+			{Name: "default"},
+			{Name: "sensitive"},
+		},
+	}
+	outputSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "value", Required: true},
+			{Name: "description"},
+			{Name: "sensitive"},
+		},
+	}
+)
+
+// Load reads the module in dir, which may be a plain directory or a git
+// checkout. All .tf and .tf.json files are read except override files,
+// which Terraform merges into the others.
+func Load(dir string) (*Module, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := hclparse.NewParser()
+	var files []*hcl.File
+	var diags hcl.Diagnostics
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || isOverride(name) {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		switch {
+		case strings.HasSuffix(name, ".tf"):
+			f, d := parser.ParseHCLFile(path)
+			files, diags = append(files, f), append(diags, d...)
+		case strings.HasSuffix(name, ".tf.json"):
+			f, d := parser.ParseJSONFile(path)
+			files, diags = append(files, f), append(diags, d...)
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s holds no Terraform files", dir)
+	}
+
+	m := &Module{Dir: dir}
+	seen := make(map[string]bool)
+	for _, f := range files {
+		content, _, d := f.Body.PartialContent(fileSchema)
+		diags = append(diags, d...)
+		for _, block := range content.Blocks {
+			key := block.Type + "." + block.Labels[0]
+			if seen[key] {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  fmt.Sprintf("Duplicate %s %q", block.Type, block.Labels[0]),
+					Subject:  block.DefRange.Ptr(),
+				})
+				continue
+			}
+			seen[key] = true
+
+			switch block.Type {
+			case "variable":
+				v, d := decodeVariable(block)
+				m.Variables, diags = append(m.Variables, v), append(diags, d...)
+			case "output":
+				o, d := decodeOutput(block)
+				m.Outputs, diags = append(m.Outputs, o), append(diags, d...)
+			}
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	slices.SortFunc(m.Variables, func(a, b Variable) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(m.Outputs, func(a, b Output) int { return strings.Compare(a.Name, b.Name) })
+
+	m.Title, m.Description, err = readReadme(filepath.Join(dir, "README.md"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return m, nil
+}
+
+func isOverride(name string) bool {
+	base := strings.TrimSuffix(strings.TrimSuffix(name, ".json"), ".tf")
+	return base == "override" || strings.HasSuffix(base, "_override")
+}
+
+func decodeVariable(block *hcl.Block) (Variable, hcl.Diagnostics) {
+	v := Variable{Name: block.Labels[0], Type: cty.DynamicPseudoType}
+	content, _, diags := block.Body.PartialContent(variableSchema)
+	if diags.HasErrors() {
+		return v, diags
+	}
+
+	if attr, ok := content.Attributes["type"]; ok {
+		var d hcl.Diagnostics
+		v.Type, v.Defaults, d = typeexpr.TypeConstraintWithDefaults(attr.Expr)
+		diags = append(diags, d...)
+	}
+	diags = append(diags, decodeString(content.Attributes["description"], &v.Description)...)
+	diags = append(diags, decodeBool(content.Attributes["sensitive"], &v.Sensitive)...)
+
+	if attr, ok := content.Attributes["default"]; ok && !diags.HasErrors() {
+		val, d := attr.Expr.Value(nil)
+		diags = append(diags, d...)
+		if d.HasErrors() {
+			return v, diags
+		}
+		if v.Defaults != nil && !val.IsNull() {
+			val = v.Defaults.Apply(val)
+		}
+		val, err := convert.Convert(val, v.Type)
+		if err != nil {
+			return v, append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid default value for variable",
+				Detail:   fmt.Sprintf("The default value of %s does not match its type: %s.", v.Name, err),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+		v.Default = val
+	}
+	return v, diags
+}
+
+func decodeOutput(block *hcl.Block) (Output, hcl.Diagnostics) {
+	o := Output{Name: block.Labels[0], Type: resource.OutputString}
+	content, _, diags := block.Body.PartialContent(outputSchema)
+	if diags.HasErrors() {
+		return o, diags
+	}
+	diags = append(diags, decodeString(content.Attributes["description"], &o.Description)...)
+	diags = append(diags, decodeBool(content.Attributes["sensitive"], &o.Sensitive)...)
+	o.Type = outputType(content.Attributes["value"].Expr)
+	return o, diags
+}
+
+func outputType(expr hcl.Expression) resource.OutputType {
+	if call, diags := hcl.ExprCall(expr); !diags.HasErrors() {
+		switch call.Name {
+		case "tonumber":
+			return resource.OutputNumber
+		case "tobool":
+			return resource.OutputBoolean
+		}
+	}
+	if val, diags := expr.Value(nil); !diags.HasErrors() {
+		switch val.Type() {
+		case cty.Number:
+			return resource.OutputNumber
+		case cty.Bool:
+			return resource.OutputBoolean
+		}
+	}
+	return resource.OutputString
+}
+
+// decodeString stores the constant string of attr, if set, in s.
+func decodeString(attr *hcl.Attribute, s *string) hcl.Diagnostics {
+	if attr == nil {
+		return nil
+	}
+	val, diags := constant(attr, cty.String)
+	if !diags.HasErrors() && !val.IsNull() {
+		*s = val.AsString()
+	}
+	return diags
+}
+
+// decodeBool stores the constant bool of attr, if set, in b.
+func decodeBool(attr *hcl.Attribute, b *bool) hcl.Diagnostics {
+	if attr == nil {
+		return nil
+	}
+	val, diags := constant(attr, cty.Bool)
+	if !diags.HasErrors() && !val.IsNull() {
+		*b = val.True()
+	}
+	return diags
+}
+
+func constant(attr *hcl.Attribute, ty cty.Type) (cty.Value, hcl.Diagnostics) {
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+	val, err := convert.Convert(val, ty)
+	if err != nil {
+		return cty.NilVal, append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Invalid %s", attr.Name),
+			Detail:   fmt.Sprintf("A %s is required: %s.", ty.FriendlyName(), err),
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+	}
+	return val, diags
+}
+
+// readReadme returns the first heading and the first paragraph of a
+// Markdown file. Badges, images and HTML are skipped.
+func readReadme(path string) (title, description string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	var paragraph []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			if len(paragraph) > 0 {
+				return title, strings.Join(paragraph, " "), nil
+			}
+		case strings.HasPrefix(line, "#"):
+			if title == "" {
+				title = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			}
+			if len(paragraph) > 0 {
+				return title, strings.Join(paragraph, " "), nil
+			}
+		case strings.HasPrefix(line, "[!["), strings.HasPrefix(line, "!["), strings.HasPrefix(line, "<"):
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	return title, strings.Join(paragraph, " "), scanner.Err()
+}
@@ -0,0 +1,104 @@
+package terraform_module
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestLoad(t *testing.T) {
+	m, err := Load("testdata/postgres")
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	if m.Title != "Managed Postgres" {
+		t.Errorf("Title = %q, want the README heading", m.Title)
+	}
+	if m.Description != "A Postgres database on the shared cluster, with daily backups." {
+		t.Errorf("Description = %q, want the first README paragraph", m.Description)
+	}
+
+	var names []string
+	for _, v := range m.Variables {
+		names = append(names, v.Name)
+	}
+	if got := strings.Join(names, ","); got != "admin_password,backup,extensions,extra,labels,name,storage_gb" {
+		t.Errorf("Variables = %s, want all variables ordered by name", got)
+	}
+	byName := make(map[string]Variable)
+	for _, v := range m.Variables {
+		byName[v.Name] = v
+	}
+	if v := byName["name"]; !v.Required() || v.Type != cty.String || v.Description != "Name of the database" {
+		t.Errorf("variable name = %+v, want a required, described string", v)
+	}
+	if v := byName["storage_gb"]; v.Required() || !v.Default.RawEquals(cty.NumberIntVal(10)) {
+		t.Errorf("variable storage_gb = %+v, want the default 10 from variables.tf", v)
+	}
+	if v := byName["admin_password"]; v.Required() || !v.Sensitive {
+		t.Errorf("variable admin_password = %+v, want an optional sensitive variable", v)
+	}
+	if v := byName["extra"]; v.Type != cty.DynamicPseudoType {
+		t.Errorf("variable extra type = %#v, want any", v.Type)
+	}
+	backup := byName["backup"].Default
+	if got := backup.GetAttr("retention_days"); !got.RawEquals(cty.NumberIntVal(7)) {
+		t.Errorf("backup default retention_days = %#v, want the optional attribute's default", got)
+	}
+
+	want := map[string]Output{
+		"host":            {Name: "host", Type: resource.OutputString},
+		"port":            {Name: "port", Type: resource.OutputNumber},
+		"username":        {Name: "username", Type: resource.OutputString, Description: "Administrator of the database"},
+		"password":        {Name: "password", Type: resource.OutputString, Sensitive: true},
+		"replicas":        {Name: "replicas", Type: resource.OutputNumber},
+		"backups_enabled": {Name: "backups_enabled", Type: resource.OutputString},
+		"database":        {Name: "database", Type: resource.OutputString},
+	}
+	if len(m.Outputs) != len(want) {
+		t.Fatalf("Outputs = %+v, want %d outputs", m.Outputs, len(want))
+	}
+	for _, o := range m.Outputs {
+		if o != want[o.Name] {
+			t.Errorf("output %s = %+v, want %+v", o.Name, o, want[o.Name])
+		}
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		dir     string
+		files   map[string]string
+		wantErr string
+	}{
+		{name: "missing", dir: "testdata/missing", wantErr: "no such file"},
+		{name: "no_terraform_files", files: map[string]string{"README.md": "# Empty"}, wantErr: "holds no Terraform files"},
+		{name: "default_mismatch", dir: "testdata/invalid", wantErr: "Invalid default value"},
+		{name: "syntax", files: map[string]string{"main.tf": `variable "a" {`}, wantErr: "Unclosed configuration block"},
+		{name: "duplicate", files: map[string]string{"main.tf": `variable "a" {}`, "more.tf": `variable "a" {}`}, wantErr: `Duplicate variable "a"`},
+		{name: "variable_reference", files: map[string]string{"main.tf": `variable "a" { default = var.b }`}, wantErr: "Variables not allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := tt.dir
+			if dir == "" {
+				dir = t.TempDir()
+				for name, content := range tt.files {
+					if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			_, err := Load(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
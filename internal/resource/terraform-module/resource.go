@@ -0,0 +1,158 @@
+package terraform_module
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Register loads the module in dir and adds it to r as version 1 of typ.
+// The module is read once; restart the server to pick up changes to it.
+func Register(r *resource.Registry, typ, dir string) error {
+	m, err := Load(dir)
+	if err != nil {
+		return fmt.Errorf("terraform module %s: %w", typ, err)
+	}
+	schema, err := m.ConfigSchema()
+	if err != nil {
+		return fmt.Errorf("terraform module %s: %w", typ, err)
+	}
+	return r.Register(typ, 1, func(settings resource.Settings) (resource.Resource, error) {
+		return New(typ, m, schema, settings)
+	})
+}
+
+// Settings are the operator options for a module resource.
+type Settings struct {
+	// Name and Description replace the ones read from the module's README.
+	Name         string  `json:"name"`
+	Description  string  `json:"description"`
+	PricePerHour float64 `json:"price_per_hour"`
+}
+
+// New creates the resource of type typ for module m, whose configuration
+// schema is schema.
+func New(typ string, m *Module, schema *huma.Schema, settings resource.Settings) (*Resource, error) {
+	var s Settings
+	if err := settings.Decode(&s); err != nil {
+		return nil, err
+	}
+	if s.PricePerHour < 0 {
+		return nil, fmt.Errorf("price_per_hour must not be negative, got %v", s.PricePerHour)
+	}
+
+	res := &Resource{
+		key:          typ,
+		name:         s.Name,
+		description:  s.Description,
+		module:       m,
+		schema:       schema,
+		pricePerHour: s.PricePerHour,
+	}
+	if res.name == "" {
+		res.name = m.Title
+	}
+	if res.name == "" {
+		res.name = filepath.Base(m.Dir)
+	}
+	if res.description == "" {
+		res.description = m.Description
+	}
+	if res.description == "" {
+		res.description = fmt.Sprintf("Provisions the Terraform module %s.", filepath.Base(m.Dir))
+	}
+	return res, nil
+}
+
+// Resource is a Terraform module offered as a resource type.
+type Resource struct {
+	key          string
+	name         string
+	description  string
+	module       *Module
+	schema       *huma.Schema
+	pricePerHour float64
+}
+
+// Module returns the module the resource provisions.
+func (r *Resource) Module() *Module {
+	return r.module
+}
+
+func (r *Resource) Key() string {
+	return r.key
+}
+
+func (r *Resource) Name() string {
+	return r.name
+}
+
+func (r *Resource) Description() string {
+	return r.description
+}
+
+// Provides offers every output of the module as a capability of the
+// resource's own type. Modules whose outputs include the standard outputs of
+// a well-known capability, such as host, port, database, username and
+// password, provide that capability as well.
+func (r *Resource) Provides() []resource.Capability {
+	if len(r.module.Outputs) == 0 {
+		return []resource.Capability{}
+	}
+
+	own := resource.Capability{Type: resource.CapabilityType(r.key)}
+	names := make(map[string]bool, len(r.module.Outputs))
+	for _, o := range r.module.Outputs {
+		own.Outputs = append(own.Outputs, resource.Output{
+			Name:        o.Name,
+			Type:        o.Type,
+			Sensitive:   o.Sensitive,
+			Description: o.Description,
+		})
+		names[o.Name] = true
+	}
+
+	provides := []resource.Capability{own}
+	for _, typ := range resource.StandardTypes() {
+		c := resource.Provide(typ)
+		if hasOutputs(names, c.Outputs) {
+			provides = append(provides, c)
+		}
+	}
+	return provides
+}
+
+func hasOutputs(names map[string]bool, outputs []resource.Output) bool {
+	for _, o := range outputs {
+		if !names[o.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+// Requires is empty; a module's inputs are its configuration.
+func (r *Resource) Requires() []resource.Requirement {
+	return []resource.Requirement{}
+}
+
+func (r *Resource) ConfigSchema() *huma.Schema {
+	return r.schema
+}
+
+func (r *Resource) Price(interval time.Duration) float64 {
+	return r.pricePerHour * interval.Hours()
+}
+
+// MetricsCPU is empty as what a module runs is not known.
+func (r *Resource) MetricsCPU() string {
+	return ""
+}
+
+// MetricsMemory is empty as what a module runs is not known.
+func (r *Resource) MetricsMemory() string {
+	return ""
+}
@@ -0,0 +1,94 @@
+package terraform_module
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/resource"
+)
+
+func TestRegister(t *testing.T) {
+	r := resource.NewRegistry()
+	if err := Register(r, "postgres", "testdata/postgres"); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+	if err := Register(r, "broken", "testdata/invalid"); err == nil {
+		t.Error("Register() should reject an invalid module")
+	}
+
+	catalog, err := r.Catalog(nil)
+	if err != nil {
+		t.Fatalf("Catalog() unexpected error: %v", err)
+	}
+	res, ok := catalog.Get("postgres").(*Resource)
+	if !ok {
+		t.Fatalf("catalog.Get(postgres) = %T, want *Resource", catalog.Get("postgres"))
+	}
+	if res.Name() != "Managed Postgres" || res.Description() != "A Postgres database on the shared cluster, with daily backups." {
+		t.Errorf("Name(), Description() = %q, %q, want them read from the README", res.Name(), res.Description())
+	}
+	if res.ConfigSchema() == nil || res.Module().Dir != "testdata/postgres" {
+		t.Error("the resource should carry the module and its schema")
+	}
+}
+
+func TestNew_Settings(t *testing.T) {
+	m := &Module{Dir: "modules/redis"}
+	res, err := New("redis", m, nil, resource.Settings{"price_per_hour": 0.5})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	if res.Name() != "redis" || res.Description() != "Provisions the Terraform module redis." {
+		t.Errorf("Name(), Description() = %q, %q, want defaults from the directory", res.Name(), res.Description())
+	}
+	if got := res.Price(2 * time.Hour); got != 1 {
+		t.Errorf("Price(2h) = %v, want 1", got)
+	}
+
+	res, err = New("redis", m, nil, resource.Settings{"name": "Redis", "description": "A cache."})
+	if err != nil || res.Name() != "Redis" || res.Description() != "A cache." {
+		t.Errorf("New() = %v, %v, want the configured name and description", res, err)
+	}
+	if _, err := New("redis", m, nil, resource.Settings{"price_per_hour": -1.0}); err == nil {
+		t.Error("New() should reject a negative price")
+	}
+	if _, err := New("redis", m, nil, resource.Settings{"replicas": 3}); err == nil {
+		t.Error("New() should reject unknown settings")
+	}
+}
+
+func TestResource_Provides(t *testing.T) {
+	m, err := Load("testdata/postgres")
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	res, err := New("postgres", m, nil, nil)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	provides := res.Provides()
+	if len(provides) != 2 {
+		t.Fatalf("Provides() = %+v, want the module's own and a standard capability", provides)
+	}
+	own := provides[0]
+	if own.Type != "postgres" || len(own.Outputs) != len(m.Outputs) {
+		t.Errorf("Provides()[0] = %+v, want every output under the resource's type", own)
+	}
+	for _, o := range own.Outputs {
+		if o.Name == "password" && !o.Sensitive {
+			t.Error("sensitive outputs should stay sensitive")
+		}
+	}
+	if provides[1].Type != resource.PostgresConnection {
+		t.Errorf("Provides()[1] = %s, want %s as its standard outputs are present", provides[1].Type, resource.PostgresConnection)
+	}
+	if len(res.Requires()) != 0 {
+		t.Errorf("Requires() = %v, want none", res.Requires())
+	}
+
+	bare, _ := New("bare", &Module{Dir: "bare"}, nil, nil)
+	if got := bare.Provides(); len(got) != 0 {
+		t.Errorf("Provides() without outputs = %+v, want none", got)
+	}
+}
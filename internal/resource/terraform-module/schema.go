@@ -0,0 +1,123 @@
+package terraform_module
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// ConfigSchema returns the JSON Schema of the module's variables, or nil if
+// it has none. Variables without a default are required; sensitive ones are
+// write-only.
+func (m *Module) ConfigSchema() (*huma.Schema, error) {
+	if len(m.Variables) == 0 {
+		return nil, nil
+	}
+
+	schema := &huma.Schema{
+		Type:                 huma.TypeObject,
+		Properties:           make(map[string]*huma.Schema, len(m.Variables)),
+		AdditionalProperties: false,
+	}
+	for _, v := range m.Variables {
+		prop, err := typeSchema(v.Type, v.Defaults)
+		if err != nil {
+			return nil, err
+		}
+		prop.Description = v.Description
+		prop.WriteOnly = v.Sensitive
+		if v.Required() {
+			schema.Required = append(schema.Required, v.Name)
+		} else if prop.Default, err = jsonValue(v.Default); err != nil {
+			return nil, err
+		}
+		schema.Properties[v.Name] = prop
+	}
+	schema.PrecomputeMessages()
+	return schema, nil
+}
+
+// typeSchema returns the JSON Schema of a Terraform type constraint. Values
+// of type any are not constrained.
+func typeSchema(ty cty.Type, defaults *typeexpr.Defaults) (*huma.Schema, error) {
+	s := &huma.Schema{}
+	switch {
+	case ty == cty.String:
+		s.Type = huma.TypeString
+	case ty == cty.Number:
+		s.Type = huma.TypeNumber
+	case ty == cty.Bool:
+		s.Type = huma.TypeBoolean
+	case ty.IsListType(), ty.IsSetType():
+		items, err := typeSchema(ty.ElementType(), child(defaults, ""))
+		if err != nil {
+			return nil, err
+		}
+		s.Type = huma.TypeArray
+		s.Items = items
+		s.UniqueItems = ty.IsSetType()
+	case ty.IsMapType():
+		values, err := typeSchema(ty.ElementType(), child(defaults, ""))
+		if err != nil {
+			return nil, err
+		}
+		s.Type = huma.TypeObject
+		s.AdditionalProperties = values
+	case ty.IsObjectType():
+		s.Type = huma.TypeObject
+		s.Properties = make(map[string]*huma.Schema)
+		s.AdditionalProperties = false
+		for name, attrType := range ty.AttributeTypes() {
+			prop, err := typeSchema(attrType, child(defaults, name))
+			if err != nil {
+				return nil, err
+			}
+			if !ty.AttributeOptional(name) {
+				s.Required = append(s.Required, name)
+			} else if defaults != nil {
+				if val, ok := defaults.DefaultValues[name]; ok {
+					if prop.Default, err = jsonValue(val); err != nil {
+						return nil, err
+					}
+				}
+			}
+			s.Properties[name] = prop
+		}
+		slices.Sort(s.Required)
+	case ty.IsTupleType():
+		n := len(ty.TupleElementTypes())
+		s.Type = huma.TypeArray
+		s.MinItems = &n
+		s.MaxItems = &n
+	}
+	s.PrecomputeMessages()
+	return s, nil
+}
+
+func child(defaults *typeexpr.Defaults, key string) *typeexpr.Defaults {
+	if defaults == nil {
+		return nil
+	}
+	return defaults.Children[key]
+}
+
+// jsonValue converts a known value to its JSON form, e.g. for a schema
+// default. Null is nil.
+func jsonValue(val cty.Value) (any, error) {
+	if val.IsNull() {
+		return nil, nil
+	}
+	b, err := ctyjson.Marshal(val, val.Type())
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,72 @@
+package terraform_module
+
+import (
+	"testing"
+
+	"github.com/Bermos/Platform/internal/resource"
+)
+
+func TestModule_ConfigSchema(t *testing.T) {
+	m, err := Load("testdata/postgres")
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	schema, err := m.ConfigSchema()
+	if err != nil {
+		t.Fatalf("ConfigSchema() unexpected error: %v", err)
+	}
+
+	if len(schema.Required) != 1 || schema.Required[0] != "name" {
+		t.Errorf("Required = %v, want only the variable without default", schema.Required)
+	}
+	if got := schema.Properties["storage_gb"].Default; got != float64(10) {
+		t.Errorf("storage_gb default = %v, want 10", got)
+	}
+	if got := schema.Properties["backup"].Properties["retention_days"].Default; got != float64(7) {
+		t.Errorf("backup.retention_days default = %v, want 7", got)
+	}
+	if !schema.Properties["admin_password"].WriteOnly {
+		t.Error("sensitive variables should be write-only")
+	}
+	if got := schema.Properties["name"].Description; got != "Name of the database" {
+		t.Errorf("name description = %q, want the variable's description", got)
+	}
+
+	tests := []struct {
+		name      string
+		config    map[string]any
+		wantValid bool
+	}{
+		{name: "minimal", config: map[string]any{"name": "orders"}, wantValid: true},
+		{name: "full", config: map[string]any{
+			"name":           "orders",
+			"storage_gb":     50,
+			"extensions":     []any{"postgis", "pgcrypto"},
+			"labels":         map[string]any{"team": "shop"},
+			"backup":         map[string]any{"enabled": false, "retention_days": 30},
+			"admin_password": "secret",
+			"extra":          []any{1, "two"},
+		}, wantValid: true},
+		{name: "missing_required", config: map[string]any{"storage_gb": 50}, wantValid: false},
+		{name: "unknown_variable", config: map[string]any{"name": "orders", "size": "large"}, wantValid: false},
+		{name: "wrong_type", config: map[string]any{"name": "orders", "storage_gb": "50"}, wantValid: false},
+		{name: "duplicate_set_items", config: map[string]any{"name": "orders", "extensions": []any{"postgis", "postgis"}}, wantValid: false},
+		{name: "map_value_type", config: map[string]any{"name": "orders", "labels": map[string]any{"team": 1}}, wantValid: false},
+		{name: "missing_object_attribute", config: map[string]any{"name": "orders", "backup": map[string]any{"retention_days": 30}}, wantValid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := resource.ValidateConfig(schema, tt.config, "config")
+			if valid := len(errs) == 0; valid != tt.wantValid {
+				t.Errorf("ValidateConfig(%v) valid = %v, want %v (errors: %v)", tt.config, valid, tt.wantValid, errs)
+			}
+		})
+	}
+}
+
+func TestModule_ConfigSchema_NoVariables(t *testing.T) {
+	schema, err := (&Module{}).ConfigSchema()
+	if err != nil || schema != nil {
+		t.Errorf("ConfigSchema() = %v, %v, want no schema for a module without variables", schema, err)
+	}
+}
@@ -0,0 +1,15 @@
+terraform {
+  required_providers {
+    random = {
+      source = "hashicorp/random"
+    }
+  }
+}
+
+resource "random_password" "admin" {
+  length = 24
+}
+
+locals {
+  host = "${var.name}.db.internal"
+}
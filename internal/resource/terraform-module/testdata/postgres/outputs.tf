@@ -0,0 +1,29 @@
+output "host" {
+  value = local.host
+}
+
+output "port" {
+  value = 5432
+}
+
+output "database" {
+  value = var.name
+}
+
+output "username" {
+  value       = "admin"
+  description = "Administrator of the database"
+}
+
+output "password" {
+  value     = random_password.admin.result
+  sensitive = true
+}
+
+output "replicas" {
+  value = tonumber(var.storage_gb > 100 ? 2 : 1)
+}
+
+output "backups_enabled" {
+  value = var.backup.enabled
+}
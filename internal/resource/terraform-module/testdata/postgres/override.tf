@@ -0,0 +1,3 @@
+variable "storage_gb" {
+  default = 20
+}
@@ -0,0 +1,43 @@
+variable "name" {
+  type        = string
+  description = "Name of the database"
+
+  validation {
+    condition     = length(var.name) <= 63
+    error_message = "The name must be at most 63 characters."
+  }
+}
+
+variable "storage_gb" {
+  type    = number
+  default = 10
+}
+
+variable "extensions" {
+  type    = set(string)
+  default = []
+}
+
+variable "labels" {
+  type    = map(string)
+  default = {}
+}
+
+variable "backup" {
+  type = object({
+    enabled        = bool
+    retention_days = optional(number, 7)
+  })
+  default = { enabled = true }
+}
+
+variable "admin_password" {
+  type      = string
+  sensitive = true
+  default   = null
+}
+
+variable "extra" {
+  description = "Passed through as is"
+  default     = null
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrDependencyCycle is returned when services depend on each other in a
+// cycle, directly or through other services.
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// Levels groups services by how deep they are in the dependency graph. The
+// first level holds the services depending on none of the others; every
+// later level holds those whose dependencies are all in earlier levels. The
+// services of one level can be provisioned in parallel once the levels
+// before them are, and destroyed in parallel once the levels after them
+// are. Each level is ordered by name, and dependencies on services not in
+// services are ignored.
+//
+// Services depending on each other in a cycle have no level; Levels fails
+// with an error wrapping ErrDependencyCycle that names them.
+func Levels(services []*Service) ([][]*Service, error) {
+	byID := make(map[uuid.UUID]*Service, len(services))
+	for _, svc := range services {
+		byID[svc.ID] = svc
+	}
+	// pending counts the dependencies of each service without a level yet.
+	pending := make(map[uuid.UUID]int, len(services))
+	dependents := make(map[uuid.UUID][]*Service, len(services))
+	for _, svc := range services {
+		for _, dep := range svc.DependsOn {
+			if _, ok := byID[dep]; ok {
+				pending[svc.ID]++
+				dependents[dep] = append(dependents[dep], svc)
+			}
+		}
+	}
+
+	var level []*Service
+	for _, svc := range services {
+		if pending[svc.ID] == 0 {
+			level = append(level, svc)
+		}
+	}
+	levels := [][]*Service{}
+	placed := 0
+	for len(level) > 0 {
+		slices.SortFunc(level, func(a, b *Service) int { return strings.Compare(a.Name, b.Name) })
+		levels = append(levels, level)
+		placed += len(level)
+
+		var next []*Service
+		for _, svc := range level {
+			for _, dependent := range dependents[svc.ID] {
+				if pending[dependent.ID]--; pending[dependent.ID] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+		level = next
+	}
+	if placed < len(services) {
+		return nil, cycleError(byID, pending)
+	}
+	return levels, nil
+}
+
+// Order returns services in dependency order: every service comes after
+// the services it depends on. See Levels.
+func Order(services []*Service) ([]*Service, error) {
+	levels, err := Levels(services)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Concat(levels...), nil
+}
+
+// cycleError names the services of one cycle among those with pending
+// dependencies, which all lie on or behind a cycle.
+func cycleError(byID map[uuid.UUID]*Service, pending map[uuid.UUID]int) error {
+	// Follow unplaced dependencies from an unplaced service until a
+	// service repeats; every unplaced service has one.
+	var start *Service
+	for _, svc := range byID {
+		if pending[svc.ID] > 0 && (start == nil || svc.Name < start.Name) {
+			start = svc
+		}
+	}
+	seen := map[uuid.UUID]int{}
+	var path []*Service
+	for svc := start; ; {
+		if i, ok := seen[svc.ID]; ok {
+			path = append(path[i:], svc)
+			break
+		}
+		seen[svc.ID] = len(path)
+		path = append(path, svc)
+		for _, dep := range svc.DependsOn {
+			if next, ok := byID[dep]; ok && pending[dep] > 0 {
+				svc = next
+				break
+			}
+		}
+	}
+	names := make([]string, len(path))
+	for i, svc := range path {
+		names[i] = svc.Name
+	}
+	return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(names, " -> "))
+}
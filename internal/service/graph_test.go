@@ -0,0 +1,100 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// graph returns services named after the keys of deps, each depending on
+// the services listed.
+func graph(deps map[string][]string) []*Service {
+	byName := make(map[string]*Service, len(deps))
+	services := make([]*Service, 0, len(deps))
+	for name := range deps {
+		svc := &Service{ID: uuid.New(), Name: name}
+		byName[name] = svc
+		services = append(services, svc)
+	}
+	for name, on := range deps {
+		for _, dep := range on {
+			byName[name].DependsOn = append(byName[name].DependsOn, byName[dep].ID)
+		}
+	}
+	return services
+}
+
+func names(services []*Service) string {
+	out := make([]string, len(services))
+	for i, svc := range services {
+		out[i] = svc.Name
+	}
+	return strings.Join(out, ",")
+}
+
+func TestLevels(t *testing.T) {
+	services := graph(map[string][]string{
+		"web":    {"api", "cdn"},
+		"api":    {"db", "cache"},
+		"worker": {"db"},
+		"db":     nil,
+		"cache":  nil,
+		"cdn":    nil,
+	})
+	// Dependencies outside the services are ignored.
+	services[0].DependsOn = append(services[0].DependsOn, uuid.New())
+
+	levels, err := Levels(services)
+	if err != nil {
+		t.Fatalf("Levels() error = %v", err)
+	}
+	got := make([]string, len(levels))
+	for i, level := range levels {
+		got[i] = names(level)
+	}
+	want := []string{"cache,cdn,db", "api,worker", "web"}
+	if strings.Join(got, " | ") != strings.Join(want, " | ") {
+		t.Errorf("Levels() = %v, want %v", got, want)
+	}
+
+	order, err := Order(services)
+	if err != nil {
+		t.Fatalf("Order() error = %v", err)
+	}
+	if got := names(order); got != "cache,cdn,db,api,worker,web" {
+		t.Errorf("Order() = %s, want dependencies first", got)
+	}
+
+	if levels, err := Levels(nil); err != nil || len(levels) != 0 {
+		t.Errorf("Levels(nil) = %v, %v, want no levels", levels, err)
+	}
+}
+
+func TestLevels_Cycle(t *testing.T) {
+	tests := []struct {
+		name string
+		deps map[string][]string
+		want string
+	}{
+		{name: "self", deps: map[string][]string{"api": {"api"}}, want: "api -> api"},
+		{name: "pair", deps: map[string][]string{"api": {"db"}, "db": {"api"}}, want: "api -> db -> api"},
+		{
+			name: "behind_other_services",
+			deps: map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"d"}, "d": {"c"}, "e": nil},
+			want: "c -> d -> c",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Levels(graph(tt.deps))
+			if !errors.Is(err, ErrDependencyCycle) {
+				t.Fatalf("Levels() error = %v, want ErrDependencyCycle", err)
+			}
+			if !strings.HasSuffix(err.Error(), ": "+tt.want) {
+				t.Errorf("Levels() error = %q, want it to name %s", err, tt.want)
+			}
+		})
+	}
+}
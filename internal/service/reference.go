@@ -0,0 +1,127 @@
+package service
+
+import (
+	"encoding/json"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// referencePattern matches references in configuration strings, and the
+// escaped form $${, which stands for a literal ${.
+var referencePattern = regexp.MustCompile(`\$\$\{|\$\{([a-z][-a-z0-9]*)\.([A-Za-z_][-A-Za-z0-9_]*)\}`)
+
+// Reference is a reference in a service's configuration to an output of a
+// service it depends on, written ${service.output} with the dependency's
+// name, e.g. ${db.host}.
+type Reference struct {
+	Service string
+	Output  string
+}
+
+func (r Reference) String() string {
+	return "${" + r.Service + "." + r.Output + "}"
+}
+
+// References returns the distinct references in the strings of config, in
+// the order they appear with map keys sorted.
+func References(config map[string]any) []Reference {
+	var refs []Reference
+	seen := map[Reference]bool{}
+	Resolve(config, func(ref Reference) (any, bool) {
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+		return nil, false
+	})
+	return refs
+}
+
+// HasReference reports whether s contains a reference.
+func HasReference(s string) bool {
+	for _, m := range referencePattern.FindAllStringSubmatchIndex(s, -1) {
+		if m[2] >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns a copy of config with the references lookup knows
+// replaced by the value it returns. A string that is a single reference
+// becomes the output's value, whatever its type; references within longer
+// strings are replaced by the value as text, and $${ by a literal ${. The
+// references lookup does not know are left in place and returned.
+func Resolve(config map[string]any, lookup func(Reference) (any, bool)) (map[string]any, []Reference) {
+	if config == nil {
+		return nil, nil
+	}
+	var unresolved []Reference
+	resolved := resolveValue(config, lookup, &unresolved).(map[string]any)
+	return resolved, unresolved
+}
+
+func resolveValue(v any, lookup func(Reference) (any, bool), unresolved *[]Reference) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			out[k] = resolveValue(v[k], lookup, unresolved)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = resolveValue(e, lookup, unresolved)
+		}
+		return out
+	case string:
+		return resolveString(v, lookup, unresolved)
+	default:
+		return v
+	}
+}
+
+func resolveString(s string, lookup func(Reference) (any, bool), unresolved *[]Reference) any {
+	if m := referencePattern.FindStringSubmatchIndex(s); m != nil && m[0] == 0 && m[1] == len(s) && m[2] >= 0 {
+		ref := Reference{Service: s[m[2]:m[3]], Output: s[m[4]:m[5]]}
+		if value, ok := lookup(ref); ok {
+			return value
+		}
+		*unresolved = append(*unresolved, ref)
+		return s
+	}
+	return referencePattern.ReplaceAllStringFunc(s, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+		sub := referencePattern.FindStringSubmatch(match)
+		ref := Reference{Service: sub[1], Output: sub[2]}
+		value, ok := lookup(ref)
+		if !ok {
+			*unresolved = append(*unresolved, ref)
+			return match
+		}
+		return valueText(value)
+	})
+}
+
+// valueText formats a decoded JSON value for use within a string.
+func valueText(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
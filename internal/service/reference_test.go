@@ -0,0 +1,68 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	config := map[string]any{
+		"url":  "${db.url}",
+		"port": "${db.port}",
+		"env": []any{
+			map[string]any{"name": "DSN", "value": "postgres://${db.host}:${db.port}/${db.name}"},
+			map[string]any{"name": "TEMPLATE", "value": "$${HOME}/${cache.missing}"},
+		},
+		"replicas": float64(2),
+	}
+	outputs := map[Reference]any{
+		{Service: "db", Output: "url"}:  "postgres://db:5432/shop",
+		{Service: "db", Output: "host"}: "db",
+		{Service: "db", Output: "port"}: float64(5432),
+		{Service: "db", Output: "name"}: "shop",
+	}
+
+	got, unresolved := Resolve(config, func(ref Reference) (any, bool) {
+		v, ok := outputs[ref]
+		return v, ok
+	})
+	want := map[string]any{
+		"url":  "postgres://db:5432/shop",
+		"port": float64(5432),
+		"env": []any{
+			map[string]any{"name": "DSN", "value": "postgres://db:5432/shop"},
+			map[string]any{"name": "TEMPLATE", "value": "${HOME}/${cache.missing}"},
+		},
+		"replicas": float64(2),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Resolve() = %v, want %v", got, want)
+	}
+	if len(unresolved) != 1 || unresolved[0].String() != "${cache.missing}" {
+		t.Errorf("Resolve() unresolved = %v, want ${cache.missing}", unresolved)
+	}
+	if config["port"] != "${db.port}" {
+		t.Error("Resolve() changed its input")
+	}
+
+	refs := References(config)
+	wantRefs := []Reference{{"db", "host"}, {"db", "port"}, {"db", "name"}, {"cache", "missing"}, {"db", "url"}}
+	if !reflect.DeepEqual(refs, wantRefs) {
+		t.Errorf("References() = %v, want %v", refs, wantRefs)
+	}
+}
+
+func TestHasReference(t *testing.T) {
+	tests := map[string]bool{
+		"${db.host}":         true,
+		"http://${web.url}/": true,
+		"$${db.host}":        false,
+		"${HOME}":            false,
+		"plain":              false,
+	}
+	for s, want := range tests {
+		if got := HasReference(s); got != want {
+			t.Errorf("HasReference(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
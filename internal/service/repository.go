@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotFound is returned by a Repository when a service does not exist.
+	ErrNotFound = errors.New("service not found")
+	// ErrAlreadyExists is returned by a Repository when a service with the
+	// same ID exists, or a service with the same name exists in the same
+	// project.
+	ErrAlreadyExists = errors.New("service already exists")
+	// ErrInvalidDependency is returned by a Repository when a service
+	// depends on itself, on a service twice or on a service that does not
+	// exist in its project.
+	ErrInvalidDependency = errors.New("invalid dependency")
+	// ErrHasDependents is returned by a Repository when deleting a service
+	// other services depend on.
+	ErrHasDependents = errors.New("other services depend on the service")
+)
+
+// Repository persists services.
+//
+// Implementations must return errors that match ErrNotFound,
+// ErrAlreadyExists, ErrInvalidDependency and ErrHasDependents with
+// errors.Is. Service names are unique within a project. Create and Update
+// store DependsOn in its order, replacing the previous dependencies. The
+// servicetest package contains a conformance suite every implementation is
+// expected to pass.
+type Repository interface {
+	Create(ctx context.Context, svc *Service) error
+	Get(ctx context.Context, id uuid.UUID) (*Service, error)
+	Update(ctx context.Context, svc *Service) error
+	// UpdateDrift sets the drift of service id, or clears it when drift is
+	// nil, leaving everything else as it is.
+	UpdateDrift(ctx context.Context, id uuid.UUID, drift *Drift) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListByProject returns the services of one project ordered by name. It
+	// returns an empty, non-nil slice when the project has no services.
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]*Service, error)
+	// Transition validates t, sets the status of service t.ServiceID to t.To
+	// and appends t to the service's history, atomically. It fails with
+	// ErrStatusConflict when the service's status is no longer t.From.
+	Transition(ctx context.Context, t *Transition) error
+	// History returns the transitions of a service, oldest first. Deleting
+	// a service deletes its history.
+	History(ctx context.Context, id uuid.UUID) ([]*Transition, error)
+}
@@ -1,12 +1,139 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
 	"github.com/Bermos/Platform/internal/resource"
+	"github.com/Bermos/Platform/internal/terraform"
 	"github.com/google/uuid"
 )
 
+// MaxNameLength is the longest name a service may have. Service names end up
+// in Kubernetes object names and labels, which are limited to 63 characters.
+const MaxNameLength = 63
+
+// NamePattern is the pattern every service name must match.
+const NamePattern = `^[a-z]([-a-z0-9]*[a-z0-9])?$`
+
+var nameRegexp = regexp.MustCompile(NamePattern)
+
+// ErrInvalidName is returned when a service name does not match NamePattern.
+var ErrInvalidName = errors.New("invalid service name")
+
 type Service struct {
-	ID       uuid.UUID `json:"id"`
-	Name     string    `json:"name"`
-	Resource resource.Resource
+	ID          uuid.UUID `json:"id"`
+	ProjectID   uuid.UUID `json:"projectId"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	// ResourceKey is the Key of the resource the service runs on. It is what
+	// repositories persist; Resource is resolved from it at runtime.
+	ResourceKey string            `json:"resourceKey"`
+	Resource    resource.Resource `json:"-"`
+	// Config is the resource configuration, valid against the resource's
+	// ConfigSchema. It holds decoded JSON values. Strings may reference
+	// outputs of the services it depends on, see Resolve.
+	Config map[string]any `json:"config,omitempty"`
+	// DependsOn lists the IDs of the services in the same project this one
+	// depends on, which are provisioned before it and destroyed after it.
+	DependsOn []uuid.UUID `json:"dependsOn,omitempty"`
+	// Status is set on Create and afterwards only changed through
+	// Repository.Transition; Update leaves it alone.
+	Status Status `json:"status"`
+	// Drift is what drift detection last found changed outside of Mahler,
+	// if anything. It is cleared once the service is reconciled.
+	Drift *Drift `json:"drift,omitempty"`
+	// Outputs are the values the service exposed after it was last
+	// provisioned, by name, such as its URL. Every successful apply
+	// replaces them. They are never encoded with the service, so that
+	// sensitive values are only shown when asked for.
+	Outputs map[string]Output `json:"-"`
+}
+
+// Output is a value a provisioned service exposes.
+type Output struct {
+	// Value holds a decoded JSON value.
+	Value any `json:"value"`
+	// Sensitive values, such as passwords, are only shown on request.
+	Sensitive bool `json:"sensitive,omitempty"`
+}
+
+// CloneOutputs returns a deep copy of outputs.
+func CloneOutputs(outputs map[string]Output) map[string]Output {
+	if outputs == nil {
+		return nil
+	}
+	out := make(map[string]Output, len(outputs))
+	for name, o := range outputs {
+		o.Value = cloneValue(o.Value)
+		out[name] = o
+	}
+	return out
+}
+
+// Drift is how a service's infrastructure differs from its configuration,
+// as found by drift detection.
+type Drift struct {
+	DetectedAt time.Time `json:"detectedAt"`
+	// Changes are the changes made outside of Mahler. Their actions say
+	// what happened to a resource: update means its attributes were
+	// changed, with Before as configured and After as found; delete means
+	// it was removed and create that it appeared.
+	Changes *terraform.Diff `json:"changes"`
+}
+
+// Clone returns a deep copy of d.
+func (d *Drift) Clone() *Drift {
+	if d == nil {
+		return nil
+	}
+	clone := *d
+	clone.Changes = d.Changes.Clone()
+	return &clone
+}
+
+// CloneConfig returns a deep copy of a decoded JSON configuration.
+func CloneConfig(config map[string]any) map[string]any {
+	if config == nil {
+		return nil
+	}
+	return cloneValue(config).(map[string]any)
+}
+
+func cloneValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = cloneValue(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = cloneValue(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// ValidateName reports whether name is usable as a service name. The
+// returned error wraps ErrInvalidName.
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: name must not be empty", ErrInvalidName)
+	}
+	if len(name) > MaxNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidName, MaxNameLength)
+	}
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("%w: name must consist of lowercase letters, digits and hyphens, start with a letter and not end with a hyphen", ErrInvalidName)
+	}
+	return nil
 }
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "simple_name", input: "api", wantErr: false},
+		{name: "name_with_hyphens_and_digits", input: "postgres-15", wantErr: false},
+		{name: "max_length", input: strings.Repeat("s", MaxNameLength), wantErr: false},
+		{name: "empty", input: "", wantErr: true},
+		{name: "too_long", input: strings.Repeat("s", MaxNameLength+1), wantErr: true},
+		{name: "uppercase", input: "API", wantErr: true},
+		{name: "starts_with_hyphen", input: "-api", wantErr: true},
+		{name: "contains_dot", input: "api.v1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateName(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidName) {
+					t.Errorf("ValidateName(%q) = %v, want ErrInvalidName", tt.input, err)
+				}
+			} else if err != nil {
+				t.Errorf("ValidateName(%q) unexpected error: %v", tt.input, err)
+			}
+		})
+	}
+}
+
+func TestCloneConfig(t *testing.T) {
+	if got := CloneConfig(nil); got != nil {
+		t.Errorf("CloneConfig(nil) = %v, want nil", got)
+	}
+
+	original := map[string]any{
+		"image": "nginx",
+		"env":   []any{map[string]any{"name": "A"}},
+	}
+	clone := CloneConfig(original)
+	clone["image"] = "redis"
+	clone["env"].([]any)[0].(map[string]any)["name"] = "B"
+
+	if original["image"] != "nginx" {
+		t.Errorf("top-level value changed to %v, want nginx", original["image"])
+	}
+	if name := original["env"].([]any)[0].(map[string]any)["name"]; name != "A" {
+		t.Errorf("nested value changed to %v, want A", name)
+	}
+}
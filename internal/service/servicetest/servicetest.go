@@ -0,0 +1,521 @@
+// Package servicetest provides a conformance suite for service.Repository
+// implementations.
+package servicetest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/Bermos/Platform/internal/terraform"
+	"github.com/google/uuid"
+)
+
+// RunRepositoryTests runs the conformance suite against repositories returned
+// by newRepo. newRepo is called once per subtest and must return an empty
+// repository.
+//
+// Some backends require services to belong to an existing project. newProject
+// is called whenever the suite needs a project and must return the ID of a
+// project the repository accepts services for.
+func RunRepositoryTests(t *testing.T, newRepo func(t *testing.T) service.Repository, newProject func(t *testing.T, repo service.Repository) uuid.UUID) {
+	t.Helper()
+
+	t.Run("create_and_get", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		svc := newService(newProject(t, repo), "api")
+
+		if err := repo.Create(ctx, svc); err != nil {
+			t.Fatalf("Create: unexpected error: %v", err)
+		}
+		got, err := repo.Get(ctx, svc.ID)
+		if err != nil {
+			t.Fatalf("Get: unexpected error: %v", err)
+		}
+		assertService(t, got, svc)
+	})
+
+	t.Run("create_duplicate_id", func(t *testing.T) {
+		repo := newRepo(t)
+		svc := newService(newProject(t, repo), "api")
+		mustCreate(t, repo, svc)
+
+		dup := newService(svc.ProjectID, "web")
+		dup.ID = svc.ID
+		if err := repo.Create(context.Background(), dup); !errors.Is(err, service.ErrAlreadyExists) {
+			t.Errorf("Create with duplicate ID: got %v, want ErrAlreadyExists", err)
+		}
+	})
+
+	t.Run("create_duplicate_name_in_project", func(t *testing.T) {
+		repo := newRepo(t)
+		projectID := newProject(t, repo)
+		mustCreate(t, repo, newService(projectID, "api"))
+
+		if err := repo.Create(context.Background(), newService(projectID, "api")); !errors.Is(err, service.ErrAlreadyExists) {
+			t.Errorf("Create with duplicate name: got %v, want ErrAlreadyExists", err)
+		}
+	})
+
+	t.Run("create_same_name_in_other_project", func(t *testing.T) {
+		repo := newRepo(t)
+		mustCreate(t, repo, newService(newProject(t, repo), "api"))
+
+		if err := repo.Create(context.Background(), newService(newProject(t, repo), "api")); err != nil {
+			t.Errorf("Create with name used in another project: unexpected error: %v", err)
+		}
+	})
+
+	t.Run("get_not_found", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if _, err := repo.Get(context.Background(), uuid.New()); !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("Get of unknown ID: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		svc := newService(newProject(t, repo), "api")
+		mustCreate(t, repo, svc)
+
+		changed := *svc
+		changed.Name = "gateway"
+		changed.Description = "Renamed"
+		changed.ResourceKey = "other-resource"
+		changed.Config = map[string]any{"image": "nginx:1.28"}
+		changed.Drift = &service.Drift{
+			DetectedAt: svc.UpdatedAt.Add(time.Second),
+			Changes: &terraform.Diff{Resources: []terraform.ResourceDiff{{
+				Address:    "Deployment/shop/api",
+				Type:       "Deployment",
+				Action:     terraform.DiffUpdate,
+				Attributes: []terraform.AttributeChange{{Path: "spec.replicas", Before: float64(2), After: float64(5)}},
+			}}},
+		}
+		changed.Outputs = map[string]service.Output{
+			"url":      {Value: "http://api.shop.svc.cluster.local:80"},
+			"password": {Value: "secret", Sensitive: true},
+		}
+		changed.UpdatedAt = svc.UpdatedAt.Add(time.Minute)
+		changed.Status = service.StatusReady
+		if err := repo.Update(ctx, &changed); err != nil {
+			t.Fatalf("Update: unexpected error: %v", err)
+		}
+		got, err := repo.Get(ctx, svc.ID)
+		if err != nil {
+			t.Fatalf("Get: unexpected error: %v", err)
+		}
+		changed.Status = svc.Status
+		assertService(t, got, &changed)
+
+		changed.Drift = nil
+		changed.Outputs = nil
+		if err := repo.Update(ctx, &changed); err != nil {
+			t.Fatalf("Update clearing drift: unexpected error: %v", err)
+		}
+		if got, _ := repo.Get(ctx, svc.ID); got.Outputs != nil {
+			t.Errorf("Outputs after clearing: got %+v, want nil", got.Outputs)
+		}
+		if got, _ := repo.Get(ctx, svc.ID); got.Drift != nil {
+			t.Errorf("Drift after clearing: got %+v, want nil", got.Drift)
+		}
+	})
+
+	t.Run("update_drift", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		svc := newService(newProject(t, repo), "api")
+		mustCreate(t, repo, svc)
+
+		drift := &service.Drift{
+			DetectedAt: svc.UpdatedAt.Add(time.Second),
+			Changes: &terraform.Diff{Resources: []terraform.ResourceDiff{{
+				Address:    "Deployment/shop/api",
+				Type:       "Deployment",
+				Action:     terraform.DiffDelete,
+				Attributes: []terraform.AttributeChange{},
+			}}},
+		}
+		if err := repo.UpdateDrift(ctx, svc.ID, drift); err != nil {
+			t.Fatalf("UpdateDrift: unexpected error: %v", err)
+		}
+		got, err := repo.Get(ctx, svc.ID)
+		if err != nil {
+			t.Fatalf("Get: unexpected error: %v", err)
+		}
+		want := *svc
+		want.Drift = drift
+		assertService(t, got, &want)
+
+		if err := repo.UpdateDrift(ctx, svc.ID, nil); err != nil {
+			t.Fatalf("UpdateDrift clearing drift: unexpected error: %v", err)
+		}
+		if got, _ := repo.Get(ctx, svc.ID); got.Drift != nil {
+			t.Errorf("Drift after clearing: got %+v, want nil", got.Drift)
+		}
+		if err := repo.UpdateDrift(ctx, uuid.New(), drift); !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("UpdateDrift of unknown ID: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("update_not_found", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if err := repo.Update(context.Background(), newService(newProject(t, repo), "api")); !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("Update of unknown ID: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("update_to_taken_name", func(t *testing.T) {
+		repo := newRepo(t)
+		projectID := newProject(t, repo)
+		mustCreate(t, repo, newService(projectID, "api"))
+		web := newService(projectID, "web")
+		mustCreate(t, repo, web)
+
+		renamed := *web
+		renamed.Name = "api"
+		if err := repo.Update(context.Background(), &renamed); !errors.Is(err, service.ErrAlreadyExists) {
+			t.Errorf("Update to a taken name: got %v, want ErrAlreadyExists", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		svc := newService(newProject(t, repo), "api")
+		mustCreate(t, repo, svc)
+
+		if err := repo.Delete(ctx, svc.ID); err != nil {
+			t.Fatalf("Delete: unexpected error: %v", err)
+		}
+		if _, err := repo.Get(ctx, svc.ID); !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("Get after Delete: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("delete_not_found", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if err := repo.Delete(context.Background(), uuid.New()); !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("Delete of unknown ID: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("list_by_project_filters_and_orders", func(t *testing.T) {
+		repo := newRepo(t)
+		projectID := newProject(t, repo)
+		otherID := newProject(t, repo)
+		mustCreate(t, repo, newService(projectID, "web"))
+		mustCreate(t, repo, newService(projectID, "api"))
+		mustCreate(t, repo, newService(otherID, "db"))
+
+		got, err := repo.ListByProject(context.Background(), projectID)
+		if err != nil {
+			t.Fatalf("ListByProject: unexpected error: %v", err)
+		}
+		want := []string{"api", "web"}
+		if len(got) != len(want) {
+			t.Fatalf("ListByProject: got %d services, want %d", len(got), len(want))
+		}
+		for i, name := range want {
+			if got[i].Name != name {
+				t.Errorf("ListByProject[%d]: got %q, want %q", i, got[i].Name, name)
+			}
+			if got[i].ProjectID != projectID {
+				t.Errorf("ListByProject[%d]: got project %s, want %s", i, got[i].ProjectID, projectID)
+			}
+		}
+	})
+
+	t.Run("transition_and_history", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		svc := newService(newProject(t, repo), "api")
+		mustCreate(t, repo, svc)
+
+		history, err := repo.History(ctx, svc.ID)
+		if err != nil {
+			t.Fatalf("History: unexpected error: %v", err)
+		}
+		if history == nil || len(history) != 0 {
+			t.Errorf("History of new service: got %v, want empty non-nil slice", history)
+		}
+
+		at := svc.CreatedAt.Add(time.Second)
+		steps := []*service.Transition{
+			{ServiceID: svc.ID, From: service.StatusPending, To: service.StatusProvisioning, Actor: "alice", Reason: "created", At: at},
+			{ServiceID: svc.ID, From: service.StatusProvisioning, To: service.StatusReady, Actor: service.ActorSystem, Reason: "all objects ready", At: at.Add(time.Second)},
+		}
+		for _, step := range steps {
+			if err := repo.Transition(ctx, step); err != nil {
+				t.Fatalf("Transition %s to %s: unexpected error: %v", step.From, step.To, err)
+			}
+		}
+
+		got, err := repo.Get(ctx, svc.ID)
+		if err != nil {
+			t.Fatalf("Get: unexpected error: %v", err)
+		}
+		if got.Status != service.StatusReady {
+			t.Errorf("Status: got %q, want %q", got.Status, service.StatusReady)
+		}
+		history, err = repo.History(ctx, svc.ID)
+		if err != nil {
+			t.Fatalf("History: unexpected error: %v", err)
+		}
+		if len(history) != len(steps) {
+			t.Fatalf("History: got %d transitions, want %d", len(history), len(steps))
+		}
+		for i, want := range steps {
+			got := history[i]
+			if got.ServiceID != want.ServiceID || got.From != want.From || got.To != want.To ||
+				got.Actor != want.Actor || got.Reason != want.Reason || !got.At.Equal(want.At) {
+				t.Errorf("History[%d]: got %+v, want %+v", i, got, want)
+			}
+		}
+	})
+
+	t.Run("transition_conflict", func(t *testing.T) {
+		repo := newRepo(t)
+		svc := newService(newProject(t, repo), "api")
+		mustCreate(t, repo, svc)
+
+		stale := &service.Transition{ServiceID: svc.ID, From: service.StatusReady, To: service.StatusUpdating, Actor: "alice", At: time.Now()}
+		if err := repo.Transition(context.Background(), stale); !errors.Is(err, service.ErrStatusConflict) {
+			t.Errorf("Transition from a stale status: got %v, want ErrStatusConflict", err)
+		}
+	})
+
+	t.Run("transition_invalid", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		svc := newService(newProject(t, repo), "api")
+		mustCreate(t, repo, svc)
+
+		skip := &service.Transition{ServiceID: svc.ID, From: service.StatusPending, To: service.StatusReady, Actor: "alice", At: time.Now()}
+		if err := repo.Transition(ctx, skip); !errors.Is(err, service.ErrInvalidTransition) {
+			t.Errorf("Transition not allowed by the state machine: got %v, want ErrInvalidTransition", err)
+		}
+		if got, _ := repo.Get(ctx, svc.ID); got.Status != service.StatusPending {
+			t.Errorf("Status after invalid transition: got %q, want %q", got.Status, service.StatusPending)
+		}
+	})
+
+	t.Run("transition_not_found", func(t *testing.T) {
+		repo := newRepo(t)
+
+		tr := &service.Transition{ServiceID: uuid.New(), From: service.StatusPending, To: service.StatusProvisioning, Actor: "alice", At: time.Now()}
+		if err := repo.Transition(context.Background(), tr); !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("Transition of unknown ID: got %v, want ErrNotFound", err)
+		}
+		if _, err := repo.History(context.Background(), uuid.New()); !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("History of unknown ID: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("delete_removes_history", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		projectID := newProject(t, repo)
+		svc := newService(projectID, "api")
+		mustCreate(t, repo, svc)
+		tr := &service.Transition{ServiceID: svc.ID, From: service.StatusPending, To: service.StatusProvisioning, Actor: "alice", At: time.Now()}
+		if err := repo.Transition(ctx, tr); err != nil {
+			t.Fatalf("Transition: unexpected error: %v", err)
+		}
+		if err := repo.Delete(ctx, svc.ID); err != nil {
+			t.Fatalf("Delete: unexpected error: %v", err)
+		}
+
+		again := newService(projectID, "api")
+		again.ID = svc.ID
+		mustCreate(t, repo, again)
+		history, err := repo.History(ctx, svc.ID)
+		if err != nil {
+			t.Fatalf("History: unexpected error: %v", err)
+		}
+		if len(history) != 0 {
+			t.Errorf("History of recreated service: got %d transitions, want none", len(history))
+		}
+	})
+
+	t.Run("dependencies", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		projectID := newProject(t, repo)
+		db := newService(projectID, "db")
+		cache := newService(projectID, "cache")
+		mustCreate(t, repo, db)
+		mustCreate(t, repo, cache)
+		api := newService(projectID, "api")
+		api.DependsOn = []uuid.UUID{db.ID, cache.ID}
+		mustCreate(t, repo, api)
+
+		got, err := repo.Get(ctx, api.ID)
+		if err != nil {
+			t.Fatalf("Get: unexpected error: %v", err)
+		}
+		assertService(t, got, api)
+
+		api.DependsOn = []uuid.UUID{cache.ID}
+		if err := repo.Update(ctx, api); err != nil {
+			t.Fatalf("Update: unexpected error: %v", err)
+		}
+		list, err := repo.ListByProject(ctx, projectID)
+		if err != nil {
+			t.Fatalf("ListByProject: unexpected error: %v", err)
+		}
+		if len(list) != 3 {
+			t.Fatalf("ListByProject: got %d services, want 3", len(list))
+		}
+		assertService(t, list[0], api)
+		if len(list[1].DependsOn) != 0 {
+			t.Errorf("DependsOn of %q: got %v, want none", list[1].Name, list[1].DependsOn)
+		}
+	})
+
+	t.Run("invalid_dependency", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		projectID := newProject(t, repo)
+		db := newService(projectID, "db")
+		mustCreate(t, repo, db)
+		other := newService(newProject(t, repo), "other")
+		mustCreate(t, repo, other)
+
+		api := newService(projectID, "api")
+		for name, deps := range map[string][]uuid.UUID{
+			"unknown":       {uuid.New()},
+			"other project": {other.ID},
+			"self":          {api.ID},
+			"duplicate":     {db.ID, db.ID},
+		} {
+			api.DependsOn = deps
+			if err := repo.Create(ctx, api); !errors.Is(err, service.ErrInvalidDependency) {
+				t.Errorf("Create with %s dependency: got %v, want ErrInvalidDependency", name, err)
+			}
+		}
+		if _, err := repo.Get(ctx, api.ID); !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("Get after failed Create: got %v, want ErrNotFound", err)
+		}
+
+		api.DependsOn = nil
+		mustCreate(t, repo, api)
+		api.DependsOn = []uuid.UUID{api.ID}
+		if err := repo.Update(ctx, api); !errors.Is(err, service.ErrInvalidDependency) {
+			t.Errorf("Update with self dependency: got %v, want ErrInvalidDependency", err)
+		}
+	})
+
+	t.Run("delete_with_dependents", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		projectID := newProject(t, repo)
+		db := newService(projectID, "db")
+		mustCreate(t, repo, db)
+		api := newService(projectID, "api")
+		api.DependsOn = []uuid.UUID{db.ID}
+		mustCreate(t, repo, api)
+
+		if err := repo.Delete(ctx, db.ID); !errors.Is(err, service.ErrHasDependents) {
+			t.Errorf("Delete of a dependency: got %v, want ErrHasDependents", err)
+		}
+		if err := repo.Delete(ctx, api.ID); err != nil {
+			t.Fatalf("Delete of the dependent: unexpected error: %v", err)
+		}
+		if err := repo.Delete(ctx, db.ID); err != nil {
+			t.Errorf("Delete after its dependent: unexpected error: %v", err)
+		}
+	})
+
+	t.Run("list_by_project_empty", func(t *testing.T) {
+		repo := newRepo(t)
+		mustCreate(t, repo, newService(newProject(t, repo), "api"))
+
+		got, err := repo.ListByProject(context.Background(), newProject(t, repo))
+		if err != nil {
+			t.Fatalf("ListByProject: unexpected error: %v", err)
+		}
+		if got == nil || len(got) != 0 {
+			t.Errorf("ListByProject of project without services: got %v, want empty non-nil slice", got)
+		}
+	})
+}
+
+func newService(projectID uuid.UUID, name string) *service.Service {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	return &service.Service{
+		ID:          uuid.New(),
+		ProjectID:   projectID,
+		Name:        name,
+		Description: "Description of " + name,
+		ResourceKey: "mock-resource",
+		Config: map[string]any{
+			"image":  "nginx:1.27",
+			"cpu":    "500m",
+			"limits": map[string]any{"memory": "256Mi"},
+			"ports":  []any{float64(80), float64(443)},
+		},
+		Status:    service.StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func mustCreate(t *testing.T, repo service.Repository, svc *service.Service) {
+	t.Helper()
+	if err := repo.Create(context.Background(), svc); err != nil {
+		t.Fatalf("Create %q: unexpected error: %v", svc.Name, err)
+	}
+}
+
+func assertService(t *testing.T, got, want *service.Service) {
+	t.Helper()
+	if got.ID != want.ID {
+		t.Errorf("ID: got %s, want %s", got.ID, want.ID)
+	}
+	if got.ProjectID != want.ProjectID {
+		t.Errorf("ProjectID: got %s, want %s", got.ProjectID, want.ProjectID)
+	}
+	if got.Name != want.Name {
+		t.Errorf("Name: got %q, want %q", got.Name, want.Name)
+	}
+	if got.Description != want.Description {
+		t.Errorf("Description: got %q, want %q", got.Description, want.Description)
+	}
+	if got.ResourceKey != want.ResourceKey {
+		t.Errorf("ResourceKey: got %q, want %q", got.ResourceKey, want.ResourceKey)
+	}
+	if got.Status != want.Status {
+		t.Errorf("Status: got %q, want %q", got.Status, want.Status)
+	}
+	if !reflect.DeepEqual(got.Config, want.Config) {
+		t.Errorf("Config: got %v, want %v", got.Config, want.Config)
+	}
+	switch {
+	case (got.Drift == nil) != (want.Drift == nil):
+		t.Errorf("Drift: got %+v, want %+v", got.Drift, want.Drift)
+	case got.Drift != nil && (!got.Drift.DetectedAt.Equal(want.Drift.DetectedAt) || !reflect.DeepEqual(got.Drift.Changes, want.Drift.Changes)):
+		t.Errorf("Drift: got %+v, want %+v", got.Drift, want.Drift)
+	}
+	if !slices.Equal(got.DependsOn, want.DependsOn) {
+		t.Errorf("DependsOn: got %v, want %v", got.DependsOn, want.DependsOn)
+	}
+	if !reflect.DeepEqual(got.Outputs, want.Outputs) {
+		t.Errorf("Outputs: got %+v, want %+v", got.Outputs, want.Outputs)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("CreatedAt: got %v, want %v", got.CreatedAt, want.CreatedAt)
+	}
+	if !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Errorf("UpdatedAt: got %v, want %v", got.UpdatedAt, want.UpdatedAt)
+	}
+}
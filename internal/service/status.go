@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is the lifecycle state of a service.
+type Status string
+
+const (
+	// StatusPending is the state of a new service that has not been
+	// provisioned yet.
+	StatusPending      Status = "pending"
+	StatusProvisioning Status = "provisioning"
+	StatusReady        Status = "ready"
+	StatusUpdating     Status = "updating"
+	// StatusDegraded means the service runs, but not as configured.
+	StatusDegraded Status = "degraded"
+	// StatusDrifted means drift detection found the service's
+	// infrastructure changed outside of Mahler. Reconciling, or updating,
+	// the service re-applies its configuration.
+	StatusDrifted  Status = "drifted"
+	StatusDeleting Status = "deleting"
+	// StatusDeleted is final.
+	StatusDeleted Status = "deleted"
+	// StatusFailed means the last provisioning step failed. Retrying it, or
+	// deleting the service, leaves the state.
+	StatusFailed Status = "failed"
+)
+
+// Actors of transitions not requested by a user.
+const (
+	ActorSystem = "system"
+	ActorAPI    = "api"
+)
+
+var (
+	// ErrInvalidTransition is returned for a status change the state
+	// machine does not allow.
+	ErrInvalidTransition = errors.New("invalid status transition")
+	// ErrStatusConflict is returned by a Repository when a service is no
+	// longer in the status a transition starts from.
+	ErrStatusConflict = errors.New("service status changed concurrently")
+)
+
+// transitions lists the statuses each status may change to. It is the only
+// definition of the state machine.
+var transitions = map[Status][]Status{
+	StatusPending:      {StatusProvisioning, StatusDeleting, StatusFailed},
+	StatusProvisioning: {StatusReady, StatusDegraded, StatusFailed, StatusDeleting},
+	StatusReady:        {StatusUpdating, StatusDegraded, StatusDrifted, StatusDeleting},
+	StatusUpdating:     {StatusReady, StatusDegraded, StatusFailed, StatusDeleting},
+	StatusDegraded:     {StatusReady, StatusUpdating, StatusFailed, StatusDeleting},
+	StatusDrifted:      {StatusReady, StatusUpdating, StatusDegraded, StatusDeleting},
+	StatusDeleting:     {StatusDeleted, StatusFailed},
+	StatusFailed:       {StatusProvisioning, StatusUpdating, StatusDeleting},
+	StatusDeleted:      {},
+}
+
+// Statuses returns every status, in lifecycle order.
+func Statuses() []Status {
+	return []Status{
+		StatusPending, StatusProvisioning, StatusReady, StatusUpdating,
+		StatusDegraded, StatusDrifted, StatusDeleting, StatusDeleted, StatusFailed,
+	}
+}
+
+// Valid reports whether s is a known status.
+func (s Status) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// CanTransition reports whether a service may change from one status to
+// another.
+func CanTransition(from, to Status) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition is one recorded status change of a service.
+type Transition struct {
+	ServiceID uuid.UUID `json:"serviceId"`
+	From      Status    `json:"from"`
+	To        Status    `json:"to"`
+	// Actor is who caused the change: a user, ActorAPI or ActorSystem.
+	Actor  string    `json:"actor"`
+	Reason string    `json:"reason"`
+	At     time.Time `json:"at"`
+}
+
+// NewTransition returns the change of svc from its current status to to. The
+// error wraps ErrInvalidTransition if the state machine does not allow it.
+func NewTransition(svc *Service, to Status, actor, reason string, at time.Time) (*Transition, error) {
+	t := &Transition{
+		ServiceID: svc.ID,
+		From:      svc.Status,
+		To:        to,
+		Actor:     actor,
+		Reason:    reason,
+		At:        at.UTC(),
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Validate checks t against the state machine. Repositories call it before
+// recording a transition.
+func (t *Transition) Validate() error {
+	if !CanTransition(t.From, t.To) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, t.From, t.To)
+	}
+	if t.Actor == "" {
+		return fmt.Errorf("%w: actor is required", ErrInvalidTransition)
+	}
+	return nil
+}
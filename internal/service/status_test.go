@@ -0,0 +1,90 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to Status
+		want     bool
+	}{
+		{StatusPending, StatusProvisioning, true},
+		{StatusProvisioning, StatusReady, true},
+		{StatusReady, StatusUpdating, true},
+		{StatusUpdating, StatusDegraded, true},
+		{StatusDegraded, StatusDeleting, true},
+		{StatusReady, StatusDrifted, true},
+		{StatusDrifted, StatusReady, true},
+		{StatusDrifted, StatusUpdating, true},
+		{StatusPending, StatusDrifted, false},
+		{StatusUpdating, StatusDrifted, false},
+		{StatusDeleting, StatusDeleted, true},
+		{StatusProvisioning, StatusFailed, true},
+		{StatusFailed, StatusProvisioning, true},
+		{StatusPending, StatusReady, false},
+		{StatusReady, StatusPending, false},
+		{StatusReady, StatusDeleted, false},
+		{StatusDeleted, StatusProvisioning, false},
+		{StatusReady, StatusReady, false},
+		{"", StatusPending, false},
+		{StatusPending, "unknown", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"_to_"+string(tt.to), func(t *testing.T) {
+			if got := CanTransition(tt.from, tt.to); got != tt.want {
+				t.Errorf("CanTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatuses(t *testing.T) {
+	for _, s := range Statuses() {
+		if !s.Valid() {
+			t.Errorf("%q should be valid", s)
+		}
+		for _, next := range transitions[s] {
+			if !next.Valid() {
+				t.Errorf("%q may change to unknown status %q", s, next)
+			}
+		}
+	}
+	if len(Statuses()) != len(transitions) {
+		t.Errorf("Statuses() lists %d statuses, the state machine has %d", len(Statuses()), len(transitions))
+	}
+	if len(transitions[StatusDeleted]) != 0 {
+		t.Errorf("%q should be final", StatusDeleted)
+	}
+	if Status("unknown").Valid() {
+		t.Error("unknown status should not be valid")
+	}
+}
+
+func TestNewTransition(t *testing.T) {
+	svc := &Service{ID: uuid.New(), Status: StatusReady}
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+
+	tr, err := NewTransition(svc, StatusUpdating, "alice", "image changed", at)
+	if err != nil {
+		t.Fatalf("NewTransition() unexpected error: %v", err)
+	}
+	if tr.ServiceID != svc.ID || tr.From != StatusReady || tr.To != StatusUpdating || tr.Actor != "alice" || tr.Reason != "image changed" {
+		t.Errorf("NewTransition() = %+v", tr)
+	}
+	if !tr.At.Equal(at) || tr.At.Location() != time.UTC {
+		t.Errorf("At = %v, want %v in UTC", tr.At, at)
+	}
+
+	if _, err := NewTransition(svc, StatusPending, "alice", "", at); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("NewTransition(ready to pending) error = %v, want ErrInvalidTransition", err)
+	}
+	if _, err := NewTransition(svc, StatusUpdating, "", "", at); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("NewTransition without actor error = %v, want ErrInvalidTransition", err)
+	}
+}
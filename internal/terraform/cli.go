@@ -0,0 +1,312 @@
+package terraform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTimeout bounds each command unless CLIOptions say otherwise.
+	DefaultTimeout = 30 * time.Minute
+	// DefaultKillDelay is how long an interrupted command may take to
+	// release its state lock and exit before it is killed.
+	DefaultKillDelay = 10 * time.Second
+
+	// stderrTail is how much of a failed command's standard error is kept
+	// for its Error.
+	stderrTail = 4096
+)
+
+// ErrTimeout is returned when a command runs longer than its timeout.
+var ErrTimeout = errors.New("timed out")
+
+// CLI is an Executor running the terraform binary, or a compatible one
+// such as tofu. Commands are interrupted when their context is cancelled
+// or their timeout elapses, and killed when they do not exit in time.
+type CLI struct {
+	binary string
+	opts   CLIOptions
+}
+
+var _ Executor = (*CLI)(nil)
+
+type CLIOptions struct {
+	// Env is added to Mahler's environment for every command, e.g.
+	// TF_PLUGIN_CACHE_DIR=/var/cache/terraform.
+	Env []string
+	// Timeout bounds each command; 0 means DefaultTimeout.
+	Timeout time.Duration
+	// KillDelay is how long an interrupted command may take to exit
+	// before it is killed; 0 means DefaultKillDelay.
+	KillDelay time.Duration
+}
+
+// NewCLI returns a CLI running binary, which is looked up in PATH unless
+// it contains a path separator.
+func NewCLI(binary string, opts CLIOptions) *CLI {
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.KillDelay == 0 {
+		opts.KillDelay = DefaultKillDelay
+	}
+	return &CLI{binary: binary, opts: opts}
+}
+
+func (c *CLI) Init(ctx context.Context, dir string, opts InitOptions) error {
+	args := []string{"-input=false", "-no-color"}
+	if opts.Upgrade {
+		args = append(args, "-upgrade")
+	}
+	if opts.MigrateState {
+		// Without input, copying has to be agreed to up front.
+		args = append(args, "-migrate-state", "-force-copy")
+	}
+	for _, k := range slices.Sorted(maps.Keys(opts.BackendConfig)) {
+		args = append(args, "-backend-config="+k+"="+opts.BackendConfig[k])
+	}
+	_, err := c.run(ctx, dir, "init", "init", args, false, opts.Log)
+	return err
+}
+
+func (c *CLI) Plan(ctx context.Context, dir string, opts PlanOptions) (*PlanSummary, error) {
+	args := []string{"-input=false", "-json", "-detailed-exitcode"}
+	if opts.Out != "" {
+		args = append(args, "-out="+opts.Out)
+	}
+	if opts.Destroy {
+		args = append(args, "-destroy")
+	}
+	if opts.RefreshOnly {
+		args = append(args, "-refresh-only")
+	}
+	res, err := c.run(ctx, dir, "plan", "plan", append(args, opts.Variables.args()...), true, opts.Log)
+	if err != nil {
+		return nil, err
+	}
+	summary := &PlanSummary{HasChanges: res.exitCode == 2, Diagnostics: res.warnings}
+	if res.changes != nil {
+		summary.Changes = *res.changes
+	}
+	return summary, nil
+}
+
+func (c *CLI) Apply(ctx context.Context, dir string, opts ApplyOptions) (*ChangeSummary, error) {
+	return c.apply(ctx, dir, "apply", nil, opts)
+}
+
+func (c *CLI) Destroy(ctx context.Context, dir string, opts ApplyOptions) (*ChangeSummary, error) {
+	var flags []string
+	if opts.PlanFile == "" {
+		flags = []string{"-destroy"}
+	}
+	return c.apply(ctx, dir, "destroy", flags, opts)
+}
+
+func (c *CLI) apply(ctx context.Context, dir, name string, flags []string, opts ApplyOptions) (*ChangeSummary, error) {
+	args := append([]string{"-input=false", "-json", "-auto-approve"}, flags...)
+	if opts.PlanFile != "" {
+		if len(opts.Vars) > 0 || len(opts.VarFiles) > 0 {
+			return nil, fmt.Errorf("terraform %s: variables cannot be set when applying a saved plan", name)
+		}
+		args = append(args, opts.PlanFile)
+	} else {
+		args = append(args, opts.Variables.args()...)
+	}
+	res, err := c.run(ctx, dir, name, "apply", args, true, opts.Log)
+	if err != nil {
+		return nil, err
+	}
+	if res.changes == nil {
+		return &ChangeSummary{Operation: name}, nil
+	}
+	return res.changes, nil
+}
+
+func (c *CLI) Show(ctx context.Context, dir, planFile string) (*Plan, error) {
+	res, err := c.run(ctx, dir, "show", "show", []string{"-json", "-no-color", planFile}, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{Raw: json.RawMessage(res.stdout)}
+	if err := json.Unmarshal(res.stdout, plan); err != nil {
+		return nil, fmt.Errorf("terraform show: decode plan: %w", err)
+	}
+	return plan, nil
+}
+
+func (c *CLI) Output(ctx context.Context, dir string) (map[string]OutputValue, error) {
+	res, err := c.run(ctx, dir, "output", "output", []string{"-json", "-no-color"}, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	outputs := map[string]OutputValue{}
+	if err := json.Unmarshal(res.stdout, &outputs); err != nil {
+		return nil, fmt.Errorf("terraform output: decode outputs: %w", err)
+	}
+	return outputs, nil
+}
+
+// result is what run gathered from a command that succeeded.
+type result struct {
+	exitCode int
+	// stdout is only kept for commands that do not stream.
+	stdout   []byte
+	changes  *ChangeSummary
+	warnings []Diagnostic
+}
+
+// run runs the subcommand sub in dir, reporting its errors as name.
+// Commands with jsonOutput, and all commands when log is set, have their
+// standard output decoded line by line and streamed to log; otherwise it is
+// returned whole.
+func (c *CLI) run(ctx context.Context, dir, name, sub string, args []string, jsonOutput bool, log LogFunc) (*result, error) {
+	runCtx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, c.binary, append([]string{sub}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "TF_IN_AUTOMATION=1", "TF_INPUT=0")
+	cmd.Env = append(cmd.Env, c.opts.Env...)
+	cmd.Env = append(cmd.Env, Env(ctx)...)
+	// Terraform stops at a safe point and releases its lock on interrupt.
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = c.opts.KillDelay
+
+	var (
+		mu       sync.Mutex
+		res      = &result{}
+		errs     []Diagnostic
+		stdout   bytes.Buffer
+		stderr   tailBuffer
+		streamed = log != nil || jsonOutput
+	)
+	emit := func(m Message) {
+		if log == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		log(m)
+	}
+	if streamed {
+		out := &lineWriter{fn: func(line string) {
+			m := parseLine(line, jsonOutput)
+			switch {
+			case m.Type == "diagnostic" && m.Diagnostic != nil && m.Diagnostic.Severity == "error":
+				errs = append(errs, *m.Diagnostic)
+			case m.Type == "diagnostic" && m.Diagnostic != nil:
+				res.warnings = append(res.warnings, *m.Diagnostic)
+			case m.Type == "change_summary" && m.Changes != nil:
+				res.changes = m.Changes
+			}
+			emit(m)
+		}}
+		cmd.Stdout = out
+	} else {
+		cmd.Stdout = &stdout
+	}
+	errOut := &lineWriter{fn: func(line string) {
+		stderr.WriteLine(line)
+		emit(Message{Level: "error", Text: line, Timestamp: time.Now().UTC(), Type: "log"})
+	}}
+	cmd.Stderr = errOut
+
+	err := cmd.Run()
+	if w, ok := cmd.Stdout.(*lineWriter); ok {
+		w.Flush()
+	}
+	errOut.Flush()
+
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("terraform %s: %w", name, context.Cause(ctx))
+	}
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("terraform %s: %w after %s", name, ErrTimeout, c.opts.Timeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		res.exitCode = exitErr.ExitCode()
+		// plan -detailed-exitcode exits with 2 when there are changes.
+		if sub != "plan" || res.exitCode != 2 {
+			return nil, &Error{Command: name, ExitCode: res.exitCode, Diagnostics: errs, Stderr: stderr.String()}
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("terraform %s: %w", name, err)
+	}
+	res.stdout = stdout.Bytes()
+	return res, nil
+}
+
+// parseLine decodes a line of output as a message. Lines that are not
+// machine-readable become "log" messages.
+func parseLine(line string, jsonOutput bool) Message {
+	if jsonOutput && strings.HasPrefix(line, "{") {
+		var m Message
+		if err := json.Unmarshal([]byte(line), &m); err == nil && m.Type != "" {
+			return m
+		}
+	}
+	return Message{Level: "info", Text: line, Timestamp: time.Now().UTC(), Type: "log"}
+}
+
+// lineWriter calls fn with each complete line written to it.
+type lineWriter struct {
+	buf []byte
+	fn  func(string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.line(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+}
+
+// Flush passes on a last line without a newline.
+func (w *lineWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.line(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *lineWriter) line(b []byte) {
+	if s := strings.TrimRight(string(b), "\r"); strings.TrimSpace(s) != "" {
+		w.fn(s)
+	}
+}
+
+// tailBuffer keeps the last lines written to it, up to stderrTail bytes.
+type tailBuffer struct {
+	lines []string
+	size  int
+}
+
+func (t *tailBuffer) WriteLine(line string) {
+	t.lines = append(t.lines, line)
+	t.size += len(line) + 1
+	for t.size > stderrTail && len(t.lines) > 1 {
+		t.size -= len(t.lines[0]) + 1
+		t.lines = t.lines[1:]
+	}
+}
+
+func (t *tailBuffer) String() string {
+	return strings.Join(t.lines, "\n")
+}
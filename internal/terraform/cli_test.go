@@ -0,0 +1,262 @@
+package terraform_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/terraform"
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+// newCLI puts the fake terraform in testdata on PATH and returns a CLI
+// running it, the working directory and the file the fake records its
+// invocations in.
+func newCLI(t *testing.T, opts terraform.CLIOptions) (*terraform.CLI, string, string) {
+	t.Helper()
+	bin, err := filepath.Abs("testdata")
+	testutil.AssertNoError(t, err, "testdata should resolve")
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	argsFile := filepath.Join(t.TempDir(), "args")
+	t.Setenv("FAKE_TERRAFORM_ARGS", argsFile)
+	return terraform.NewCLI("terraform", opts), t.TempDir(), argsFile
+}
+
+// invocations returns the recorded invocations, one per line.
+func invocations(t *testing.T, argsFile string) []string {
+	t.Helper()
+	b, err := os.ReadFile(argsFile)
+	testutil.AssertNoError(t, err, "the fake should record its arguments")
+	return strings.Split(strings.TrimSpace(string(b)), "\n")
+}
+
+// recorder collects streamed messages.
+type recorder struct {
+	mu       sync.Mutex
+	messages []terraform.Message
+}
+
+func (r *recorder) log(m terraform.Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, m)
+}
+
+func (r *recorder) types() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	types := make([]string, len(r.messages))
+	for i, m := range r.messages {
+		types[i] = m.Type
+	}
+	return types
+}
+
+func TestCLI_Init(t *testing.T) {
+	cli, dir, argsFile := newCLI(t, terraform.CLIOptions{})
+	var rec recorder
+
+	err := cli.Init(context.Background(), dir, terraform.InitOptions{
+		BackendConfig: map[string]string{"username": "svc", "address": "http://mahler/state"},
+		Log:           rec.log,
+	})
+	testutil.AssertNoError(t, err, "init should succeed")
+	testutil.AssertEqual(t, invocations(t, argsFile)[0], dir+" init -input=false -no-color -backend-config=address=http://mahler/state -backend-config=username=svc", "init should run in dir with sorted backend config")
+	testutil.AssertEqual(t, len(rec.messages), 3, "every line should be logged")
+	testutil.AssertEqual(t, rec.messages[2].Text, "Terraform has been successfully initialized!", "plain lines should become messages")
+	testutil.AssertEqual(t, rec.messages[2].Type, "log", "plain lines should be log messages")
+
+	err = cli.Init(context.Background(), dir, terraform.InitOptions{MigrateState: true})
+	testutil.AssertNoError(t, err, "init should succeed")
+	testutil.AssertEqual(t, invocations(t, argsFile)[1], dir+" init -input=false -no-color -migrate-state -force-copy", "migrating should not ask for confirmation")
+}
+
+func TestCLI_Plan(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        terraform.PlanOptions
+		noChanges   bool
+		wantArgs    string
+		wantChanges bool
+		wantAdd     int
+	}{
+		{
+			name: "changes",
+			opts: terraform.PlanOptions{
+				Out:       "tfplan",
+				Variables: terraform.Variables{Vars: map[string]string{"replicas": "2", "image": "nginx"}, VarFiles: []string{"mahler.tfvars.json"}},
+			},
+			wantArgs:    "plan -input=false -json -detailed-exitcode -out=tfplan -var-file=mahler.tfvars.json -var=image=nginx -var=replicas=2",
+			wantChanges: true,
+			wantAdd:     1,
+		},
+		{
+			name:      "no_changes",
+			opts:      terraform.PlanOptions{RefreshOnly: true},
+			noChanges: true,
+			wantArgs:  "plan -input=false -json -detailed-exitcode -refresh-only",
+		},
+		{
+			name:        "destroy",
+			opts:        terraform.PlanOptions{Destroy: true},
+			wantArgs:    "plan -input=false -json -detailed-exitcode -destroy",
+			wantChanges: true,
+			wantAdd:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, dir, argsFile := newCLI(t, terraform.CLIOptions{})
+			if tt.noChanges {
+				t.Setenv("FAKE_TERRAFORM_NO_CHANGES", "1")
+			}
+			var rec recorder
+			tt.opts.Log = rec.log
+
+			got, err := cli.Plan(context.Background(), dir, tt.opts)
+			testutil.AssertNoError(t, err, "plan should succeed")
+			testutil.AssertEqual(t, invocations(t, argsFile)[0], dir+" "+tt.wantArgs, "plan arguments")
+			testutil.AssertEqual(t, got.HasChanges, tt.wantChanges, "HasChanges should follow the exit code")
+			testutil.AssertEqual(t, got.Changes.Add, tt.wantAdd, "the change summary should be parsed")
+			testutil.AssertEqual(t, len(got.Diagnostics), 1, "warnings should be reported")
+			testutil.AssertEqual(t, got.Diagnostics[0].Summary, "Deprecated attribute", "the warning should be decoded")
+			testutil.AssertEqual(t, rec.types()[0], "version", "messages should be streamed decoded")
+		})
+	}
+}
+
+func TestCLI_ApplyAndDestroy(t *testing.T) {
+	cli, dir, argsFile := newCLI(t, terraform.CLIOptions{})
+	ctx := context.Background()
+	var rec recorder
+
+	got, err := cli.Apply(ctx, dir, terraform.ApplyOptions{PlanFile: "tfplan", Log: rec.log})
+	testutil.AssertNoError(t, err, "apply should succeed")
+	testutil.AssertEqual(t, *got, terraform.ChangeSummary{Add: 1, Operation: "apply"}, "apply should report its changes")
+	testutil.AssertEqual(t, strings.Join(rec.types(), ","), "version,apply_complete,change_summary", "apply should stream its messages")
+	testutil.AssertTrue(t, len(rec.messages[1].Hook) > 0, "hook details should be kept")
+
+	_, err = cli.Apply(ctx, dir, terraform.ApplyOptions{PlanFile: "tfplan", Variables: terraform.Variables{Vars: map[string]string{"a": "b"}}})
+	testutil.AssertError(t, err, "variables cannot be combined with a saved plan")
+
+	got, err = cli.Destroy(ctx, dir, terraform.ApplyOptions{Variables: terraform.Variables{Vars: map[string]string{"image": "nginx"}}})
+	testutil.AssertNoError(t, err, "destroy should succeed")
+	testutil.AssertEqual(t, got.Remove, 1, "destroy should report its changes")
+
+	calls := invocations(t, argsFile)
+	testutil.AssertEqual(t, len(calls), 2, "the rejected apply should not run")
+	testutil.AssertEqual(t, calls[0], dir+" apply -input=false -json -auto-approve tfplan", "apply arguments")
+	testutil.AssertEqual(t, calls[1], dir+" apply -input=false -json -auto-approve -destroy -var=image=nginx", "destroy arguments")
+}
+
+func TestCLI_ShowAndOutput(t *testing.T) {
+	cli, dir, _ := newCLI(t, terraform.CLIOptions{})
+	ctx := context.Background()
+
+	plan, err := cli.Show(ctx, dir, "tfplan")
+	testutil.AssertNoError(t, err, "show should succeed")
+	testutil.AssertEqual(t, plan.TerraformVersion, "1.9.0", "the plan should be decoded")
+	testutil.AssertEqual(t, len(plan.ResourceChanges), 1, "resource changes should be decoded")
+	rc := plan.ResourceChanges[0]
+	testutil.AssertEqual(t, rc.Address, "null_resource.web", "the address should be decoded")
+	testutil.AssertEqual(t, strings.Join(rc.Change.Actions, ","), "create", "the actions should be decoded")
+	testutil.AssertEqual(t, string(rc.Change.After), `{"triggers":{"image":"nginx"}}`, "the values should be kept raw")
+	testutil.AssertTrue(t, strings.HasPrefix(string(plan.Raw), `{"format_version"`), "the raw plan should be kept")
+
+	outputs, err := cli.Output(ctx, dir)
+	testutil.AssertNoError(t, err, "output should succeed")
+	testutil.AssertEqual(t, string(outputs["url"].Value), `"https://web.example.com"`, "output values should be decoded")
+	testutil.AssertFalse(t, outputs["url"].Sensitive, "url should not be sensitive")
+	testutil.AssertTrue(t, outputs["password"].Sensitive, "password should be sensitive")
+	testutil.AssertEqual(t, string(outputs["password"].Type), `"string"`, "output types should be decoded")
+}
+
+func TestCLI_Env(t *testing.T) {
+	cli, dir, _ := newCLI(t, terraform.CLIOptions{Env: []string{"TF_PLUGIN_CACHE_DIR=/var/cache/terraform"}})
+	envFile := filepath.Join(t.TempDir(), "env")
+	t.Setenv("FAKE_TERRAFORM_ENV", envFile)
+
+	ctx := terraform.WithEnv(context.Background(), "TF_HTTP_USERNAME=svc")
+	ctx = terraform.WithEnv(ctx, "TF_HTTP_PASSWORD=secret")
+	testutil.AssertEqual(t, strings.Join(terraform.Env(ctx), " "), "TF_HTTP_USERNAME=svc TF_HTTP_PASSWORD=secret", "the environment should accumulate")
+	_, err := cli.Output(ctx, dir)
+	testutil.AssertNoError(t, err, "output should succeed")
+
+	b, err := os.ReadFile(envFile)
+	testutil.AssertNoError(t, err, "the fake should record its environment")
+	env := strings.Split(string(b), "\n")
+	for _, want := range []string{"TF_PLUGIN_CACHE_DIR=/var/cache/terraform", "TF_HTTP_USERNAME=svc", "TF_HTTP_PASSWORD=secret", "TF_IN_AUTOMATION=1"} {
+		testutil.AssertTrue(t, slices.Contains(env, want), "the command should get "+want)
+	}
+}
+
+func TestCLI_Errors(t *testing.T) {
+	t.Run("diagnostics", func(t *testing.T) {
+		cli, dir, _ := newCLI(t, terraform.CLIOptions{})
+		t.Setenv("FAKE_TERRAFORM_FAIL", "plan")
+
+		_, err := cli.Plan(context.Background(), dir, terraform.PlanOptions{})
+		var tfErr *terraform.Error
+		if !errors.As(err, &tfErr) {
+			t.Fatalf("plan error = %v, want a *terraform.Error", err)
+		}
+		testutil.AssertEqual(t, tfErr.Command, "plan", "the command should be named")
+		testutil.AssertEqual(t, tfErr.ExitCode, 1, "the exit code should be kept")
+		testutil.AssertEqual(t, len(tfErr.Diagnostics), 1, "the error diagnostic should be kept")
+		testutil.AssertEqual(t, err.Error(), `terraform plan: exit status 1: Unsupported argument (main.tf line 3): An argument named "colour" is not expected here.`, "the message should explain the failure")
+	})
+
+	t.Run("stderr", func(t *testing.T) {
+		cli, dir, _ := newCLI(t, terraform.CLIOptions{})
+		t.Setenv("FAKE_TERRAFORM_FAIL", "init")
+
+		err := cli.Init(context.Background(), dir, terraform.InitOptions{})
+		testutil.AssertEqual(t, err.Error(), "terraform init: exit status 1: Error: Failed to query available provider packages", "stderr should explain failures without diagnostics")
+	})
+
+	t.Run("missing_binary", func(t *testing.T) {
+		cli := terraform.NewCLI(filepath.Join(t.TempDir(), "terraform"), terraform.CLIOptions{})
+
+		_, err := cli.Output(context.Background(), t.TempDir())
+		testutil.AssertError(t, err, "a missing binary should fail")
+	})
+}
+
+func TestCLI_Timeout(t *testing.T) {
+	cli, dir, _ := newCLI(t, terraform.CLIOptions{Timeout: 100 * time.Millisecond, KillDelay: time.Second})
+	t.Setenv("FAKE_TERRAFORM_SLEEP", "1")
+	var rec recorder
+
+	start := time.Now()
+	_, err := cli.Apply(context.Background(), dir, terraform.ApplyOptions{Log: rec.log})
+	testutil.AssertTrue(t, errors.Is(err, terraform.ErrTimeout), "apply should time out, got "+errString(err))
+	testutil.AssertTrue(t, time.Since(start) < 5*time.Second, "the command should be stopped")
+	testutil.AssertEqual(t, strings.Join(rec.types(), ","), "version", "output before the timeout should be streamed")
+}
+
+func TestCLI_Cancel(t *testing.T) {
+	cli, dir, _ := newCLI(t, terraform.CLIOptions{KillDelay: time.Second})
+	t.Setenv("FAKE_TERRAFORM_SLEEP", "1")
+	cause := errors.New("operation cancelled")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	time.AfterFunc(100*time.Millisecond, func() { cancel(cause) })
+
+	start := time.Now()
+	_, err := cli.Plan(ctx, dir, terraform.PlanOptions{})
+	testutil.AssertTrue(t, errors.Is(err, cause), "plan should fail with the cancellation cause, got "+errString(err))
+	testutil.AssertTrue(t, time.Since(start) < 5*time.Second, "the command should be stopped")
+}
+
+func errString(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return err.Error()
+}
@@ -0,0 +1,251 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DiffAction is what a plan does to a resource.
+type DiffAction string
+
+const (
+	DiffCreate  DiffAction = "create"
+	DiffUpdate  DiffAction = "update"
+	DiffReplace DiffAction = "replace"
+	DiffDelete  DiffAction = "delete"
+)
+
+// Diff is the reviewable form of a plan: the resources it changes, in plan
+// order, with the attributes that change. Data source reads and resources
+// left as they are do not appear.
+type Diff struct {
+	Resources []ResourceDiff `json:"resources"`
+}
+
+// ResourceDiff is the change of one resource instance.
+type ResourceDiff struct {
+	Address string     `json:"address" doc:"Resource address, e.g. module.service.aws_db_instance.main"`
+	Type    string     `json:"type"`
+	Action  DiffAction `json:"action" enum:"create,update,replace,delete"`
+	Reason  string     `json:"reason,omitempty" doc:"Why Terraform replaces the resource, e.g. replace_because_cannot_update"`
+	// Attributes are empty for deletes.
+	Attributes []AttributeChange `json:"attributes"`
+}
+
+// AttributeChange is the change of one attribute value. Objects and lists
+// are descended into, so every change is of a single value.
+type AttributeChange struct {
+	Path              string `json:"path" doc:"Attribute path, e.g. tags.env or ports[0].number"`
+	Before            any    `json:"before,omitempty"`
+	After             any    `json:"after,omitempty"`
+	Unknown           bool   `json:"unknown,omitempty" doc:"Whether the new value is only known after apply"`
+	Sensitive         bool   `json:"sensitive,omitempty" doc:"Whether the values are hidden as sensitive"`
+	ForcesReplacement bool   `json:"forcesReplacement,omitempty" doc:"Whether changing the attribute replaces the resource"`
+}
+
+// Diff returns the diff of the plan's resource changes.
+func (p *Plan) Diff() (*Diff, error) {
+	return NewDiff(p.ResourceChanges)
+}
+
+// NewDiff returns the diff of changes, e.g. of a plan's resource changes or
+// drift.
+func NewDiff(changes []ResourceChange) (*Diff, error) {
+	d := &Diff{Resources: []ResourceDiff{}}
+	for _, rc := range changes {
+		action, ok := diffAction(rc.Change.Actions)
+		if !ok || rc.Mode == "data" {
+			continue
+		}
+		rd := ResourceDiff{Address: rc.Address, Type: rc.Type, Action: action, Attributes: []AttributeChange{}}
+		if action == DiffReplace {
+			rd.Reason = rc.ActionReason
+		}
+		if action != DiffDelete {
+			attrs, err := attributeChanges(rc.Change)
+			if err != nil {
+				return nil, fmt.Errorf("diff %s: %w", rc.Address, err)
+			}
+			rd.Attributes = attrs
+		}
+		d.Resources = append(d.Resources, rd)
+	}
+	return d, nil
+}
+
+func diffAction(actions []string) (DiffAction, bool) {
+	switch strings.Join(actions, ",") {
+	case "create":
+		return DiffCreate, true
+	case "update":
+		return DiffUpdate, true
+	case "delete":
+		return DiffDelete, true
+	case "delete,create", "create,delete":
+		return DiffReplace, true
+	}
+	return "", false
+}
+
+// Clone returns a copy of d that shares no slices with it. Attribute values
+// are shared, as they are never modified.
+func (d *Diff) Clone() *Diff {
+	if d == nil {
+		return nil
+	}
+	clone := &Diff{Resources: slices.Clone(d.Resources)}
+	for i := range clone.Resources {
+		clone.Resources[i].Attributes = slices.Clone(clone.Resources[i].Attributes)
+	}
+	return clone
+}
+
+// Empty reports whether the plan changes nothing.
+func (d *Diff) Empty() bool {
+	return len(d.Resources) == 0
+}
+
+// Count returns the number of resources the plan acts on with action.
+func (d *Diff) Count(action DiffAction) int {
+	n := 0
+	for _, r := range d.Resources {
+		if r.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Destructive reports whether the plan deletes or replaces a resource.
+func (d *Diff) Destructive() bool {
+	return d.Count(DiffDelete) > 0 || d.Count(DiffReplace) > 0
+}
+
+func (d *Diff) String() string {
+	return fmt.Sprintf("%d to create, %d to update, %d to replace, %d to delete",
+		d.Count(DiffCreate), d.Count(DiffUpdate), d.Count(DiffReplace), d.Count(DiffDelete))
+}
+
+// attributeChanges compares the values before and after c.
+func attributeChanges(c Change) ([]AttributeChange, error) {
+	var before, after, unknown, beforeSensitive, afterSensitive any
+	var replacePaths [][]any
+	for _, f := range []struct {
+		raw json.RawMessage
+		dst any
+	}{
+		{c.Before, &before}, {c.After, &after}, {c.AfterUnknown, &unknown},
+		{c.BeforeSensitive, &beforeSensitive}, {c.AfterSensitive, &afterSensitive},
+		{c.ReplacePaths, &replacePaths},
+	} {
+		if len(f.raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(f.raw, f.dst); err != nil {
+			return nil, err
+		}
+	}
+
+	w := &diffWalker{changes: []AttributeChange{}}
+	for _, p := range replacePaths {
+		w.replace = append(w.replace, formatPath(p))
+	}
+	w.walk("", before, after, unknown, beforeSensitive, afterSensitive)
+	return w.changes, nil
+}
+
+type diffWalker struct {
+	replace []string
+	changes []AttributeChange
+}
+
+func (w *diffWalker) walk(path string, before, after, unknown, beforeSensitive, afterSensitive any) {
+	switch {
+	case beforeSensitive == true || afterSensitive == true:
+		if unknown == true || !reflect.DeepEqual(before, after) {
+			w.add(AttributeChange{Path: path, Sensitive: true, Unknown: unknown == true})
+		}
+		return
+	case unknown == true:
+		w.add(AttributeChange{Path: path, Before: before, Unknown: true})
+		return
+	}
+
+	beforeMap, bok := before.(map[string]any)
+	afterMap, aok := after.(map[string]any)
+	unknownMap, uok := unknown.(map[string]any)
+	if (bok || before == nil) && (aok || after == nil) && (bok || aok || uok) {
+		keys := make(map[string]bool)
+		for _, m := range []map[string]any{beforeMap, afterMap, unknownMap} {
+			for k := range m {
+				keys[k] = true
+			}
+		}
+		bs, _ := beforeSensitive.(map[string]any)
+		as, _ := afterSensitive.(map[string]any)
+		for _, k := range slices.Sorted(maps.Keys(keys)) {
+			w.walk(joinPath(path, k), beforeMap[k], afterMap[k], unknownMap[k], bs[k], as[k])
+		}
+		return
+	}
+
+	beforeList, bok := before.([]any)
+	afterList, aok := after.([]any)
+	unknownList, uok := unknown.([]any)
+	if (bok || before == nil) && (aok || after == nil) && (bok || aok || uok) {
+		bs, _ := beforeSensitive.([]any)
+		as, _ := afterSensitive.([]any)
+		for i := range max(len(beforeList), len(afterList), len(unknownList)) {
+			w.walk(fmt.Sprintf("%s[%d]", path, i), at(beforeList, i), at(afterList, i), at(unknownList, i), at(bs, i), at(as, i))
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		w.add(AttributeChange{Path: path, Before: before, After: after})
+	}
+}
+
+func (w *diffWalker) add(c AttributeChange) {
+	for _, p := range w.replace {
+		if c.Path == p || strings.HasPrefix(c.Path, p+".") || strings.HasPrefix(c.Path, p+"[") {
+			c.ForcesReplacement = true
+			break
+		}
+	}
+	w.changes = append(w.changes, c)
+}
+
+// formatPath formats a replace path, a list of attribute names and indexes,
+// like the walker does.
+func formatPath(steps []any) string {
+	var path string
+	for _, s := range steps {
+		switch s := s.(type) {
+		case string:
+			path = joinPath(path, s)
+		case float64:
+			path += "[" + strconv.Itoa(int(s)) + "]"
+		}
+	}
+	return path
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func at(list []any, i int) any {
+	if i < len(list) {
+		return list[i]
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package terraform_test
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/terraform"
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+func TestPlan_Diff(t *testing.T) {
+	b, err := os.ReadFile("testdata/plan.json")
+	testutil.AssertNoError(t, err, "the plan should be readable")
+	var plan terraform.Plan
+	testutil.AssertNoError(t, json.Unmarshal(b, &plan), "the plan should decode")
+
+	diff, err := plan.Diff()
+	testutil.AssertNoError(t, err, "the diff should succeed")
+	testutil.AssertEqual(t, diff.String(), "1 to create, 1 to update, 1 to replace, 1 to delete", "reads and no-ops should be left out")
+	testutil.AssertTrue(t, diff.Destructive(), "a plan with a replace should be destructive")
+
+	want := []terraform.ResourceDiff{
+		{
+			Address: "module.service.random_password.admin",
+			Type:    "random_password",
+			Action:  terraform.DiffCreate,
+			Attributes: []terraform.AttributeChange{
+				{Path: "id", Unknown: true},
+				{Path: "length", After: float64(24)},
+				{Path: "result", Sensitive: true, Unknown: true},
+				{Path: "special", After: true},
+			},
+		},
+		{
+			Address: "module.service.aws_db_instance.main",
+			Type:    "aws_db_instance",
+			Action:  terraform.DiffReplace,
+			Reason:  "replace_because_cannot_update",
+			Attributes: []terraform.AttributeChange{
+				{Path: "engine_version", Before: "15.4", After: "16.1", ForcesReplacement: true},
+				{Path: "id", Before: "db-1", Unknown: true},
+				{Path: "instance_class", Before: "db.t3.micro", After: "db.t3.small"},
+				{Path: "password", Sensitive: true},
+				{Path: "subnets[1]", Before: "b"},
+				{Path: "tags.env", After: "prod"},
+			},
+		},
+		{
+			Address: "module.service.aws_security_group.db",
+			Type:    "aws_security_group",
+			Action:  terraform.DiffUpdate,
+			Attributes: []terraform.AttributeChange{
+				{Path: "ingress[0].cidr", Before: "10.0.0.0/8", After: "10.1.0.0/16"},
+			},
+		},
+		{
+			Address:    "module.service.aws_s3_bucket.backups",
+			Type:       "aws_s3_bucket",
+			Action:     terraform.DiffDelete,
+			Attributes: []terraform.AttributeChange{},
+		},
+	}
+	if !reflect.DeepEqual(diff.Resources, want) {
+		got, _ := json.MarshalIndent(diff.Resources, "", "  ")
+		t.Errorf("Diff() resources =\n%s", got)
+	}
+}
+
+func TestDiff_Destructive(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions [][]string
+		want    bool
+	}{
+		{name: "empty"},
+		{name: "create_and_update", actions: [][]string{{"create"}, {"update"}, {"no-op"}}},
+		{name: "delete", actions: [][]string{{"create"}, {"delete"}}, want: true},
+		{name: "create_before_destroy", actions: [][]string{{"create", "delete"}}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var changes []terraform.ResourceChange
+			for _, actions := range tt.actions {
+				changes = append(changes, terraform.ResourceChange{Address: "null_resource.x", Mode: "managed", Change: terraform.Change{Actions: actions}})
+			}
+			diff, err := terraform.NewDiff(changes)
+			testutil.AssertNoError(t, err, "the diff should succeed")
+			testutil.AssertEqual(t, diff.Destructive(), tt.want, "destructive should match")
+		})
+	}
+}
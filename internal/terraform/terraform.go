@@ -0,0 +1,252 @@
+// Package terraform runs Terraform, or OpenTofu, against a working
+// directory. Executor is the interface the rest of Mahler provisions
+// through; CLI implements it with the terraform binary.
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Executor runs Terraform commands in a working directory holding a root
+// module. Long-running commands report their progress to the Log function
+// of their options as it happens.
+type Executor interface {
+	// Init prepares dir: it configures the backend and installs providers
+	// and modules.
+	Init(ctx context.Context, dir string, opts InitOptions) error
+	// Plan computes the changes needed to reach the configuration, saving
+	// them to opts.Out when set.
+	Plan(ctx context.Context, dir string, opts PlanOptions) (*PlanSummary, error)
+	// Apply makes the changes of a saved plan, or of a fresh one when
+	// opts.PlanFile is empty, without asking for approval.
+	Apply(ctx context.Context, dir string, opts ApplyOptions) (*ChangeSummary, error)
+	// Destroy destroys everything in the state without asking for approval.
+	Destroy(ctx context.Context, dir string, opts ApplyOptions) (*ChangeSummary, error)
+	// Show returns the saved plan planFile.
+	Show(ctx context.Context, dir, planFile string) (*Plan, error)
+	// Output returns the root module's outputs from the state.
+	Output(ctx context.Context, dir string) (map[string]OutputValue, error)
+}
+
+// envKey is the context key of the environment added with WithEnv.
+type envKey struct{}
+
+// WithEnv returns a copy of ctx adding env, of the form KEY=value, to the
+// environment of the commands an Executor runs with it. Unlike files in
+// the working directory, it does not outlive the commands, which makes it
+// the place for secrets such as a backend's credentials.
+func WithEnv(ctx context.Context, env ...string) context.Context {
+	return context.WithValue(ctx, envKey{}, slices.Concat(Env(ctx), env))
+}
+
+// Env returns the environment added to ctx with WithEnv.
+func Env(ctx context.Context) []string {
+	env, _ := ctx.Value(envKey{}).([]string)
+	return env
+}
+
+// LogFunc receives the messages of a running command.
+type LogFunc func(Message)
+
+// Variables are passed to the commands that evaluate the configuration.
+type Variables struct {
+	// Vars are set with -var. Values are in Terraform's syntax for their
+	// type; strings are used as they are.
+	Vars map[string]string
+	// VarFiles are .tfvars or .tfvars.json files, relative to the working
+	// directory or absolute.
+	VarFiles []string
+}
+
+func (v Variables) args() []string {
+	var args []string
+	for _, f := range v.VarFiles {
+		args = append(args, "-var-file="+f)
+	}
+	for _, k := range slices.Sorted(maps.Keys(v.Vars)) {
+		args = append(args, "-var="+k+"="+v.Vars[k])
+	}
+	return args
+}
+
+type InitOptions struct {
+	// BackendConfig overrides settings of the backend block.
+	BackendConfig map[string]string
+	// MigrateState copies the existing state to a changed backend, e.g.
+	// local state to the backend configured since.
+	MigrateState bool
+	// Upgrade picks the newest provider and module versions the
+	// constraints allow, ignoring the lock file.
+	Upgrade bool
+	Log     LogFunc
+}
+
+type PlanOptions struct {
+	Variables
+	// Out saves the plan to this file for Apply and Show.
+	Out string
+	// Destroy plans to destroy everything.
+	Destroy bool
+	// RefreshOnly plans only to update the state to match the real
+	// infrastructure, which reveals drift.
+	RefreshOnly bool
+	Log         LogFunc
+}
+
+type ApplyOptions struct {
+	// Variables cannot be combined with PlanFile.
+	Variables
+	// PlanFile is a plan saved by Plan.
+	PlanFile string
+	Log      LogFunc
+}
+
+// Message is one line of Terraform's machine-readable UI output, as
+// printed with -json. Commands without -json support report each line of
+// their output as a message of type "log".
+type Message struct {
+	Level     string    `json:"@level"`
+	Text      string    `json:"@message"`
+	Module    string    `json:"@module,omitempty"`
+	Timestamp time.Time `json:"@timestamp"`
+	// Type is "version", "log", "diagnostic", "planned_change",
+	// "change_summary", "apply_start", "apply_complete" and so on.
+	Type       string         `json:"type"`
+	Diagnostic *Diagnostic    `json:"diagnostic,omitempty"`
+	Changes    *ChangeSummary `json:"changes,omitempty"`
+	// Hook holds the details of resource progress messages, such as
+	// "apply_complete".
+	Hook json.RawMessage `json:"hook,omitempty"`
+}
+
+// Diagnostic is an error or warning reported by Terraform.
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail,omitempty"`
+	Address  string `json:"address,omitempty"`
+	Range    *struct {
+		Filename string `json:"filename"`
+		Start    struct {
+			Line   int `json:"line"`
+			Column int `json:"column"`
+		} `json:"start"`
+	} `json:"range,omitempty"`
+}
+
+func (d Diagnostic) String() string {
+	s := d.Summary
+	if d.Range != nil {
+		s = fmt.Sprintf("%s (%s line %d)", s, d.Range.Filename, d.Range.Start.Line)
+	}
+	if d.Detail != "" {
+		s += ": " + d.Detail
+	}
+	return s
+}
+
+// ChangeSummary counts the resource changes of a plan or apply.
+type ChangeSummary struct {
+	Add    int `json:"add"`
+	Change int `json:"change"`
+	Import int `json:"import"`
+	Remove int `json:"remove"`
+	// Operation is "plan", "apply" or "destroy".
+	Operation string `json:"operation"`
+}
+
+// PlanSummary is the outcome of Plan.
+type PlanSummary struct {
+	// HasChanges reports whether applying the plan would change anything,
+	// including outputs and, for refresh-only plans, the state.
+	HasChanges bool
+	Changes    ChangeSummary
+	// Diagnostics are the warnings of the plan.
+	Diagnostics []Diagnostic
+}
+
+// OutputValue is a root module output, as printed by terraform output
+// -json.
+type OutputValue struct {
+	Sensitive bool `json:"sensitive"`
+	// Type is the output's type constraint in Terraform's JSON type
+	// syntax, such as "string" or ["list","number"].
+	Type  json.RawMessage `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Plan is a saved plan, as printed by terraform show -json. Only the parts
+// Mahler uses are decoded; Raw holds all of it.
+type Plan struct {
+	FormatVersion    string           `json:"format_version"`
+	TerraformVersion string           `json:"terraform_version"`
+	ResourceChanges  []ResourceChange `json:"resource_changes"`
+	// ResourceDrift lists the changes made outside of Terraform that the
+	// plan detected while refreshing.
+	ResourceDrift []ResourceChange  `json:"resource_drift"`
+	OutputChanges map[string]Change `json:"output_changes"`
+	Errored       bool              `json:"errored"`
+	Raw           json.RawMessage   `json:"-"`
+}
+
+// ResourceChange is the planned change of one resource instance.
+type ResourceChange struct {
+	Address      string `json:"address"`
+	ModuleAddr   string `json:"module_address,omitempty"`
+	Mode         string `json:"mode"`
+	Type         string `json:"type"`
+	Name         string `json:"name"`
+	ProviderName string `json:"provider_name"`
+	Change       Change `json:"change"`
+	// ActionReason explains replacements, e.g. "replace_because_cannot_update".
+	ActionReason string `json:"action_reason,omitempty"`
+}
+
+// Change describes the values before and after a change. Before and After
+// are null for creates and deletes respectively; AfterUnknown,
+// BeforeSensitive and AfterSensitive mirror their structure with true for
+// values unknown until apply or hidden as sensitive.
+type Change struct {
+	// Actions are ["no-op"], ["create"], ["read"], ["update"], ["delete"],
+	// ["delete","create"] or ["create","delete"]; the latter two replace.
+	Actions         []string        `json:"actions"`
+	Before          json.RawMessage `json:"before"`
+	After           json.RawMessage `json:"after"`
+	AfterUnknown    json.RawMessage `json:"after_unknown,omitempty"`
+	BeforeSensitive json.RawMessage `json:"before_sensitive,omitempty"`
+	AfterSensitive  json.RawMessage `json:"after_sensitive,omitempty"`
+	ReplacePaths    json.RawMessage `json:"replace_paths,omitempty"`
+}
+
+// Error is the error of a command that exited unsuccessfully.
+type Error struct {
+	// Command is the Terraform subcommand, e.g. "plan".
+	Command  string
+	ExitCode int
+	// Diagnostics are the errors Terraform reported.
+	Diagnostics []Diagnostic
+	// Stderr is the end of the command's standard error.
+	Stderr string
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "terraform %s: exit status %d", e.Command, e.ExitCode)
+	switch {
+	case len(e.Diagnostics) > 0:
+		msgs := make([]string, len(e.Diagnostics))
+		for i, d := range e.Diagnostics {
+			msgs[i] = d.String()
+		}
+		b.WriteString(": " + strings.Join(msgs, "; "))
+	case e.Stderr != "":
+		b.WriteString(": " + e.Stderr)
+	}
+	return b.String()
+}
@@ -0,0 +1,183 @@
+package terraform
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// pluginCacheDir is the directory below the root the workspaces share as
+// Terraform's plugin cache. Its name starts with a dot, so it is not a
+// workspace.
+const pluginCacheDir = ".plugin-cache"
+
+// Workspaces manages working directories below a root directory, one per
+// name. A workspace holds the files written to it, such as the root module
+// and its variables, and what Terraform adds: the dependency lock file,
+// which keeps providers at the versions selected first, the providers init
+// installs in .terraform, saved plans and, unless a backend is configured,
+// local state. Commands in different workspaces can run concurrently; the
+// only thing they share is the plugin cache, which Terraform itself keeps
+// consistent.
+type Workspaces struct {
+	root string
+}
+
+// NewWorkspaces returns the workspaces below root, which is created when
+// the first workspace is.
+func NewWorkspaces(root string) *Workspaces {
+	return &Workspaces{root: root}
+}
+
+// WorkspaceInfo describes a workspace on disk.
+type WorkspaceInfo struct {
+	Name string
+	Dir  string
+	// Size is the total size of the workspace's files in bytes, and Files
+	// their number.
+	Size  int64
+	Files int
+	// ModifiedAt is when a file in the workspace last changed.
+	ModifiedAt time.Time
+}
+
+// Dir returns the directory of workspace name, which need not exist.
+func (w *Workspaces) Dir(name string) string {
+	return filepath.Join(w.root, name)
+}
+
+// PluginCache creates the plugin cache shared by the workspaces and returns
+// its absolute path, for TF_PLUGIN_CACHE_DIR.
+func (w *Workspaces) PluginCache() (string, error) {
+	dir, err := filepath.Abs(filepath.Join(w.root, pluginCacheDir))
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("create plugin cache: %w", err)
+	}
+	return dir, nil
+}
+
+// Write creates workspace name if needed and writes files, by name, to it.
+// Files of the same name are replaced; the others, such as the lock file
+// and state, are kept. It returns the workspace's directory.
+func (w *Workspaces) Write(name string, files map[string][]byte) (string, error) {
+	if err := checkName(name); err != nil {
+		return "", err
+	}
+	dir := w.Dir(name)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("create workspace %s: %w", name, err)
+	}
+	for _, file := range slices.Sorted(maps.Keys(files)) {
+		if err := checkName(file); err != nil {
+			return "", err
+		}
+		if err := writeFile(filepath.Join(dir, file), files[file]); err != nil {
+			return "", fmt.Errorf("workspace %s: %w", name, err)
+		}
+	}
+	return dir, nil
+}
+
+// Remove deletes workspace name with everything in it. Removing a
+// workspace that does not exist is not an error.
+func (w *Workspaces) Remove(name string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(w.Dir(name)); err != nil {
+		return fmt.Errorf("remove workspace %s: %w", name, err)
+	}
+	return nil
+}
+
+// List returns the workspaces ordered by name, measuring each. Entries of
+// the root directory that are not directories, or whose name starts with a
+// dot, are not workspaces.
+func (w *Workspaces) List() ([]WorkspaceInfo, error) {
+	entries, err := os.ReadDir(w.root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []WorkspaceInfo{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list workspaces: %w", err)
+	}
+
+	infos := []WorkspaceInfo{}
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		info, err := w.measure(e.Name())
+		if errors.Is(err, fs.ErrNotExist) {
+			// Removed while we looked.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (w *Workspaces) measure(name string) (WorkspaceInfo, error) {
+	info := WorkspaceInfo{Name: name, Dir: w.Dir(name)}
+	err := filepath.WalkDir(info.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if fi.ModTime().After(info.ModifiedAt) {
+			info.ModifiedAt = fi.ModTime()
+		}
+		if fi.Mode().IsRegular() {
+			info.Size += fi.Size()
+			info.Files++
+		}
+		return nil
+	})
+	if err != nil {
+		return info, fmt.Errorf("measure workspace %s: %w", name, err)
+	}
+	info.ModifiedAt = info.ModifiedAt.UTC()
+	return info, nil
+}
+
+// checkName rejects names that are not a single path element, so that
+// nothing outside the root is touched.
+func checkName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid workspace or file name %q", name)
+	}
+	return nil
+}
+
+// writeFile replaces path with data through a temporary file, so that a
+// command never reads a partly written file.
+func writeFile(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,83 @@
+package terraform_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/terraform"
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+func TestWorkspaces(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "workspaces")
+	w := terraform.NewWorkspaces(root)
+
+	list, err := w.List()
+	testutil.AssertNoError(t, err, "listing a missing root should succeed")
+	testutil.AssertEqual(t, len(list), 0, "a missing root should hold no workspaces")
+
+	dir, err := w.Write("web", map[string][]byte{"main.tf.json": []byte(`{}`), "terraform.tfvars.json": []byte(`{"size":1}`)})
+	testutil.AssertNoError(t, err, "write should create the workspace")
+	testutil.AssertEqual(t, dir, w.Dir("web"), "write should return the workspace's directory")
+	// What Terraform adds must survive later writes.
+	testutil.AssertNoError(t, os.WriteFile(filepath.Join(dir, ".terraform.lock.hcl"), []byte("lock"), 0o600), "writing the lock file should succeed")
+	testutil.AssertNoError(t, os.MkdirAll(filepath.Join(dir, ".terraform", "providers"), 0o700), "creating the providers should succeed")
+	testutil.AssertNoError(t, os.WriteFile(filepath.Join(dir, ".terraform", "providers", "null"), []byte("provider"), 0o600), "writing a provider should succeed")
+
+	_, err = w.Write("web", map[string][]byte{"terraform.tfvars.json": []byte(`{"size":2}`)})
+	testutil.AssertNoError(t, err, "writing again should succeed")
+	b, err := os.ReadFile(filepath.Join(dir, "terraform.tfvars.json"))
+	testutil.AssertNoError(t, err, "the variables should be readable")
+	testutil.AssertEqual(t, string(b), `{"size":2}`, "files should be replaced")
+	_, err = os.Stat(filepath.Join(dir, ".terraform.lock.hcl"))
+	testutil.AssertNoError(t, err, "the lock file should be kept")
+
+	_, err = w.Write("api", nil)
+	testutil.AssertNoError(t, err, "an empty workspace should be created")
+	testutil.AssertNoError(t, os.MkdirAll(filepath.Join(root, ".cache"), 0o700), "creating a hidden directory should succeed")
+	testutil.AssertNoError(t, os.WriteFile(filepath.Join(root, "notes.txt"), nil, 0o600), "creating a file should succeed")
+
+	list, err = w.List()
+	testutil.AssertNoError(t, err, "list should succeed")
+	testutil.AssertEqual(t, len(list), 2, "only workspaces should be listed")
+	testutil.AssertEqual(t, list[0].Name, "api", "workspaces should be ordered by name")
+	testutil.AssertEqual(t, list[0].Size, int64(0), "an empty workspace should use no space")
+	web := list[1]
+	testutil.AssertEqual(t, web.Files, 4, "every file should be counted, including installed providers")
+	testutil.AssertEqual(t, web.Size, int64(len(`{}`)+len(`{"size":2}`)+len("lock")+len("provider")), "the size should add up the files")
+	testutil.AssertFalse(t, web.ModifiedAt.IsZero(), "the modification time should be set")
+
+	testutil.AssertNoError(t, w.Remove("web"), "remove should succeed")
+	_, err = os.Stat(dir)
+	testutil.AssertTrue(t, os.IsNotExist(err), "the workspace should be gone")
+	testutil.AssertNoError(t, w.Remove("web"), "removing a missing workspace should succeed")
+}
+
+func TestWorkspaces_PluginCache(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "workspaces")
+	w := terraform.NewWorkspaces(root)
+
+	dir, err := w.PluginCache()
+	testutil.AssertNoError(t, err, "creating the plugin cache should succeed")
+	testutil.AssertTrue(t, filepath.IsAbs(dir), "the plugin cache should be absolute, as Terraform runs in the workspaces")
+	info, err := os.Stat(dir)
+	testutil.AssertNoError(t, err, "the plugin cache should exist")
+	testutil.AssertTrue(t, info.IsDir(), "the plugin cache should be a directory")
+	testutil.AssertEqual(t, filepath.Dir(dir), root, "the plugin cache should be below the root")
+
+	list, err := w.List()
+	testutil.AssertNoError(t, err, "list should succeed")
+	testutil.AssertEqual(t, len(list), 0, "the plugin cache should not be a workspace")
+}
+
+func TestWorkspaces_InvalidNames(t *testing.T) {
+	w := terraform.NewWorkspaces(t.TempDir())
+	for _, name := range []string{"", ".", "..", "../escape", "a/b", `a\b`} {
+		_, err := w.Write(name, nil)
+		testutil.AssertError(t, err, "write should reject "+name)
+		testutil.AssertError(t, w.Remove(name), "remove should reject "+name)
+	}
+	_, err := w.Write("web", map[string][]byte{"../main.tf": nil})
+	testutil.AssertError(t, err, "files outside the workspace should be rejected")
+}
@@ -58,9 +58,10 @@ func (b *ProjectBuilder) Build() *project.Project {
 
 // ServiceBuilder builds test Service instances using the builder pattern
 type ServiceBuilder struct {
-	id       uuid.UUID
-	name     string
-	resource resource.Resource
+	id        uuid.UUID
+	projectID uuid.UUID
+	name      string
+	resource  resource.Resource
 }
 
 // NewServiceBuilder creates a new ServiceBuilder with default values
@@ -78,6 +79,12 @@ func (b *ServiceBuilder) WithID(id uuid.UUID) *ServiceBuilder {
 	return b
 }
 
+// WithProjectID sets the ID of the project the service belongs to
+func (b *ServiceBuilder) WithProjectID(id uuid.UUID) *ServiceBuilder {
+	b.projectID = id
+	return b
+}
+
 // WithName sets the service name
 func (b *ServiceBuilder) WithName(name string) *ServiceBuilder {
 	b.name = name
@@ -92,11 +99,16 @@ func (b *ServiceBuilder) WithResource(res resource.Resource) *ServiceBuilder {
 
 // Build creates the Service instance
 func (b *ServiceBuilder) Build() *service.Service {
-	return &service.Service{
-		ID:       b.id,
-		Name:     b.name,
-		Resource: b.resource,
+	svc := &service.Service{
+		ID:        b.id,
+		ProjectID: b.projectID,
+		Name:      b.name,
+		Resource:  b.resource,
+	}
+	if b.resource != nil {
+		svc.ResourceKey = b.resource.Key()
 	}
+	return svc
 }
 
 // Test Fixtures - commonly used test data
@@ -127,3 +139,13 @@ func NewTestService() *service.Service {
 func NewTestServiceWithResource(res resource.Resource) *service.Service {
 	return NewServiceBuilder().WithResource(res).Build()
 }
+
+// NewTestCatalog creates a catalog offering resources. It panics on duplicate
+// keys.
+func NewTestCatalog(resources ...resource.Resource) *resource.Catalog {
+	catalog, err := resource.NewCatalog(resources...)
+	if err != nil {
+		panic(err)
+	}
+	return catalog
+}
@@ -87,6 +87,15 @@ func TestServiceBuilder(t *testing.T) {
 		AssertEqual(t, svc.ID, id, "custom ID should be set")
 	})
 
+	t.Run("builder_with_project_id", func(t *testing.T) {
+		projectID := uuid.New()
+		svc := NewServiceBuilder().
+			WithProjectID(projectID).
+			Build()
+
+		AssertEqual(t, svc.ProjectID, projectID, "project ID should be set")
+	})
+
 	t.Run("builder_with_custom_resource", func(t *testing.T) {
 		mockResource := NewMockResource().WithName("custom-resource")
 		svc := NewServiceBuilder().
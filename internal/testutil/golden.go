@@ -0,0 +1,54 @@
+package testutil
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bermos/Platform/internal/k8s"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// AssertGolden compares got with the file testdata/name. Run the test with
+// -update to rewrite the file instead.
+func AssertGolden(t testing.TB, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create testdata: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s mismatch (run go test -update to accept)\n--- got ---\n%s\n--- want ---\n%s", name, got, want)
+	}
+}
+
+// AssertManifestsGolden renders objects as YAML and JSON and compares them
+// with testdata/name.yaml.golden and testdata/name.json.golden.
+func AssertManifestsGolden(t testing.TB, name string, objects []k8s.Object) {
+	t.Helper()
+
+	yamlOut, err := k8s.YAML(objects...)
+	if err != nil {
+		t.Fatalf("YAML() error = %v", err)
+	}
+	AssertGolden(t, name+".yaml.golden", yamlOut)
+
+	jsonOut, err := k8s.JSON(objects...)
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	AssertGolden(t, name+".json.golden", append(jsonOut, '\n'))
+}
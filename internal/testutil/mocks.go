@@ -2,16 +2,27 @@ package testutil
 
 import (
 	"time"
+
+	"github.com/Bermos/Platform/internal/resource"
+	"github.com/danielgtaylor/huma/v2"
 )
 
 // MockResource is a mock implementation of the resource.Resource interface
 type MockResource struct {
-	NameValue        string
-	DescriptionValue string
-	ProvidesValue    []interface{}
-	PriceValue       float64
-	CPUMetrics       string
-	MemoryMetrics    string
+	KeyValue          string
+	NameValue         string
+	DescriptionValue  string
+	ProvidesValue     []resource.Capability
+	RequiresValue     []resource.Requirement
+	ConfigSchemaValue *huma.Schema
+	PriceValue        float64
+	CPUMetrics        string
+	MemoryMetrics     string
+}
+
+// Key returns the mock resource key
+func (m *MockResource) Key() string {
+	return m.KeyValue
 }
 
 // Name returns the mock resource name
@@ -25,10 +36,20 @@ func (m *MockResource) Description() string {
 }
 
 // Provides returns what the mock resource provides
-func (m *MockResource) Provides() []interface{} {
+func (m *MockResource) Provides() []resource.Capability {
 	return m.ProvidesValue
 }
 
+// Requires returns what the mock resource requires
+func (m *MockResource) Requires() []resource.Requirement {
+	return m.RequiresValue
+}
+
+// ConfigSchema returns the mock resource config schema
+func (m *MockResource) ConfigSchema() *huma.Schema {
+	return m.ConfigSchemaValue
+}
+
 // Price returns the mock resource price for the given duration
 func (m *MockResource) Price(duration time.Duration) float64 {
 	return m.PriceValue
@@ -47,15 +68,22 @@ func (m *MockResource) MetricsMemory() string {
 // NewMockResource creates a new mock resource with default values
 func NewMockResource() *MockResource {
 	return &MockResource{
+		KeyValue:         "mock-resource",
 		NameValue:        "mock-resource",
 		DescriptionValue: "A mock resource for testing",
-		ProvidesValue:    []interface{}{"compute", "storage"},
+		ProvidesValue:    []resource.Capability{},
 		PriceValue:       10.0,
 		CPUMetrics:       "/metrics/cpu",
 		MemoryMetrics:    "/metrics/memory",
 	}
 }
 
+// WithKey sets the key of the mock resource (builder pattern)
+func (m *MockResource) WithKey(key string) *MockResource {
+	m.KeyValue = key
+	return m
+}
+
 // WithName sets the name of the mock resource (builder pattern)
 func (m *MockResource) WithName(name string) *MockResource {
 	m.NameValue = name
@@ -69,11 +97,23 @@ func (m *MockResource) WithDescription(desc string) *MockResource {
 }
 
 // WithProvides sets what the mock resource provides (builder pattern)
-func (m *MockResource) WithProvides(provides []interface{}) *MockResource {
+func (m *MockResource) WithProvides(provides ...resource.Capability) *MockResource {
 	m.ProvidesValue = provides
 	return m
 }
 
+// WithRequires sets what the mock resource requires (builder pattern)
+func (m *MockResource) WithRequires(requires ...resource.Requirement) *MockResource {
+	m.RequiresValue = requires
+	return m
+}
+
+// WithConfigSchema sets the config schema of the mock resource (builder pattern)
+func (m *MockResource) WithConfigSchema(schema *huma.Schema) *MockResource {
+	m.ConfigSchemaValue = schema
+	return m
+}
+
 // WithPrice sets the price of the mock resource (builder pattern)
 func (m *MockResource) WithPrice(price float64) *MockResource {
 	m.PriceValue = price
@@ -3,6 +3,8 @@ package testutil
 import (
 	"testing"
 	"time"
+
+	"github.com/Bermos/Platform/internal/resource"
 )
 
 func TestNewMockResource(t *testing.T) {
@@ -10,6 +12,7 @@ func TestNewMockResource(t *testing.T) {
 		mock := NewMockResource()
 
 		AssertNotNil(t, mock, "mock resource should not be nil")
+		AssertEqual(t, mock.Key(), "mock-resource", "default key should be set")
 		AssertEqual(t, mock.Name(), "mock-resource", "default name should be set")
 		AssertEqual(t, mock.Description(), "A mock resource for testing", "default description should be set")
 		AssertNotNil(t, mock.Provides(), "provides should not be nil")
@@ -22,22 +25,33 @@ func TestNewMockResource(t *testing.T) {
 func TestMockResource_Builder(t *testing.T) {
 	t.Run("builder_pattern_works", func(t *testing.T) {
 		mock := NewMockResource().
+			WithKey("custom-key").
 			WithName("custom-resource").
 			WithDescription("Custom description").
 			WithPrice(25.5)
 
+		AssertEqual(t, mock.Key(), "custom-key", "key should be customized")
 		AssertEqual(t, mock.Name(), "custom-resource", "name should be customized")
 		AssertEqual(t, mock.Description(), "Custom description", "description should be customized")
 		AssertEqual(t, mock.Price(time.Hour), 25.5, "price should be customized")
 	})
 
 	t.Run("with_provides", func(t *testing.T) {
-		provides := []interface{}{"database", "cache"}
-		mock := NewMockResource().WithProvides(provides)
+		mock := NewMockResource().WithProvides(
+			resource.Provide(resource.PostgresConnection),
+			resource.Provide(resource.HTTPEndpoint),
+		)
 
 		AssertNotNil(t, mock.Provides(), "provides should not be nil")
 		AssertEqual(t, len(mock.Provides()), 2, "provides should have 2 items")
 	})
+
+	t.Run("with_requires", func(t *testing.T) {
+		mock := NewMockResource().WithRequires(resource.Require(resource.ObjectBucket))
+
+		AssertEqual(t, len(mock.Requires()), 1, "requires should have 1 item")
+		AssertEqual(t, mock.Requires()[0].Type, resource.ObjectBucket, "requirement type should match")
+	})
 }
 
 func TestMockResource_Price(t *testing.T) {
@@ -3,6 +3,8 @@ package testutil
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
 	"sync"
 
 	"github.com/Bermos/Platform/internal/project"
@@ -10,25 +12,11 @@ import (
 	"github.com/google/uuid"
 )
 
-// ProjectRepository defines the interface for project persistence operations
-// This interface will be moved to internal/project package in Phase 0.3.3
-type ProjectRepository interface {
-	Create(ctx context.Context, proj *project.Project) error
-	Get(ctx context.Context, id uuid.UUID) (*project.Project, error)
-	Update(ctx context.Context, proj *project.Project) error
-	Delete(ctx context.Context, id uuid.UUID) error
-	List(ctx context.Context) ([]*project.Project, error)
-}
-
-// ServiceRepository defines the interface for service persistence operations
-// This interface will be moved to internal/service package in Phase 0.3.3
-type ServiceRepository interface {
-	Create(ctx context.Context, svc *service.Service) error
-	Get(ctx context.Context, id uuid.UUID) (*service.Service, error)
-	Update(ctx context.Context, svc *service.Service) error
-	Delete(ctx context.Context, id uuid.UUID) error
-	ListByProject(ctx context.Context, projectID uuid.UUID) ([]*service.Service, error)
-}
+// Compile-time checks that the mocks satisfy the domain repository interfaces.
+var (
+	_ project.Repository = (*MockProjectRepository)(nil)
+	_ service.Repository = (*MockServiceRepository)(nil)
+)
 
 // MockProjectRepository is an in-memory mock implementation of ProjectRepository
 type MockProjectRepository struct {
@@ -58,7 +46,10 @@ func (r *MockProjectRepository) Create(ctx context.Context, proj *project.Projec
 	defer r.mu.Unlock()
 
 	if _, exists := r.projects[proj.ID]; exists {
-		return fmt.Errorf("project with ID %s already exists", proj.ID)
+		return fmt.Errorf("project with ID %s: %w", proj.ID, project.ErrAlreadyExists)
+	}
+	if r.nameTaken(proj) {
+		return fmt.Errorf("project with name %q: %w", proj.Name, project.ErrAlreadyExists)
 	}
 
 	r.projects[proj.ID] = proj
@@ -75,7 +66,7 @@ func (r *MockProjectRepository) Get(ctx context.Context, id uuid.UUID) (*project
 
 	proj, exists := r.projects[id]
 	if !exists {
-		return nil, fmt.Errorf("project with ID %s not found", id)
+		return nil, fmt.Errorf("project with ID %s: %w", id, project.ErrNotFound)
 	}
 
 	return proj, nil
@@ -90,7 +81,10 @@ func (r *MockProjectRepository) Update(ctx context.Context, proj *project.Projec
 	defer r.mu.Unlock()
 
 	if _, exists := r.projects[proj.ID]; !exists {
-		return fmt.Errorf("project with ID %s not found", proj.ID)
+		return fmt.Errorf("project with ID %s: %w", proj.ID, project.ErrNotFound)
+	}
+	if r.nameTaken(proj) {
+		return fmt.Errorf("project with name %q: %w", proj.Name, project.ErrAlreadyExists)
 	}
 
 	r.projects[proj.ID] = proj
@@ -106,7 +100,7 @@ func (r *MockProjectRepository) Delete(ctx context.Context, id uuid.UUID) error
 	defer r.mu.Unlock()
 
 	if _, exists := r.projects[id]; !exists {
-		return fmt.Errorf("project with ID %s not found", id)
+		return fmt.Errorf("project with ID %s: %w", id, project.ErrNotFound)
 	}
 
 	delete(r.projects, id)
@@ -125,26 +119,43 @@ func (r *MockProjectRepository) List(ctx context.Context) ([]*project.Project, e
 	for _, proj := range r.projects {
 		projects = append(projects, proj)
 	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Name < projects[j].Name
+	})
 
 	return projects, nil
 }
 
+// nameTaken reports whether another project already uses the name of proj
+func (r *MockProjectRepository) nameTaken(proj *project.Project) bool {
+	for id, other := range r.projects {
+		if id != proj.ID && other.Name == proj.Name {
+			return true
+		}
+	}
+	return false
+}
+
 // MockServiceRepository is an in-memory mock implementation of ServiceRepository
 type MockServiceRepository struct {
 	mu       sync.RWMutex
 	services map[uuid.UUID]*service.Service
+	history  map[uuid.UUID][]*service.Transition
 	// For simulating errors
 	CreateError        error
 	GetError           error
 	UpdateError        error
 	DeleteError        error
 	ListByProjectError error
+	TransitionError    error
+	HistoryError       error
 }
 
 // NewMockServiceRepository creates a new mock service repository
 func NewMockServiceRepository() *MockServiceRepository {
 	return &MockServiceRepository{
 		services: make(map[uuid.UUID]*service.Service),
+		history:  make(map[uuid.UUID][]*service.Transition),
 	}
 }
 
@@ -157,7 +168,13 @@ func (r *MockServiceRepository) Create(ctx context.Context, svc *service.Service
 	defer r.mu.Unlock()
 
 	if _, exists := r.services[svc.ID]; exists {
-		return fmt.Errorf("service with ID %s already exists", svc.ID)
+		return fmt.Errorf("service with ID %s: %w", svc.ID, service.ErrAlreadyExists)
+	}
+	if r.nameTaken(svc) {
+		return fmt.Errorf("service with name %q: %w", svc.Name, service.ErrAlreadyExists)
+	}
+	if err := r.checkDependencies(svc); err != nil {
+		return err
 	}
 
 	r.services[svc.ID] = svc
@@ -174,7 +191,7 @@ func (r *MockServiceRepository) Get(ctx context.Context, id uuid.UUID) (*service
 
 	svc, exists := r.services[id]
 	if !exists {
-		return nil, fmt.Errorf("service with ID %s not found", id)
+		return nil, fmt.Errorf("service with ID %s: %w", id, service.ErrNotFound)
 	}
 
 	return svc, nil
@@ -188,11 +205,38 @@ func (r *MockServiceRepository) Update(ctx context.Context, svc *service.Service
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.services[svc.ID]; !exists {
-		return fmt.Errorf("service with ID %s not found", svc.ID)
+	stored, exists := r.services[svc.ID]
+	if !exists {
+		return fmt.Errorf("service with ID %s: %w", svc.ID, service.ErrNotFound)
+	}
+	if r.nameTaken(svc) {
+		return fmt.Errorf("service with name %q: %w", svc.Name, service.ErrAlreadyExists)
+	}
+	if err := r.checkDependencies(svc); err != nil {
+		return err
 	}
 
-	r.services[svc.ID] = svc
+	updated := *svc
+	updated.Status = stored.Status
+	r.services[svc.ID] = &updated
+	return nil
+}
+
+// UpdateDrift sets the drift of a service in the mock repository
+func (r *MockServiceRepository) UpdateDrift(ctx context.Context, id uuid.UUID, drift *service.Drift) error {
+	if r.UpdateError != nil {
+		return r.UpdateError
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.services[id]
+	if !exists {
+		return fmt.Errorf("service with ID %s: %w", id, service.ErrNotFound)
+	}
+	updated := *stored
+	updated.Drift = drift
+	r.services[id] = &updated
 	return nil
 }
 
@@ -205,16 +249,20 @@ func (r *MockServiceRepository) Delete(ctx context.Context, id uuid.UUID) error
 	defer r.mu.Unlock()
 
 	if _, exists := r.services[id]; !exists {
-		return fmt.Errorf("service with ID %s not found", id)
+		return fmt.Errorf("service with ID %s: %w", id, service.ErrNotFound)
+	}
+	for _, other := range r.services {
+		if slices.Contains(other.DependsOn, id) {
+			return fmt.Errorf("service %s depends on service with ID %s: %w", other.Name, id, service.ErrHasDependents)
+		}
 	}
 
 	delete(r.services, id)
+	delete(r.history, id)
 	return nil
 }
 
 // ListByProject returns all services for a given project from the mock repository
-// Note: In a real implementation, this would filter by project ID
-// For now, this mock implementation returns all services
 func (r *MockServiceRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*service.Service, error) {
 	if r.ListByProjectError != nil {
 		return nil, r.ListByProjectError
@@ -222,10 +270,81 @@ func (r *MockServiceRepository) ListByProject(ctx context.Context, projectID uui
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	services := make([]*service.Service, 0, len(r.services))
+	services := make([]*service.Service, 0)
 	for _, svc := range r.services {
-		services = append(services, svc)
+		if svc.ProjectID == projectID {
+			services = append(services, svc)
+		}
 	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
 
 	return services, nil
 }
+
+// Transition changes the status of a service in the mock repository
+func (r *MockServiceRepository) Transition(ctx context.Context, t *service.Transition) error {
+	if r.TransitionError != nil {
+		return r.TransitionError
+	}
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	svc, exists := r.services[t.ServiceID]
+	if !exists {
+		return fmt.Errorf("service with ID %s: %w", t.ServiceID, service.ErrNotFound)
+	}
+	if svc.Status != t.From {
+		return fmt.Errorf("service with ID %s is %s: %w", t.ServiceID, svc.Status, service.ErrStatusConflict)
+	}
+
+	updated := *svc
+	updated.Status = t.To
+	r.services[t.ServiceID] = &updated
+	recorded := *t
+	r.history[t.ServiceID] = append(r.history[t.ServiceID], &recorded)
+	return nil
+}
+
+// History returns the transitions of a service from the mock repository
+func (r *MockServiceRepository) History(ctx context.Context, id uuid.UUID) ([]*service.Transition, error) {
+	if r.HistoryError != nil {
+		return nil, r.HistoryError
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, exists := r.services[id]; !exists {
+		return nil, fmt.Errorf("service with ID %s: %w", id, service.ErrNotFound)
+	}
+	return append(make([]*service.Transition, 0, len(r.history[id])), r.history[id]...), nil
+}
+
+// nameTaken reports whether another service in the same project already uses
+// the name of svc
+func (r *MockServiceRepository) nameTaken(svc *service.Service) bool {
+	for id, other := range r.services {
+		if id != svc.ID && other.ProjectID == svc.ProjectID && other.Name == svc.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDependencies reports whether svc depends on anything but distinct
+// other services of its project
+func (r *MockServiceRepository) checkDependencies(svc *service.Service) error {
+	seen := make(map[uuid.UUID]bool, len(svc.DependsOn))
+	for _, id := range svc.DependsOn {
+		dep, exists := r.services[id]
+		if !exists || id == svc.ID || dep.ProjectID != svc.ProjectID || seen[id] {
+			return fmt.Errorf("service %s cannot depend on %s: %w", svc.Name, id, service.ErrInvalidDependency)
+		}
+		seen[id] = true
+	}
+	return nil
+}
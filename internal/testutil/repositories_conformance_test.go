@@ -0,0 +1,29 @@
+package testutil_test
+
+import (
+	"testing"
+
+	"github.com/Bermos/Platform/internal/project"
+	"github.com/Bermos/Platform/internal/project/projecttest"
+	"github.com/Bermos/Platform/internal/service"
+	"github.com/Bermos/Platform/internal/service/servicetest"
+	"github.com/Bermos/Platform/internal/testutil"
+	"github.com/google/uuid"
+)
+
+func TestMockProjectRepository_Conformance(t *testing.T) {
+	projecttest.RunRepositoryTests(t, func(t *testing.T) project.Repository {
+		return testutil.NewMockProjectRepository()
+	})
+}
+
+func TestMockServiceRepository_Conformance(t *testing.T) {
+	servicetest.RunRepositoryTests(t,
+		func(t *testing.T) service.Repository {
+			return testutil.NewMockServiceRepository()
+		},
+		func(t *testing.T, repo service.Repository) uuid.UUID {
+			return uuid.New()
+		},
+	)
+}
@@ -339,8 +339,8 @@ func TestMockServiceRepository_ListByProject(t *testing.T) {
 		ctx := context.Background()
 		projectID := uuid.New()
 
-		svc1 := NewServiceBuilder().WithName("service-1").Build()
-		svc2 := NewServiceBuilder().WithName("service-2").Build()
+		svc1 := NewServiceBuilder().WithProjectID(projectID).WithName("service-1").Build()
+		svc2 := NewServiceBuilder().WithProjectID(projectID).WithName("service-2").Build()
 
 		err := repo.Create(ctx, svc1)
 		AssertNoError(t, err, "create service 1 should succeed")
@@ -353,6 +353,23 @@ func TestMockServiceRepository_ListByProject(t *testing.T) {
 		AssertEqual(t, len(services), 2, "should list 2 services")
 	})
 
+	t.Run("filters_by_project", func(t *testing.T) {
+		repo := NewMockServiceRepository()
+		ctx := context.Background()
+		projectID := uuid.New()
+
+		err := repo.Create(ctx, NewServiceBuilder().WithProjectID(projectID).WithName("mine").Build())
+		AssertNoError(t, err, "create own service should succeed")
+
+		err = repo.Create(ctx, NewServiceBuilder().WithProjectID(uuid.New()).WithName("theirs").Build())
+		AssertNoError(t, err, "create foreign service should succeed")
+
+		services, err := repo.ListByProject(ctx, projectID)
+		AssertNoError(t, err, "list should succeed")
+		AssertEqual(t, len(services), 1, "should only list services of the project")
+		AssertEqual(t, services[0].Name, "mine", "should list the project's service")
+	})
+
 	t.Run("returns_empty_list_when_no_services", func(t *testing.T) {
 		repo := NewMockServiceRepository()
 		ctx := context.Background()
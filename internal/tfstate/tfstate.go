@@ -0,0 +1,196 @@
+// Package tfstate keeps Terraform state for Terraform's http backend. State
+// lives in workspaces, each with its own password; every write adds a
+// version, so earlier states can be inspected and restored.
+package tfstate
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned for unknown workspaces, and for versions and
+	// locks a workspace does not have.
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("workspace already exists")
+	// ErrLocked is returned when a lock is held by someone else.
+	ErrLocked = errors.New("state is locked")
+	// ErrInvalidState is returned for state that is not a JSON object.
+	ErrInvalidState = errors.New("invalid state")
+)
+
+// namePattern restricts workspace names to what is safe in URLs and file
+// names.
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,62}$`)
+
+// ValidName reports whether name can name a workspace.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Workspace holds the state of one Terraform root module.
+type Workspace struct {
+	Name string
+	// PasswordHash is the SHA-256 of the workspace's password. Passwords
+	// are random, so a slow hash adds nothing.
+	PasswordHash []byte
+	CreatedAt    time.Time
+}
+
+// NewWorkspace returns a workspace with a fresh random password, which is
+// only returned here.
+func NewWorkspace(name string, now time.Time) (*Workspace, string, error) {
+	if !ValidName(name) {
+		return nil, "", fmt.Errorf("invalid workspace name %q", name)
+	}
+	w := &Workspace{Name: name, CreatedAt: now}
+	password, err := w.ResetPassword()
+	if err != nil {
+		return nil, "", err
+	}
+	return w, password, nil
+}
+
+// ResetPassword gives the workspace a fresh random password, which is only
+// returned here, and invalidates the old one once the workspace is
+// updated.
+func (w *Workspace) ResetPassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate password: %w", err)
+	}
+	password := base64.RawURLEncoding.EncodeToString(b)
+	w.PasswordHash = hashPassword(password)
+	return password, nil
+}
+
+// CheckPassword reports whether password is the workspace's password.
+func (w *Workspace) CheckPassword(password string) bool {
+	return subtle.ConstantTimeCompare(hashPassword(password), w.PasswordHash) == 1
+}
+
+func hashPassword(password string) []byte {
+	sum := sha256.Sum256([]byte(password))
+	return sum[:]
+}
+
+// Version is one state written to a workspace. Versions are numbered from 1
+// per workspace.
+type Version struct {
+	Workspace string
+	Version   int
+	// Serial and Lineage are copied from the state.
+	Serial  int64
+	Lineage string
+	// MD5 is the base64 MD5 of Data, as in a Content-MD5 header.
+	MD5       string
+	Data      []byte
+	CreatedAt time.Time
+}
+
+// NewVersion returns a version of workspace holding the state data. Its
+// number is assigned when it is added to a repository.
+func NewVersion(workspace string, data []byte, now time.Time) (*Version, error) {
+	var meta struct {
+		Serial  int64  `json:"serial"`
+		Lineage string `json:"lineage"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+	return &Version{
+		Workspace: workspace,
+		Serial:    meta.Serial,
+		Lineage:   meta.Lineage,
+		MD5:       Checksum(data),
+		Data:      data,
+		CreatedAt: now,
+	}, nil
+}
+
+// Restore returns a new version of v's workspace holding v's state with its
+// serial raised to serial. Terraform only accepts a state that is newer
+// than the one it last read.
+func (v *Version) Restore(serial int64, now time.Time) (*Version, error) {
+	var state map[string]json.RawMessage
+	if err := json.Unmarshal(v.Data, &state); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+	state["serial"] = json.RawMessage(fmt.Sprint(serial))
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode state: %w", err)
+	}
+	return NewVersion(v.Workspace, data, now)
+}
+
+// Checksum returns the base64 MD5 of data.
+func Checksum(data []byte) string {
+	sum := md5.Sum(data)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Lock is the lock Terraform holds on a workspace while it changes the
+// state.
+type Lock struct {
+	Workspace string
+	// ID identifies the lock; Terraform sends it with the writes it makes
+	// while holding the lock.
+	ID string
+	// Info is the lock information Terraform sent, returned as is to those
+	// who find the workspace locked.
+	Info      json.RawMessage
+	CreatedAt time.Time
+}
+
+// NewLock returns the lock described by Terraform's lock information.
+func NewLock(workspace string, info []byte, now time.Time) (*Lock, error) {
+	var meta struct {
+		ID string `json:"ID"`
+	}
+	if err := json.Unmarshal(info, &meta); err != nil || meta.ID == "" {
+		return nil, errors.New("lock information must be a JSON object with an ID")
+	}
+	return &Lock{Workspace: workspace, ID: meta.ID, Info: info, CreatedAt: now}, nil
+}
+
+// Repository stores workspaces with their state versions and locks.
+type Repository interface {
+	CreateWorkspace(ctx context.Context, w *Workspace) error
+	GetWorkspace(ctx context.Context, name string) (*Workspace, error)
+	// UpdateWorkspace stores the password hash of w.
+	UpdateWorkspace(ctx context.Context, w *Workspace) error
+	// DeleteWorkspace deletes the workspace with its versions and lock.
+	DeleteWorkspace(ctx context.Context, name string) error
+
+	// AddVersion stores v as the workspace's newest version and sets its
+	// number.
+	AddVersion(ctx context.Context, v *Version) error
+	// LatestVersion returns the newest version, or ErrNotFound when the
+	// workspace has no state.
+	LatestVersion(ctx context.Context, workspace string) (*Version, error)
+	GetVersion(ctx context.Context, workspace string, version int) (*Version, error)
+	// ListVersions returns the workspace's versions, oldest first.
+	ListVersions(ctx context.Context, workspace string) ([]*Version, error)
+	// DeleteVersions deletes the workspace's state.
+	DeleteVersions(ctx context.Context, workspace string) error
+
+	// Lock locks the workspace. When it is already locked it returns the
+	// lock held and ErrLocked.
+	Lock(ctx context.Context, l *Lock) (*Lock, error)
+	// GetLock returns the lock held, or ErrNotFound.
+	GetLock(ctx context.Context, workspace string) (*Lock, error)
+	// Unlock releases the lock with the given ID, or any lock when id is
+	// empty. It returns ErrLocked when the lock held has a different ID and
+	// ErrNotFound when there is none.
+	Unlock(ctx context.Context, workspace, id string) error
+}
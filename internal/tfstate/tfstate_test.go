@@ -0,0 +1,65 @@
+package tfstate
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/testutil"
+)
+
+func TestNewWorkspace(t *testing.T) {
+	ws, password, err := NewWorkspace("shop-web", time.Now())
+	testutil.AssertNoError(t, err, "a valid name should be accepted")
+	testutil.AssertTrue(t, len(password) >= 32, "the password should be long")
+	testutil.AssertTrue(t, ws.CheckPassword(password), "the password should check")
+	testutil.AssertFalse(t, ws.CheckPassword(password+"x"), "other passwords should not check")
+
+	_, other, _ := NewWorkspace("shop-web", time.Now())
+	testutil.AssertNotEqual(t, other, password, "passwords should be random")
+
+	for _, name := range []string{"", "Shop", "-web", "a/b", "../etc"} {
+		_, _, err := NewWorkspace(name, time.Now())
+		testutil.AssertError(t, err, "invalid name "+name+" should be rejected")
+	}
+}
+
+func TestNewVersion(t *testing.T) {
+	data := []byte(`{"version":4,"serial":3,"lineage":"b1f3c2d4","resources":[]}`)
+	v, err := NewVersion("shop-web", data, time.Now())
+	testutil.AssertNoError(t, err, "valid state should be accepted")
+	testutil.AssertEqual(t, v.Serial, int64(3), "the serial should be read")
+	testutil.AssertEqual(t, v.Lineage, "b1f3c2d4", "the lineage should be read")
+	testutil.AssertEqual(t, v.MD5, Checksum(data), "the checksum should be computed")
+
+	_, err = NewVersion("shop-web", []byte("not json"), time.Now())
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidState), "invalid state should be rejected")
+}
+
+func TestVersion_Restore(t *testing.T) {
+	old, err := NewVersion("shop-web", []byte(`{"version":4,"serial":3,"lineage":"b1f3c2d4","resources":[{"type":"null_resource"}]}`), time.Now())
+	testutil.AssertNoError(t, err, "valid state should be accepted")
+
+	restored, err := old.Restore(9, time.Now())
+	testutil.AssertNoError(t, err, "restore should succeed")
+	testutil.AssertEqual(t, restored.Serial, int64(9), "the serial should be raised")
+	testutil.AssertEqual(t, restored.Lineage, old.Lineage, "the lineage should be kept")
+
+	var state struct {
+		Resources []map[string]any `json:"resources"`
+	}
+	testutil.AssertNoError(t, json.Unmarshal(restored.Data, &state), "the restored state should be JSON")
+	testutil.AssertEqual(t, len(state.Resources), 1, "the resources should be kept")
+}
+
+func TestNewLock(t *testing.T) {
+	info := []byte(`{"ID":"f0e1","Operation":"OperationTypePlan","Who":"ci@runner"}`)
+	l, err := NewLock("shop-web", info, time.Now())
+	testutil.AssertNoError(t, err, "valid lock information should be accepted")
+	testutil.AssertEqual(t, l.ID, "f0e1", "the ID should be read")
+	testutil.AssertEqual(t, string(l.Info), string(info), "the information should be kept as is")
+
+	_, err = NewLock("shop-web", []byte(`{"Who":"ci@runner"}`), time.Now())
+	testutil.AssertError(t, err, "lock information without an ID should be rejected")
+}
@@ -0,0 +1,302 @@
+// Package tfstatetest provides a conformance suite for tfstate.Repository
+// implementations.
+package tfstatetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Bermos/Platform/internal/tfstate"
+)
+
+// RunRepositoryTests runs the conformance suite against repositories returned
+// by newRepo. newRepo is called once per subtest and must return an empty
+// repository.
+func RunRepositoryTests(t *testing.T, newRepo func(t *testing.T) tfstate.Repository) {
+	t.Helper()
+
+	t.Run("create_and_get_workspace", func(t *testing.T) {
+		repo := newRepo(t)
+		ws, password := mustCreateWorkspace(t, repo, "shop-web")
+
+		got, err := repo.GetWorkspace(context.Background(), ws.Name)
+		if err != nil {
+			t.Fatalf("GetWorkspace: unexpected error: %v", err)
+		}
+		if got.Name != ws.Name || !got.CreatedAt.Equal(ws.CreatedAt) {
+			t.Errorf("GetWorkspace: got %+v, want %+v", got, ws)
+		}
+		if !got.CheckPassword(password) {
+			t.Error("GetWorkspace: the password hash should be kept")
+		}
+	})
+
+	t.Run("create_duplicate_workspace", func(t *testing.T) {
+		repo := newRepo(t)
+		ws, _ := mustCreateWorkspace(t, repo, "shop-web")
+
+		if err := repo.CreateWorkspace(context.Background(), ws); !errors.Is(err, tfstate.ErrAlreadyExists) {
+			t.Errorf("CreateWorkspace with duplicate name: got %v, want ErrAlreadyExists", err)
+		}
+	})
+
+	t.Run("update_workspace", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		ws, old := mustCreateWorkspace(t, repo, "shop-web")
+		password, err := ws.ResetPassword()
+		if err != nil {
+			t.Fatalf("ResetPassword: unexpected error: %v", err)
+		}
+
+		if err := repo.UpdateWorkspace(ctx, ws); err != nil {
+			t.Fatalf("UpdateWorkspace: unexpected error: %v", err)
+		}
+		got, err := repo.GetWorkspace(ctx, ws.Name)
+		if err != nil {
+			t.Fatalf("GetWorkspace: unexpected error: %v", err)
+		}
+		if !got.CheckPassword(password) || got.CheckPassword(old) {
+			t.Error("GetWorkspace: only the new password should be accepted")
+		}
+		if !got.CreatedAt.Equal(ws.CreatedAt) {
+			t.Errorf("GetWorkspace: CreatedAt = %v, want %v", got.CreatedAt, ws.CreatedAt)
+		}
+
+		missing := &tfstate.Workspace{Name: "missing"}
+		if err := repo.UpdateWorkspace(ctx, missing); !errors.Is(err, tfstate.ErrNotFound) {
+			t.Errorf("UpdateWorkspace of unknown name: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("get_workspace_not_found", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if _, err := repo.GetWorkspace(context.Background(), "missing"); !errors.Is(err, tfstate.ErrNotFound) {
+			t.Errorf("GetWorkspace of unknown name: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("versions_are_numbered_in_order", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		ws, _ := mustCreateWorkspace(t, repo, "shop-web")
+		other, _ := mustCreateWorkspace(t, repo, "shop-db")
+
+		first := mustAddVersion(t, repo, ws.Name, 1)
+		second := mustAddVersion(t, repo, ws.Name, 2)
+		mustAddVersion(t, repo, other.Name, 7)
+		if first.Version != 1 || second.Version != 2 {
+			t.Fatalf("AddVersion: got versions %d and %d, want 1 and 2", first.Version, second.Version)
+		}
+
+		latest, err := repo.LatestVersion(ctx, ws.Name)
+		if err != nil {
+			t.Fatalf("LatestVersion: unexpected error: %v", err)
+		}
+		assertVersion(t, latest, second)
+
+		got, err := repo.GetVersion(ctx, ws.Name, 1)
+		if err != nil {
+			t.Fatalf("GetVersion: unexpected error: %v", err)
+		}
+		assertVersion(t, got, first)
+
+		list, err := repo.ListVersions(ctx, ws.Name)
+		if err != nil {
+			t.Fatalf("ListVersions: unexpected error: %v", err)
+		}
+		if len(list) != 2 {
+			t.Fatalf("ListVersions: got %d versions, want 2", len(list))
+		}
+		assertVersion(t, list[0], first)
+		assertVersion(t, list[1], second)
+	})
+
+	t.Run("versions_not_found", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		ws, _ := mustCreateWorkspace(t, repo, "shop-web")
+
+		if _, err := repo.LatestVersion(ctx, ws.Name); !errors.Is(err, tfstate.ErrNotFound) {
+			t.Errorf("LatestVersion without state: got %v, want ErrNotFound", err)
+		}
+		if _, err := repo.GetVersion(ctx, ws.Name, 1); !errors.Is(err, tfstate.ErrNotFound) {
+			t.Errorf("GetVersion of unknown version: got %v, want ErrNotFound", err)
+		}
+		list, err := repo.ListVersions(ctx, ws.Name)
+		if err != nil || list == nil || len(list) != 0 {
+			t.Errorf("ListVersions without state: got %v, %v, want an empty non-nil slice", list, err)
+		}
+		v, _ := tfstate.NewVersion("missing", []byte(`{"serial":1}`), now())
+		if err := repo.AddVersion(ctx, v); !errors.Is(err, tfstate.ErrNotFound) {
+			t.Errorf("AddVersion to unknown workspace: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("delete_versions", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		ws, _ := mustCreateWorkspace(t, repo, "shop-web")
+		mustAddVersion(t, repo, ws.Name, 1)
+
+		if err := repo.DeleteVersions(ctx, ws.Name); err != nil {
+			t.Fatalf("DeleteVersions: unexpected error: %v", err)
+		}
+		if _, err := repo.LatestVersion(ctx, ws.Name); !errors.Is(err, tfstate.ErrNotFound) {
+			t.Errorf("LatestVersion after DeleteVersions: got %v, want ErrNotFound", err)
+		}
+		if _, err := repo.GetWorkspace(ctx, ws.Name); err != nil {
+			t.Errorf("GetWorkspace after DeleteVersions: unexpected error: %v", err)
+		}
+	})
+
+	t.Run("lock_and_unlock", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		ws, _ := mustCreateWorkspace(t, repo, "shop-web")
+		lock := newLock(t, ws.Name, "lock-1")
+
+		if held, err := repo.Lock(ctx, lock); err != nil || held != nil {
+			t.Fatalf("Lock: got %v, %v, want the lock taken", held, err)
+		}
+		got, err := repo.GetLock(ctx, ws.Name)
+		if err != nil {
+			t.Fatalf("GetLock: unexpected error: %v", err)
+		}
+		assertLock(t, got, lock)
+
+		held, err := repo.Lock(ctx, newLock(t, ws.Name, "lock-2"))
+		if !errors.Is(err, tfstate.ErrLocked) {
+			t.Fatalf("Lock of locked workspace: got %v, want ErrLocked", err)
+		}
+		assertLock(t, held, lock)
+
+		if err := repo.Unlock(ctx, ws.Name, "lock-2"); !errors.Is(err, tfstate.ErrLocked) {
+			t.Errorf("Unlock with another ID: got %v, want ErrLocked", err)
+		}
+		if err := repo.Unlock(ctx, ws.Name, "lock-1"); err != nil {
+			t.Fatalf("Unlock: unexpected error: %v", err)
+		}
+		if _, err := repo.GetLock(ctx, ws.Name); !errors.Is(err, tfstate.ErrNotFound) {
+			t.Errorf("GetLock after Unlock: got %v, want ErrNotFound", err)
+		}
+		if err := repo.Unlock(ctx, ws.Name, "lock-1"); !errors.Is(err, tfstate.ErrNotFound) {
+			t.Errorf("Unlock of unlocked workspace: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("force_unlock", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		ws, _ := mustCreateWorkspace(t, repo, "shop-web")
+		if _, err := repo.Lock(ctx, newLock(t, ws.Name, "lock-1")); err != nil {
+			t.Fatalf("Lock: unexpected error: %v", err)
+		}
+
+		if err := repo.Unlock(ctx, ws.Name, ""); err != nil {
+			t.Fatalf("Unlock without ID: unexpected error: %v", err)
+		}
+		if _, err := repo.GetLock(ctx, ws.Name); !errors.Is(err, tfstate.ErrNotFound) {
+			t.Errorf("GetLock after forced Unlock: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("lock_unknown_workspace", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if _, err := repo.Lock(context.Background(), newLock(t, "missing", "lock-1")); !errors.Is(err, tfstate.ErrNotFound) {
+			t.Errorf("Lock of unknown workspace: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("delete_workspace", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		ws, _ := mustCreateWorkspace(t, repo, "shop-web")
+		mustAddVersion(t, repo, ws.Name, 1)
+		if _, err := repo.Lock(ctx, newLock(t, ws.Name, "lock-1")); err != nil {
+			t.Fatalf("Lock: unexpected error: %v", err)
+		}
+
+		if err := repo.DeleteWorkspace(ctx, ws.Name); err != nil {
+			t.Fatalf("DeleteWorkspace: unexpected error: %v", err)
+		}
+		if _, err := repo.GetWorkspace(ctx, ws.Name); !errors.Is(err, tfstate.ErrNotFound) {
+			t.Errorf("GetWorkspace after delete: got %v, want ErrNotFound", err)
+		}
+		if err := repo.DeleteWorkspace(ctx, ws.Name); !errors.Is(err, tfstate.ErrNotFound) {
+			t.Errorf("DeleteWorkspace twice: got %v, want ErrNotFound", err)
+		}
+
+		// A new workspace of the same name starts afresh.
+		mustCreateWorkspace(t, repo, ws.Name)
+		if _, err := repo.LatestVersion(ctx, ws.Name); !errors.Is(err, tfstate.ErrNotFound) {
+			t.Errorf("LatestVersion of recreated workspace: got %v, want ErrNotFound", err)
+		}
+		if _, err := repo.GetLock(ctx, ws.Name); !errors.Is(err, tfstate.ErrNotFound) {
+			t.Errorf("GetLock of recreated workspace: got %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func mustCreateWorkspace(t *testing.T, repo tfstate.Repository, name string) (*tfstate.Workspace, string) {
+	t.Helper()
+	ws, password, err := tfstate.NewWorkspace(name, now())
+	if err != nil {
+		t.Fatalf("NewWorkspace: unexpected error: %v", err)
+	}
+	if err := repo.CreateWorkspace(context.Background(), ws); err != nil {
+		t.Fatalf("CreateWorkspace: unexpected error: %v", err)
+	}
+	return ws, password
+}
+
+func mustAddVersion(t *testing.T, repo tfstate.Repository, workspace string, serial int) *tfstate.Version {
+	t.Helper()
+	data := fmt.Sprintf(`{"version":4,"serial":%d,"lineage":"b1f3c2d4","resources":[]}`, serial)
+	v, err := tfstate.NewVersion(workspace, []byte(data), now())
+	if err != nil {
+		t.Fatalf("NewVersion: unexpected error: %v", err)
+	}
+	if err := repo.AddVersion(context.Background(), v); err != nil {
+		t.Fatalf("AddVersion: unexpected error: %v", err)
+	}
+	return v
+}
+
+func newLock(t *testing.T, workspace, id string) *tfstate.Lock {
+	t.Helper()
+	l, err := tfstate.NewLock(workspace, []byte(`{"ID":"`+id+`","Operation":"OperationTypeApply","Who":"ci@runner"}`), now())
+	if err != nil {
+		t.Fatalf("NewLock: unexpected error: %v", err)
+	}
+	return l
+}
+
+func assertVersion(t *testing.T, got, want *tfstate.Version) {
+	t.Helper()
+	if got.Workspace != want.Workspace || got.Version != want.Version || got.Serial != want.Serial ||
+		got.Lineage != want.Lineage || got.MD5 != want.MD5 || string(got.Data) != string(want.Data) ||
+		!got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("version: got %+v, want %+v", got, want)
+	}
+}
+
+func assertLock(t *testing.T, got, want *tfstate.Lock) {
+	t.Helper()
+	if got == nil {
+		t.Fatal("lock: got nil")
+	}
+	if got.Workspace != want.Workspace || got.ID != want.ID || string(got.Info) != string(want.Info) ||
+		!got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("lock: got %+v, want %+v", got, want)
+	}
+}